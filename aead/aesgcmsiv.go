@@ -6,6 +6,8 @@ import (
 	"crypto/subtle"
 	"encoding/binary"
 	"errors"
+
+	"github.com/AeonDave/cryptonite-go/internal/polyval"
 )
 
 const (
@@ -107,14 +109,16 @@ func deriveGCMSIVKeys(masterKey, nonce []byte) ([aesGCMSIVTagSize]byte, []byte,
 }
 
 func polyvalDigest(authKey [aesGCMSIVTagSize]byte, ad, plaintext []byte) [aesGCMSIVTagSize]byte {
-	state := newPolyval(authKey)
-	state.update(ad)
-	state.update(plaintext)
+	state := polyval.New(&authKey)
+	state.Write(ad)
+	state.Pad()
+	state.Write(plaintext)
+	state.Pad()
 	var lengthBlock [aesGCMSIVTagSize]byte
 	binary.LittleEndian.PutUint64(lengthBlock[:8], uint64(len(ad))*8)
 	binary.LittleEndian.PutUint64(lengthBlock[8:], uint64(len(plaintext))*8)
-	state.update(lengthBlock[:])
-	return state.finish()
+	state.Write(lengthBlock[:])
+	return state.Sum()
 }
 
 func streamXORCipher(block cipher.Block, tag [aesGCMSIVTagSize]byte, src []byte, dst []byte) {
@@ -149,110 +153,3 @@ func bytesToArray16(in []byte) [aesGCMSIVTagSize]byte {
 	copy(out[:], in)
 	return out
 }
-
-type polyvalState struct {
-	key gf128
-	acc gf128
-}
-
-func newPolyval(keyBytes [aesGCMSIVTagSize]byte) *polyvalState {
-	return &polyvalState{
-		key: gf128{
-			lo: binary.LittleEndian.Uint64(keyBytes[:8]),
-			hi: binary.LittleEndian.Uint64(keyBytes[8:]),
-		},
-	}
-}
-
-func (p *polyvalState) update(data []byte) {
-	for len(data) >= aesGCMSIVTagSize {
-		p.mixBlock(data[:aesGCMSIVTagSize])
-		data = data[aesGCMSIVTagSize:]
-	}
-	if len(data) > 0 {
-		var block [aesGCMSIVTagSize]byte
-		copy(block[:], data)
-		p.mixBlock(block[:])
-	}
-}
-
-func (p *polyvalState) mixBlock(block []byte) {
-	p.acc.lo ^= binary.LittleEndian.Uint64(block[:8])
-	p.acc.hi ^= binary.LittleEndian.Uint64(block[8:])
-	p.acc = polyvalMul(p.acc, p.key)
-}
-
-func (p *polyvalState) finish() [aesGCMSIVTagSize]byte {
-	var out [aesGCMSIVTagSize]byte
-	binary.LittleEndian.PutUint64(out[:8], p.acc.lo)
-	binary.LittleEndian.PutUint64(out[8:], p.acc.hi)
-	return out
-}
-
-type gf128 struct {
-	lo uint64
-	hi uint64
-}
-
-func polyvalMul(a, b gf128) gf128 {
-	loProd := clMul64(a.lo, b.lo)
-	hiProd := clMul64(a.hi, b.hi)
-	mid := clMul64(a.lo^a.hi, b.lo^b.hi)
-	mid.lo ^= loProd.lo ^ hiProd.lo
-	mid.hi ^= loProd.hi ^ hiProd.hi
-	hiProd.lo ^= mid.hi
-	loProd.hi ^= mid.lo
-	loProd.hi ^= (loProd.lo << 63) ^ (loProd.lo << 62) ^ (loProd.lo << 57)
-	hiProd.lo ^= loProd.lo
-	hiProd.hi ^= loProd.hi
-	hiProd.lo ^= loProd.lo >> 1
-	hiProd.lo ^= loProd.hi << 63
-	hiProd.hi ^= loProd.hi >> 1
-	hiProd.lo ^= loProd.lo >> 2
-	hiProd.lo ^= loProd.hi << 62
-	hiProd.hi ^= loProd.hi >> 2
-	hiProd.lo ^= loProd.lo >> 7
-	hiProd.lo ^= loProd.hi << 57
-	hiProd.hi ^= loProd.hi >> 7
-	return hiProd
-}
-
-func clMul64(x, y uint64) gf128 {
-	x0 := uint32(x)
-	x1 := uint32(x >> 32)
-	y0 := uint32(y)
-	y1 := uint32(y >> 32)
-	p0 := clMul32(x0, y0)
-	p1 := clMul32(x1, y1)
-	pMid := clMul32(x0^x1, y0^y1) ^ p0 ^ p1
-	return gf128{
-		lo: p0 ^ (pMid << 32),
-		hi: p1 ^ (pMid >> 32),
-	}
-}
-
-func clMul32(x, y uint32) uint64 {
-	var result uint64
-	var base uint64 = uint64(x)
-	for shift := 0; shift < 32; shift += 4 {
-		nib := (uint64(y) >> shift) & 0xF
-		if nib == 0 {
-			continue
-		}
-		var partial uint64
-		if nib&0x1 != 0 {
-			partial ^= base
-		}
-		if nib&0x2 != 0 {
-			partial ^= base << 1
-		}
-		if nib&0x4 != 0 {
-			partial ^= base << 2
-		}
-		if nib&0x8 != 0 {
-			partial ^= base << 3
-		}
-		result ^= partial << shift
-	}
-	return result
-}
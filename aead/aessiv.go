@@ -5,6 +5,8 @@ import (
 	"crypto/cipher"
 	"crypto/subtle"
 	"errors"
+
+	"github.com/AeonDave/cryptonite-go/internal/cmac"
 )
 
 const aesSIVTagSize = 16
@@ -135,109 +137,31 @@ func computeS2V(macKey, nonce []byte, ad [][]byte, plaintext []byte) ([aesSIVTag
 }
 
 func s2v(macKey []byte, ad [][]byte, plaintext []byte) ([aesSIVTagSize]byte, error) {
-	cm, err := newCMAC(macKey)
+	block, err := aes.NewCipher(macKey)
 	if err != nil {
 		return [aesSIVTagSize]byte{}, err
 	}
+	cm := cmac.New(block)
 	var zero [aesSIVTagSize]byte
-	d := cm.sum(zero[:])
+	d := cm.Sum(zero[:])
 	for _, s := range ad {
-		t := cm.sum(s)
-		d = dblBlock16(d)
+		t := cm.Sum(s)
+		d = cmac.Double(d)
 		d = xorBlock16(d, t)
 	}
 	if len(plaintext) >= aesSIVTagSize {
-		var mask [aesSIVTagSize]byte = d
-		return cm.sumWithLastMask(plaintext, mask), nil
+		// xorend: mask the last block of the plaintext with D.
+		buf := make([]byte, len(plaintext))
+		copy(buf, plaintext)
+		xorBytes(buf[len(buf)-aesSIVTagSize:], d[:])
+		return cm.Sum(buf), nil
 	}
-	dbl := dblBlock16(d)
+	dbl := cmac.Double(d)
 	var buf [aesSIVTagSize]byte
 	copy(buf[:], plaintext)
 	buf[len(plaintext)] = 0x80
 	xorBytes(buf[:], dbl[:])
-	return cm.sum(buf[:]), nil
-}
-
-type cmacState struct {
-	block cipher.Block
-	k1    [aesSIVTagSize]byte
-	k2    [aesSIVTagSize]byte
-}
-
-func newCMAC(key []byte) (*cmacState, error) {
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, err
-	}
-	var l [aesSIVTagSize]byte
-	block.Encrypt(l[:], l[:])
-	k1 := dblBlock16(l)
-	k2 := dblBlock16(k1)
-	return &cmacState{block: block, k1: k1, k2: k2}, nil
-}
-
-func (c *cmacState) sum(msg []byte) [aesSIVTagSize]byte {
-	var state [aesSIVTagSize]byte
-	n := len(msg)
-	if n == 0 {
-		var last [aesSIVTagSize]byte
-		last[0] = 0x80
-		xorBytes(last[:], c.k2[:])
-		for i := 0; i < aesSIVTagSize; i++ {
-			state[i] ^= last[i]
-		}
-		c.block.Encrypt(state[:], state[:])
-		return state
-	}
-	blocks := (n + aesSIVTagSize - 1) / aesSIVTagSize
-	for i := 0; i < blocks-1; i++ {
-		offset := i * aesSIVTagSize
-		for j := 0; j < aesSIVTagSize; j++ {
-			state[j] ^= msg[offset+j]
-		}
-		c.block.Encrypt(state[:], state[:])
-	}
-	offset := (blocks - 1) * aesSIVTagSize
-	var last [aesSIVTagSize]byte
-	if n%aesSIVTagSize == 0 {
-		copy(last[:], msg[offset:offset+aesSIVTagSize])
-		xorBytes(last[:], c.k1[:])
-	} else {
-		copy(last[:], msg[offset:])
-		last[n-offset] = 0x80
-		xorBytes(last[:], c.k2[:])
-	}
-	for i := 0; i < aesSIVTagSize; i++ {
-		state[i] ^= last[i]
-	}
-	c.block.Encrypt(state[:], state[:])
-	return state
-}
-
-func (c *cmacState) sumWithLastMask(msg []byte, mask [aesSIVTagSize]byte) [aesSIVTagSize]byte {
-	if len(msg) < aesSIVTagSize {
-		return c.sum(msg)
-	}
-	buf := make([]byte, len(msg))
-	copy(buf, msg)
-	offset := len(buf) - aesSIVTagSize
-	xorBytes(buf[offset:], mask[:])
-	return c.sum(buf)
-}
-
-func dblBlock16(in [aesSIVTagSize]byte) [aesSIVTagSize]byte {
-	var out [aesSIVTagSize]byte
-	msb := in[0] >> 7
-	var carry byte
-	for i := aesSIVTagSize - 1; i >= 0; i-- {
-		b := in[i]
-		out[i] = (b << 1) | carry
-		carry = b >> 7
-	}
-	if msb != 0 {
-		out[aesSIVTagSize-1] ^= 0x87
-	}
-	return out
+	return cm.Sum(buf[:]), nil
 }
 
 func xorBlock16(a, b [aesSIVTagSize]byte) [aesSIVTagSize]byte {
@@ -0,0 +1,152 @@
+// Package csprng implements a user-space cryptographically secure random
+// number generator: ChaCha20 with fast key erasure.
+//
+// Each refill computes one 768-byte ChaCha20 keystream buffer under the
+// current key with an all-zero nonce. The first 32 bytes replace the key and
+// the rest is handed out, with every byte wiped from the buffer as it is
+// consumed, so a later compromise of the state reveals nothing about earlier
+// output. Generators returned by New mix fresh crypto/rand entropy into the
+// key every ReseedInterval output bytes. Generators returned by NewSeeded
+// never reseed and produce a reproducible stream, and implement
+// math/rand/v2.Source for seedable simulation randomness.
+//
+// Reader and Read serve concurrent callers from a pool of generators, so
+// goroutines never share one and most requests cost no system call.
+package csprng
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	mathrand "math/rand/v2"
+	"sync"
+
+	"github.com/AeonDave/cryptonite-go/internal/chacha20"
+)
+
+const (
+	// SeedSize is the size of a generator key in bytes.
+	SeedSize = chacha20.KeySize
+	// ReseedInterval is the number of output bytes after which generators
+	// returned by New mix fresh crypto/rand entropy into their key.
+	ReseedInterval = 1 << 20
+
+	bufSize = 12 * chacha20.BlockSize
+)
+
+var _ mathrand.Source = (*Generator)(nil)
+
+// Generator is a fast-key-erasure ChaCha20 generator. A Generator is not
+// safe for concurrent use; share Reader instead or give each goroutine its
+// own.
+type Generator struct {
+	key       [SeedSize]byte
+	buf       [bufSize]byte
+	pos       int
+	reseeding bool
+	seeded    bool
+	generated int
+}
+
+// New returns a generator keyed from crypto/rand that reseeds every
+// ReseedInterval output bytes. The key is drawn on first use, so entropy
+// source failures surface from Read.
+func New() *Generator {
+	return &Generator{pos: bufSize, reseeding: true}
+}
+
+// NewSeeded returns a deterministic generator keyed with seed. It never
+// reseeds: the same seed always yields the same stream, which makes it
+// suitable for reproducible simulations and tests but not for secrets
+// unless seed is itself secret and uniformly random.
+func NewSeeded(seed [SeedSize]byte) *Generator {
+	g := &Generator{}
+	g.Seed(seed)
+	return g
+}
+
+// Seed discards any buffered output and rekeys g deterministically with
+// seed, turning off reseeding.
+func (g *Generator) Seed(seed [SeedSize]byte) {
+	clear(g.buf[:])
+	g.key = seed
+	g.pos = bufSize
+	g.reseeding = false
+	g.seeded = true
+	g.generated = 0
+}
+
+// Read fills p with random bytes. It returns an error only when a
+// reseeding generator cannot read from crypto/rand.
+func (g *Generator) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if g.pos == bufSize {
+			if err := g.refill(); err != nil {
+				return n, err
+			}
+		}
+		c := copy(p[n:], g.buf[g.pos:])
+		clear(g.buf[g.pos : g.pos+c])
+		g.pos += c
+		n += c
+	}
+	return n, nil
+}
+
+// Uint64 returns 64 random bits, the next eight output bytes in
+// little-endian order. It implements math/rand/v2.Source and panics if a
+// reseeding generator cannot read from crypto/rand.
+func (g *Generator) Uint64() uint64 {
+	var b [8]byte
+	if _, err := g.Read(b[:]); err != nil {
+		panic("csprng: entropy source failed: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// refill rekeys the generator and refills the output buffer, first mixing
+// in fresh entropy when the generator is due for a reseed.
+func (g *Generator) refill() error {
+	if g.reseeding && (!g.seeded || g.generated >= ReseedInterval) {
+		var fresh [SeedSize]byte
+		if _, err := io.ReadFull(rand.Reader, fresh[:]); err != nil {
+			return err
+		}
+		for i := range g.key {
+			g.key[i] ^= fresh[i]
+		}
+		clear(fresh[:])
+		g.seeded = true
+		g.generated = 0
+	}
+	var nonce [chacha20.NonceSize]byte
+	// The buffer is all zero here, so XORing yields the raw keystream.
+	chacha20.XORKeyStream(g.buf[:], g.buf[:], g.key[:], nonce[:], 0)
+	copy(g.key[:], g.buf[:SeedSize])
+	clear(g.buf[:SeedSize])
+	g.pos = SeedSize
+	g.generated += bufSize - SeedSize
+	return nil
+}
+
+var pool = sync.Pool{New: func() any { return New() }}
+
+type reader struct{}
+
+func (reader) Read(p []byte) (int, error) {
+	g := pool.Get().(*Generator)
+	defer pool.Put(g)
+	return g.Read(p)
+}
+
+// Reader is a global, concurrency-safe source of cryptographically secure
+// random bytes backed by pooled reseeding generators, one per concurrent
+// caller. It is a drop-in replacement for crypto/rand.Reader where syscall
+// overhead matters.
+var Reader io.Reader = reader{}
+
+// Read fills b from Reader.
+func Read(b []byte) (int, error) {
+	return Reader.Read(b)
+}
@@ -0,0 +1,168 @@
+package drbg
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+)
+
+const (
+	ctrKeyLen  = 32
+	ctrSeedLen = ctrKeyLen + aes.BlockSize
+)
+
+// ctrDRBG is CTR_DRBG with AES-256 and a 128-bit counter (SP 800-90A,
+// Section 10.2.1).
+type ctrDRBG struct {
+	block cipher.Block
+	v     [aes.BlockSize]byte
+	df    bool
+}
+
+// NewCTR returns a CTR_DRBG over AES-256 that uses the block cipher
+// derivation function, so entropy, nonce, personalization and additional
+// inputs may have any length.
+func NewCTR(p Params) (DRBG, error) {
+	return newGenerator(p, limits{strength: 32, entropyLen: 32, nonceLen: 16}, func(entropy, nonce, pers []byte) mechanism {
+		d := &ctrDRBG{df: true}
+		d.init(blockCipherDF(ctrSeedLen, entropy, nonce, pers))
+		return d
+	})
+}
+
+// NewCTRNoDF returns a CTR_DRBG over AES-256 without derivation function,
+// the variant used by the NIST PQC known-answer test generator. Entropy
+// inputs must be exactly 48 bytes of full entropy and personalization and
+// additional inputs at most 48 bytes; no nonce is used.
+func NewCTRNoDF(p Params) (DRBG, error) {
+	lim := limits{strength: 32, entropyLen: ctrSeedLen, exactEntropy: true, maxInput: ctrSeedLen}
+	return newGenerator(p, lim, func(entropy, _, pers []byte) mechanism {
+		d := &ctrDRBG{}
+		d.init(xorPadded(entropy, pers))
+		return d
+	})
+}
+
+func (d *ctrDRBG) init(seed []byte) {
+	d.block, _ = aes.NewCipher(make([]byte, ctrKeyLen))
+	d.update(seed)
+}
+
+// update is CTR_DRBG_Update; provided is nil or exactly ctrSeedLen bytes.
+func (d *ctrDRBG) update(provided []byte) {
+	var temp [ctrSeedLen]byte
+	for i := 0; i < ctrSeedLen; i += aes.BlockSize {
+		d.increment()
+		d.block.Encrypt(temp[i:], d.v[:])
+	}
+	for i := range provided {
+		temp[i] ^= provided[i]
+	}
+	d.block, _ = aes.NewCipher(temp[:ctrKeyLen])
+	copy(d.v[:], temp[ctrKeyLen:])
+}
+
+func (d *ctrDRBG) increment() {
+	for i := len(d.v) - 1; i >= 0; i-- {
+		d.v[i]++
+		if d.v[i] != 0 {
+			return
+		}
+	}
+}
+
+// seedMaterial conditions entropy and additional input into ctrSeedLen
+// bytes, with the derivation function or by XOR.
+func (d *ctrDRBG) seedMaterial(parts ...[]byte) []byte {
+	if d.df {
+		return blockCipherDF(ctrSeedLen, parts...)
+	}
+	if len(parts) == 1 {
+		return xorPadded(nil, parts[0])
+	}
+	return xorPadded(parts[0], parts[1])
+}
+
+func (d *ctrDRBG) reseed(entropy, additional []byte) {
+	d.update(d.seedMaterial(entropy, additional))
+}
+
+func (d *ctrDRBG) generate(out, additional []byte) {
+	var seed []byte
+	if len(additional) > 0 {
+		seed = d.seedMaterial(additional)
+		d.update(seed)
+	}
+	var block [aes.BlockSize]byte
+	for len(out) > 0 {
+		d.increment()
+		d.block.Encrypt(block[:], d.v[:])
+		out = out[copy(out, block[:]):]
+	}
+	d.update(seed)
+}
+
+// xorPadded returns a XOR b, both zero-padded to ctrSeedLen bytes.
+func xorPadded(a, b []byte) []byte {
+	out := make([]byte, ctrSeedLen)
+	copy(out, a)
+	for i := range b {
+		out[i] ^= b[i]
+	}
+	return out
+}
+
+// blockCipherDF is Block_Cipher_df with AES-256 over the concatenation of
+// parts, returning n bytes.
+func blockCipherDF(n int, parts ...[]byte) []byte {
+	total := 0
+	for _, p := range parts {
+		total += len(p)
+	}
+	s := make([]byte, 8, 8+total+aes.BlockSize)
+	binary.BigEndian.PutUint32(s, uint32(total))
+	binary.BigEndian.PutUint32(s[4:], uint32(n))
+	for _, p := range parts {
+		s = append(s, p...)
+	}
+	s = append(s, 0x80)
+	for len(s)%aes.BlockSize != 0 {
+		s = append(s, 0)
+	}
+
+	key := make([]byte, ctrKeyLen)
+	for i := range key {
+		key[i] = byte(i)
+	}
+	k, _ := aes.NewCipher(key)
+	temp := make([]byte, 0, ctrSeedLen)
+	var iv [aes.BlockSize]byte
+	for i := uint32(0); len(temp) < ctrSeedLen; i++ {
+		binary.BigEndian.PutUint32(iv[:], i)
+		temp = append(temp, bcc(k, iv[:], s)...)
+	}
+
+	k, _ = aes.NewCipher(temp[:ctrKeyLen])
+	x := temp[ctrKeyLen:ctrSeedLen]
+	out := make([]byte, 0, n+aes.BlockSize)
+	for len(out) < n {
+		k.Encrypt(x, x)
+		out = append(out, x...)
+	}
+	return out[:n]
+}
+
+// bcc is the CBC-MAC of iv || data under k, with data a multiple of the
+// block size.
+func bcc(k cipher.Block, iv, data []byte) []byte {
+	chain := make([]byte, aes.BlockSize)
+	for _, blk := range [][]byte{iv, data} {
+		for i := 0; i < len(blk); i += aes.BlockSize {
+			for j := 0; j < aes.BlockSize; j++ {
+				chain[j] ^= blk[i+j]
+			}
+			k.Encrypt(chain, chain)
+		}
+	}
+	return chain
+}
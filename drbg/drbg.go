@@ -0,0 +1,249 @@
+// Package drbg implements the deterministic random bit generators of NIST
+// SP 800-90A Rev. 1: HMAC_DRBG, Hash_DRBG and CTR_DRBG with AES-256, the
+// latter with or without the block cipher derivation function.
+//
+// A DRBG is seeded from an entropy source (crypto/rand unless Params says
+// otherwise) or from explicit entropy input, which makes its output
+// reproducible for known-answer tests and simulations. Generators reseed
+// automatically after Params.ReseedInterval requests, support prediction
+// resistance and additional input, and implement io.Reader.
+package drbg
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+)
+
+const (
+	// MaxRequestSize is the largest output of a single Generate call
+	// (2^19 bits). Read splits longer requests.
+	MaxRequestSize = 1 << 16
+	// MaxReseedInterval is the largest reseed interval SP 800-90A allows
+	// (2^48 requests); it is also the default.
+	MaxReseedInterval = 1 << 48
+)
+
+var (
+	errRequestTooLarge = errors.New("drbg: request exceeds MaxRequestSize")
+	errInputTooLong    = errors.New("drbg: additional input or personalization string too long")
+	errEntropyLength   = errors.New("drbg: entropy input has the wrong length")
+	errNonceLength     = errors.New("drbg: nonce too short")
+	errReseedInterval  = errors.New("drbg: reseed interval exceeds MaxReseedInterval")
+)
+
+// DRBG is an SP 800-90A deterministic random bit generator. Implementations
+// are safe for concurrent use.
+type DRBG interface {
+	// Read fills p with pseudorandom bytes, issuing one Generate request
+	// without additional input per MaxRequestSize bytes.
+	Read(p []byte) (int, error)
+	// Generate fills out (at most MaxRequestSize bytes) with pseudorandom
+	// bytes, mixing in the optional additional input.
+	Generate(out, additionalInput []byte) error
+	// Reseed draws fresh entropy from the entropy source and mixes it into
+	// the state together with the optional additional input.
+	Reseed(additionalInput []byte) error
+	// ReseedWithEntropy reseeds from caller-supplied entropy input.
+	ReseedWithEntropy(entropyInput, additionalInput []byte) error
+	// SecurityStrength returns the security strength in bits.
+	SecurityStrength() int
+}
+
+// Params configures instantiation. The zero value seeds the generator from
+// crypto/rand with the maximum reseed interval and no prediction resistance.
+type Params struct {
+	// Entropy is the entropy source used for instantiation when EntropyInput
+	// is nil, for automatic reseeding and for prediction resistance. Nil
+	// selects crypto/rand.
+	Entropy io.Reader
+	// EntropyInput and Nonce seed the generator explicitly. When nil they
+	// are read from Entropy: security strength bits of entropy (384 bits for
+	// CTR_DRBG without derivation function) and half as many of nonce.
+	// CTR_DRBG without derivation function uses no nonce.
+	EntropyInput []byte
+	Nonce        []byte
+	// Personalization is mixed into the initial state. CTR_DRBG without
+	// derivation function accepts at most 48 bytes.
+	Personalization []byte
+	// ReseedInterval is the number of Generate requests after which the
+	// generator reseeds from Entropy. Zero selects MaxReseedInterval.
+	ReseedInterval uint64
+	// PredictionResistance reseeds from Entropy before every request.
+	PredictionResistance bool
+}
+
+// mechanism is the state and update functions of one DRBG construction.
+type mechanism interface {
+	reseed(entropy, additional []byte)
+	generate(out, additional []byte)
+}
+
+// limits describes the input sizes of a mechanism, in bytes.
+type limits struct {
+	strength   int
+	entropyLen int
+	nonceLen   int
+	// exactEntropy requires entropy inputs of exactly entropyLen bytes.
+	exactEntropy bool
+	// maxInput bounds additional input and personalization; zero means
+	// unbounded.
+	maxInput int
+}
+
+type generator struct {
+	mu       sync.Mutex
+	m        mechanism
+	lim      limits
+	source   io.Reader
+	interval uint64
+	counter  uint64
+	pr       bool
+}
+
+func newGenerator(p Params, lim limits, instantiate func(entropy, nonce, pers []byte) mechanism) (*generator, error) {
+	g := &generator{lim: lim, source: p.Entropy, interval: p.ReseedInterval, pr: p.PredictionResistance, counter: 1}
+	if g.source == nil {
+		g.source = rand.Reader
+	}
+	if g.interval == 0 {
+		g.interval = MaxReseedInterval
+	}
+	if g.interval > MaxReseedInterval {
+		return nil, errReseedInterval
+	}
+	if err := g.checkInput(p.Personalization); err != nil {
+		return nil, err
+	}
+	entropy := p.EntropyInput
+	if entropy == nil {
+		var err error
+		if entropy, err = g.readEntropy(lim.entropyLen); err != nil {
+			return nil, err
+		}
+	} else if err := g.checkEntropy(entropy); err != nil {
+		return nil, err
+	}
+	var nonce []byte
+	if lim.nonceLen > 0 {
+		nonce = p.Nonce
+		if nonce == nil {
+			var err error
+			if nonce, err = g.readEntropy(lim.nonceLen); err != nil {
+				return nil, err
+			}
+		} else if len(nonce) < lim.nonceLen {
+			return nil, errNonceLength
+		}
+	}
+	g.m = instantiate(entropy, nonce, p.Personalization)
+	return g, nil
+}
+
+func (g *generator) checkInput(b []byte) error {
+	if g.lim.maxInput > 0 && len(b) > g.lim.maxInput {
+		return errInputTooLong
+	}
+	return nil
+}
+
+func (g *generator) checkEntropy(b []byte) error {
+	if len(b) < g.lim.entropyLen || g.lim.exactEntropy && len(b) != g.lim.entropyLen {
+		return errEntropyLength
+	}
+	return nil
+}
+
+func (g *generator) readEntropy(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(g.source, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func (g *generator) SecurityStrength() int { return 8 * g.lim.strength }
+
+func (g *generator) Generate(out, additionalInput []byte) error {
+	if len(out) > MaxRequestSize {
+		return errRequestTooLarge
+	}
+	if err := g.checkInput(additionalInput); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.generateLocked(out, additionalInput)
+}
+
+func (g *generator) generateLocked(out, additional []byte) error {
+	if g.pr || g.counter > g.interval {
+		entropy, err := g.readEntropy(g.lim.entropyLen)
+		if err != nil {
+			return err
+		}
+		g.m.reseed(entropy, additional)
+		g.counter = 1
+		additional = nil
+	}
+	g.m.generate(out, additional)
+	g.counter++
+	return nil
+}
+
+func (g *generator) Read(p []byte) (int, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n := 0
+	for n < len(p) {
+		chunk := min(len(p)-n, MaxRequestSize)
+		if err := g.generateLocked(p[n:n+chunk], nil); err != nil {
+			return n, err
+		}
+		n += chunk
+	}
+	return n, nil
+}
+
+func (g *generator) Reseed(additionalInput []byte) error {
+	if err := g.checkInput(additionalInput); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entropy, err := g.readEntropy(g.lim.entropyLen)
+	if err != nil {
+		return err
+	}
+	g.m.reseed(entropy, additionalInput)
+	g.counter = 1
+	return nil
+}
+
+func (g *generator) ReseedWithEntropy(entropyInput, additionalInput []byte) error {
+	if err := g.checkEntropy(entropyInput); err != nil {
+		return err
+	}
+	if err := g.checkInput(additionalInput); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.m.reseed(entropyInput, additionalInput)
+	g.counter = 1
+	return nil
+}
+
+// strengthOf returns the security strength in bytes of a hash-based DRBG
+// with the given digest size (SP 800-57 Part 1, Table 3).
+func strengthOf(size int) int {
+	switch {
+	case size <= 20:
+		return 16
+	case size <= 28:
+		return 24
+	default:
+		return 32
+	}
+}
@@ -0,0 +1,102 @@
+package drbg
+
+import (
+	"encoding/binary"
+	stdhash "hash"
+)
+
+// hashDRBG is Hash_DRBG (SP 800-90A, Section 10.1.1).
+type hashDRBG struct {
+	h       func() stdhash.Hash
+	v, c    []byte
+	counter uint64
+}
+
+// NewHash returns a Hash_DRBG over h, for example hash.NewSHA256 or
+// hash.NewSHA512. Its security strength follows the digest size as for
+// NewHMAC; the seed length is 440 bits for digests of up to 256 bits and
+// 888 bits otherwise.
+func NewHash(h func() stdhash.Hash, p Params) (DRBG, error) {
+	size := h().Size()
+	s := strengthOf(size)
+	seedLen := 55
+	if size > 32 {
+		seedLen = 111
+	}
+	return newGenerator(p, limits{strength: s, entropyLen: s, nonceLen: s / 2}, func(entropy, nonce, pers []byte) mechanism {
+		d := &hashDRBG{h: h}
+		d.v = d.df(seedLen, entropy, nonce, pers)
+		d.c = d.df(seedLen, []byte{0x00}, d.v)
+		d.counter = 1
+		return d
+	})
+}
+
+// df is Hash_df, returning n bytes derived from the concatenation of parts.
+func (d *hashDRBG) df(n int, parts ...[]byte) []byte {
+	h := d.h()
+	out := make([]byte, 0, n+h.Size())
+	var prefix [5]byte
+	binary.BigEndian.PutUint32(prefix[1:], uint32(8*n))
+	for counter := byte(1); len(out) < n; counter++ {
+		prefix[0] = counter
+		h.Reset()
+		h.Write(prefix[:])
+		for _, p := range parts {
+			h.Write(p)
+		}
+		out = h.Sum(out)
+	}
+	return out[:n]
+}
+
+func (d *hashDRBG) reseed(entropy, additional []byte) {
+	d.v = d.df(len(d.v), []byte{0x01}, d.v, entropy, additional)
+	d.c = d.df(len(d.v), []byte{0x00}, d.v)
+	d.counter = 1
+}
+
+func (d *hashDRBG) generate(out, additional []byte) {
+	h := d.h()
+	if len(additional) > 0 {
+		h.Write([]byte{0x02})
+		h.Write(d.v)
+		h.Write(additional)
+		addBE(d.v, h.Sum(nil))
+	}
+
+	// Hashgen
+	data := append([]byte(nil), d.v...)
+	var block []byte
+	for len(out) > 0 {
+		h.Reset()
+		h.Write(data)
+		block = h.Sum(block[:0])
+		out = out[copy(out, block):]
+		addBE(data, []byte{1})
+	}
+
+	h.Reset()
+	h.Write([]byte{0x03})
+	h.Write(d.v)
+	addBE(d.v, h.Sum(nil))
+	addBE(d.v, d.c)
+	var ctr [8]byte
+	binary.BigEndian.PutUint64(ctr[:], d.counter)
+	addBE(d.v, ctr[:])
+	d.counter++
+}
+
+// addBE sets x = (x + y) mod 2^(8*len(x)), with both as big-endian integers
+// and len(y) <= len(x).
+func addBE(x, y []byte) {
+	var carry uint16
+	for i, j := len(x)-1, len(y)-1; i >= 0; i, j = i-1, j-1 {
+		s := uint16(x[i]) + carry
+		if j >= 0 {
+			s += uint16(y[j])
+		}
+		x[i] = byte(s)
+		carry = s >> 8
+	}
+}
@@ -0,0 +1,70 @@
+package drbg
+
+import (
+	"crypto/hmac"
+	stdhash "hash"
+)
+
+// hmacDRBG is HMAC_DRBG (SP 800-90A, Section 10.1.2).
+type hmacDRBG struct {
+	h    func() stdhash.Hash
+	k, v []byte
+}
+
+// NewHMAC returns an HMAC_DRBG over h, for example hash.NewSHA256 or
+// hash.NewSHA512. Its security strength follows the digest size: 128 bits
+// for SHA-1, 192 for SHA-224 and 256 for larger digests.
+func NewHMAC(h func() stdhash.Hash, p Params) (DRBG, error) {
+	size := h().Size()
+	s := strengthOf(size)
+	return newGenerator(p, limits{strength: s, entropyLen: s, nonceLen: s / 2}, func(entropy, nonce, pers []byte) mechanism {
+		d := &hmacDRBG{h: h, k: make([]byte, size), v: make([]byte, size)}
+		for i := range d.v {
+			d.v[i] = 0x01
+		}
+		d.update(entropy, nonce, pers)
+		return d
+	})
+}
+
+// update is HMAC_DRBG_Update with provided data given as the concatenation
+// of parts.
+func (d *hmacDRBG) update(parts ...[]byte) {
+	empty := true
+	for _, p := range parts {
+		empty = empty && len(p) == 0
+	}
+	for _, sep := range []byte{0x00, 0x01} {
+		m := hmac.New(d.h, d.k)
+		m.Write(d.v)
+		m.Write([]byte{sep})
+		for _, p := range parts {
+			m.Write(p)
+		}
+		d.k = m.Sum(d.k[:0])
+		m = hmac.New(d.h, d.k)
+		m.Write(d.v)
+		d.v = m.Sum(d.v[:0])
+		if empty {
+			return
+		}
+	}
+}
+
+func (d *hmacDRBG) reseed(entropy, additional []byte) {
+	d.update(entropy, additional)
+}
+
+func (d *hmacDRBG) generate(out, additional []byte) {
+	if len(additional) > 0 {
+		d.update(additional)
+	}
+	m := hmac.New(d.h, d.k)
+	for len(out) > 0 {
+		m.Reset()
+		m.Write(d.v)
+		d.v = m.Sum(d.v[:0])
+		out = out[copy(out, d.v):]
+	}
+	d.update(additional)
+}
@@ -0,0 +1,138 @@
+// Package duplex exposes stateful duplex objects for building session
+// protocols, transcripts and custom modes on top of a permutation.
+//
+// Cyclist is the Xoodyak mode of use: an unkeyed Cyclist hashes whatever it
+// absorbs, and a keyed Cyclist additionally encrypts, derives keys and
+// ratchets. Strobe is the STROBE protocol framework over Keccak-f[1600], and
+// Transcript builds Merlin Fiat–Shamir transcripts on STROBE-128. Every
+// output depends on the full sequence of operations, so two parties running
+// the same sequence stay in sync and any divergence shows up in the next
+// output.
+package duplex
+
+import (
+	"errors"
+
+	"github.com/AeonDave/cryptonite-go/internal/xoodyak"
+)
+
+const (
+	// CyclistMaxKeyIDSize bounds len(key)+len(id) for NewCyclist.
+	CyclistMaxKeyIDSize = xoodyak.KeyRate - 1
+	// CyclistRatchetSize is the number of bytes Ratchet squeezes and
+	// re-absorbs.
+	CyclistRatchetSize = xoodyak.LRatchet
+)
+
+var (
+	errEmptyKey  = errors.New("duplex: Cyclist key must not be empty; use NewCyclistHash")
+	errKeyIDSize = errors.New("duplex: len(key)+len(id) exceeds CyclistMaxKeyIDSize")
+	errNotKeyed  = errors.New("duplex: operation requires a keyed Cyclist")
+	errLength    = errors.New("duplex: dst and src lengths differ")
+)
+
+// Cyclist is a Xoodyak duplex session. It is not safe for concurrent use.
+type Cyclist struct {
+	inst xoodyak.Instance
+}
+
+// NewCyclistHash returns an unkeyed Cyclist. It supports Absorb and Squeeze
+// only; absorbing a message and squeezing 32 bytes yields Xoodyak-Hash.
+func NewCyclistHash() *Cyclist {
+	c := &Cyclist{}
+	_ = c.inst.Initialize(nil, nil, nil)
+	return c
+}
+
+// NewCyclist returns a keyed Cyclist initialised with key, an optional key
+// identifier id and an optional counter. The counter is absorbed one byte
+// per permutation call, which limits the leakage of the key under
+// side-channel attacks; leave it empty otherwise.
+func NewCyclist(key, id, counter []byte) (*Cyclist, error) {
+	if len(key) == 0 {
+		return nil, errEmptyKey
+	}
+	if len(key)+len(id) > CyclistMaxKeyIDSize {
+		return nil, errKeyIDSize
+	}
+	c := &Cyclist{}
+	if err := c.inst.Initialize(key, id, counter); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Keyed reports whether c runs in keyed mode.
+func (c *Cyclist) Keyed() bool { return c.inst.Keyed() }
+
+// Absorb absorbs data into the state. Successive calls are domain
+// separated: absorbing "ab" differs from absorbing "a" then "b".
+func (c *Cyclist) Absorb(data []byte) {
+	c.inst.Absorb(data)
+}
+
+// Squeeze fills dst with output that depends on everything processed so
+// far; in keyed mode it serves as an authentication tag.
+func (c *Cyclist) Squeeze(dst []byte) {
+	c.inst.Squeeze(dst)
+}
+
+// SqueezeKey fills dst with key material, domain separated from Squeeze.
+func (c *Cyclist) SqueezeKey(dst []byte) error {
+	if !c.inst.Keyed() {
+		return errNotKeyed
+	}
+	c.inst.SqueezeKey(dst)
+	return nil
+}
+
+// Encrypt encrypts src into dst, which must have the same length and may
+// alias src exactly, and absorbs the plaintext.
+func (c *Cyclist) Encrypt(dst, src []byte) error {
+	if !c.inst.Keyed() {
+		return errNotKeyed
+	}
+	if len(dst) != len(src) {
+		return errLength
+	}
+	c.inst.Crypt(src, dst, false)
+	return nil
+}
+
+// Decrypt decrypts src into dst, which must have the same length and may
+// alias src exactly, and absorbs the recovered plaintext. It does not
+// authenticate: follow it with Squeeze and compare the result against the
+// received tag in constant time before using dst.
+func (c *Cyclist) Decrypt(dst, src []byte) error {
+	if !c.inst.Keyed() {
+		return errNotKeyed
+	}
+	if len(dst) != len(src) {
+		return errLength
+	}
+	c.inst.Crypt(src, dst, true)
+	return nil
+}
+
+// Ratchet irreversibly transforms the keyed state so that a later state
+// compromise does not reveal earlier outputs.
+func (c *Cyclist) Ratchet() error {
+	if !c.inst.Keyed() {
+		return errNotKeyed
+	}
+	c.inst.Ratchet()
+	return nil
+}
+
+// Clone returns an independent copy of c, for example to fork a transcript
+// or to compute a tag without ending the session.
+func (c *Cyclist) Clone() *Cyclist {
+	d := *c
+	return &d
+}
+
+// Clear wipes the state, leaving c as a fresh unkeyed Cyclist.
+func (c *Cyclist) Clear() {
+	c.inst.Clear()
+	_ = c.inst.Initialize(nil, nil, nil)
+}
@@ -0,0 +1,118 @@
+package duplex
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+const merlinProtocol = "Merlin v1.0"
+
+// Transcript is a Merlin transcript for Fiat–Shamir transforms: the prover
+// and verifier append the same labelled messages and derive identical
+// challenges. It produces the same outputs as the Rust merlin crate. It is
+// not safe for concurrent use.
+type Transcript struct {
+	s Strobe
+}
+
+// NewTranscript starts a transcript for the application protocol label.
+func NewTranscript(label []byte) *Transcript {
+	t := &Transcript{s: *NewStrobe128([]byte(merlinProtocol))}
+	t.AppendMessage([]byte("dom-sep"), label)
+	return t
+}
+
+// AppendMessage appends a labelled message.
+func (t *Transcript) AppendMessage(label, message []byte) {
+	appendLabelled(&t.s, label, uint32Len(len(message)))
+	t.s.AD(message, false)
+}
+
+// AppendUint64 appends x as a labelled 8-byte little-endian message.
+func (t *Transcript) AppendUint64(label []byte, x uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], x)
+	t.AppendMessage(label, b[:])
+}
+
+// ChallengeBytes fills dst with a challenge bound to everything appended so
+// far and to label.
+func (t *Transcript) ChallengeBytes(label, dst []byte) {
+	appendLabelled(&t.s, label, uint32Len(len(dst)))
+	t.s.PRF(dst, false)
+}
+
+// Clone returns an independent copy of t.
+func (t *Transcript) Clone() *Transcript {
+	c := *t
+	return &c
+}
+
+// BuildRNG starts a TranscriptRNG forked from the current transcript. The
+// transcript itself is not modified.
+func (t *Transcript) BuildRNG() *TranscriptRNGBuilder {
+	return &TranscriptRNGBuilder{s: t.s}
+}
+
+// TranscriptRNGBuilder rekeys a transcript fork with prover secrets before
+// it becomes a TranscriptRNG.
+type TranscriptRNGBuilder struct {
+	s Strobe
+}
+
+// RekeyWithWitnessBytes binds the RNG to a labelled secret witness.
+func (b *TranscriptRNGBuilder) RekeyWithWitnessBytes(label, witness []byte) *TranscriptRNGBuilder {
+	appendLabelled(&b.s, label, uint32Len(len(witness)))
+	b.s.Key(witness, false)
+	return b
+}
+
+// Finalize keys the RNG with 32 bytes from rng (crypto/rand when nil) and
+// returns it. The result is secure as long as either the external
+// randomness or the witnesses are unpredictable.
+func (b *TranscriptRNGBuilder) Finalize(rng io.Reader) (*TranscriptRNG, error) {
+	if rng == nil {
+		rng = rand.Reader
+	}
+	var seed [32]byte
+	if _, err := io.ReadFull(rng, seed[:]); err != nil {
+		return nil, err
+	}
+	r := &TranscriptRNG{s: b.s}
+	r.s.MetaAD([]byte("rng"), false)
+	r.s.Key(seed[:], false)
+	clear(seed[:])
+	return r, nil
+}
+
+// TranscriptRNG generates prover randomness bound to a transcript and its
+// witnesses.
+type TranscriptRNG struct {
+	s Strobe
+}
+
+// Read fills p with random bytes. It never fails.
+func (r *TranscriptRNG) Read(p []byte) (int, error) {
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32Len(len(p)))
+	r.s.MetaAD(n[:], false)
+	r.s.PRF(p, false)
+	return len(p), nil
+}
+
+// appendLabelled absorbs the Merlin framing label || LE32(n) as metadata.
+func appendLabelled(s *Strobe, label []byte, n uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], n)
+	s.MetaAD(label, false)
+	s.MetaAD(b[:], true)
+}
+
+func uint32Len(n int) uint32 {
+	if uint64(n) > math.MaxUint32 {
+		panic("duplex: Merlin message length exceeds 2^32-1 bytes")
+	}
+	return uint32(n)
+}
@@ -0,0 +1,217 @@
+package duplex
+
+import (
+	"errors"
+
+	"github.com/AeonDave/cryptonite-go/internal/keccak"
+)
+
+// Flags is a STROBE operation: a combination of the I, A, C and T flags,
+// optionally marked as metadata with FlagM.
+type Flags byte
+
+const (
+	// FlagI marks data flowing inbound, from the transport or to the
+	// application.
+	FlagI Flags = 1 << iota
+	// FlagA marks data exchanged with the application.
+	FlagA
+	// FlagC marks cipher operations that use the state as a keystream.
+	FlagC
+	// FlagT marks data exchanged with the transport.
+	FlagT
+	// FlagM marks framing metadata rather than protocol data.
+	FlagM
+)
+
+// The ten STROBE operations. Combine with FlagM for their meta variants.
+const (
+	OpAD      = FlagA
+	OpKey     = FlagA | FlagC
+	OpPRF     = FlagI | FlagA | FlagC
+	OpSendCLR = FlagA | FlagT
+	OpRecvCLR = FlagI | FlagA | FlagT
+	OpSendENC = FlagA | FlagC | FlagT
+	OpRecvENC = FlagI | FlagA | FlagC | FlagT
+	OpSendMAC = FlagC | FlagT
+	OpRecvMAC = FlagI | FlagC | FlagT
+	OpRatchet = FlagC
+)
+
+const (
+	strobeStateSize = 200
+	strobeVersion   = "STROBEv1.0.2"
+)
+
+var (
+	errStrobeOp   = errors.New("duplex: unknown STROBE operation")
+	errStrobeMore = errors.New("duplex: STROBE operation continued with different flags")
+	errStrobeMAC  = errors.New("duplex: STROBE MAC verification failed")
+)
+
+// Strobe is a STROBE v1.0.2 protocol object over Keccak-f[1600]. It is not
+// safe for concurrent use.
+//
+// Operations taking a more argument continue the previous operation when
+// more is true, so data can be streamed in pieces with the same result as
+// one call; continuing with a different operation panics. Operate exposes
+// the same machinery with arbitrary flags and reports misuse as an error.
+type Strobe struct {
+	st       [strobeStateSize]byte
+	rate     int
+	pos      int
+	posBegin byte
+	// i0 is FlagI of the first transport operation, which fixes whether
+	// this side is the initiator (0) or the responder (FlagI).
+	i0       Flags
+	roleSet  bool
+	curFlags Flags
+}
+
+// NewStrobe128 returns a STROBE-128 object (Keccak-f[1600], 166-byte rate)
+// initialised with the protocol customisation string.
+func NewStrobe128(protocol []byte) *Strobe { return newStrobe(protocol, 128) }
+
+// NewStrobe256 returns a STROBE-256 object (Keccak-f[1600], 134-byte rate)
+// initialised with the protocol customisation string.
+func NewStrobe256(protocol []byte) *Strobe { return newStrobe(protocol, 256) }
+
+func newStrobe(protocol []byte, security int) *Strobe {
+	s := &Strobe{rate: strobeStateSize - security/4 - 2}
+	s.st[0], s.st[1], s.st[2], s.st[3], s.st[4], s.st[5] = 1, byte(s.rate+2), 1, 0, 1, 8*byte(len(strobeVersion))
+	copy(s.st[6:], strobeVersion)
+	keccak.F1600(&s.st)
+	s.MetaAD(protocol, false)
+	return s
+}
+
+// Operate runs the operation flags over data in place. For OpPRF, OpSendMAC
+// and OpRatchet the contents of data are ignored and replaced by the output;
+// for the encrypting and decrypting operations data is replaced by the
+// ciphertext or plaintext; otherwise data is left unchanged. OpRecvMAC
+// returns an error when data is not the expected MAC and cannot be
+// continued with more.
+func (s *Strobe) Operate(flags Flags, data []byte, more bool) error {
+	switch flags &^ FlagM {
+	case OpAD, OpKey, OpPRF, OpSendCLR, OpRecvCLR, OpSendENC, OpRecvENC, OpSendMAC, OpRecvMAC, OpRatchet:
+	default:
+		return errStrobeOp
+	}
+	recvMAC := flags&^FlagM == OpRecvMAC
+	if more {
+		if flags != s.curFlags || recvMAC {
+			return errStrobeMore
+		}
+	} else {
+		s.beginOp(flags)
+		s.curFlags = flags
+	}
+	if flags&(FlagI|FlagT) != FlagI|FlagT && flags&(FlagI|FlagA) != FlagA {
+		clear(data)
+	}
+	cAfter := flags&(FlagC|FlagI|FlagT) == FlagC|FlagT
+	cBefore := flags&FlagC != 0 && !cAfter
+	s.duplex(data, cBefore, cAfter, false)
+	if recvMAC {
+		var diff byte
+		for _, b := range data {
+			diff |= b
+		}
+		if diff != 0 {
+			return errStrobeMAC
+		}
+	}
+	return nil
+}
+
+func (s *Strobe) mustOperate(flags Flags, data []byte, more bool) {
+	if err := s.Operate(flags, data, more); err != nil {
+		panic(err)
+	}
+}
+
+// AD absorbs associated data.
+func (s *Strobe) AD(data []byte, more bool) { s.mustOperate(OpAD, data, more) }
+
+// MetaAD absorbs framing metadata such as labels and lengths.
+func (s *Strobe) MetaAD(data []byte, more bool) { s.mustOperate(OpAD|FlagM, data, more) }
+
+// Key overwrites the state with key material.
+func (s *Strobe) Key(key []byte, more bool) {
+	s.mustOperate(OpKey, append([]byte(nil), key...), more)
+}
+
+// PRF fills dst with pseudorandom output.
+func (s *Strobe) PRF(dst []byte, more bool) { s.mustOperate(OpPRF, dst, more) }
+
+// SendCLR absorbs cleartext that is sent to the peer.
+func (s *Strobe) SendCLR(data []byte, more bool) { s.mustOperate(OpSendCLR, data, more) }
+
+// RecvCLR absorbs cleartext received from the peer.
+func (s *Strobe) RecvCLR(data []byte, more bool) { s.mustOperate(OpRecvCLR, data, more) }
+
+// SendENC encrypts data in place for sending. It does not authenticate;
+// follow it with SendMAC.
+func (s *Strobe) SendENC(data []byte, more bool) { s.mustOperate(OpSendENC, data, more) }
+
+// RecvENC decrypts received data in place. It does not authenticate;
+// follow it with RecvMAC before trusting the plaintext.
+func (s *Strobe) RecvENC(data []byte, more bool) { s.mustOperate(OpRecvENC, data, more) }
+
+// SendMAC fills dst with a MAC over the transcript so far.
+func (s *Strobe) SendMAC(dst []byte, more bool) { s.mustOperate(OpSendMAC, dst, more) }
+
+// RecvMAC checks a received MAC in constant time, returning an error if it
+// does not match. mac is not modified.
+func (s *Strobe) RecvMAC(mac []byte) error {
+	return s.Operate(OpRecvMAC, append([]byte(nil), mac...), false)
+}
+
+// Ratchet zeroes n bytes of the rate so that a later state compromise does
+// not reveal earlier outputs.
+func (s *Strobe) Ratchet(n int, more bool) { s.mustOperate(OpRatchet, make([]byte, n), more) }
+
+// Clone returns an independent copy of s.
+func (s *Strobe) Clone() *Strobe {
+	c := *s
+	return &c
+}
+
+func (s *Strobe) beginOp(flags Flags) {
+	if flags&FlagT != 0 {
+		if !s.roleSet {
+			s.i0, s.roleSet = flags&FlagI, true
+		}
+		flags ^= s.i0
+	}
+	oldBegin := s.posBegin
+	s.posBegin = byte(s.pos + 1)
+	s.duplex([]byte{oldBegin, byte(flags)}, false, false, flags&FlagC != 0)
+}
+
+func (s *Strobe) duplex(data []byte, cBefore, cAfter, forceF bool) {
+	for i := range data {
+		if cBefore {
+			data[i] ^= s.st[s.pos]
+		}
+		s.st[s.pos] ^= data[i]
+		if cAfter {
+			data[i] = s.st[s.pos]
+		}
+		s.pos++
+		if s.pos == s.rate {
+			s.runF()
+		}
+	}
+	if forceF && s.pos != 0 {
+		s.runF()
+	}
+}
+
+func (s *Strobe) runF() {
+	s.st[s.pos] ^= s.posBegin
+	s.st[s.pos+1] ^= 0x04
+	s.st[s.rate+1] ^= 0x80
+	keccak.F1600(&s.st)
+	s.pos, s.posBegin = 0, 0
+}
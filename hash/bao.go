@@ -0,0 +1,397 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+
+	"github.com/AeonDave/cryptonite-go/internal/blake3"
+)
+
+// Bao is BLAKE3 verified streaming: the BLAKE3 tree of the content is
+// serialised next to (combined) or apart from (outboard) the content so a
+// reader holding only the 32-byte BLAKE3 hash can authenticate every 1 KiB
+// chunk as it arrives. Encodings follow the Bao specification: an 8-byte
+// little-endian content length followed by the tree in pre-order, each parent
+// node as its two 32-byte child chaining values and, in combined encodings,
+// each chunk's bytes in place.
+
+const (
+	baoHeaderLen = 8
+	baoParentLen = 64
+)
+
+// ErrBaoVerify reports content or tree data that does not match the expected
+// BLAKE3 root.
+var ErrBaoVerify = errors.New("hash: bao verification failed")
+
+var errBaoSliceRange = errors.New("hash: bao slice out of range")
+
+// BaoEncodedSize returns the size in bytes of the combined (outboard false)
+// or outboard encoding of contentLen bytes.
+func BaoEncodedSize(contentLen int64, outboard bool) int64 {
+	size := baoHeaderLen + baoParentLen*int64(baoParentCount(uint64(contentLen)))
+	if !outboard {
+		size += contentLen
+	}
+	return size
+}
+
+// BaoEncode reads contentLen bytes from content and writes their Bao encoding
+// to dst, returning the BLAKE3 hash of the content. When outboard is true
+// only the header and tree are written. dst is written out of order and must
+// accept BaoEncodedSize bytes.
+func BaoEncode(dst io.WriterAt, content io.Reader, contentLen int64, outboard bool) ([32]byte, error) {
+	if contentLen < 0 {
+		return [32]byte{}, errors.New("hash: negative bao content length")
+	}
+	e := baoEncoder{dst: dst, src: content, outboard: outboard}
+	var header [baoHeaderLen]byte
+	binary.LittleEndian.PutUint64(header[:], uint64(contentLen))
+	e.write(header[:], 0)
+	root := e.subtree(0, uint64(contentLen), baoHeaderLen, true)
+	return blake3.CVBytes(root), e.err
+}
+
+// BaoEncodeBuf returns the combined or outboard Bao encoding of content and
+// its BLAKE3 hash.
+func BaoEncodeBuf(content []byte, outboard bool) ([]byte, [32]byte) {
+	buf := &baoBuffer{buf: make([]byte, BaoEncodedSize(int64(len(content)), outboard))}
+	root, _ := BaoEncode(buf, bytes.NewReader(content), int64(len(content)), outboard)
+	return buf.buf, root
+}
+
+// NewBaoReader returns a reader that decodes a combined Bao encoding and
+// yields its content. Each chunk is checked against root before any of its
+// bytes are returned; the first mismatch fails the read with ErrBaoVerify.
+func NewBaoReader(encoding io.Reader, root [32]byte) io.Reader {
+	return newBaoDecoder(encoding, encoding, root, 0, 0, false)
+}
+
+// NewBaoOutboardReader is like NewBaoReader for an outboard encoding: the
+// content is read from content and the header and tree from outboard.
+func NewBaoOutboardReader(content, outboard io.Reader, root [32]byte) io.Reader {
+	return newBaoDecoder(content, outboard, root, 0, 0, false)
+}
+
+// BaoExtractSlice reads a combined encoding and writes to dst the slice
+// encoding of the content range [offset, offset+length): the header plus the
+// parents and chunks on the paths to that range. A zero-length slice still
+// carries the chunk containing offset (the final chunk when offset equals the
+// content length) so that the content length is authenticated.
+func BaoExtractSlice(dst io.Writer, encoding io.Reader, offset, length uint64) error {
+	return baoExtract(dst, encoding, nil, encoding, offset, length)
+}
+
+// BaoExtractSliceOutboard is like BaoExtractSlice for an outboard encoding;
+// only the chunks that belong to the slice are read from content.
+func BaoExtractSliceOutboard(dst io.Writer, content io.ReaderAt, outboard io.Reader, offset, length uint64) error {
+	return baoExtract(dst, nil, content, outboard, offset, length)
+}
+
+// NewBaoSliceReader returns a reader that verifies a slice encoding produced
+// by BaoExtractSlice against root and yields the content bytes in
+// [offset, offset+length).
+func NewBaoSliceReader(slice io.Reader, root [32]byte, offset, length uint64) io.Reader {
+	return newBaoDecoder(slice, slice, root, offset, length, true)
+}
+
+// baoParentCount returns the number of parent nodes in the tree of n bytes.
+func baoParentCount(n uint64) uint64 {
+	chunks := (n + blake3.ChunkLen - 1) / blake3.ChunkLen
+	if chunks == 0 {
+		return 0
+	}
+	return chunks - 1
+}
+
+// baoLeftLen returns the content length of the left subtree of a parent
+// covering n > ChunkLen bytes: the largest power of two below n.
+func baoLeftLen(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
+
+func baoSubtreeSize(n uint64, outboard bool) uint64 {
+	size := baoParentLen * baoParentCount(n)
+	if !outboard {
+		size += n
+	}
+	return size
+}
+
+// baoSliceRange normalises a requested slice to the half-open content range
+// whose chunks it must carry.
+func baoSliceRange(contentLen, offset, length uint64) (uint64, uint64, error) {
+	end := offset + length
+	if end < offset || end > contentLen {
+		return 0, 0, errBaoSliceRange
+	}
+	if length == 0 && contentLen > 0 {
+		if offset == contentLen {
+			offset--
+		}
+		end = offset + 1
+	}
+	return offset, end, nil
+}
+
+func baoNodeCV(out blake3.Output, root bool) [8]uint32 {
+	if root {
+		return out.RootCV()
+	}
+	return out.ChainingValue()
+}
+
+type baoEncoder struct {
+	dst      io.WriterAt
+	src      io.Reader
+	outboard bool
+	chunk    [blake3.ChunkLen]byte
+	err      error
+}
+
+func (e *baoEncoder) write(p []byte, off uint64) {
+	if e.err == nil {
+		_, e.err = e.dst.WriteAt(p, int64(off))
+	}
+}
+
+// subtree encodes the n content bytes starting at content offset start into
+// the encoding at off and returns the subtree's chaining value.
+func (e *baoEncoder) subtree(start, n, off uint64, root bool) [8]uint32 {
+	if e.err != nil {
+		return [8]uint32{}
+	}
+	if n <= blake3.ChunkLen {
+		chunk := e.chunk[:n]
+		if _, err := io.ReadFull(e.src, chunk); err != nil {
+			e.err = err
+			return [8]uint32{}
+		}
+		if !e.outboard {
+			e.write(chunk, off)
+		}
+		return baoNodeCV(blake3.ChunkOutput(chunk, start/blake3.ChunkLen, blake3.IV, 0), root)
+	}
+	mid := baoLeftLen(n)
+	left := e.subtree(start, mid, off+baoParentLen, false)
+	right := e.subtree(start+mid, n-mid, off+baoParentLen+baoSubtreeSize(mid, e.outboard), false)
+	var parent [baoParentLen]byte
+	l, r := blake3.CVBytes(left), blake3.CVBytes(right)
+	copy(parent[:32], l[:])
+	copy(parent[32:], r[:])
+	e.write(parent[:], off)
+	return baoNodeCV(blake3.ParentOutput(left, right, blake3.IV, 0), root)
+}
+
+func baoExtract(dst io.Writer, combined io.Reader, content io.ReaderAt, tree io.Reader, offset, length uint64) error {
+	var header [baoHeaderLen]byte
+	if _, err := io.ReadFull(tree, header[:]); err != nil {
+		return err
+	}
+	contentLen := binary.LittleEndian.Uint64(header[:])
+	start, end, err := baoSliceRange(contentLen, offset, length)
+	if err != nil {
+		return err
+	}
+	if _, err := dst.Write(header[:]); err != nil {
+		return err
+	}
+	x := baoExtractor{dst: dst, combined: combined, content: content, tree: tree, start: start, end: end}
+	x.subtree(0, contentLen)
+	return x.err
+}
+
+type baoExtractor struct {
+	dst        io.Writer
+	combined   io.Reader
+	content    io.ReaderAt
+	tree       io.Reader
+	start, end uint64
+	buf        [blake3.ChunkLen]byte
+	err        error
+}
+
+func (x *baoExtractor) subtree(pos, n uint64) {
+	if x.err != nil {
+		return
+	}
+	inSlice := n == 0 || (pos < x.end && x.start < pos+n)
+	if !inSlice {
+		// Skip the subtree's tree data and, in combined encodings, its
+		// content.
+		skip := baoSubtreeSize(n, x.combined == nil)
+		_, x.err = io.CopyN(io.Discard, x.tree, int64(skip))
+		if x.err == io.EOF {
+			x.err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+	if n <= blake3.ChunkLen {
+		chunk := x.buf[:n]
+		if x.combined != nil {
+			_, x.err = io.ReadFull(x.combined, chunk)
+		} else if k, err := x.content.ReadAt(chunk, int64(pos)); k < len(chunk) {
+			x.err = err
+			if err == nil || err == io.EOF {
+				x.err = io.ErrUnexpectedEOF
+			}
+		}
+		if x.err == nil {
+			_, x.err = x.dst.Write(chunk)
+		}
+		return
+	}
+	parent := x.buf[:baoParentLen]
+	if _, x.err = io.ReadFull(x.tree, parent); x.err != nil {
+		return
+	}
+	if _, x.err = x.dst.Write(parent); x.err != nil {
+		return
+	}
+	mid := baoLeftLen(n)
+	x.subtree(pos, mid)
+	x.subtree(pos+mid, n-mid)
+}
+
+type baoNode struct {
+	cv   [8]uint32
+	pos  uint64
+	n    uint64
+	root bool
+}
+
+// baoDecoder walks a Bao tree in pre-order with an explicit stack, verifying
+// each node before descending and releasing chunk bytes only once they match
+// their chaining value.
+type baoDecoder struct {
+	content io.Reader
+	tree    io.Reader
+	root    [8]uint32
+	slice   bool
+	offset  uint64
+	length  uint64
+	// start and end bound the content whose chunks are present; offset and
+	// outEnd bound the bytes returned to the caller.
+	start, end uint64
+	outEnd     uint64
+	stack      []baoNode
+	started    bool
+	chunk      [blake3.ChunkLen]byte
+	pending    []byte
+	err        error
+}
+
+func newBaoDecoder(content, tree io.Reader, root [32]byte, offset, length uint64, slice bool) *baoDecoder {
+	return &baoDecoder{
+		content: content,
+		tree:    tree,
+		root:    blake3.CVFromBytes(root[:]),
+		slice:   slice,
+		offset:  offset,
+		length:  length,
+	}
+}
+
+func (d *baoDecoder) Read(p []byte) (int, error) {
+	for len(d.pending) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.step()
+	}
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	return n, nil
+}
+
+func (d *baoDecoder) fail(err error) {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	d.err = err
+}
+
+// step advances the walk by one node, possibly producing pending output.
+func (d *baoDecoder) step() {
+	if !d.started {
+		d.started = true
+		var header [baoHeaderLen]byte
+		if _, err := io.ReadFull(d.tree, header[:]); err != nil {
+			d.fail(err)
+			return
+		}
+		contentLen := binary.LittleEndian.Uint64(header[:])
+		d.start, d.end, d.outEnd = 0, contentLen, contentLen
+		if d.slice {
+			start, end, err := baoSliceRange(contentLen, d.offset, d.length)
+			if err != nil {
+				d.fail(err)
+				return
+			}
+			d.start, d.end, d.outEnd = start, end, d.offset+d.length
+		}
+		d.stack = append(d.stack, baoNode{cv: d.root, n: contentLen, root: true})
+		return
+	}
+	if len(d.stack) == 0 {
+		d.err = io.EOF
+		return
+	}
+	node := d.stack[len(d.stack)-1]
+	d.stack = d.stack[:len(d.stack)-1]
+	if node.n > 0 && (node.pos >= d.end || d.start >= node.pos+node.n) {
+		return
+	}
+	if node.n <= blake3.ChunkLen {
+		chunk := d.chunk[:node.n]
+		if _, err := io.ReadFull(d.content, chunk); err != nil {
+			d.fail(err)
+			return
+		}
+		out := blake3.ChunkOutput(chunk, node.pos/blake3.ChunkLen, blake3.IV, 0)
+		if baoNodeCV(out, node.root) != node.cv {
+			d.err = ErrBaoVerify
+			return
+		}
+		lo, hi := node.pos, node.pos+node.n
+		if d.slice {
+			if lo < d.offset {
+				lo = d.offset
+			}
+			if hi > d.outEnd {
+				hi = d.outEnd
+			}
+		}
+		if lo < hi {
+			d.pending = chunk[lo-node.pos : hi-node.pos]
+		}
+		return
+	}
+	var parent [baoParentLen]byte
+	if _, err := io.ReadFull(d.tree, parent[:]); err != nil {
+		d.fail(err)
+		return
+	}
+	left := blake3.CVFromBytes(parent[:32])
+	right := blake3.CVFromBytes(parent[32:])
+	if baoNodeCV(blake3.ParentOutput(left, right, blake3.IV, 0), node.root) != node.cv {
+		d.err = ErrBaoVerify
+		return
+	}
+	mid := baoLeftLen(node.n)
+	d.stack = append(d.stack,
+		baoNode{cv: right, pos: node.pos + mid, n: node.n - mid},
+		baoNode{cv: left, pos: node.pos, n: mid},
+	)
+}
+
+type baoBuffer struct {
+	buf []byte
+}
+
+func (b *baoBuffer) WriteAt(p []byte, off int64) (int, error) {
+	return copy(b.buf[off:], p), nil
+}
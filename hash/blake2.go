@@ -19,15 +19,44 @@ const Blake2bXOFUnknown = xof.Blake2bUnknown
 // unspecified in advance.
 const Blake2sXOFUnknown = xof.Blake2sUnknown
 
-// Blake2bBuilder constructs keyed or unkeyed BLAKE2b hash and XOF instances.
+// Blake2Tree holds the BLAKE2 tree-hashing fields of the parameter block
+// (BLAKE2 specification, section 2.10). Builders start from the sequential
+// mode values, Fanout 1 and MaxDepth 1 with every other field zero.
+type Blake2Tree struct {
+	// Fanout is the number of children per node; 0 means unlimited.
+	Fanout uint8
+	// MaxDepth is the maximal tree depth, between 1 and 255.
+	MaxDepth uint8
+	// LeafLength is the maximal leaf byte length; 0 means unlimited.
+	LeafLength uint32
+	// NodeOffset is the node position within its level. BLAKE2s limits it
+	// to 48 bits.
+	NodeOffset uint64
+	// NodeDepth is the node level, 0 for leaves.
+	NodeDepth uint8
+	// InnerSize is the digest length of the inner nodes.
+	InnerSize uint8
+	// LastNode marks the rightmost node of its level.
+	LastNode bool
+}
+
+var blake2Sequential = Blake2Tree{Fanout: 1, MaxDepth: 1}
+
+// Blake2bBuilder constructs keyed or unkeyed BLAKE2b hash and XOF instances,
+// optionally salted, personalized or configured as a tree node.
 type Blake2bBuilder struct {
-	size int
-	key  []byte
+	size     int
+	key      []byte
+	salt     []byte
+	personal []byte
+	tree     Blake2Tree
 }
 
 // NewBlake2bBuilder returns a builder configured for the default 64-byte
-// BLAKE2b digest without a key.
-func NewBlake2bBuilder() Blake2bBuilder { return Blake2bBuilder{size: blake2b.Size} }
+// sequential BLAKE2b digest without a key, salt or personalization.
+func NewBlake2bBuilder() Blake2bBuilder {
+	return Blake2bBuilder{size: blake2b.Size, tree: blake2Sequential}
+}
 
 // Size sets the desired digest length. The value must be between 1 and 64.
 func (b Blake2bBuilder) Size(size int) Blake2bBuilder {
@@ -48,15 +77,66 @@ func (b Blake2bBuilder) Key(key []byte) Blake2bBuilder {
 	return b
 }
 
+// Salt configures the salt of up to 16 bytes; shorter values are
+// zero-padded. The salt is copied.
+func (b Blake2bBuilder) Salt(salt []byte) Blake2bBuilder {
+	b.salt = cloneBytes(salt)
+	return b
+}
+
+// Personal configures the personalization string of up to 16 bytes; shorter
+// values are zero-padded. The string is copied.
+func (b Blake2bBuilder) Personal(personal []byte) Blake2bBuilder {
+	b.personal = cloneBytes(personal)
+	return b
+}
+
+// Tree configures the tree-hashing parameters.
+func (b Blake2bBuilder) Tree(tree Blake2Tree) Blake2bBuilder {
+	b.tree = tree
+	return b
+}
+
+func (b Blake2bBuilder) sequential() bool {
+	return len(b.salt) == 0 && len(b.personal) == 0 && b.tree == blake2Sequential
+}
+
+func (b Blake2bBuilder) newFunc() func(int, []byte) (stdhash.Hash, error) {
+	if b.sequential() {
+		return blake2b.New
+	}
+	params := &blake2b.Params{
+		Salt:       b.salt,
+		Personal:   b.personal,
+		Fanout:     b.tree.Fanout,
+		MaxDepth:   b.tree.MaxDepth,
+		LeafLength: b.tree.LeafLength,
+		NodeOffset: b.tree.NodeOffset,
+		NodeDepth:  b.tree.NodeDepth,
+		InnerSize:  b.tree.InnerSize,
+		LastNode:   b.tree.LastNode,
+	}
+	return func(size int, key []byte) (stdhash.Hash, error) {
+		return blake2b.NewWithParams(size, key, params)
+	}
+}
+
 // Hash returns a stateful hash.Hash instance implementing the configured
 // BLAKE2b variant.
 func (b Blake2bBuilder) Hash() (stdhash.Hash, error) {
-	return blake2b.New(b.size, b.key)
+	return b.newFunc()(b.size, b.key)
 }
 
 // Hasher returns a stateless helper implementing hash.Hasher.
 func (b Blake2bBuilder) Hasher() (Hasher, error) {
-	return newBlake2Hasher(blake2b.New, blake2b.Size, b.size, b.key, "BLAKE2b")
+	h, err := newBlake2Hasher(b.newFunc(), blake2b.Size, b.size, b.key, "BLAKE2b")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := b.Hash(); err != nil {
+		return nil, err
+	}
+	return h, nil
 }
 
 // Sum computes a single-shot digest using the configured parameters.
@@ -71,7 +151,11 @@ func (b Blake2bBuilder) Sum(msg []byte) ([]byte, error) {
 
 // XOF returns an extendable-output function using the configured key and the
 // requested output length. For unknown-length output use Blake2bXOFUnknown.
+// Salt, personalization and tree parameters are not supported.
 func (b Blake2bBuilder) XOF(length uint32) (xof.XOF, error) {
+	if !b.sequential() {
+		return nil, errors.New("hash: BLAKE2b XOF does not support salt, personalization or tree parameters")
+	}
 	return xof.Blake2b(length, b.key)
 }
 
@@ -93,15 +177,21 @@ func NewBlake2bXOF(length uint32, key []byte) (xof.XOF, error) {
 	return xof.Blake2b(length, key)
 }
 
-// Blake2sBuilder constructs keyed or unkeyed BLAKE2s hash and XOF instances.
+// Blake2sBuilder constructs keyed or unkeyed BLAKE2s hash and XOF instances,
+// optionally salted, personalized or configured as a tree node.
 type Blake2sBuilder struct {
-	size int
-	key  []byte
+	size     int
+	key      []byte
+	salt     []byte
+	personal []byte
+	tree     Blake2Tree
 }
 
 // NewBlake2sBuilder returns a builder configured for the default 32-byte
-// BLAKE2s digest without a key.
-func NewBlake2sBuilder() Blake2sBuilder { return Blake2sBuilder{size: blake2s.Size} }
+// sequential BLAKE2s digest without a key, salt or personalization.
+func NewBlake2sBuilder() Blake2sBuilder {
+	return Blake2sBuilder{size: blake2s.Size, tree: blake2Sequential}
+}
 
 // Size sets the desired digest length. The value must be between 1 and 32.
 func (b Blake2sBuilder) Size(size int) Blake2sBuilder {
@@ -122,15 +212,67 @@ func (b Blake2sBuilder) Key(key []byte) Blake2sBuilder {
 	return b
 }
 
+// Salt configures the salt of up to 8 bytes; shorter values are zero-padded.
+// The salt is copied.
+func (b Blake2sBuilder) Salt(salt []byte) Blake2sBuilder {
+	b.salt = cloneBytes(salt)
+	return b
+}
+
+// Personal configures the personalization string of up to 8 bytes; shorter
+// values are zero-padded. The string is copied.
+func (b Blake2sBuilder) Personal(personal []byte) Blake2sBuilder {
+	b.personal = cloneBytes(personal)
+	return b
+}
+
+// Tree configures the tree-hashing parameters. NodeOffset must fit in 48
+// bits.
+func (b Blake2sBuilder) Tree(tree Blake2Tree) Blake2sBuilder {
+	b.tree = tree
+	return b
+}
+
+func (b Blake2sBuilder) sequential() bool {
+	return len(b.salt) == 0 && len(b.personal) == 0 && b.tree == blake2Sequential
+}
+
+func (b Blake2sBuilder) newFunc() func(int, []byte) (stdhash.Hash, error) {
+	if b.sequential() {
+		return blake2s.New
+	}
+	params := &blake2s.Params{
+		Salt:       b.salt,
+		Personal:   b.personal,
+		Fanout:     b.tree.Fanout,
+		MaxDepth:   b.tree.MaxDepth,
+		LeafLength: b.tree.LeafLength,
+		NodeOffset: b.tree.NodeOffset,
+		NodeDepth:  b.tree.NodeDepth,
+		InnerSize:  b.tree.InnerSize,
+		LastNode:   b.tree.LastNode,
+	}
+	return func(size int, key []byte) (stdhash.Hash, error) {
+		return blake2s.NewWithParams(size, key, params)
+	}
+}
+
 // Hash returns a stateful hash.Hash instance implementing the configured
 // BLAKE2s variant.
 func (b Blake2sBuilder) Hash() (stdhash.Hash, error) {
-	return blake2s.New(b.size, b.key)
+	return b.newFunc()(b.size, b.key)
 }
 
 // Hasher returns a stateless helper implementing hash.Hasher.
 func (b Blake2sBuilder) Hasher() (Hasher, error) {
-	return newBlake2Hasher(blake2s.New, blake2s.Size, b.size, b.key, "BLAKE2s")
+	h, err := newBlake2Hasher(b.newFunc(), blake2s.Size, b.size, b.key, "BLAKE2s")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := b.Hash(); err != nil {
+		return nil, err
+	}
+	return h, nil
 }
 
 // Sum computes a single-shot digest using the configured parameters.
@@ -144,7 +286,11 @@ func (b Blake2sBuilder) Sum(msg []byte) ([]byte, error) {
 
 // XOF returns an extendable-output function using the configured key and the
 // requested output length. For unknown-length output use Blake2sXOFUnknown.
+// Salt, personalization and tree parameters are not supported.
 func (b Blake2sBuilder) XOF(length uint32) (xof.XOF, error) {
+	if !b.sequential() {
+		return nil, errors.New("hash: BLAKE2s XOF does not support salt, personalization or tree parameters")
+	}
 	if length != Blake2sXOFUnknown && length >= math.MaxUint16 {
 		return nil, errors.New("hash: blake2s XOF length too large")
 	}
@@ -171,6 +317,32 @@ func NewBlake2sXOF(length uint32, key []byte) (xof.XOF, error) {
 	return xof.Blake2s(length, key)
 }
 
+// NewBlake2bp returns a streaming BLAKE2bp hash.Hash with the specified digest
+// length (1–64 bytes) and optional key. BLAKE2bp hashes four interleaved
+// BLAKE2b leaves; large inputs are processed on four goroutines.
+func NewBlake2bp(size int, key []byte) (stdhash.Hash, error) {
+	return blake2b.NewParallel(size, key)
+}
+
+// NewBlake2bpHasher creates a stateless BLAKE2bp helper with the given digest
+// length and optional key.
+func NewBlake2bpHasher(size int, key []byte) (Hasher, error) {
+	return newBlake2Hasher(blake2b.NewParallel, blake2b.Size, size, key, "BLAKE2bp")
+}
+
+// NewBlake2sp returns a streaming BLAKE2sp hash.Hash with the specified digest
+// length (1–32 bytes) and optional key. BLAKE2sp hashes eight interleaved
+// BLAKE2s leaves; large inputs are processed on eight goroutines.
+func NewBlake2sp(size int, key []byte) (stdhash.Hash, error) {
+	return blake2s.NewParallel(size, key)
+}
+
+// NewBlake2spHasher creates a stateless BLAKE2sp helper with the given digest
+// length and optional key.
+func NewBlake2spHasher(size int, key []byte) (Hasher, error) {
+	return newBlake2Hasher(blake2s.NewParallel, blake2s.Size, size, key, "BLAKE2sp")
+}
+
 type blake2Hasher struct {
 	size    int
 	key     []byte
@@ -202,3 +374,10 @@ func (h blake2Hasher) Hash(msg []byte) []byte {
 }
 
 func (h blake2Hasher) Size() int { return h.size }
+
+func cloneBytes(b []byte) []byte {
+	if len(b) == 0 {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
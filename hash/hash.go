@@ -1,5 +1,12 @@
 package hash
 
+import (
+	"encoding"
+	stdhash "hash"
+
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
 // Hasher defines the minimal single-shot hashing API exposed by the library.
 //
 // It mirrors the simple AEAD interface in the aead package by offering a
@@ -14,3 +21,85 @@ type Hasher interface {
 	Hash(msg []byte) []byte
 	Size() int
 }
+
+// Cloner is a streaming hash whose state can be copied. Clone returns an
+// independent hash in the same state.
+//
+// Every streaming hash.Hash returned by this package implements Cloner as
+// well as encoding.BinaryMarshaler and encoding.BinaryUnmarshaler, so a
+// long-running computation can be forked, or saved and resumed later. The
+// concrete KangarooTwelve, TupleHash and ParallelHash types offer the same
+// methods, with Clone returning their own type.
+type Cloner interface {
+	stdhash.Hash
+	Clone() stdhash.Hash
+}
+
+// Named is a streaming hash that reports its algorithm identifier. Every
+// streaming hash.Hash returned by this package implements Named, as do the
+// concrete KangarooTwelve, TupleHash and ParallelHash types.
+type Named interface {
+	stdhash.Hash
+	// Algorithm returns the identifier, such as "SHA-256", "SHA3-256",
+	// "BLAKE2b-512" or "KT128".
+	Algorithm() string
+}
+
+var (
+	// ErrInvalidState is returned by UnmarshalBinary for truncated, corrupted
+	// or unsupported state encodings.
+	ErrInvalidState = marshal.ErrInvalid
+	// ErrStateMismatch is returned by UnmarshalBinary when the encoding was
+	// produced by a different algorithm or configuration (digest size,
+	// parameters, customization) than the receiving hash. Keys are not part
+	// of the encoding: restore keyed states into a hash created with the
+	// same key.
+	ErrStateMismatch = marshal.ErrMismatch
+)
+
+// stdState is implemented by the standard library hash states.
+type stdState interface {
+	stdhash.Hash
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+// marshalStd wraps the standard library encoding of state in the library's
+// versioned envelope under alg.
+func marshalStd(alg string, state stdState) ([]byte, error) {
+	b, err := state.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	e := marshal.NewEncoder(alg)
+	e.Bytes(b)
+	return e.Data(), nil
+}
+
+// unmarshalStd decodes an encoding produced by marshalStd into state.
+func unmarshalStd(alg string, b []byte, state stdState) error {
+	d, err := marshal.NewDecoder(b, alg)
+	if err != nil {
+		return err
+	}
+	inner := d.Bytes(len(b))
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	if state.UnmarshalBinary(inner) != nil {
+		return marshal.ErrInvalid
+	}
+	return nil
+}
+
+// cloneStd copies state into a fresh instance from newState.
+func cloneStd[T stdState](state, fresh T) T {
+	b, err := state.MarshalBinary()
+	if err != nil {
+		panic("hash: " + err.Error())
+	}
+	if err := fresh.UnmarshalBinary(b); err != nil {
+		panic("hash: " + err.Error())
+	}
+	return fresh
+}
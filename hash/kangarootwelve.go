@@ -0,0 +1,161 @@
+package hash
+
+import (
+	"errors"
+	"fmt"
+	stdhash "hash"
+	"io"
+
+	"github.com/AeonDave/cryptonite-go/internal/keccak"
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
+var errTurboSHAKEDomain = errors.New("hash: TurboSHAKE domain byte must be in 0x01..0x7f")
+
+// TurboSHAKE128 returns outLen bytes of TurboSHAKE128 output over msg with the
+// given domain separation byte (0x01..0x7f; 0x1f is the default).
+func TurboSHAKE128(msg []byte, domain byte, outLen int) ([]byte, error) {
+	return turboSHAKE(keccak.TurboSHAKE128Rate, msg, domain, outLen)
+}
+
+// TurboSHAKE256 returns outLen bytes of TurboSHAKE256 output over msg with the
+// given domain separation byte (0x01..0x7f; 0x1f is the default).
+func TurboSHAKE256(msg []byte, domain byte, outLen int) ([]byte, error) {
+	return turboSHAKE(keccak.TurboSHAKE256Rate, msg, domain, outLen)
+}
+
+func turboSHAKE(rate int, msg []byte, domain byte, outLen int) ([]byte, error) {
+	if domain == 0 || domain > 0x7f {
+		return nil, errTurboSHAKEDomain
+	}
+	if outLen <= 0 {
+		return nil, errors.New("hash: invalid TurboSHAKE output length")
+	}
+	s := keccak.NewTurboSHAKE(rate, domain)
+	s.Absorb(msg)
+	out := make([]byte, outLen)
+	s.Squeeze(out)
+	return out, nil
+}
+
+// KT128 returns outLen bytes of KangarooTwelve (KT128) output over msg. The
+// optional customization string may be nil.
+func KT128(msg []byte, outLen int, customization []byte) ([]byte, error) {
+	k, err := NewKT128(outLen, customization)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = k.Write(msg)
+	return k.Sum(nil), nil
+}
+
+// KT256 returns outLen bytes of KT256 output over msg. The optional
+// customization string may be nil.
+func KT256(msg []byte, outLen int, customization []byte) ([]byte, error) {
+	k, err := NewKT256(outLen, customization)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = k.Write(msg)
+	return k.Sum(nil), nil
+}
+
+// KangarooTwelve is a streaming KT128/KT256 implementing hash.Hash. Input
+// past the first 8 KiB is hashed as a tree of 8 KiB leaves whose chaining
+// values are computed across goroutines once enough data has been buffered.
+type KangarooTwelve struct {
+	state  *keccak.KangarooTwelve
+	rate   int
+	outLen int
+}
+
+// NewKT128 returns a streaming KT128 producing outLen-byte digests.
+func NewKT128(outLen int, customization []byte) (*KangarooTwelve, error) {
+	return newKangarooTwelve(keccak.TurboSHAKE128Rate, outLen, customization, "KT128")
+}
+
+// NewKT256 returns a streaming KT256 producing outLen-byte digests.
+func NewKT256(outLen int, customization []byte) (*KangarooTwelve, error) {
+	return newKangarooTwelve(keccak.TurboSHAKE256Rate, outLen, customization, "KT256")
+}
+
+func newKangarooTwelve(rate, outLen int, customization []byte, alg string) (*KangarooTwelve, error) {
+	if outLen <= 0 {
+		return nil, fmt.Errorf("hash: invalid %s output length", alg)
+	}
+	return &KangarooTwelve{
+		state:  keccak.NewKangarooTwelve(rate, customization),
+		rate:   rate,
+		outLen: outLen,
+	}, nil
+}
+
+// Write absorbs p. It never returns an error.
+func (k *KangarooTwelve) Write(data []byte) (int, error) {
+	k.state.Write(data)
+	return len(data), nil
+}
+
+// Sum appends the digest of the data written so far to b without modifying
+// the running state.
+func (k *KangarooTwelve) Sum(b []byte) []byte {
+	out := make([]byte, k.outLen)
+	k.state.Sum(out)
+	return append(b, out...)
+}
+
+// XOF returns a reader over the arbitrary-length output of the data written so
+// far. The running state remains usable.
+func (k *KangarooTwelve) XOF() io.Reader { return k.state.XOF() }
+
+// Reset discards all data written so far.
+func (k *KangarooTwelve) Reset() { k.state.Reset() }
+
+// Size returns the digest length in bytes produced by Sum.
+func (k *KangarooTwelve) Size() int { return k.outLen }
+
+// BlockSize returns the rate of the underlying TurboSHAKE sponge.
+func (k *KangarooTwelve) BlockSize() int { return k.rate }
+
+// Algorithm returns "KT128" or "KT256".
+func (k *KangarooTwelve) Algorithm() string {
+	if k.rate == keccak.TurboSHAKE128Rate {
+		return "KT128"
+	}
+	return "KT256"
+}
+
+// MarshalBinary encodes the output length, customization string and running
+// state, including any input buffered for the next batch of leaves.
+func (k *KangarooTwelve) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder(k.Algorithm())
+	e.Uint64(uint64(k.outLen))
+	k.state.Encode(e)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on a hash with
+// the same function, output length and customization string.
+func (k *KangarooTwelve) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, k.Algorithm())
+	if err != nil {
+		return err
+	}
+	d.Expect(uint64(k.outLen))
+	state := k.state.Clone()
+	state.Decode(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	k.state = state
+	return nil
+}
+
+// Clone returns an independent copy of the hash.
+func (k *KangarooTwelve) Clone() *KangarooTwelve {
+	c := *k
+	c.state = k.state.Clone()
+	return &c
+}
+
+var _ stdhash.Hash = (*KangarooTwelve)(nil)
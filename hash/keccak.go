@@ -0,0 +1,106 @@
+package hash
+
+import (
+	stdhash "hash"
+
+	"github.com/AeonDave/cryptonite-go/internal/keccak"
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
+// Keccak-256 as submitted to the SHA-3 competition: identical to SHA3-256
+// except for the original pad10*1 padding (domain byte 0x01 instead of 0x06).
+// It is the hash used by Ethereum and is not interchangeable with SHA3-256.
+const (
+	keccak256Size  = 32
+	keccak256Rate  = 136
+	keccakLegacyDS = 0x01
+)
+
+type keccakHasher struct{}
+
+func (keccakHasher) Hash(msg []byte) []byte {
+	out := make([]byte, keccak256Size)
+	keccak.SumFixed(keccak256Rate, keccakLegacyDS, out, msg)
+	return out
+}
+
+func (keccakHasher) Size() int { return keccak256Size }
+
+type keccakDigest struct {
+	state keccak.Sponge
+}
+
+func (d *keccakDigest) Reset() { d.state.Init(keccak256Rate, keccakLegacyDS) }
+
+func (d *keccakDigest) Write(p []byte) (int, error) {
+	d.state.Absorb(p)
+	return len(p), nil
+}
+
+func (d *keccakDigest) Sum(b []byte) []byte {
+	s := d.state
+	var out [keccak256Size]byte
+	s.Squeeze(out[:])
+	return append(b, out[:]...)
+}
+
+func (d *keccakDigest) Size() int { return keccak256Size }
+
+func (d *keccakDigest) BlockSize() int { return keccak256Rate }
+
+func (d *keccakDigest) Hash(msg []byte) []byte { return keccakHasher{}.Hash(msg) }
+
+// Algorithm returns "Keccak-256".
+func (d *keccakDigest) Algorithm() string { return "Keccak-256" }
+
+// MarshalBinary encodes the sponge state in the library's versioned format.
+func (d *keccakDigest) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder("Keccak-256")
+	d.state.Encode(e)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary.
+func (d *keccakDigest) UnmarshalBinary(b []byte) error {
+	dec, err := marshal.NewDecoder(b, "Keccak-256")
+	if err != nil {
+		return err
+	}
+	s := d.state
+	s.Decode(dec)
+	if err := dec.Finish(); err != nil {
+		return err
+	}
+	d.state = s
+	return nil
+}
+
+// Clone returns an independent copy of the hash.
+func (d *keccakDigest) Clone() stdhash.Hash {
+	c := *d
+	return &c
+}
+
+var (
+	_ stdhash.Hash = (*keccakDigest)(nil)
+	_ Cloner       = (*keccakDigest)(nil)
+	_ Hasher       = (*keccakDigest)(nil)
+	_ Hasher       = keccakHasher{}
+)
+
+// NewKeccak256 returns a hash computing the legacy Keccak-256 digest.
+func NewKeccak256() stdhash.Hash {
+	d := &keccakDigest{}
+	d.Reset()
+	return d
+}
+
+// NewKeccak256Hasher returns a stateless Keccak-256 helper implementing hash.Hasher.
+func NewKeccak256Hasher() Hasher { return keccakHasher{} }
+
+// SumKeccak256 returns the legacy Keccak-256 digest of the input.
+func SumKeccak256(data []byte) [keccak256Size]byte {
+	var out [keccak256Size]byte
+	keccak.SumFixed(keccak256Rate, keccakLegacyDS, out[:], data)
+	return out
+}
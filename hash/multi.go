@@ -0,0 +1,149 @@
+package hash
+
+import (
+	"fmt"
+	stdhash "hash"
+	"io"
+	"runtime"
+	"sync"
+)
+
+const (
+	// multiChunkSize is the read size used by MultiHash.ReadFrom; while the
+	// hashes consume one chunk the next is read into a second buffer.
+	multiChunkSize = 256 << 10
+	// multiParallelMin is the smallest write worth fanning out to one
+	// goroutine per hash.
+	multiParallelMin = 64 << 10
+)
+
+// Digest is one result of a MultiHash: the algorithm identifier and the
+// digest it produced.
+type Digest struct {
+	Algorithm string
+	Sum       []byte
+}
+
+// MultiHash feeds a single input to several streaming hashes, so a file can
+// be read once to compute, for example, its SHA-256, SHA-512, BLAKE2b and
+// BLAKE3 digests. Writes of 64 KiB or more update the hashes on separate
+// goroutines when more than one CPU is available. A MultiHash is not safe for
+// concurrent use.
+type MultiHash struct {
+	hashes []stdhash.Hash
+	names  []string
+	n      int64
+}
+
+// NewMultiHash returns a MultiHash over one instance from each constructor,
+// for example NewSHA256 or a closure around NewBlake2b.
+func NewMultiHash(constructors ...func() stdhash.Hash) *MultiHash {
+	m := &MultiHash{
+		hashes: make([]stdhash.Hash, len(constructors)),
+		names:  make([]string, len(constructors)),
+	}
+	for i, newHash := range constructors {
+		m.hashes[i] = newHash()
+		m.names[i] = AlgorithmName(m.hashes[i])
+	}
+	return m
+}
+
+// Write feeds p to every hash. It never returns an error.
+func (m *MultiHash) Write(p []byte) (int, error) {
+	var wg sync.WaitGroup
+	m.write(&wg, p)
+	wg.Wait()
+	return len(p), nil
+}
+
+// ReadFrom reads r until EOF, feeding every hash, and returns the number of
+// bytes read. Reading the next chunk overlaps with hashing the previous one.
+func (m *MultiHash) ReadFrom(r io.Reader) (int64, error) {
+	var (
+		bufs  [2][]byte
+		wg    sync.WaitGroup
+		total int64
+	)
+	for cur := 0; ; cur ^= 1 {
+		if bufs[cur] == nil {
+			bufs[cur] = make([]byte, multiChunkSize)
+		}
+		k, err := io.ReadFull(r, bufs[cur])
+		// The hashes may still be consuming the other buffer.
+		wg.Wait()
+		if k > 0 {
+			total += int64(k)
+			m.write(&wg, bufs[cur][:k])
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			err = nil
+		}
+		if err != nil || k < len(bufs[cur]) {
+			wg.Wait()
+			return total, err
+		}
+	}
+}
+
+// write feeds p to every hash, adding one goroutine per hash to wg when the
+// write is large enough to benefit.
+func (m *MultiHash) write(wg *sync.WaitGroup, p []byte) {
+	m.n += int64(len(p))
+	if len(m.hashes) < 2 || len(p) < multiParallelMin || runtime.GOMAXPROCS(0) == 1 {
+		for _, h := range m.hashes {
+			h.Write(p)
+		}
+		return
+	}
+	wg.Add(len(m.hashes))
+	for _, h := range m.hashes {
+		go func(h stdhash.Hash) {
+			defer wg.Done()
+			h.Write(p)
+		}(h)
+	}
+}
+
+// Len returns the number of bytes written since creation or the last Reset.
+func (m *MultiHash) Len() int64 { return m.n }
+
+// Sums returns the digest of every hash, in constructor order, without
+// modifying the running states.
+func (m *MultiHash) Sums() []Digest {
+	out := make([]Digest, len(m.hashes))
+	for i, h := range m.hashes {
+		out[i] = Digest{Algorithm: m.names[i], Sum: h.Sum(nil)}
+	}
+	return out
+}
+
+// Reset resets every hash.
+func (m *MultiHash) Reset() {
+	for _, h := range m.hashes {
+		h.Reset()
+	}
+	m.n = 0
+}
+
+// SumReader reads r once and returns its digest under every constructor, in
+// order.
+func SumReader(r io.Reader, constructors ...func() stdhash.Hash) ([]Digest, error) {
+	m := NewMultiHash(constructors...)
+	if _, err := m.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	return m.Sums(), nil
+}
+
+// AlgorithmName returns the identifier of h: its Algorithm method for hashes
+// created by this package ("SHA-256", "SHA3-256", "BLAKE2b-512", "KT128",
+// ...), or the Go type for hashes from other packages.
+func AlgorithmName(h stdhash.Hash) string {
+	if n, ok := h.(Named); ok {
+		return n.Algorithm()
+	}
+	return fmt.Sprintf("%T", h)
+}
+
+var _ io.ReaderFrom = (*MultiHash)(nil)
@@ -14,6 +14,7 @@ const (
 )
 
 type sha2Hasher struct {
+	name string
 	size int
 	sum  func([]byte) []byte
 }
@@ -23,14 +24,16 @@ func (h sha2Hasher) Hash(msg []byte) []byte { return h.sum(msg) }
 func (h sha2Hasher) Size() int { return h.size }
 
 type sha2Digest struct {
-	state  stdhash.Hash
-	hasher sha2Hasher
+	state    stdState
+	newState func() stdhash.Hash
+	hasher   sha2Hasher
 }
 
 func newSHA2Digest(newState func() stdhash.Hash, hasher sha2Hasher) *sha2Digest {
 	return &sha2Digest{
-		state:  newState(),
-		hasher: hasher,
+		state:    newState().(stdState),
+		newState: newState,
+		hasher:   hasher,
 	}
 }
 
@@ -46,6 +49,32 @@ func (d *sha2Digest) BlockSize() int { return d.state.BlockSize() }
 
 func (d *sha2Digest) Hash(msg []byte) []byte { return d.hasher.Hash(msg) }
 
+// Algorithm returns the SHA-2 variant, such as "SHA-256" or "SHA-512/256".
+func (d *sha2Digest) Algorithm() string { return d.hasher.name }
+
+// MarshalBinary encodes the running state in the library's versioned format.
+func (d *sha2Digest) MarshalBinary() ([]byte, error) {
+	return marshalStd(d.hasher.name, d.state)
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on the same
+// SHA-2 variant.
+func (d *sha2Digest) UnmarshalBinary(b []byte) error {
+	state := d.newState().(stdState)
+	if err := unmarshalStd(d.hasher.name, b, state); err != nil {
+		return err
+	}
+	d.state = state
+	return nil
+}
+
+// Clone returns an independent copy of the hash.
+func (d *sha2Digest) Clone() stdhash.Hash {
+	c := *d
+	c.state = cloneStd(d.state, d.newState().(stdState))
+	return &c
+}
+
 func sha224Sum(msg []byte) []byte {
 	sum := sha256.Sum224(msg)
 	return append([]byte(nil), sum[:]...)
@@ -66,16 +95,30 @@ func sha512Sum(msg []byte) []byte {
 	return append([]byte(nil), sum[:]...)
 }
 
+func sha512_224Sum(msg []byte) []byte {
+	sum := sha512.Sum512_224(msg)
+	return append([]byte(nil), sum[:]...)
+}
+
+func sha512_256Sum(msg []byte) []byte {
+	sum := sha512.Sum512_256(msg)
+	return append([]byte(nil), sum[:]...)
+}
+
 var (
-	sha2Variant224 = sha2Hasher{size: sha2Size224, sum: sha224Sum}
-	sha2Variant256 = sha2Hasher{size: sha2Size256, sum: sha256Sum}
-	sha2Variant384 = sha2Hasher{size: sha2Size384, sum: sha384Sum}
-	sha2Variant512 = sha2Hasher{size: sha2Size512, sum: sha512Sum}
+	sha2Variant224 = sha2Hasher{name: "SHA-224", size: sha2Size224, sum: sha224Sum}
+	sha2Variant256 = sha2Hasher{name: "SHA-256", size: sha2Size256, sum: sha256Sum}
+	sha2Variant384 = sha2Hasher{name: "SHA-384", size: sha2Size384, sum: sha384Sum}
+	sha2Variant512 = sha2Hasher{name: "SHA-512", size: sha2Size512, sum: sha512Sum}
+
+	sha2Variant512_224 = sha2Hasher{name: "SHA-512/224", size: sha2Size224, sum: sha512_224Sum}
+	sha2Variant512_256 = sha2Hasher{name: "SHA-512/256", size: sha2Size256, sum: sha512_256Sum}
 )
 
 var (
 	_ stdhash.Hash = (*sha2Digest)(nil)
 	_ Hasher       = (*sha2Digest)(nil)
+	_ Cloner       = (*sha2Digest)(nil)
 )
 
 // NewSHA224 returns a hash computing the SHA-224 digest.
@@ -113,3 +156,21 @@ func NewSHA512Hasher() Hasher { return sha2Variant512 }
 
 // SumSHA512 returns the SHA-512 digest of the input.
 func SumSHA512(data []byte) [sha2Size512]byte { return sha512.Sum512(data) }
+
+// NewSHA512_224 returns a hash computing the SHA-512/224 digest.
+func NewSHA512_224() stdhash.Hash { return newSHA2Digest(sha512.New512_224, sha2Variant512_224) }
+
+// NewSHA512_224Hasher returns a stateless SHA-512/224 helper implementing hash.Hasher.
+func NewSHA512_224Hasher() Hasher { return sha2Variant512_224 }
+
+// SumSHA512_224 returns the SHA-512/224 digest of the input.
+func SumSHA512_224(data []byte) [sha2Size224]byte { return sha512.Sum512_224(data) }
+
+// NewSHA512_256 returns a hash computing the SHA-512/256 digest.
+func NewSHA512_256() stdhash.Hash { return newSHA2Digest(sha512.New512_256, sha2Variant512_256) }
+
+// NewSHA512_256Hasher returns a stateless SHA-512/256 helper implementing hash.Hasher.
+func NewSHA512_256Hasher() Hasher { return sha2Variant512_256 }
+
+// SumSHA512_256 returns the SHA-512/256 digest of the input.
+func SumSHA512_256(data []byte) [sha2Size256]byte { return sha512.Sum512_256(data) }
@@ -13,6 +13,7 @@ const (
 )
 
 type sha3Hasher struct {
+	name string
 	size int
 	sum  func([]byte) []byte
 }
@@ -24,14 +25,16 @@ func (h sha3Hasher) Hash(msg []byte) []byte {
 func (h sha3Hasher) Size() int { return h.size }
 
 type sha3Digest struct {
-	state  *sha3.SHA3
-	hasher sha3Hasher
+	state    *sha3.SHA3
+	newState func() *sha3.SHA3
+	hasher   sha3Hasher
 }
 
 func newSHA3Digest(newState func() *sha3.SHA3, hasher sha3Hasher) *sha3Digest {
 	return &sha3Digest{
-		state:  newState(),
-		hasher: hasher,
+		state:    newState(),
+		newState: newState,
+		hasher:   hasher,
 	}
 }
 
@@ -47,6 +50,32 @@ func (d *sha3Digest) BlockSize() int { return d.state.BlockSize() }
 
 func (d *sha3Digest) Hash(msg []byte) []byte { return d.hasher.Hash(msg) }
 
+// Algorithm returns the SHA-3 variant, such as "SHA3-256".
+func (d *sha3Digest) Algorithm() string { return d.hasher.name }
+
+// MarshalBinary encodes the running state in the library's versioned format.
+func (d *sha3Digest) MarshalBinary() ([]byte, error) {
+	return marshalStd(d.hasher.name, d.state)
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on the same
+// SHA-3 variant.
+func (d *sha3Digest) UnmarshalBinary(b []byte) error {
+	state := d.newState()
+	if err := unmarshalStd(d.hasher.name, b, state); err != nil {
+		return err
+	}
+	d.state = state
+	return nil
+}
+
+// Clone returns an independent copy of the hash.
+func (d *sha3Digest) Clone() stdhash.Hash {
+	c := *d
+	c.state = cloneStd(d.state, d.newState())
+	return &c
+}
+
 func sha3Sum224(msg []byte) []byte {
 	sum := sha3.Sum224(msg)
 	return append([]byte(nil), sum[:]...)
@@ -68,15 +97,16 @@ func sha3Sum512(msg []byte) []byte {
 }
 
 var (
-	sha3Variant224 = sha3Hasher{size: sha3Size224, sum: sha3Sum224}
-	sha3Variant256 = sha3Hasher{size: sha3Size256, sum: sha3Sum256}
-	sha3Variant384 = sha3Hasher{size: sha3Size384, sum: sha3Sum384}
-	sha3Variant512 = sha3Hasher{size: sha3Size512, sum: sha3Sum512}
+	sha3Variant224 = sha3Hasher{name: "SHA3-224", size: sha3Size224, sum: sha3Sum224}
+	sha3Variant256 = sha3Hasher{name: "SHA3-256", size: sha3Size256, sum: sha3Sum256}
+	sha3Variant384 = sha3Hasher{name: "SHA3-384", size: sha3Size384, sum: sha3Sum384}
+	sha3Variant512 = sha3Hasher{name: "SHA3-512", size: sha3Size512, sum: sha3Sum512}
 )
 
 var (
 	_ stdhash.Hash = (*sha3Digest)(nil)
 	_ Hasher       = (*sha3Digest)(nil)
+	_ Cloner       = (*sha3Digest)(nil)
 )
 
 // NewSHA3224 returns a hash computing the SHA3-224 digest.
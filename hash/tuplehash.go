@@ -1,6 +1,14 @@
 package hash
 
-import "github.com/AeonDave/cryptonite-go/internal/keccak"
+import (
+	"errors"
+	"fmt"
+	stdhash "hash"
+	"io"
+
+	"github.com/AeonDave/cryptonite-go/internal/keccak"
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
 
 // TupleHash128 returns the TupleHash-128 digest of the provided tuple, producing
 // outLen bytes of output. The optional customization string may be nil.
@@ -24,3 +32,223 @@ func ParallelHash128(msg []byte, blockSize int, outLen int, customization []byte
 func ParallelHash256(msg []byte, blockSize int, outLen int, customization []byte) ([]byte, error) {
 	return keccak.ParallelHash256(msg, blockSize, customization, outLen)
 }
+
+// TupleHashXOF128 returns outLen bytes of TupleHashXOF-128 output for the
+// provided tuple. The optional customization string may be nil.
+func TupleHashXOF128(tuple [][]byte, outLen int, customization []byte) ([]byte, error) {
+	return keccak.TupleHashXOF128(tuple, customization, outLen)
+}
+
+// TupleHashXOF256 returns outLen bytes of TupleHashXOF-256 output.
+func TupleHashXOF256(tuple [][]byte, outLen int, customization []byte) ([]byte, error) {
+	return keccak.TupleHashXOF256(tuple, customization, outLen)
+}
+
+// ParallelHashXOF128 returns outLen bytes of ParallelHashXOF-128 output over
+// msg using the specified block size.
+func ParallelHashXOF128(msg []byte, blockSize int, outLen int, customization []byte) ([]byte, error) {
+	return keccak.ParallelHashXOF128(msg, blockSize, customization, outLen)
+}
+
+// ParallelHashXOF256 returns outLen bytes of ParallelHashXOF-256 output over
+// msg using the specified block size.
+func ParallelHashXOF256(msg []byte, blockSize int, outLen int, customization []byte) ([]byte, error) {
+	return keccak.ParallelHashXOF256(msg, blockSize, customization, outLen)
+}
+
+// TupleHash incrementally builds a TupleHash digest, absorbing one tuple
+// element at a time so large tuples need not be held in memory together.
+type TupleHash struct {
+	state  *keccak.TupleHash
+	rate   int
+	outLen int
+}
+
+// NewTupleHash128 returns an incremental TupleHash-128 builder producing
+// outLen-byte digests.
+func NewTupleHash128(outLen int, customization []byte) (*TupleHash, error) {
+	if outLen <= 0 {
+		return nil, errors.New("hash: invalid TupleHash128 output length")
+	}
+	return &TupleHash{state: keccak.NewTupleHash(168, customization), rate: 168, outLen: outLen}, nil
+}
+
+// NewTupleHash256 returns an incremental TupleHash-256 builder producing
+// outLen-byte digests.
+func NewTupleHash256(outLen int, customization []byte) (*TupleHash, error) {
+	if outLen <= 0 {
+		return nil, errors.New("hash: invalid TupleHash256 output length")
+	}
+	return &TupleHash{state: keccak.NewTupleHash(136, customization), rate: 136, outLen: outLen}, nil
+}
+
+// WriteElement appends element to the tuple. Element boundaries are part of
+// the digest, so ("ab", "c") and ("a", "bc") hash differently.
+func (t *TupleHash) WriteElement(element []byte) {
+	t.state.WriteElement(element)
+}
+
+// Sum appends the TupleHash digest of the elements written so far to b. The
+// builder remains usable and further elements may be appended.
+func (t *TupleHash) Sum(b []byte) []byte {
+	out := make([]byte, t.outLen)
+	t.state.Sum(out)
+	return append(b, out...)
+}
+
+// XOF returns a reader over the TupleHashXOF output of the elements written
+// so far. The builder remains usable.
+func (t *TupleHash) XOF() io.Reader { return t.state.XOF() }
+
+// Reset discards all elements written so far.
+func (t *TupleHash) Reset() { t.state.Reset() }
+
+// Size returns the digest length in bytes produced by Sum.
+func (t *TupleHash) Size() int { return t.outLen }
+
+// Algorithm returns "TupleHash128" or "TupleHash256".
+func (t *TupleHash) Algorithm() string {
+	if t.rate == 168 {
+		return "TupleHash128"
+	}
+	return "TupleHash256"
+}
+
+// MarshalBinary encodes the output length, customization string and the
+// elements absorbed so far.
+func (t *TupleHash) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder(t.Algorithm())
+	e.Uint64(uint64(t.outLen))
+	t.state.Encode(e)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on a builder
+// with the same function, output length and customization string.
+func (t *TupleHash) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, t.Algorithm())
+	if err != nil {
+		return err
+	}
+	d.Expect(uint64(t.outLen))
+	state := t.state.Clone()
+	state.Decode(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	t.state = state
+	return nil
+}
+
+// Clone returns an independent copy of the builder.
+func (t *TupleHash) Clone() *TupleHash {
+	c := *t
+	c.state = t.state.Clone()
+	return &c
+}
+
+// ParallelHash is a streaming ParallelHash implementing hash.Hash. Input is
+// split into blockSize-byte blocks whose inner digests are computed across
+// goroutines once enough data has been buffered, so large inputs hash faster
+// on multi-core machines without being held in memory.
+type ParallelHash struct {
+	state  *keccak.ParallelHash
+	rate   int
+	outLen int
+}
+
+// NewParallelHash128 returns a streaming ParallelHash-128 producing
+// outLen-byte digests over blocks of blockSize bytes.
+func NewParallelHash128(blockSize int, outLen int, customization []byte) (*ParallelHash, error) {
+	return newParallelHash(168, blockSize, outLen, customization, "ParallelHash128")
+}
+
+// NewParallelHash256 returns a streaming ParallelHash-256 producing
+// outLen-byte digests over blocks of blockSize bytes.
+func NewParallelHash256(blockSize int, outLen int, customization []byte) (*ParallelHash, error) {
+	return newParallelHash(136, blockSize, outLen, customization, "ParallelHash256")
+}
+
+func newParallelHash(rate, blockSize, outLen int, customization []byte, alg string) (*ParallelHash, error) {
+	if blockSize <= 0 {
+		return nil, fmt.Errorf("hash: invalid block size for %s", alg)
+	}
+	if outLen <= 0 {
+		return nil, fmt.Errorf("hash: invalid %s output length", alg)
+	}
+	return &ParallelHash{
+		state:  keccak.NewParallelHash(rate, blockSize, customization),
+		rate:   rate,
+		outLen: outLen,
+	}, nil
+}
+
+// Write absorbs p. It never returns an error.
+func (p *ParallelHash) Write(data []byte) (int, error) {
+	p.state.Write(data)
+	return len(data), nil
+}
+
+// Sum appends the ParallelHash digest of the data written so far to b without
+// modifying the running state.
+func (p *ParallelHash) Sum(b []byte) []byte {
+	out := make([]byte, p.outLen)
+	p.state.Sum(out)
+	return append(b, out...)
+}
+
+// XOF returns a reader over the ParallelHashXOF output of the data written so
+// far. The running state remains usable.
+func (p *ParallelHash) XOF() io.Reader { return p.state.XOF() }
+
+// Reset discards all data written so far.
+func (p *ParallelHash) Reset() { p.state.Reset() }
+
+// Size returns the digest length in bytes produced by Sum.
+func (p *ParallelHash) Size() int { return p.outLen }
+
+// BlockSize returns the rate of the underlying cSHAKE sponge.
+func (p *ParallelHash) BlockSize() int { return p.rate }
+
+// Algorithm returns "ParallelHash128" or "ParallelHash256".
+func (p *ParallelHash) Algorithm() string {
+	if p.rate == 168 {
+		return "ParallelHash128"
+	}
+	return "ParallelHash256"
+}
+
+// MarshalBinary encodes the block size, output length, customization string
+// and running state, including any blocks buffered for the next batch.
+func (p *ParallelHash) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder(p.Algorithm())
+	e.Uint64(uint64(p.outLen))
+	p.state.Encode(e)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on a hash with
+// the same function, block size, output length and customization string.
+func (p *ParallelHash) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, p.Algorithm())
+	if err != nil {
+		return err
+	}
+	d.Expect(uint64(p.outLen))
+	state := p.state.Clone()
+	state.Decode(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	p.state = state
+	return nil
+}
+
+// Clone returns an independent copy of the hash.
+func (p *ParallelHash) Clone() *ParallelHash {
+	c := *p
+	c.state = p.state.Clone()
+	return &c
+}
+
+var _ stdhash.Hash = (*ParallelHash)(nil)
@@ -3,6 +3,7 @@ package hash
 import (
 	stdhash "hash"
 
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
 	xo "github.com/AeonDave/cryptonite-go/internal/xoodyak"
 )
 
@@ -50,6 +51,37 @@ func (h *Hash) Hash(msg []byte) []byte {
 func (h *Hash) Size() int      { return DigestSize }
 func (h *Hash) BlockSize() int { return xo.HashRate }
 
+// Algorithm returns "Xoodyak".
+func (h *Hash) Algorithm() string { return "Xoodyak" }
+
+// MarshalBinary encodes the Cyclist state in the library's versioned format.
+func (h *Hash) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder("Xoodyak")
+	h.inst.Encode(e)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary.
+func (h *Hash) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, "Xoodyak")
+	if err != nil {
+		return err
+	}
+	inst := h.inst
+	inst.Decode(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	h.inst = inst
+	return nil
+}
+
+// Clone returns an independent copy of the hash.
+func (h *Hash) Clone() stdhash.Hash {
+	c := *h
+	return &c
+}
+
 // SumXoodyak returns the Xoodyak hash of msg.
 func SumXoodyak(msg []byte) [DigestSize]byte {
 	var inst xo.Instance
@@ -72,3 +104,5 @@ func (xoodyakHasher) Hash(msg []byte) []byte {
 }
 
 func (xoodyakHasher) Size() int { return DigestSize }
+
+var _ Cloner = (*Hash)(nil)
@@ -0,0 +1,162 @@
+package hash2curve
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/AeonDave/cryptonite-go/internal/primefield"
+)
+
+func mustBig(hex string) *big.Int {
+	v, ok := new(big.Int).SetString(hex, 16)
+	if !ok {
+		panic("hash2curve: invalid constant")
+	}
+	return v
+}
+
+var (
+	p256Curve = sync.OnceValue(func() curve {
+		return newWeierstrass(
+			mustBig("ffffffff00000001000000000000000000000000ffffffffffffffffffffffff"),
+			mustBig("5ac635d8aa3a93e7b3ebbd55769886bc651d06b0cc53b0f63bce3c3e27d2604b"),
+			-10)
+	})
+	p384Curve = sync.OnceValue(func() curve {
+		return newWeierstrass(
+			mustBig("fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff"),
+			mustBig("b3312fa7e23ee7e4988e056be3f82d19181d9c6efe8141120314088f5013875ac656398d8a2ed19d2a85c8edd3ec2aef"),
+			-12)
+	})
+	p521Curve = sync.OnceValue(func() curve {
+		return newWeierstrass(
+			mustBig("01ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+			mustBig("0051953eb9618e1c9a1f929a21a0b68540eea2da725b99b315f3b8b489918ef109e156193951ec7e937b1652c0bd3bb1bf073573df883d2c34f1ef451fd46b503f00"),
+			-4)
+	})
+
+	// Curve25519: J = 486662, Z = 2, cofactor 8.
+	curve25519Map = sync.OnceValue(func() *montgomery {
+		return newMontgomery(primefield.New(mustBig("7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffed")), 486662, 2)
+	})
+	// Curve448: J = 156326, Z = -1, cofactor 4.
+	curve448Map = sync.OnceValue(func() *montgomery {
+		return newMontgomery(primefield.NewP448(), 156326, -1)
+	})
+
+	curve25519Curve = sync.OnceValue(func() curve { return newEll2Montgomery(curve25519Map(), 3) })
+	curve448Curve   = sync.OnceValue(func() curve { return newEll2Montgomery(curve448Map(), 2) })
+
+	edwards25519Curve = sync.OnceValue(func() curve {
+		m := curve25519Map()
+		f := m.f
+		e := &edwards{f: f, a: f.FromBig(big.NewInt(-1))}
+		var t fe
+		e.d = f.FromBig(big.NewInt(121666))
+		f.Inv(&e.d, &e.d)
+		t = f.FromBig(big.NewInt(-121665))
+		f.Mul(&e.d, &e.d, &t)
+
+		// c1 = sqrt(-486664) with sgn0(c1) = 0.
+		var c1, nc1 fe
+		t = f.FromBig(big.NewInt(-486664))
+		f.Sqrt(&c1, &t)
+		f.Neg(&nc1, &c1)
+		f.CMov(&c1, &nc1, f.Sgn0(&c1))
+
+		c := &ell2Curve{m: m, e: e, encode: e.encode, doublings: 3}
+		// Rational map of RFC 9380, Section 6.8.2:
+		// (x, y) = (c1*s/t, (s-1)/(s+1)), and (0, 1) when t = 0 or s = -1.
+		c.toEdwards = func(s, t *fe) ePoint {
+			var x, y, den fe
+			f.Inv(&x, t)
+			f.Mul(&x, &x, s)
+			f.Mul(&x, &x, &c1)
+			f.Add(&den, s, f.One())
+			exceptional := f.IsZero(t) | f.IsZero(&den)
+			f.Inv(&den, &den)
+			f.Sub(&y, s, f.One())
+			f.Mul(&y, &y, &den)
+			var zero fe
+			f.CMov(&x, &zero, exceptional)
+			f.CMov(&y, f.One(), exceptional)
+			return e.fromAffine(&x, &y)
+		}
+		return c
+	})
+
+	edwards448Curve = sync.OnceValue(func() curve {
+		m := curve448Map()
+		f := m.f
+		e := &edwards{f: f, a: *f.One(), d: f.FromBig(big.NewInt(-39081))}
+		c := &ell2Curve{m: m, e: e, encode: e.encode, doublings: 2}
+		// 4-isogeny of RFC 7748, Section 4.2, mapping (0, 1) when either
+		// denominator vanishes:
+		//   x = 4v(u^2-1) / (u^4 - 2u^2 + 4v^2 + 1)
+		//   y = -(u^5 - 2u^3 - 4uv^2 + u) / (u^5 - 2u^2v^2 - 2u^3 - 2v^2 + u)
+		c.toEdwards = func(u, v *fe) ePoint {
+			var u2, u3, u4, u5, v2, uv2, t fe
+			f.Sqr(&u2, u)
+			f.Mul(&u3, &u2, u)
+			f.Sqr(&u4, &u2)
+			f.Mul(&u5, &u4, u)
+			f.Sqr(&v2, v)
+			f.Mul(&uv2, u, &v2)
+
+			var xn, xd, yn, yd fe
+			f.Sub(&xn, &u2, f.One())
+			f.Mul(&xn, &xn, v)
+			f.Add(&xn, &xn, &xn)
+			f.Add(&xn, &xn, &xn)
+
+			f.Add(&t, &u2, &u2)
+			f.Sub(&xd, &u4, &t)
+			f.Add(&t, &v2, &v2)
+			f.Add(&t, &t, &t)
+			f.Add(&xd, &xd, &t)
+			f.Add(&xd, &xd, f.One())
+
+			f.Add(&t, &u3, &u3)
+			f.Sub(&yn, &u5, &t)
+			f.Add(&t, &uv2, &uv2)
+			f.Add(&t, &t, &t)
+			f.Sub(&yn, &yn, &t)
+			f.Add(&yn, &yn, u)
+			f.Neg(&yn, &yn)
+
+			f.Mul(&t, &u2, &v2)
+			f.Add(&t, &t, &t)
+			f.Sub(&yd, &u5, &t)
+			f.Add(&t, &u3, &u3)
+			f.Sub(&yd, &yd, &t)
+			f.Add(&t, &v2, &v2)
+			f.Sub(&yd, &yd, &t)
+			f.Add(&yd, &yd, u)
+
+			var den, x, y fe
+			f.Mul(&den, &xd, &yd)
+			exceptional := f.IsZero(&den)
+			f.Inv(&den, &den)
+			f.Mul(&x, &xn, &yd)
+			f.Mul(&x, &x, &den)
+			f.Mul(&y, &yn, &xd)
+			f.Mul(&y, &y, &den)
+			var zero fe
+			f.CMov(&x, &zero, exceptional)
+			f.CMov(&y, f.One(), exceptional)
+			return e.fromAffine(&x, &y)
+		}
+		return c
+	})
+)
+
+func (c *weierstrass) field() *primefield.Field { return c.f }
+
+func (c *weierstrass) hashToCurve(u []fe) ([]byte, error) {
+	p := c.mapToCurve(&u[0])
+	for i := 1; i < len(u); i++ {
+		q := c.mapToCurve(&u[i])
+		c.add(&p, &p, &q)
+	}
+	return c.encode(&p)
+}
@@ -0,0 +1,195 @@
+package hash2curve
+
+import (
+	"math/big"
+
+	"github.com/AeonDave/cryptonite-go/internal/primefield"
+)
+
+// montgomery is a Montgomery curve t^2 = s^3 + J*s^2 + s (K = 1), mapped to
+// with Elligator 2.
+type montgomery struct {
+	f          *primefield.Field
+	j, negJ, z fe
+}
+
+func newMontgomery(f *primefield.Field, j, z int64) *montgomery {
+	m := &montgomery{f: f, j: f.FromBig(big.NewInt(j)), z: f.FromBig(big.NewInt(z))}
+	f.Neg(&m.negJ, &m.j)
+	return m
+}
+
+// gx sets z = x^3 + J*x^2 + x.
+func (m *montgomery) gx(z, x *fe) {
+	f := m.f
+	var t fe
+	f.Add(&t, x, &m.j)
+	f.Mul(&t, &t, x)
+	f.Add(&t, &t, f.One())
+	f.Mul(z, &t, x)
+}
+
+// mapToCurve implements the Elligator 2 map (RFC 9380, Section 6.7.1)
+// without secret-dependent branches and returns the point (s, t).
+func (m *montgomery) mapToCurve(u *fe) (s, t fe) {
+	f := m.f
+	var x1, x2, gx1, gx2 fe
+	f.Sqr(&x1, u)
+	f.Mul(&x1, &x1, &m.z)
+	f.Add(&x1, &x1, f.One())
+	f.Inv(&x1, &x1)
+	f.Mul(&x1, &x1, &m.negJ)
+	f.CMov(&x1, &m.negJ, f.IsZero(&x1))
+	m.gx(&gx1, &x1)
+	f.Sub(&x2, &m.negJ, &x1)
+	m.gx(&gx2, &x2)
+
+	e := f.IsSquare(&gx1)
+	f.CMov(&x2, &x1, e)
+	f.CMov(&gx2, &gx1, e)
+	f.Sqrt(&t, &gx2)
+	// The root is odd for x1 and even for x2.
+	var nt fe
+	f.Neg(&nt, &t)
+	f.CMov(&t, &nt, f.Sgn0(&t)^e)
+	return x2, t
+}
+
+// edwards is a twisted Edwards curve a*x^2 + y^2 = 1 + d*x^2*y^2 with a
+// square and d non-square, so that the unified addition law is complete.
+type edwards struct {
+	f    *primefield.Field
+	a, d fe
+}
+
+// ePoint is a point in extended coordinates (X:Y:Z:T) with x = X/Z,
+// y = Y/Z and x*y = T/Z.
+type ePoint struct{ x, y, z, t fe }
+
+func (c *edwards) fromAffine(x, y *fe) ePoint {
+	p := ePoint{x: *x, y: *y, z: *c.f.One()}
+	c.f.Mul(&p.t, x, y)
+	return p
+}
+
+// add sets r = p + q with the unified formulas of Hisil, Wong, Carter and
+// Dawson ("add-2008-hwcd").
+func (c *edwards) add(r, p, q *ePoint) {
+	f := c.f
+	var a, b, cc, d, e, g, h, t fe
+	f.Mul(&a, &p.x, &q.x)
+	f.Mul(&b, &p.y, &q.y)
+	f.Mul(&cc, &p.t, &q.t)
+	f.Mul(&cc, &cc, &c.d)
+	f.Mul(&d, &p.z, &q.z)
+	f.Add(&e, &p.x, &p.y)
+	f.Add(&t, &q.x, &q.y)
+	f.Mul(&e, &e, &t)
+	f.Sub(&e, &e, &a)
+	f.Sub(&e, &e, &b)
+	f.Sub(&t, &d, &cc) // F
+	f.Add(&g, &d, &cc)
+	f.Mul(&h, &a, &c.a)
+	f.Sub(&h, &b, &h)
+	f.Mul(&r.x, &e, &t)
+	f.Mul(&r.y, &g, &h)
+	f.Mul(&r.t, &e, &h)
+	f.Mul(&r.z, &t, &g)
+}
+
+func (c *edwards) toAffine(p *ePoint) (x, y fe) {
+	var zi fe
+	c.f.Inv(&zi, &p.z)
+	c.f.Mul(&x, &p.x, &zi)
+	c.f.Mul(&y, &p.y, &zi)
+	return x, y
+}
+
+// encode returns the RFC 8032 encoding: y in little-endian with the parity
+// of x in the most significant bit. Edwards448 uses one extra byte.
+func (c *edwards) encode(p *ePoint) ([]byte, error) {
+	x, y := c.toAffine(p)
+	out := c.f.BytesLE(&y)
+	if c.f.BitLen()%8 == 0 {
+		out = append(out, 0)
+	}
+	out[len(out)-1] |= byte(c.f.Sgn0(&x) << 7)
+	return out, nil
+}
+
+// ell2Curve hashes to a Montgomery curve or a twisted Edwards curve through
+// Elligator 2 on the Montgomery curve. Points are added and cofactors
+// cleared in the Edwards model, where the formulas are complete.
+type ell2Curve struct {
+	m *montgomery
+	e *edwards
+	// toEdwards maps the output of m.mapToCurve to e.
+	toEdwards func(s, t *fe) ePoint
+	encode    func(p *ePoint) ([]byte, error)
+	// doublings is log2 of the cofactor.
+	doublings int
+}
+
+func (c *ell2Curve) field() *primefield.Field { return c.m.f }
+
+func (c *ell2Curve) hashToCurve(u []fe) ([]byte, error) {
+	s, t := c.m.mapToCurve(&u[0])
+	p := c.toEdwards(&s, &t)
+	for i := 1; i < len(u); i++ {
+		s, t = c.m.mapToCurve(&u[i])
+		q := c.toEdwards(&s, &t)
+		c.e.add(&p, &p, &q)
+	}
+	for i := 0; i < c.doublings; i++ {
+		c.e.add(&p, &p, &p)
+	}
+	return c.encode(&p)
+}
+
+// newEll2Montgomery returns the suite curve for the Montgomery curve itself.
+// Its points are carried on the birationally equivalent Edwards curve with
+// a = J+2 and d = J-2, x = s/t and y = (s-1)/(s+1); when J+2 is not a
+// square, a and d are swapped by inverting y, which keeps the law complete.
+func newEll2Montgomery(m *montgomery, doublings int) *ell2Curve {
+	f := m.f
+	var two fe
+	f.Add(&two, f.One(), f.One())
+	e := &edwards{f: f}
+	f.Add(&e.a, &m.j, &two)
+	f.Sub(&e.d, &m.j, &two)
+	flip := f.IsSquare(&e.a) == 0
+	if flip {
+		e.a, e.d = e.d, e.a
+	}
+	c := &ell2Curve{m: m, e: e, doublings: doublings}
+	c.toEdwards = func(s, t *fe) ePoint {
+		var x, y, num, den fe
+		f.Inv(&x, t)
+		f.Mul(&x, &x, s)
+		f.Sub(&num, s, f.One())
+		f.Add(&den, s, f.One())
+		if flip {
+			num, den = den, num
+		}
+		f.Inv(&den, &den)
+		f.Mul(&y, &num, &den)
+		return e.fromAffine(&x, &y)
+	}
+	c.encode = func(p *ePoint) ([]byte, error) {
+		x, y := e.toAffine(p)
+		if f.IsZero(&x) == 1 {
+			return nil, errIdentity
+		}
+		// s = (1+y)/(1-y), negated when y was inverted.
+		var num, den, s fe
+		f.Add(&num, f.One(), &y)
+		f.Sub(&den, f.One(), &y)
+		if flip {
+			f.Neg(&den, &den)
+		}
+		f.Inv(&den, &den)
+		f.Mul(&s, &num, &den)
+		return f.BytesLE(&s), nil
+	}
+	return c
+}
@@ -0,0 +1,205 @@
+// Package hash2curve implements hashing to elliptic curves as specified in
+// RFC 9380, for protocols such as OPRFs, PAKEs and BLS-style signatures that
+// need a point whose discrete logarithm is unknown.
+//
+// Every suite of RFC 9380, Section 8 on P-256, P-384, P-521, curve25519,
+// edwards25519, curve448 and edwards448 is available in its random oracle
+// (RO, hash_to_curve) and nonuniform (NU, encode_to_curve) variants. Field
+// elements are derived with expand_message_xmd over the hash package or
+// expand_message_xof over the xof package, and the maps, point additions and
+// cofactor clearing run in constant time.
+//
+// Points are returned in the encoding the rest of the library consumes:
+// uncompressed SEC 1 for the NIST curves (as accepted by ecdh.NewPublicKeyP256
+// and friends), the RFC 8032 encoding for edwards25519 and edwards448, and
+// the little-endian u-coordinate for curve25519 and curve448 (an X25519 or
+// X448 public key).
+//
+// The base field arithmetic comes from internal/primefield. The ecdh package
+// cannot supply it for P-256, P-384, P-521 or X25519, which wrap crypto/ecdh
+// and its unexported field elements, so those fields use primefield's
+// Montgomery arithmetic, while the 448 suites run on the internal/x448 field.
+package hash2curve
+
+import (
+	"errors"
+	stdhash "hash"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	"github.com/AeonDave/cryptonite-go/internal/primefield"
+	"github.com/AeonDave/cryptonite-go/xof"
+)
+
+var (
+	errEmptyDST     = errors.New("hash2curve: domain separation tag must not be empty")
+	errLength       = errors.New("hash2curve: requested output length out of range")
+	errCount        = errors.New("hash2curve: field element count must be positive")
+	errIdentity     = errors.New("hash2curve: hashed to the identity element")
+	errUnknownSuite = errors.New("hash2curve: unknown suite")
+)
+
+// fe is an element of the curve's base field.
+type fe = primefield.Element
+
+// curve maps field elements to a point, adding the images of several
+// elements, clears the cofactor and encodes the result.
+type curve interface {
+	field() *primefield.Field
+	hashToCurve(u []fe) ([]byte, error)
+}
+
+// Suite is an RFC 9380 hash-to-curve suite. It is safe for concurrent use.
+type Suite struct {
+	id string
+	ro bool
+	// l is the number of uniform bytes reduced into each field element.
+	l      int
+	expand func(msg, dst []byte, length int) ([]byte, error)
+	curve  func() curve
+}
+
+func xmd(h func() stdhash.Hash) func(msg, dst []byte, length int) ([]byte, error) {
+	return func(msg, dst []byte, length int) ([]byte, error) {
+		return ExpandMessageXMD(h, msg, dst, length)
+	}
+}
+
+func shake256(k int) func(msg, dst []byte, length int) ([]byte, error) {
+	return func(msg, dst []byte, length int) ([]byte, error) {
+		return ExpandMessageXOF(xof.SHAKE256, k, msg, dst, length)
+	}
+}
+
+var (
+	p256RO         = &Suite{"P256_XMD:SHA-256_SSWU_RO_", true, 48, xmd(cryptohash.NewSHA256), p256Curve}
+	p256NU         = &Suite{"P256_XMD:SHA-256_SSWU_NU_", false, 48, xmd(cryptohash.NewSHA256), p256Curve}
+	p384RO         = &Suite{"P384_XMD:SHA-384_SSWU_RO_", true, 72, xmd(cryptohash.NewSHA384), p384Curve}
+	p384NU         = &Suite{"P384_XMD:SHA-384_SSWU_NU_", false, 72, xmd(cryptohash.NewSHA384), p384Curve}
+	p521RO         = &Suite{"P521_XMD:SHA-512_SSWU_RO_", true, 98, xmd(cryptohash.NewSHA512), p521Curve}
+	p521NU         = &Suite{"P521_XMD:SHA-512_SSWU_NU_", false, 98, xmd(cryptohash.NewSHA512), p521Curve}
+	curve25519RO   = &Suite{"curve25519_XMD:SHA-512_ELL2_RO_", true, 48, xmd(cryptohash.NewSHA512), curve25519Curve}
+	curve25519NU   = &Suite{"curve25519_XMD:SHA-512_ELL2_NU_", false, 48, xmd(cryptohash.NewSHA512), curve25519Curve}
+	edwards25519RO = &Suite{"edwards25519_XMD:SHA-512_ELL2_RO_", true, 48, xmd(cryptohash.NewSHA512), edwards25519Curve}
+	edwards25519NU = &Suite{"edwards25519_XMD:SHA-512_ELL2_NU_", false, 48, xmd(cryptohash.NewSHA512), edwards25519Curve}
+	curve448RO     = &Suite{"curve448_XOF:SHAKE256_ELL2_RO_", true, 84, shake256(224), curve448Curve}
+	curve448NU     = &Suite{"curve448_XOF:SHAKE256_ELL2_NU_", false, 84, shake256(224), curve448Curve}
+	edwards448RO   = &Suite{"edwards448_XOF:SHAKE256_ELL2_RO_", true, 84, shake256(224), edwards448Curve}
+	edwards448NU   = &Suite{"edwards448_XOF:SHAKE256_ELL2_NU_", false, 84, shake256(224), edwards448Curve}
+
+	suites = []*Suite{
+		p256RO, p256NU, p384RO, p384NU, p521RO, p521NU,
+		curve25519RO, curve25519NU, edwards25519RO, edwards25519NU,
+		curve448RO, curve448NU, edwards448RO, edwards448NU,
+	}
+)
+
+// P256RO returns the P256_XMD:SHA-256_SSWU_RO_ suite.
+func P256RO() *Suite { return p256RO }
+
+// P256NU returns the P256_XMD:SHA-256_SSWU_NU_ suite.
+func P256NU() *Suite { return p256NU }
+
+// P384RO returns the P384_XMD:SHA-384_SSWU_RO_ suite.
+func P384RO() *Suite { return p384RO }
+
+// P384NU returns the P384_XMD:SHA-384_SSWU_NU_ suite.
+func P384NU() *Suite { return p384NU }
+
+// P521RO returns the P521_XMD:SHA-512_SSWU_RO_ suite.
+func P521RO() *Suite { return p521RO }
+
+// P521NU returns the P521_XMD:SHA-512_SSWU_NU_ suite.
+func P521NU() *Suite { return p521NU }
+
+// Curve25519RO returns the curve25519_XMD:SHA-512_ELL2_RO_ suite.
+func Curve25519RO() *Suite { return curve25519RO }
+
+// Curve25519NU returns the curve25519_XMD:SHA-512_ELL2_NU_ suite.
+func Curve25519NU() *Suite { return curve25519NU }
+
+// Edwards25519RO returns the edwards25519_XMD:SHA-512_ELL2_RO_ suite.
+func Edwards25519RO() *Suite { return edwards25519RO }
+
+// Edwards25519NU returns the edwards25519_XMD:SHA-512_ELL2_NU_ suite.
+func Edwards25519NU() *Suite { return edwards25519NU }
+
+// Curve448RO returns the curve448_XOF:SHAKE256_ELL2_RO_ suite.
+func Curve448RO() *Suite { return curve448RO }
+
+// Curve448NU returns the curve448_XOF:SHAKE256_ELL2_NU_ suite.
+func Curve448NU() *Suite { return curve448NU }
+
+// Edwards448RO returns the edwards448_XOF:SHAKE256_ELL2_RO_ suite.
+func Edwards448RO() *Suite { return edwards448RO }
+
+// Edwards448NU returns the edwards448_XOF:SHAKE256_ELL2_NU_ suite.
+func Edwards448NU() *Suite { return edwards448NU }
+
+// Lookup returns the suite with the given RFC 9380 identifier, such as
+// "P256_XMD:SHA-256_SSWU_RO_".
+func Lookup(id string) (*Suite, error) {
+	for _, s := range suites {
+		if s.id == id {
+			return s, nil
+		}
+	}
+	return nil, errUnknownSuite
+}
+
+// ID returns the RFC 9380 suite identifier.
+func (s *Suite) ID() string { return s.id }
+
+// RandomOracle reports whether Hash implements hash_to_curve (RO suites)
+// rather than encode_to_curve (NU suites), whose output distribution is
+// not uniform.
+func (s *Suite) RandomOracle() bool { return s.ro }
+
+// Hash hashes msg to a point of the suite's curve under the domain
+// separation tag dst and returns its encoding. Tags should be unique to the
+// protocol and at most 255 bytes; longer tags are hashed first as RFC 9380,
+// Section 5.3.3 describes. On the Weierstrass and Montgomery curves, whose
+// encodings cannot represent the identity, Hash fails in the negligible
+// case that the result is the identity.
+func (s *Suite) Hash(msg, dst []byte) ([]byte, error) {
+	count := 1
+	if s.ro {
+		count = 2
+	}
+	c := s.curve()
+	u, err := s.hashToField(c.field(), msg, dst, count)
+	if err != nil {
+		return nil, err
+	}
+	return c.hashToCurve(u)
+}
+
+// HashToField implements hash_to_field (RFC 9380, Section 5.2) for the
+// suite's base field and returns count field elements as fixed-length
+// big-endian integers.
+func (s *Suite) HashToField(msg, dst []byte, count int) ([][]byte, error) {
+	f := s.curve().field()
+	u, err := s.hashToField(f, msg, dst, count)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]byte, len(u))
+	for i := range u {
+		out[i] = f.Bytes(&u[i])
+	}
+	return out, nil
+}
+
+func (s *Suite) hashToField(f *primefield.Field, msg, dst []byte, count int) ([]fe, error) {
+	if count <= 0 {
+		return nil, errCount
+	}
+	uniform, err := s.expand(msg, dst, count*s.l)
+	if err != nil {
+		return nil, err
+	}
+	u := make([]fe, count)
+	for i := range u {
+		f.Reduce(&u[i], uniform[i*s.l:(i+1)*s.l])
+	}
+	return u, nil
+}
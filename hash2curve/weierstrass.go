@@ -0,0 +1,140 @@
+package hash2curve
+
+import (
+	"math/big"
+
+	"github.com/AeonDave/cryptonite-go/internal/primefield"
+)
+
+// weierstrass is a short Weierstrass curve y^2 = x^3 - 3x + b with prime
+// order, mapped to with the simplified SWU method.
+type weierstrass struct {
+	f *primefield.Field
+	b fe
+	z fe
+	// a is -3, negBA is -b/a and bZA is b/(z*a) for the SSWU map.
+	a, negBA, bZA fe
+}
+
+func newWeierstrass(p, b *big.Int, z int64) *weierstrass {
+	f := primefield.New(p)
+	c := &weierstrass{f: f, b: f.FromBig(b), z: f.FromBig(big.NewInt(z))}
+	c.a = f.FromBig(big.NewInt(-3))
+	var t fe
+	f.Inv(&t, &c.a)
+	f.Mul(&c.negBA, &c.b, &t)
+	f.Neg(&c.negBA, &c.negBA)
+	f.Mul(&t, &c.z, &c.a)
+	f.Inv(&t, &t)
+	f.Mul(&c.bZA, &c.b, &t)
+	return c
+}
+
+// wPoint is a point in homogeneous projective coordinates (X:Y:Z).
+type wPoint struct{ x, y, z fe }
+
+// gx sets z = x^3 + a*x + b.
+func (c *weierstrass) gx(z, x *fe) {
+	f := c.f
+	var t fe
+	f.Sqr(&t, x)
+	f.Add(&t, &t, &c.a)
+	f.Mul(&t, &t, x)
+	f.Add(z, &t, &c.b)
+}
+
+// mapToCurve implements the simplified SWU map (RFC 9380, Section 6.6.2)
+// without secret-dependent branches.
+func (c *weierstrass) mapToCurve(u *fe) wPoint {
+	f := c.f
+	var zu2, tv1, x1, x2, gx1, gx2, y fe
+	f.Sqr(&zu2, u)
+	f.Mul(&zu2, &zu2, &c.z)
+	f.Sqr(&tv1, &zu2)
+	f.Add(&tv1, &tv1, &zu2)
+	exceptional := f.IsZero(&tv1)
+	f.Inv(&tv1, &tv1)
+	f.Add(&tv1, &tv1, f.One())
+	f.Mul(&x1, &c.negBA, &tv1)
+	f.CMov(&x1, &c.bZA, exceptional)
+	c.gx(&gx1, &x1)
+	f.Mul(&x2, &zu2, &x1)
+	c.gx(&gx2, &x2)
+
+	e := f.IsSquare(&gx1)
+	f.CMov(&x2, &x1, e)
+	f.CMov(&gx2, &gx1, e)
+	f.Sqrt(&y, &gx2)
+	var ny fe
+	f.Neg(&ny, &y)
+	f.CMov(&y, &ny, f.Sgn0(u)^f.Sgn0(&y))
+	return wPoint{x: x2, y: y, z: *f.One()}
+}
+
+// add sets r = p + q using the complete formulas for a = -3 of Renes,
+// Costello and Batina (Algorithm 4), which also handle doubling and the
+// identity (0:1:0).
+func (c *weierstrass) add(r, p, q *wPoint) {
+	f := c.f
+	var t0, t1, t2, t3, t4, x3, y3, z3 fe
+	f.Mul(&t0, &p.x, &q.x)
+	f.Mul(&t1, &p.y, &q.y)
+	f.Mul(&t2, &p.z, &q.z)
+	f.Add(&t3, &p.x, &p.y)
+	f.Add(&t4, &q.x, &q.y)
+	f.Mul(&t3, &t3, &t4)
+	f.Add(&t4, &t0, &t1)
+	f.Sub(&t3, &t3, &t4)
+	f.Add(&t4, &p.y, &p.z)
+	f.Add(&x3, &q.y, &q.z)
+	f.Mul(&t4, &t4, &x3)
+	f.Add(&x3, &t1, &t2)
+	f.Sub(&t4, &t4, &x3)
+	f.Add(&x3, &p.x, &p.z)
+	f.Add(&y3, &q.x, &q.z)
+	f.Mul(&x3, &x3, &y3)
+	f.Add(&y3, &t0, &t2)
+	f.Sub(&y3, &x3, &y3)
+	f.Mul(&z3, &c.b, &t2)
+	f.Sub(&x3, &y3, &z3)
+	f.Add(&z3, &x3, &x3)
+	f.Add(&x3, &x3, &z3)
+	f.Sub(&z3, &t1, &x3)
+	f.Add(&x3, &t1, &x3)
+	f.Mul(&y3, &c.b, &y3)
+	f.Add(&t1, &t2, &t2)
+	f.Add(&t2, &t1, &t2)
+	f.Sub(&y3, &y3, &t2)
+	f.Sub(&y3, &y3, &t0)
+	f.Add(&t1, &y3, &y3)
+	f.Add(&y3, &t1, &y3)
+	f.Add(&t1, &t0, &t0)
+	f.Add(&t0, &t1, &t0)
+	f.Sub(&t0, &t0, &t2)
+	f.Mul(&t1, &t4, &y3)
+	f.Mul(&t2, &t0, &y3)
+	f.Mul(&y3, &x3, &z3)
+	f.Add(&y3, &y3, &t2)
+	f.Mul(&x3, &t3, &x3)
+	f.Sub(&x3, &x3, &t1)
+	f.Mul(&z3, &t4, &z3)
+	f.Mul(&t1, &t3, &t0)
+	f.Add(&z3, &z3, &t1)
+	*r = wPoint{x: x3, y: y3, z: z3}
+}
+
+// encode returns the uncompressed SEC 1 encoding 0x04 || x || y.
+func (c *weierstrass) encode(p *wPoint) ([]byte, error) {
+	f := c.f
+	if f.IsZero(&p.z) == 1 {
+		return nil, errIdentity
+	}
+	var zi, x, y fe
+	f.Inv(&zi, &p.z)
+	f.Mul(&x, &p.x, &zi)
+	f.Mul(&y, &p.y, &zi)
+	out := make([]byte, 0, 1+2*f.Size())
+	out = append(out, 4)
+	out = append(out, f.Bytes(&x)...)
+	return append(out, f.Bytes(&y)...), nil
+}
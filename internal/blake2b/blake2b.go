@@ -22,6 +22,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"hash"
+	"strconv"
 )
 
 const (
@@ -94,8 +95,9 @@ func New256(key []byte) (hash.Hash, error) { return newDigest(Size256, key) }
 // values equal or greater than:
 // - 32 if BLAKE2b is used as a hash function (The key is zero bytes long).
 // - 16 if BLAKE2b is used as a MAC function (The key is at least 16 bytes long).
-// When the key is nil, the returned hash.Hash implements BinaryMarshaler
-// and BinaryUnmarshaler for state (de)serialization as documented by hash.Hash.
+// The returned hash.Hash implements BinaryMarshaler and BinaryUnmarshaler;
+// keys are not encoded, so a MAC state must be restored into an instance
+// created with the same key.
 func New(size int, key []byte) (hash.Hash, error) { return newDigest(size, key) }
 
 func newDigest(hashSize int, key []byte) (*digest, error) {
@@ -124,7 +126,7 @@ func checkSum(sum *[Size]byte, hashSize int, data []byte) {
 		if length == n {
 			n -= BlockSize
 		}
-		hashBlocks(&h, &c, 0, data[:n])
+		hashBlocks(&h, &c, 0, 0, data[:n])
 		data = data[n:]
 	}
 
@@ -136,7 +138,7 @@ func checkSum(sum *[Size]byte, hashSize int, data []byte) {
 	}
 	c[0] -= remaining
 
-	hashBlocks(&h, &c, 0xFFFFFFFFFFFFFFFF, block[:])
+	hashBlocks(&h, &c, 0xFFFFFFFFFFFFFFFF, 0, block[:])
 
 	for i, v := range h[:(hashSize+7)/8] {
 		binary.LittleEndian.PutUint64(sum[8*i:], v)
@@ -152,59 +154,29 @@ type digest struct {
 
 	key    [BlockSize]byte
 	keyLen int
-}
-
-const (
-	magic         = "b2b"
-	marshaledSize = len(magic) + 8*8 + 2*8 + 1 + BlockSize + 1
-)
-
-func (d *digest) MarshalBinary() ([]byte, error) {
-	if d.keyLen != 0 {
-		return nil, errors.New("crypto/blake2b: cannot marshal MACs")
-	}
-	b := make([]byte, 0, marshaledSize)
-	b = append(b, magic...)
-	for i := 0; i < 8; i++ {
-		b = appendUint64(b, d.h[i])
-	}
-	b = appendUint64(b, d.c[0])
-	b = appendUint64(b, d.c[1])
-	// Maximum value for size is 64
-	b = append(b, byte(d.size))
-	b = append(b, d.block[:]...)
-	b = append(b, byte(d.offset))
-	return b, nil
-}
 
-func (d *digest) UnmarshalBinary(b []byte) error {
-	if len(b) < len(magic) || string(b[:len(magic)]) != magic {
-		return errors.New("crypto/blake2b: invalid hash state identifier")
-	}
-	if len(b) != marshaledSize {
-		return errors.New("crypto/blake2b: invalid hash state size")
-	}
-	b = b[len(magic):]
-	for i := 0; i < 8; i++ {
-		b, d.h[i] = consumeUint64(b)
-	}
-	b, d.c[0] = consumeUint64(b)
-	b, d.c[1] = consumeUint64(b)
-	d.size = int(b[0])
-	b = b[1:]
-	copy(d.block[:], b[:BlockSize])
-	b = b[BlockSize:]
-	d.offset = int(b[0])
-	return nil
+	// param, when set, is the full parameter block XORed into the IV in
+	// place of the sequential-mode default derived from size and keyLen.
+	param    *[8]uint64
+	lastNode bool
 }
 
 func (d *digest) BlockSize() int { return BlockSize }
 
+// Algorithm returns "BLAKE2b-" followed by the digest size in bits.
+func (d *digest) Algorithm() string { return "BLAKE2b-" + strconv.Itoa(8*d.size) }
+
 func (d *digest) Size() int { return d.size }
 
 func (d *digest) Reset() {
 	d.h = iv
-	d.h[0] ^= uint64(d.size) | (uint64(d.keyLen) << 8) | (1 << 16) | (1 << 24)
+	if d.param != nil {
+		for i := range d.h {
+			d.h[i] ^= d.param[i]
+		}
+	} else {
+		d.h[0] ^= uint64(d.size) | (uint64(d.keyLen) << 8) | (1 << 16) | (1 << 24)
+	}
 	d.offset, d.c[0], d.c[1] = 0, 0, 0
 	if d.keyLen > 0 {
 		d.block = d.key
@@ -222,7 +194,7 @@ func (d *digest) Write(p []byte) (n int, err error) {
 			return
 		}
 		copy(d.block[d.offset:], p[:remaining])
-		hashBlocks(&d.h, &d.c, 0, d.block[:])
+		hashBlocks(&d.h, &d.c, 0, 0, d.block[:])
 		d.offset = 0
 		p = p[remaining:]
 	}
@@ -232,7 +204,7 @@ func (d *digest) Write(p []byte) (n int, err error) {
 		if length == nn {
 			nn -= BlockSize
 		}
-		hashBlocks(&d.h, &d.c, 0, p[:nn])
+		hashBlocks(&d.h, &d.c, 0, 0, p[:nn])
 		p = p[nn:]
 	}
 
@@ -261,31 +233,13 @@ func (d *digest) finalize(hash *[Size]byte) {
 	c[0] -= remaining
 
 	h := d.h
-	hashBlocks(&h, &c, 0xFFFFFFFFFFFFFFFF, block[:])
+	var last uint64
+	if d.lastNode {
+		last = 0xFFFFFFFFFFFFFFFF
+	}
+	hashBlocks(&h, &c, 0xFFFFFFFFFFFFFFFF, last, block[:])
 
 	for i, v := range h {
 		binary.LittleEndian.PutUint64(hash[8*i:], v)
 	}
 }
-
-func appendUint64(b []byte, x uint64) []byte {
-	var a [8]byte
-	binary.BigEndian.PutUint64(a[:], x)
-	return append(b, a[:]...)
-}
-
-func appendUint32(b []byte, x uint32) []byte {
-	var a [4]byte
-	binary.BigEndian.PutUint32(a[:], x)
-	return append(b, a[:]...)
-}
-
-func consumeUint64(b []byte) ([]byte, uint64) {
-	x := binary.BigEndian.Uint64(b)
-	return b[8:], x
-}
-
-func consumeUint32(b []byte) ([]byte, uint32) {
-	x := binary.BigEndian.Uint32(b)
-	return b[4:], x
-}
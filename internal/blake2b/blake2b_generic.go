@@ -27,7 +27,7 @@ var precomputed = [12][16]byte{
 	{14, 4, 9, 13, 10, 8, 15, 6, 1, 0, 11, 5, 12, 2, 7, 3}, // equal to the second
 }
 
-func hashBlocksGeneric(h *[8]uint64, c *[2]uint64, flag uint64, blocks []byte) {
+func hashBlocksGeneric(h *[8]uint64, c *[2]uint64, flag, last uint64, blocks []byte) {
 	var m [16]uint64
 	c0, c1 := c[0], c[1]
 
@@ -42,6 +42,7 @@ func hashBlocksGeneric(h *[8]uint64, c *[2]uint64, flag uint64, blocks []byte) {
 		v12 ^= c0
 		v13 ^= c1
 		v14 ^= flag
+		v15 ^= last
 
 		for j := range m {
 			m[j] = binary.LittleEndian.Uint64(blocks[i:])
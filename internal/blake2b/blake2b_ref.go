@@ -4,6 +4,6 @@
 
 package blake2b
 
-func hashBlocks(h *[8]uint64, c *[2]uint64, flag uint64, blocks []byte) {
-	hashBlocksGeneric(h, c, flag, blocks)
+func hashBlocks(h *[8]uint64, c *[2]uint64, flag, last uint64, blocks []byte) {
+	hashBlocksGeneric(h, c, flag, last, blocks)
 }
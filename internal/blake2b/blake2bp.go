@@ -0,0 +1,136 @@
+package blake2b
+
+import (
+	"hash"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+const (
+	// parallelDegree is the number of BLAKE2bp leaves.
+	parallelDegree = 4
+	// parallelBatch is the amount of input buffered before the leaves are
+	// updated concurrently. It is a multiple of parallelDegree*BlockSize so
+	// every batch starts at leaf 0.
+	parallelBatch = 64 << 10
+)
+
+// parallelDigest computes BLAKE2bp: four leaves, each hashing every fourth
+// 128-byte block of the input, and a root over the leaves' 64-byte outputs.
+type parallelDigest struct {
+	size   int
+	leaves [parallelDegree]*digest
+	root   *digest
+	buf    []byte
+}
+
+// NewParallel returns a new hash.Hash computing BLAKE2bp with a digest size
+// between 1 and 64 bytes. A non-nil key turns the hash into a MAC. Input is
+// buffered in 64 KiB batches whose leaves are hashed on separate goroutines.
+func NewParallel(size int, key []byte) (hash.Hash, error) {
+	d := &parallelDigest{size: size}
+	for i := range d.leaves {
+		leaf, err := newDigestParams(size, key, &Params{
+			Fanout:     parallelDegree,
+			MaxDepth:   2,
+			NodeOffset: uint64(i),
+			InnerSize:  Size,
+			LastNode:   i == parallelDegree-1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		d.leaves[i] = leaf
+	}
+	// The root records the key length in its parameter block but, unlike
+	// the leaves, does not absorb a key block.
+	root, err := newDigestParams(size, nil, &Params{
+		Fanout:    parallelDegree,
+		MaxDepth:  2,
+		NodeDepth: 1,
+		InnerSize: Size,
+		LastNode:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	root.param[0] |= uint64(len(key)) << 8
+	root.Reset()
+	d.root = root
+	return d, nil
+}
+
+func (d *parallelDigest) BlockSize() int { return BlockSize }
+
+func (d *parallelDigest) Size() int { return d.size }
+
+// Algorithm returns "BLAKE2bp-" followed by the digest size in bits.
+func (d *parallelDigest) Algorithm() string { return "BLAKE2bp-" + strconv.Itoa(8*d.size) }
+
+func (d *parallelDigest) Reset() {
+	for _, leaf := range d.leaves {
+		leaf.Reset()
+	}
+	d.buf = d.buf[:0]
+}
+
+func (d *parallelDigest) Write(p []byte) (n int, err error) {
+	n = len(p)
+	if len(d.buf) > 0 {
+		fill := parallelBatch - len(d.buf)
+		if len(p) < fill {
+			d.buf = append(d.buf, p...)
+			return
+		}
+		d.buf = append(d.buf, p[:fill]...)
+		p = p[fill:]
+		d.absorb(d.buf)
+		d.buf = d.buf[:0]
+	}
+	if full := len(p) / parallelBatch * parallelBatch; full > 0 {
+		d.absorb(p[:full])
+		p = p[full:]
+	}
+	d.buf = append(d.buf, p...)
+	return
+}
+
+func (d *parallelDigest) Sum(sum []byte) []byte {
+	root := *d.root
+	for i, leaf := range d.leaves {
+		l := *leaf
+		writeStripes(&l, d.buf, i)
+		var out [Size]byte
+		l.finalize(&out)
+		root.Write(out[:])
+	}
+	return root.Sum(sum)
+}
+
+// absorb feeds a whole number of batches to the leaves, concurrently unless
+// only one goroutine can run at a time.
+func (d *parallelDigest) absorb(data []byte) {
+	if runtime.GOMAXPROCS(0) == 1 {
+		for i, leaf := range d.leaves {
+			writeStripes(leaf, data, i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for i, leaf := range d.leaves {
+		wg.Add(1)
+		go func(leaf *digest, i int) {
+			defer wg.Done()
+			writeStripes(leaf, data, i)
+		}(leaf, i)
+	}
+	wg.Wait()
+}
+
+// writeStripes writes blocks i, i+parallelDegree, ... of data to leaf.
+func writeStripes(leaf *digest, data []byte, i int) {
+	for off := i * BlockSize; off < len(data); off += parallelDegree * BlockSize {
+		leaf.Write(data[off:min(off+BlockSize, len(data))])
+	}
+}
@@ -0,0 +1,91 @@
+package blake2b
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+const (
+	// SaltSize is the maximum length of the BLAKE2b salt in bytes.
+	SaltSize = 16
+	// PersonalSize is the maximum length of the BLAKE2b personalization
+	// string in bytes.
+	PersonalSize = 16
+)
+
+var (
+	errSaltSize     = errors.New("blake2b: invalid salt size")
+	errPersonalSize = errors.New("blake2b: invalid personalization size")
+	errDepth        = errors.New("blake2b: invalid tree depth")
+	errInnerSize    = errors.New("blake2b: invalid inner hash size")
+)
+
+// Params holds the parameter block fields beyond digest size and key
+// (BLAKE2 specification, section 2.8). Salt and Personal shorter than 16
+// bytes are zero-padded. Sequential hashing uses Fanout 1 and MaxDepth 1
+// with every other field zero.
+type Params struct {
+	Salt       []byte
+	Personal   []byte
+	Fanout     uint8
+	MaxDepth   uint8
+	LeafLength uint32
+	NodeOffset uint64
+	NodeDepth  uint8
+	InnerSize  uint8
+	// LastNode sets the final-node flag when the node is finalized.
+	LastNode bool
+}
+
+// NewWithParams returns a new hash.Hash computing BLAKE2b with the given
+// digest size, optional key and parameter block.
+func NewWithParams(size int, key []byte, p *Params) (hash.Hash, error) {
+	return newDigestParams(size, key, p)
+}
+
+func newDigestParams(hashSize int, key []byte, p *Params) (*digest, error) {
+	if hashSize < 1 || hashSize > Size {
+		return nil, errHashSize
+	}
+	if len(key) > Size {
+		return nil, errKeySize
+	}
+	if len(p.Salt) > SaltSize {
+		return nil, errSaltSize
+	}
+	if len(p.Personal) > PersonalSize {
+		return nil, errPersonalSize
+	}
+	if p.MaxDepth == 0 {
+		return nil, errDepth
+	}
+	if p.InnerSize > Size {
+		return nil, errInnerSize
+	}
+	var block [Size]byte
+	block[0] = byte(hashSize)
+	block[1] = byte(len(key))
+	block[2] = p.Fanout
+	block[3] = p.MaxDepth
+	binary.LittleEndian.PutUint32(block[4:], p.LeafLength)
+	binary.LittleEndian.PutUint64(block[8:], p.NodeOffset)
+	block[16] = p.NodeDepth
+	block[17] = p.InnerSize
+	copy(block[32:], p.Salt)
+	copy(block[48:], p.Personal)
+
+	param := new([8]uint64)
+	for i := range param {
+		param[i] = binary.LittleEndian.Uint64(block[8*i:])
+	}
+	d := &digest{
+		size:     hashSize,
+		keyLen:   len(key),
+		param:    param,
+		lastNode: p.LastNode,
+	}
+	copy(d.key[:], key)
+	d.Reset()
+	return d, nil
+}
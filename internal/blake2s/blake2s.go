@@ -23,6 +23,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"hash"
+	"strconv"
 )
 
 const (
@@ -55,8 +56,9 @@ func Sum256(data []byte) [Size]byte {
 
 // New256 returns a new hash.Hash computing the BLAKE2s-256 checksum. A non-nil
 // key turns the hash into a MAC. The key must between zero and 32 bytes long.
-// When the key is nil, the returned hash.Hash implements BinaryMarshaler
-// and BinaryUnmarshaler for state (de)serialization as documented by hash.Hash.
+// The returned hash.Hash implements BinaryMarshaler and BinaryUnmarshaler;
+// keys are not encoded, so a MAC state must be restored into an instance
+// created with the same key.
 func New256(key []byte) (hash.Hash, error) { return newDigest(Size, key) }
 
 // New returns a new hash.Hash computing the BLAKE2s checksum with a custom
@@ -114,7 +116,7 @@ func checkSum(sum *[Size]byte, hashSize int, data []byte) {
 		if length == n {
 			n -= BlockSize
 		}
-		hashBlocks(&h, &c, 0, data[:n])
+		hashBlocks(&h, &c, 0, 0, data[:n])
 		data = data[n:]
 	}
 
@@ -127,7 +129,7 @@ func checkSum(sum *[Size]byte, hashSize int, data []byte) {
 	}
 	c[0] -= remaining
 
-	hashBlocks(&h, &c, 0xFFFFFFFF, block[:])
+	hashBlocks(&h, &c, 0xFFFFFFFF, 0, block[:])
 
 	for i, v := range h {
 		binary.LittleEndian.PutUint32(sum[4*i:], v)
@@ -143,59 +145,29 @@ type digest struct {
 
 	key    [BlockSize]byte
 	keyLen int
-}
 
-const (
-	magic         = "b2s"
-	marshaledSize = len(magic) + 8*4 + 2*4 + 1 + BlockSize + 1
-)
-
-func (d *digest) MarshalBinary() ([]byte, error) {
-	if d.keyLen != 0 {
-		return nil, errors.New("crypto/blake2s: cannot marshal MACs")
-	}
-	b := make([]byte, 0, marshaledSize)
-	b = append(b, magic...)
-	for i := 0; i < 8; i++ {
-		b = appendUint32(b, d.h[i])
-	}
-	b = appendUint32(b, d.c[0])
-	b = appendUint32(b, d.c[1])
-	// Maximum value for size is 32
-	b = append(b, byte(d.size))
-	b = append(b, d.block[:]...)
-	b = append(b, byte(d.offset))
-	return b, nil
-}
-
-func (d *digest) UnmarshalBinary(b []byte) error {
-	if len(b) < len(magic) || string(b[:len(magic)]) != magic {
-		return errors.New("crypto/blake2s: invalid hash state identifier")
-	}
-	if len(b) != marshaledSize {
-		return errors.New("crypto/blake2s: invalid hash state size")
-	}
-	b = b[len(magic):]
-	for i := 0; i < 8; i++ {
-		b, d.h[i] = consumeUint32(b)
-	}
-	b, d.c[0] = consumeUint32(b)
-	b, d.c[1] = consumeUint32(b)
-	d.size = int(b[0])
-	b = b[1:]
-	copy(d.block[:], b[:BlockSize])
-	b = b[BlockSize:]
-	d.offset = int(b[0])
-	return nil
+	// param, when set, is the full parameter block XORed into the IV in
+	// place of the sequential-mode default derived from size and keyLen.
+	param    *[8]uint32
+	lastNode bool
 }
 
 func (d *digest) BlockSize() int { return BlockSize }
 
+// Algorithm returns "BLAKE2s-" followed by the digest size in bits.
+func (d *digest) Algorithm() string { return "BLAKE2s-" + strconv.Itoa(8*d.size) }
+
 func (d *digest) Size() int { return d.size }
 
 func (d *digest) Reset() {
 	d.h = iv
-	d.h[0] ^= uint32(d.size) | (uint32(d.keyLen) << 8) | (1 << 16) | (1 << 24)
+	if d.param != nil {
+		for i := range d.h {
+			d.h[i] ^= d.param[i]
+		}
+	} else {
+		d.h[0] ^= uint32(d.size) | (uint32(d.keyLen) << 8) | (1 << 16) | (1 << 24)
+	}
 	d.offset, d.c[0], d.c[1] = 0, 0, 0
 	if d.keyLen > 0 {
 		d.block = d.key
@@ -213,7 +185,7 @@ func (d *digest) Write(p []byte) (n int, err error) {
 			return
 		}
 		copy(d.block[d.offset:], p[:remaining])
-		hashBlocks(&d.h, &d.c, 0, d.block[:])
+		hashBlocks(&d.h, &d.c, 0, 0, d.block[:])
 		d.offset = 0
 		p = p[remaining:]
 	}
@@ -223,7 +195,7 @@ func (d *digest) Write(p []byte) (n int, err error) {
 		if length == nn {
 			nn -= BlockSize
 		}
-		hashBlocks(&d.h, &d.c, 0, p[:nn])
+		hashBlocks(&d.h, &d.c, 0, 0, p[:nn])
 		p = p[nn:]
 	}
 
@@ -249,19 +221,12 @@ func (d *digest) finalize(hash *[Size]byte) {
 	}
 	c[0] -= remaining
 
-	hashBlocks(&h, &c, 0xFFFFFFFF, block[:])
+	var last uint32
+	if d.lastNode {
+		last = 0xFFFFFFFF
+	}
+	hashBlocks(&h, &c, 0xFFFFFFFF, last, block[:])
 	for i, v := range h {
 		binary.LittleEndian.PutUint32(hash[4*i:], v)
 	}
 }
-
-func appendUint32(b []byte, x uint32) []byte {
-	var a [4]byte
-	binary.BigEndian.PutUint32(a[:], x)
-	return append(b, a[:]...)
-}
-
-func consumeUint32(b []byte) ([]byte, uint32) {
-	x := binary.BigEndian.Uint32(b)
-	return b[4:], x
-}
@@ -24,7 +24,7 @@ var precomputed = [10][16]byte{
 	{10, 8, 7, 1, 2, 4, 6, 5, 15, 9, 3, 13, 11, 14, 12, 0},
 }
 
-func hashBlocksGeneric(h *[8]uint32, c *[2]uint32, flag uint32, blocks []byte) {
+func hashBlocksGeneric(h *[8]uint32, c *[2]uint32, flag, last uint32, blocks []byte) {
 	var m [16]uint32
 	c0, c1 := c[0], c[1]
 
@@ -39,6 +39,7 @@ func hashBlocksGeneric(h *[8]uint32, c *[2]uint32, flag uint32, blocks []byte) {
 		v12 ^= c0
 		v13 ^= c1
 		v14 ^= flag
+		v15 ^= last
 
 		for j := range m {
 			m[j] = uint32(blocks[i]) | uint32(blocks[i+1])<<8 | uint32(blocks[i+2])<<16 | uint32(blocks[i+3])<<24
@@ -10,6 +10,6 @@ var (
 	useSSE2  = false
 )
 
-func hashBlocks(h *[8]uint32, c *[2]uint32, flag uint32, blocks []byte) {
-	hashBlocksGeneric(h, c, flag, blocks)
+func hashBlocks(h *[8]uint32, c *[2]uint32, flag, last uint32, blocks []byte) {
+	hashBlocksGeneric(h, c, flag, last, blocks)
 }
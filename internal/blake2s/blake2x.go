@@ -5,6 +5,7 @@
 package blake2s
 
 import (
+	"encoding"
 	"encoding/binary"
 	"errors"
 	"io"
@@ -26,6 +27,11 @@ type XOF interface {
 
 	// Reset resets the XOF to its initial state.
 	Reset()
+
+	// MarshalBinary and UnmarshalBinary save and restore the running state,
+	// excluding the key.
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
 }
 
 // OutputLengthUnknown can be used as the size argument to NewXOF to indicate
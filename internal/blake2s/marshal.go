@@ -0,0 +1,204 @@
+package blake2s
+
+import (
+	"encoding/binary"
+	"hash"
+
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
+// paramWords returns the parameter block words the digest was created with.
+func (d *digest) paramWords() [8]uint32 {
+	if d.param != nil {
+		return *d.param
+	}
+	var p [8]uint32
+	p[0] = uint32(d.size) | (uint32(d.keyLen) << 8) | (1 << 16) | (1 << 24)
+	return p
+}
+
+func (d *digest) encode(e *marshal.Encoder) {
+	e.Uint64(uint64(d.size))
+	e.Uint64(uint64(d.keyLen))
+	p := d.paramWords()
+	e.Uint32s(p[:])
+	e.Bool(d.lastNode)
+	e.Uint32s(d.h[:])
+	e.Uint32s(d.c[:])
+	e.Bytes(d.block[:])
+	e.Uint32(uint32(d.offset))
+}
+
+func (d *digest) decode(dec *marshal.Decoder) {
+	dec.Expect(uint64(d.size))
+	dec.Expect(uint64(d.keyLen))
+	for _, w := range d.paramWords() {
+		dec.Check(dec.Uint32() == w, marshal.ErrMismatch)
+	}
+	dec.Check(dec.Bool() == d.lastNode, marshal.ErrMismatch)
+	dec.Uint32s(d.h[:])
+	dec.Uint32s(d.c[:])
+	block := dec.Bytes(BlockSize)
+	offset := dec.Uint32()
+	dec.Check(len(block) == BlockSize && offset <= BlockSize, marshal.ErrInvalid)
+	if dec.Err() != nil {
+		return
+	}
+	copy(d.block[:], block)
+	d.offset = int(offset)
+}
+
+// MarshalBinary encodes the running state and the parameters it was created
+// with. The key is not included.
+func (d *digest) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder("BLAKE2s")
+	d.encode(e)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on a digest
+// with the same size, key length and parameters. It also accepts the
+// "b2s" encoding written by earlier releases (and by
+// golang.org/x/crypto/blake2s) for unkeyed digests with default parameters.
+func (d *digest) UnmarshalBinary(b []byte) error {
+	if len(b) == legacyMarshaledSize && string(b[:len(legacyMagic)]) == legacyMagic {
+		return d.unmarshalLegacy(b[len(legacyMagic):])
+	}
+	dec, err := marshal.NewDecoder(b, "BLAKE2s")
+	if err != nil {
+		return err
+	}
+	t := *d
+	t.decode(dec)
+	if err := dec.Finish(); err != nil {
+		return err
+	}
+	*d = t
+	return nil
+}
+
+// The legacy encoding is the magic, h and c as big-endian words, the
+// digest size, the block buffer and the buffer offset.
+const (
+	legacyMagic         = "b2s"
+	legacyMarshaledSize = len(legacyMagic) + 8*4 + 2*4 + 1 + BlockSize + 1
+)
+
+func (d *digest) unmarshalLegacy(b []byte) error {
+	if d.keyLen != 0 || d.param != nil || d.lastNode {
+		return marshal.ErrMismatch
+	}
+	t := *d
+	for i := range t.h {
+		t.h[i] = binary.BigEndian.Uint32(b)
+		b = b[4:]
+	}
+	for i := range t.c {
+		t.c[i] = binary.BigEndian.Uint32(b)
+		b = b[4:]
+	}
+	if int(b[0]) != d.size {
+		return marshal.ErrMismatch
+	}
+	copy(t.block[:], b[1:1+BlockSize])
+	t.offset = int(b[1+BlockSize])
+	if t.offset > BlockSize {
+		return marshal.ErrInvalid
+	}
+	*d = t
+	return nil
+}
+
+// Clone returns an independent copy of the digest.
+func (d *digest) Clone() hash.Hash {
+	c := *d
+	return &c
+}
+
+// MarshalBinary encodes the running state, including the output position
+// once reading has started.
+func (x *xof) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder("BLAKE2Xs")
+	e.Uint64(uint64(x.length))
+	x.d.encode(e)
+	e.Uint64(x.remaining)
+	e.Bytes(x.cfg[:])
+	e.Bytes(x.root[:])
+	e.Bytes(x.block[:])
+	e.Uint32(uint32(x.offset))
+	e.Uint32(x.nodeOffset)
+	e.Bool(x.readMode)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on an XOF with
+// the same output length and key length.
+func (x *xof) UnmarshalBinary(b []byte) error {
+	dec, err := marshal.NewDecoder(b, "BLAKE2Xs")
+	if err != nil {
+		return err
+	}
+	t := *x
+	dec.Expect(uint64(t.length))
+	t.d.decode(dec)
+	t.remaining = dec.Uint64()
+	cfg, root, block := dec.Bytes(Size), dec.Bytes(Size), dec.Bytes(Size)
+	offset := dec.Uint32()
+	t.nodeOffset = dec.Uint32()
+	t.readMode = dec.Bool()
+	dec.Check(len(cfg) == Size && len(root) == Size && len(block) == Size && offset < Size, marshal.ErrInvalid)
+	if err := dec.Finish(); err != nil {
+		return err
+	}
+	copy(t.cfg[:], cfg)
+	copy(t.root[:], root)
+	copy(t.block[:], block)
+	t.offset = int(offset)
+	*x = t
+	return nil
+}
+
+// MarshalBinary encodes the leaf states and the buffered input.
+func (d *parallelDigest) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder("BLAKE2sp")
+	e.Uint64(uint64(d.root.param[0]))
+	for _, leaf := range d.leaves {
+		leaf.encode(e)
+	}
+	e.Bytes(d.buf)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on a BLAKE2bp
+// hash with the same size and key length.
+func (d *parallelDigest) UnmarshalBinary(b []byte) error {
+	dec, err := marshal.NewDecoder(b, "BLAKE2sp")
+	if err != nil {
+		return err
+	}
+	dec.Expect(uint64(d.root.param[0]))
+	var leaves [parallelDegree]digest
+	for i := range leaves {
+		leaves[i] = *d.leaves[i]
+		leaves[i].decode(dec)
+	}
+	buf := dec.Bytes(parallelBatch - 1)
+	if err := dec.Finish(); err != nil {
+		return err
+	}
+	for i := range leaves {
+		*d.leaves[i] = leaves[i]
+	}
+	d.buf = append(d.buf[:0], buf...)
+	return nil
+}
+
+// Clone returns an independent copy of the hash.
+func (d *parallelDigest) Clone() hash.Hash {
+	c := &parallelDigest{size: d.size, root: d.root, buf: append([]byte(nil), d.buf...)}
+	for i, leaf := range d.leaves {
+		l := *leaf
+		c.leaves[i] = &l
+	}
+	return c
+}
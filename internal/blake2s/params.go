@@ -0,0 +1,99 @@
+package blake2s
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+const (
+	// SaltSize is the maximum length of the BLAKE2s salt in bytes.
+	SaltSize = 8
+	// PersonalSize is the maximum length of the BLAKE2s personalization
+	// string in bytes.
+	PersonalSize = 8
+	// MaxNodeOffset is the largest node offset representable in the 48-bit
+	// BLAKE2s parameter field.
+	MaxNodeOffset = 1<<48 - 1
+)
+
+var (
+	errSaltSize     = errors.New("blake2s: invalid salt size")
+	errPersonalSize = errors.New("blake2s: invalid personalization size")
+	errDepth        = errors.New("blake2s: invalid tree depth")
+	errInnerSize    = errors.New("blake2s: invalid inner hash size")
+	errNodeOffset   = errors.New("blake2s: node offset too large")
+)
+
+// Params holds the parameter block fields beyond digest size and key
+// (BLAKE2 specification, section 2.8). Salt and Personal shorter than 8
+// bytes are zero-padded. Sequential hashing uses Fanout 1 and MaxDepth 1
+// with every other field zero.
+type Params struct {
+	Salt       []byte
+	Personal   []byte
+	Fanout     uint8
+	MaxDepth   uint8
+	LeafLength uint32
+	NodeOffset uint64
+	NodeDepth  uint8
+	InnerSize  uint8
+	// LastNode sets the final-node flag when the node is finalized.
+	LastNode bool
+}
+
+// NewWithParams returns a new hash.Hash computing BLAKE2s with the given
+// digest size, optional key and parameter block.
+func NewWithParams(size int, key []byte, p *Params) (hash.Hash, error) {
+	return newDigestParams(size, key, p)
+}
+
+func newDigestParams(hashSize int, key []byte, p *Params) (*digest, error) {
+	if hashSize < 1 || hashSize > Size {
+		return nil, errHashSize
+	}
+	if len(key) > Size {
+		return nil, errKeySize
+	}
+	if len(p.Salt) > SaltSize {
+		return nil, errSaltSize
+	}
+	if len(p.Personal) > PersonalSize {
+		return nil, errPersonalSize
+	}
+	if p.MaxDepth == 0 {
+		return nil, errDepth
+	}
+	if p.InnerSize > Size {
+		return nil, errInnerSize
+	}
+	if p.NodeOffset > MaxNodeOffset {
+		return nil, errNodeOffset
+	}
+	var block [Size]byte
+	block[0] = byte(hashSize)
+	block[1] = byte(len(key))
+	block[2] = p.Fanout
+	block[3] = p.MaxDepth
+	binary.LittleEndian.PutUint32(block[4:], p.LeafLength)
+	binary.LittleEndian.PutUint32(block[8:], uint32(p.NodeOffset))
+	binary.LittleEndian.PutUint16(block[12:], uint16(p.NodeOffset>>32))
+	block[14] = p.NodeDepth
+	block[15] = p.InnerSize
+	copy(block[16:], p.Salt)
+	copy(block[24:], p.Personal)
+
+	param := new([8]uint32)
+	for i := range param {
+		param[i] = binary.LittleEndian.Uint32(block[4*i:])
+	}
+	d := &digest{
+		size:     hashSize,
+		keyLen:   len(key),
+		param:    param,
+		lastNode: p.LastNode,
+	}
+	copy(d.key[:], key)
+	d.Reset()
+	return d, nil
+}
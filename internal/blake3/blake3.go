@@ -0,0 +1,376 @@
+// Package blake3 implements the BLAKE3 hash function in its hash, keyed_hash
+// and derive_key modes, with extendable output and multi-goroutine hashing of
+// large inputs.
+package blake3
+
+import "encoding/binary"
+
+const (
+	// OutLen is the default digest size in bytes.
+	OutLen = 32
+	// KeyLen is the key size in bytes for keyed hashing.
+	KeyLen = 32
+	// BlockLen is the compression function block size in bytes.
+	BlockLen = 64
+	// ChunkLen is the size in bytes of a leaf of the BLAKE3 tree.
+	ChunkLen = 1024
+)
+
+// Domain separation flags.
+const (
+	FlagChunkStart        = 1 << 0
+	FlagChunkEnd          = 1 << 1
+	FlagParent            = 1 << 2
+	FlagRoot              = 1 << 3
+	FlagKeyedHash         = 1 << 4
+	FlagDeriveKeyContext  = 1 << 5
+	FlagDeriveKeyMaterial = 1 << 6
+)
+
+// IV is the BLAKE3 initialisation vector, used as the key words in the
+// unkeyed modes.
+var IV = [8]uint32{0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A, 0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19}
+
+var msgPermutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+// Hasher is an incremental BLAKE3 state. The zero value is not usable; build
+// instances with New, NewKeyed or NewDeriveKey.
+type Hasher struct {
+	chunk    chunkState
+	key      [8]uint32
+	stack    [54][8]uint32
+	stackLen int
+	flags    uint32
+}
+
+type chunkState struct {
+	chainingValue    [8]uint32
+	chunkCounter     uint64
+	block            [BlockLen]byte
+	blockLen         uint32
+	blocksCompressed uint32
+	flags            uint32
+}
+
+// Output is the last compression input of a BLAKE3 tree: a chunk's final
+// block or a parent node. It yields either a chaining value or, for the root,
+// an arbitrary amount of output.
+type Output struct {
+	inputCV    [8]uint32
+	blockWords [16]uint32
+	counter    uint64
+	blockLen   uint32
+	flags      uint32
+}
+
+// New returns a Hasher in the default hash mode.
+func New() *Hasher {
+	return NewWithKeyWords(IV, 0)
+}
+
+// NewKeyed returns a Hasher in keyed_hash mode.
+func NewKeyed(key *[KeyLen]byte) *Hasher {
+	var words [8]uint32
+	loadWords(key[:], words[:])
+	return NewWithKeyWords(words, FlagKeyedHash)
+}
+
+// NewDeriveKey returns a Hasher in derive_key mode. The context string should
+// be hardcoded, globally unique and application specific; the key material is
+// supplied through Write.
+func NewDeriveKey(context string) *Hasher {
+	ctx := NewWithKeyWords(IV, FlagDeriveKeyContext)
+	ctx.Write([]byte(context))
+	var contextKey [KeyLen]byte
+	ctx.Sum(contextKey[:])
+	var words [8]uint32
+	loadWords(contextKey[:], words[:])
+	return NewWithKeyWords(words, FlagDeriveKeyMaterial)
+}
+
+// NewWithKeyWords returns a Hasher keyed with the given words and mode flags.
+func NewWithKeyWords(key [8]uint32, flags uint32) *Hasher {
+	h := &Hasher{key: key, flags: flags}
+	h.chunk = newChunkState(key, 0, flags)
+	return h
+}
+
+// Reset discards all absorbed input while keeping the mode and key.
+func (h *Hasher) Reset() {
+	h.chunk = newChunkState(h.key, 0, h.flags)
+	h.stackLen = 0
+}
+
+// Write absorbs input. Large inputs are split into chunks whose chaining
+// values are computed concurrently.
+func (h *Hasher) Write(input []byte) {
+	for len(input) > 0 {
+		if h.chunk.len() == ChunkLen {
+			cv := h.chunk.output().ChainingValue()
+			total := h.chunk.chunkCounter + 1
+			h.addChunkCV(cv, total)
+			h.chunk = newChunkState(h.key, total, h.flags)
+		}
+		if h.chunk.len() == 0 && len(input) > parallelMinBytes {
+			// Keep at least one byte back so the final chunk stays open
+			// for the root computation.
+			n := (len(input) - 1) / ChunkLen * ChunkLen
+			h.writeChunks(input[:n])
+			input = input[n:]
+			continue
+		}
+		want := ChunkLen - h.chunk.len()
+		if want > len(input) {
+			want = len(input)
+		}
+		h.chunk.update(input[:want])
+		input = input[want:]
+	}
+}
+
+// Sum writes len(out) bytes of root output into out without modifying the
+// state.
+func (h *Hasher) Sum(out []byte) {
+	o := h.RootOutput()
+	o.RootBytes(out, 0)
+}
+
+// RootOutput returns the root node of the tree built from the input absorbed
+// so far.
+func (h *Hasher) RootOutput() Output {
+	output := h.chunk.output()
+	for i := h.stackLen; i > 0; i-- {
+		output = ParentOutput(h.stack[i-1], output.ChainingValue(), h.key, h.flags)
+	}
+	return output
+}
+
+// XOF returns a reader over the extendable output of the input absorbed so
+// far. The Hasher remains usable.
+func (h *Hasher) XOF() *OutputReader {
+	return &OutputReader{output: h.RootOutput()}
+}
+
+// KeyWords returns the key words the tree is keyed with.
+func (h *Hasher) KeyWords() [8]uint32 { return h.key }
+
+// Flags returns the mode flags applied to every compression.
+func (h *Hasher) Flags() uint32 { return h.flags }
+
+func (h *Hasher) addChunkCV(cv [8]uint32, totalChunks uint64) {
+	for totalChunks&1 == 0 {
+		totalChunks >>= 1
+		h.stackLen--
+		cv = ParentCV(h.stack[h.stackLen], cv, h.key, h.flags)
+	}
+	h.stack[h.stackLen] = cv
+	h.stackLen++
+}
+
+func newChunkState(key [8]uint32, counter uint64, flags uint32) chunkState {
+	return chunkState{chainingValue: key, chunkCounter: counter, flags: flags}
+}
+
+func (c *chunkState) len() int {
+	return int(c.blocksCompressed)*BlockLen + int(c.blockLen)
+}
+
+func (c *chunkState) startFlag() uint32 {
+	if c.blocksCompressed == 0 {
+		return FlagChunkStart
+	}
+	return 0
+}
+
+func (c *chunkState) update(input []byte) {
+	for len(input) > 0 {
+		if int(c.blockLen) == BlockLen {
+			var words [16]uint32
+			loadWords(c.block[:], words[:])
+			c.chainingValue = firstEightWords(compress(&c.chainingValue, &words, c.chunkCounter, BlockLen, c.flags|c.startFlag()))
+			c.blocksCompressed++
+			c.block = [BlockLen]byte{}
+			c.blockLen = 0
+		}
+		want := BlockLen - int(c.blockLen)
+		if want > len(input) {
+			want = len(input)
+		}
+		copy(c.block[int(c.blockLen):], input[:want])
+		c.blockLen += uint32(want)
+		input = input[want:]
+	}
+}
+
+func (c *chunkState) output() Output {
+	var words [16]uint32
+	loadWords(c.block[:], words[:])
+	return Output{
+		inputCV:    c.chainingValue,
+		blockWords: words,
+		counter:    c.chunkCounter,
+		blockLen:   c.blockLen,
+		flags:      c.flags | c.startFlag() | FlagChunkEnd,
+	}
+}
+
+// ChunkOutput returns the output node of a single chunk of at most ChunkLen
+// bytes with the given chunk index.
+func ChunkOutput(chunk []byte, counter uint64, key [8]uint32, flags uint32) Output {
+	c := newChunkState(key, counter, flags)
+	c.update(chunk)
+	return c.output()
+}
+
+// ParentOutput returns the output node of a parent with the given children.
+func ParentOutput(left, right [8]uint32, key [8]uint32, flags uint32) Output {
+	var block [16]uint32
+	copy(block[:8], left[:])
+	copy(block[8:], right[:])
+	return Output{
+		inputCV:    key,
+		blockWords: block,
+		blockLen:   BlockLen,
+		flags:      flags | FlagParent,
+	}
+}
+
+// ParentCV returns the chaining value of a non-root parent node.
+func ParentCV(left, right [8]uint32, key [8]uint32, flags uint32) [8]uint32 {
+	return ParentOutput(left, right, key, flags).ChainingValue()
+}
+
+// ChainingValue returns the chaining value of a non-root node.
+func (o Output) ChainingValue() [8]uint32 {
+	return firstEightWords(compress(&o.inputCV, &o.blockWords, o.counter, o.blockLen, o.flags))
+}
+
+// RootCV returns the first 32 bytes of root output as chaining value words,
+// i.e. the BLAKE3 hash when o is the root node.
+func (o Output) RootCV() [8]uint32 {
+	return firstEightWords(compress(&o.inputCV, &o.blockWords, 0, o.blockLen, o.flags|FlagRoot))
+}
+
+// RootBytes fills dst with root output starting at output block blockIndex.
+func (o Output) RootBytes(dst []byte, blockIndex uint64) {
+	var block [BlockLen]byte
+	for len(dst) > 0 {
+		words := compress(&o.inputCV, &o.blockWords, blockIndex, o.blockLen, o.flags|FlagRoot)
+		storeWords(words[:], block[:])
+		n := copy(dst, block[:])
+		dst = dst[n:]
+		blockIndex++
+	}
+}
+
+// CVBytes serialises a chaining value in little-endian order.
+func CVBytes(cv [8]uint32) [32]byte {
+	var out [32]byte
+	storeWords(cv[:], out[:])
+	return out
+}
+
+// CVFromBytes parses a little-endian chaining value.
+func CVFromBytes(b []byte) [8]uint32 {
+	var cv [8]uint32
+	loadWords(b[:32], cv[:])
+	return cv
+}
+
+func firstEightWords(words [16]uint32) [8]uint32 {
+	var out [8]uint32
+	copy(out[:], words[:8])
+	return out
+}
+
+func compress(cv *[8]uint32, block *[16]uint32, counter uint64, blockLen uint32, flags uint32) [16]uint32 {
+	var state [16]uint32
+	copy(state[0:8], cv[:])
+	state[8] = IV[0]
+	state[9] = IV[1]
+	state[10] = IV[2]
+	state[11] = IV[3]
+	state[12] = uint32(counter)
+	state[13] = uint32(counter >> 32)
+	state[14] = blockLen
+	state[15] = flags
+
+	words := *block
+	round(&state, &words)
+	permute(&words)
+	round(&state, &words)
+	permute(&words)
+	round(&state, &words)
+	permute(&words)
+	round(&state, &words)
+	permute(&words)
+	round(&state, &words)
+	permute(&words)
+	round(&state, &words)
+	permute(&words)
+	round(&state, &words)
+
+	for i := 0; i < 8; i++ {
+		state[i] ^= state[i+8]
+		state[i+8] ^= cv[i]
+	}
+	return state
+}
+
+func round(state *[16]uint32, m *[16]uint32) {
+	g(state, 0, 4, 8, 12, m[0], m[1])
+	g(state, 1, 5, 9, 13, m[2], m[3])
+	g(state, 2, 6, 10, 14, m[4], m[5])
+	g(state, 3, 7, 11, 15, m[6], m[7])
+	g(state, 0, 5, 10, 15, m[8], m[9])
+	g(state, 1, 6, 11, 12, m[10], m[11])
+	g(state, 2, 7, 8, 13, m[12], m[13])
+	g(state, 3, 4, 9, 14, m[14], m[15])
+}
+
+func permute(m *[16]uint32) {
+	var tmp [16]uint32
+	for i := range tmp {
+		tmp[i] = m[msgPermutation[i]]
+	}
+	*m = tmp
+}
+
+func g(state *[16]uint32, a, b, c, d int, mx, my uint32) {
+	state[a] = state[a] + state[b] + mx
+	state[d] = rotateRight(state[d]^state[a], 16)
+	state[c] = state[c] + state[d]
+	state[b] = rotateRight(state[b]^state[c], 12)
+	state[a] = state[a] + state[b] + my
+	state[d] = rotateRight(state[d]^state[a], 8)
+	state[c] = state[c] + state[d]
+	state[b] = rotateRight(state[b]^state[c], 7)
+}
+
+func rotateRight(x uint32, n uint) uint32 { return (x >> n) | (x << (32 - n)) }
+
+func loadWords(src []byte, dst []uint32) {
+	for i := 0; i < len(dst); i++ {
+		base := 4 * i
+		if base+4 <= len(src) {
+			dst[i] = binary.LittleEndian.Uint32(src[base : base+4])
+			continue
+		}
+		var buf [4]byte
+		copy(buf[:], src[base:])
+		dst[i] = binary.LittleEndian.Uint32(buf[:])
+	}
+}
+
+func storeWords(words []uint32, dst []byte) {
+	for i, w := range words {
+		base := 4 * i
+		if base+4 <= len(dst) {
+			binary.LittleEndian.PutUint32(dst[base:base+4], w)
+			continue
+		}
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], w)
+		copy(dst[base:], buf[:])
+	}
+}
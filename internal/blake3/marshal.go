@@ -0,0 +1,85 @@
+package blake3
+
+import (
+	"math/bits"
+
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
+// Clone returns an independent copy of the hasher.
+func (h *Hasher) Clone() *Hasher {
+	c := *h
+	return &c
+}
+
+// Encode appends the mode flags and running state to e. The key words are
+// not included.
+func (h *Hasher) Encode(e *marshal.Encoder) {
+	e.Uint64(uint64(h.flags))
+	e.Uint32s(h.chunk.chainingValue[:])
+	e.Uint64(h.chunk.chunkCounter)
+	e.Bytes(h.chunk.block[:])
+	e.Uint32(h.chunk.blockLen)
+	e.Uint32(h.chunk.blocksCompressed)
+	e.Uint32(uint32(h.stackLen))
+	for i := 0; i < h.stackLen; i++ {
+		e.Uint32s(h.stack[i][:])
+	}
+}
+
+// Decode restores a state written by Encode into h, which must use the same
+// mode and key.
+func (h *Hasher) Decode(d *marshal.Decoder) {
+	d.Expect(uint64(h.flags))
+	d.Uint32s(h.chunk.chainingValue[:])
+	h.chunk.chunkCounter = d.Uint64()
+	block := d.Bytes(BlockLen)
+	h.chunk.blockLen = d.Uint32()
+	h.chunk.blocksCompressed = d.Uint32()
+	stackLen := d.Uint32()
+	// The stack holds one chaining value per set bit of the number of
+	// completed chunks, and a chunk only has an empty block before its
+	// first compression.
+	d.Check(len(block) == BlockLen && h.chunk.blockLen <= BlockLen &&
+		h.chunk.len() <= ChunkLen &&
+		(h.chunk.blockLen > 0 || h.chunk.blocksCompressed == 0) &&
+		stackLen < uint32(len(h.stack)) &&
+		int(stackLen) == bits.OnesCount64(h.chunk.chunkCounter), marshal.ErrInvalid)
+	if d.Err() != nil {
+		return
+	}
+	copy(h.chunk.block[:], block)
+	h.chunk.flags = h.flags
+	h.stackLen = int(stackLen)
+	for i := 0; i < h.stackLen; i++ {
+		d.Uint32s(h.stack[i][:])
+	}
+}
+
+// Encode appends the root node and output position to e.
+func (r *OutputReader) Encode(e *marshal.Encoder) {
+	e.Uint32s(r.output.inputCV[:])
+	e.Uint32s(r.output.blockWords[:])
+	e.Uint64(r.output.counter)
+	e.Uint32(r.output.blockLen)
+	e.Uint32(r.output.flags)
+	e.Uint64(r.position)
+}
+
+// DecodeOutputReader reads a reader written by OutputReader.Encode.
+func DecodeOutputReader(d *marshal.Decoder) *OutputReader {
+	r := &OutputReader{}
+	d.Uint32s(r.output.inputCV[:])
+	d.Uint32s(r.output.blockWords[:])
+	r.output.counter = d.Uint64()
+	r.output.blockLen = d.Uint32()
+	r.output.flags = d.Uint32()
+	r.position = d.Uint64()
+	return r
+}
+
+// Clone returns an independent copy of the reader.
+func (r *OutputReader) Clone() *OutputReader {
+	c := *r
+	return &c
+}
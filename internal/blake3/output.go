@@ -0,0 +1,77 @@
+package blake3
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	errSeekWhence   = errors.New("blake3: unsupported seek whence")
+	errSeekNegative = errors.New("blake3: negative seek position")
+)
+
+// OutputReader reads the extendable root output of a BLAKE3 tree. It
+// implements io.Reader and io.Seeker; the output stream is unbounded for
+// practical purposes, so io.SeekEnd is not supported.
+type OutputReader struct {
+	output   Output
+	position uint64
+	block    [BlockLen]byte
+	// cached records whether block holds the output block at
+	// position/BlockLen.
+	cached bool
+}
+
+// Read fills p with output bytes starting at the current position. It never
+// returns an error.
+func (r *OutputReader) Read(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		off := int(r.position % BlockLen)
+		if off == 0 && len(p) >= BlockLen {
+			// Whole aligned blocks bypass the cache.
+			full := len(p) / BlockLen * BlockLen
+			r.output.RootBytes(p[:full], r.position/BlockLen)
+			r.position += uint64(full)
+			r.cached = false
+			p = p[full:]
+			continue
+		}
+		if !r.cached {
+			r.output.RootBytes(r.block[:], r.position/BlockLen)
+			r.cached = true
+		}
+		k := copy(p, r.block[off:])
+		p = p[k:]
+		r.position += uint64(k)
+		if r.position%BlockLen == 0 {
+			r.cached = false
+		}
+	}
+	return n, nil
+}
+
+// Seek sets the output position for the next Read. Only io.SeekStart and
+// io.SeekCurrent are supported.
+func (r *OutputReader) Seek(offset int64, whence int) (int64, error) {
+	var base int64
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		base = int64(r.position)
+	default:
+		return int64(r.position), errSeekWhence
+	}
+	pos := base + offset
+	if pos < 0 {
+		return int64(r.position), errSeekNegative
+	}
+	if uint64(pos)/BlockLen != r.position/BlockLen {
+		r.cached = false
+	}
+	r.position = uint64(pos)
+	return pos, nil
+}
+
+// Position returns the offset of the next byte Read will produce.
+func (r *OutputReader) Position() uint64 { return r.position }
@@ -0,0 +1,67 @@
+package blake3
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelMinBytes is the smallest input worth spreading across goroutines;
+// below it the scheduling overhead outweighs the per-chunk hashing cost.
+const parallelMinBytes = 64 << 10
+
+// chunksPerWorker bounds the chaining values buffered per batch.
+const chunksPerWorker = 64
+
+// writeChunks absorbs data, a whole number of chunks, starting at an empty
+// chunk boundary. Chunk chaining values are computed concurrently batch by
+// batch and merged into the tree in order.
+func (h *Hasher) writeChunks(data []byte) {
+	workers := runtime.GOMAXPROCS(0)
+	batch := workers * chunksPerWorker
+	cvs := make([][8]uint32, batch)
+	counter := h.chunk.chunkCounter
+	for len(data) > 0 {
+		n := len(data) / ChunkLen
+		if n > batch {
+			n = batch
+		}
+		h.chunkCVs(data[:n*ChunkLen], counter, cvs[:n], workers)
+		for i := 0; i < n; i++ {
+			h.addChunkCV(cvs[i], counter+uint64(i)+1)
+		}
+		counter += uint64(n)
+		data = data[n*ChunkLen:]
+	}
+	h.chunk = newChunkState(h.key, counter, h.flags)
+}
+
+func (h *Hasher) chunkCVs(data []byte, counter uint64, cvs [][8]uint32, workers int) {
+	n := len(cvs)
+	if workers > n {
+		workers = n
+	}
+	hashRange := func(start, end int) {
+		for i := start; i < end; i++ {
+			chunk := data[i*ChunkLen : (i+1)*ChunkLen]
+			cvs[i] = ChunkOutput(chunk, counter+uint64(i), h.key, h.flags).ChainingValue()
+		}
+	}
+	if workers <= 1 {
+		hashRange(0, n)
+		return
+	}
+	per := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += per {
+		end := start + per
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			hashRange(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,137 @@
+// Package blowfish implements the Blowfish block cipher and the expensive
+// key schedule (EksBlowfish) that bcrypt builds on.
+package blowfish
+
+import "encoding/binary"
+
+const BlockSize = 8
+
+// Cipher is a Blowfish key schedule: the P-array and four S-boxes.
+type Cipher struct {
+	p              [18]uint32
+	s0, s1, s2, s3 [256]uint32
+}
+
+// New returns a Blowfish cipher keyed with key using the standard schedule.
+func New(key []byte) *Cipher {
+	c := NewState()
+	c.ExpandKey(key)
+	return c
+}
+
+// NewState returns a cipher holding the initial digits-of-pi state, before
+// any key has been mixed in.
+func NewState() *Cipher {
+	return &Cipher{p: p, s0: s0, s1: s1, s2: s2, s3: s3}
+}
+
+// ExpandKey mixes key into the schedule. Bytes are consumed cyclically, so
+// any non-empty key length is accepted.
+func (c *Cipher) ExpandKey(key []byte) {
+	j := 0
+	for i := range c.p {
+		var w uint32
+		w, j = nextWord(key, j)
+		c.p[i] ^= w
+	}
+	var l, r uint32
+	for i := 0; i < len(c.p); i += 2 {
+		l, r = c.encrypt(l, r)
+		c.p[i], c.p[i+1] = l, r
+	}
+	for _, s := range []*[256]uint32{&c.s0, &c.s1, &c.s2, &c.s3} {
+		for i := 0; i < len(s); i += 2 {
+			l, r = c.encrypt(l, r)
+			s[i], s[i+1] = l, r
+		}
+	}
+}
+
+// ExpandKeyWithSalt is the salted key schedule of EksBlowfish: it mixes key
+// into the P-array and XORs successive salt words into the running block
+// before each encryption.
+func (c *Cipher) ExpandKeyWithSalt(key, salt []byte) {
+	j := 0
+	for i := range c.p {
+		var w uint32
+		w, j = nextWord(key, j)
+		c.p[i] ^= w
+	}
+	j = 0
+	var l, r, w uint32
+	for i := 0; i < len(c.p); i += 2 {
+		w, j = nextWord(salt, j)
+		l ^= w
+		w, j = nextWord(salt, j)
+		r ^= w
+		l, r = c.encrypt(l, r)
+		c.p[i], c.p[i+1] = l, r
+	}
+	for _, s := range []*[256]uint32{&c.s0, &c.s1, &c.s2, &c.s3} {
+		for i := 0; i < len(s); i += 2 {
+			w, j = nextWord(salt, j)
+			l ^= w
+			w, j = nextWord(salt, j)
+			r ^= w
+			l, r = c.encrypt(l, r)
+			s[i], s[i+1] = l, r
+		}
+	}
+}
+
+// Encrypt encrypts the 8-byte block src into dst (big-endian halves).
+func (c *Cipher) Encrypt(dst, src []byte) {
+	l, r := c.encrypt(binary.BigEndian.Uint32(src[0:4]), binary.BigEndian.Uint32(src[4:8]))
+	binary.BigEndian.PutUint32(dst[0:4], l)
+	binary.BigEndian.PutUint32(dst[4:8], r)
+}
+
+// Decrypt decrypts the 8-byte block src into dst.
+func (c *Cipher) Decrypt(dst, src []byte) {
+	l, r := c.decrypt(binary.BigEndian.Uint32(src[0:4]), binary.BigEndian.Uint32(src[4:8]))
+	binary.BigEndian.PutUint32(dst[0:4], l)
+	binary.BigEndian.PutUint32(dst[4:8], r)
+}
+
+// Clear zeroes the key schedule.
+func (c *Cipher) Clear() {
+	*c = Cipher{}
+}
+
+func (c *Cipher) f(x uint32) uint32 {
+	return ((c.s0[x>>24] + c.s1[x>>16&0xff]) ^ c.s2[x>>8&0xff]) + c.s3[x&0xff]
+}
+
+func (c *Cipher) encrypt(l, r uint32) (uint32, uint32) {
+	l ^= c.p[0]
+	for i := 1; i < 17; i += 2 {
+		r ^= c.f(l) ^ c.p[i]
+		l ^= c.f(r) ^ c.p[i+1]
+	}
+	r ^= c.p[17]
+	return r, l
+}
+
+func (c *Cipher) decrypt(l, r uint32) (uint32, uint32) {
+	l ^= c.p[17]
+	for i := 16; i > 0; i -= 2 {
+		r ^= c.f(l) ^ c.p[i]
+		l ^= c.f(r) ^ c.p[i-1]
+	}
+	r ^= c.p[0]
+	return r, l
+}
+
+// nextWord reads four bytes of b cyclically from position j as a big-endian
+// word.
+func nextWord(b []byte, j int) (uint32, int) {
+	var w uint32
+	for k := 0; k < 4; k++ {
+		w = w<<8 | uint32(b[j])
+		j++
+		if j == len(b) {
+			j = 0
+		}
+	}
+	return w, j
+}
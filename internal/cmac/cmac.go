@@ -0,0 +1,73 @@
+// Package cmac implements CMAC (NIST SP 800-38B, RFC 4493) over a 128-bit
+// block cipher, shared by AES-SIV and the CMAC-based SP 800-108 KBKDF.
+package cmac
+
+import "crypto/cipher"
+
+// BlockSize is the cipher block size CMAC is defined for here, and the size
+// of its tags.
+const BlockSize = 16
+
+// CMAC holds a block cipher and the two subkeys derived from it. It carries
+// no per-message state, so one CMAC may compute any number of tags.
+type CMAC struct {
+	block  cipher.Block
+	k1, k2 [BlockSize]byte
+}
+
+// New returns a CMAC keyed by block, which must have a 16-byte block size.
+func New(block cipher.Block) *CMAC {
+	c := &CMAC{block: block}
+	var l [BlockSize]byte
+	block.Encrypt(l[:], l[:])
+	c.k1 = Double(l)
+	c.k2 = Double(c.k1)
+	return c
+}
+
+// Sum returns the CMAC tag of the concatenation of parts.
+func (c *CMAC) Sum(parts ...[]byte) [BlockSize]byte {
+	var msg []byte
+	if len(parts) == 1 {
+		msg = parts[0]
+	} else {
+		for _, part := range parts {
+			msg = append(msg, part...)
+		}
+	}
+	var x [BlockSize]byte
+	for len(msg) > BlockSize {
+		xorInto(x[:], msg[:BlockSize])
+		c.block.Encrypt(x[:], x[:])
+		msg = msg[BlockSize:]
+	}
+	if len(msg) == BlockSize {
+		xorInto(x[:], msg)
+		xorInto(x[:], c.k1[:])
+	} else {
+		xorInto(x[:], msg)
+		x[len(msg)] ^= 0x80
+		xorInto(x[:], c.k2[:])
+	}
+	c.block.Encrypt(x[:], x[:])
+	return x
+}
+
+// Double multiplies in by x in GF(2^128) with the polynomial
+// x^128 + x^7 + x^2 + x + 1, the subkey step of SP 800-38B and the dbl
+// operation of RFC 5297.
+func Double(in [BlockSize]byte) [BlockSize]byte {
+	var out [BlockSize]byte
+	carry := in[0] >> 7
+	for i := 0; i < BlockSize-1; i++ {
+		out[i] = in[i]<<1 | in[i+1]>>7
+	}
+	out[BlockSize-1] = in[BlockSize-1]<<1 ^ 0x87&-carry
+	return out
+}
+
+func xorInto(dst, src []byte) {
+	for i := range src {
+		dst[i] ^= src[i]
+	}
+}
@@ -0,0 +1,245 @@
+package keccak
+
+import (
+	"bytes"
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
+// TurboSHAKE rates (RFC 9861): 168 bytes for TurboSHAKE128 and 136 bytes for
+// TurboSHAKE256.
+const (
+	TurboSHAKE128Rate = 168
+	TurboSHAKE256Rate = 136
+)
+
+// KangarooTwelve domain separation bytes and tree parameters (RFC 9861).
+const (
+	ktChunkSize    = 8192
+	ktSingleNode   = 0x07
+	ktFinalNode    = 0x06
+	ktLeafNode     = 0x0b
+	ktFirstPadding = 0x03
+)
+
+// NewTurboSHAKE returns a TurboSHAKE sponge with the given rate and domain
+// separation byte, which must be in 0x01..0x7f.
+func NewTurboSHAKE(rate int, domain byte) *Sponge {
+	s := &Sponge{}
+	s.InitRounds(rate, domain, TurboRounds)
+	return s
+}
+
+// KangarooTwelve incrementally computes KT128 or KT256 (RFC 9861). Input
+// beyond the first 8192-byte chunk is split into leaves whose chaining values
+// are computed concurrently, batch by batch, and absorbed in order.
+type KangarooTwelve struct {
+	rate   int
+	cvLen  int
+	suffix []byte
+	// buf holds S_0 until the input outgrows a single chunk, then any
+	// leaf bytes not yet hashed.
+	buf     []byte
+	tree    bool
+	final   Sponge
+	leaves  uint64
+	batch   int
+	workers int
+}
+
+// NewKangarooTwelve returns a KT128 (rate 168) or KT256 (rate 136) state with
+// the given customization string.
+func NewKangarooTwelve(rate int, customization []byte) *KangarooTwelve {
+	workers := runtime.GOMAXPROCS(0)
+	k := &KangarooTwelve{
+		rate:    rate,
+		cvLen:   200 - rate,
+		workers: workers,
+		batch:   ktChunkSize * 2 * workers,
+	}
+	k.suffix = append(append([]byte(nil), customization...), lengthEncode(uint64(len(customization)))...)
+	return k
+}
+
+// Reset discards all absorbed input.
+func (k *KangarooTwelve) Reset() {
+	k.buf = k.buf[:0]
+	k.tree = false
+	k.leaves = 0
+}
+
+// Write absorbs message bytes.
+func (k *KangarooTwelve) Write(data []byte) {
+	if !k.tree {
+		if len(k.buf)+len(data) <= ktChunkSize {
+			k.buf = append(k.buf, data...)
+			return
+		}
+		need := ktChunkSize - len(k.buf)
+		k.buf = append(k.buf, data[:need]...)
+		data = data[need:]
+		k.final.InitRounds(k.rate, ktFinalNode, TurboRounds)
+		k.final.Absorb(k.buf)
+		k.final.Absorb([]byte{ktFirstPadding, 0, 0, 0, 0, 0, 0, 0})
+		k.buf = k.buf[:0]
+		k.tree = true
+	}
+	if len(k.buf) > 0 {
+		need := k.batch - len(k.buf)
+		if need > len(data) {
+			k.buf = append(k.buf, data...)
+			return
+		}
+		k.buf = append(k.buf, data[:need]...)
+		data = data[need:]
+		k.hashLeaves(k.buf)
+		k.buf = k.buf[:0]
+	}
+	if full := len(data) / k.batch * k.batch; full > 0 {
+		k.hashLeaves(data[:full])
+		data = data[full:]
+	}
+	k.buf = append(k.buf, data...)
+}
+
+// Sum writes len(out) bytes of output into out without modifying the state.
+func (k *KangarooTwelve) Sum(out []byte) {
+	s := k.finish()
+	s.Squeeze(out)
+}
+
+// XOF returns a reader over the output of the input absorbed so far. The
+// state remains usable.
+func (k *KangarooTwelve) XOF() *Reader {
+	return &Reader{sponge: k.finish()}
+}
+
+func (k *KangarooTwelve) finish() Sponge {
+	tmp := *k
+	tmp.buf = append([]byte(nil), k.buf...)
+	tmp.Write(tmp.suffix)
+	if !tmp.tree {
+		var s Sponge
+		s.InitRounds(tmp.rate, ktSingleNode, TurboRounds)
+		s.Absorb(tmp.buf)
+		return s
+	}
+	if len(tmp.buf) > 0 {
+		tmp.hashLeaves(tmp.buf)
+	}
+	tmp.final.Absorb(lengthEncode(tmp.leaves))
+	tmp.final.Absorb([]byte{0xff, 0xff})
+	return tmp.final
+}
+
+// hashLeaves absorbs the chaining values of data split into ktChunkSize
+// leaves; only the final leaf may be shorter.
+func (k *KangarooTwelve) hashLeaves(data []byte) {
+	n := (len(data) + ktChunkSize - 1) / ktChunkSize
+	cvs := make([]byte, n*k.cvLen)
+	workers := k.workers
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 || len(data) < parallelMinBytes {
+		k.leafRange(data, cvs, 0, n)
+	} else {
+		per := (n + workers - 1) / workers
+		var wg sync.WaitGroup
+		for start := 0; start < n; start += per {
+			end := start + per
+			if end > n {
+				end = n
+			}
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				k.leafRange(data, cvs, start, end)
+			}(start, end)
+		}
+		wg.Wait()
+	}
+	k.final.Absorb(cvs)
+	k.leaves += uint64(n)
+}
+
+func (k *KangarooTwelve) leafRange(data, cvs []byte, start, end int) {
+	var s Sponge
+	for i := start; i < end; i++ {
+		lo := i * ktChunkSize
+		hi := lo + ktChunkSize
+		if hi > len(data) {
+			hi = len(data)
+		}
+		s.InitRounds(k.rate, ktLeafNode, TurboRounds)
+		s.Absorb(data[lo:hi])
+		s.Squeeze(cvs[i*k.cvLen : (i+1)*k.cvLen])
+	}
+}
+
+// lengthEncode is the RFC 9861 length_encode: the big-endian bytes of x
+// without leading zeros, followed by their count.
+func lengthEncode(x uint64) []byte {
+	var buf [9]byte
+	n := 0
+	for v := x; v > 0; v >>= 8 {
+		n++
+	}
+	for i := 0; i < n; i++ {
+		buf[i] = byte(x >> (8 * uint(n-1-i)))
+	}
+	buf[n] = byte(n)
+	return append([]byte(nil), buf[:n+1]...)
+}
+
+// Rate returns the rate of the underlying TurboSHAKE sponge.
+func (k *KangarooTwelve) Rate() int { return k.rate }
+
+// Clone returns an independent copy of the state.
+func (k *KangarooTwelve) Clone() *KangarooTwelve {
+	c := *k
+	c.buf = append([]byte(nil), k.buf...)
+	return &c
+}
+
+// Encode appends the KangarooTwelve configuration and running state to e.
+func (k *KangarooTwelve) Encode(e *marshal.Encoder) {
+	e.Uint64(uint64(k.rate))
+	e.Bytes(k.suffix)
+	e.Bool(k.tree)
+	e.Uint64(k.leaves)
+	e.Bytes(k.buf)
+	if k.tree {
+		k.final.Encode(e)
+	}
+}
+
+// Decode restores a state written by Encode into k, which must have been
+// created with the same rate and customization string.
+func (k *KangarooTwelve) Decode(d *marshal.Decoder) {
+	d.Expect(uint64(k.rate))
+	suffix := d.Bytes(math.MaxInt32)
+	d.Check(bytes.Equal(suffix, k.suffix), marshal.ErrMismatch)
+	k.tree = d.Bool()
+	k.leaves = d.Uint64()
+	buf := d.Bytes(math.MaxInt32)
+	if k.tree {
+		k.final.InitRounds(k.rate, ktFinalNode, TurboRounds)
+		k.final.Decode(d)
+	}
+	d.Check(k.tree || len(buf) <= ktChunkSize, marshal.ErrInvalid)
+	if d.Err() != nil {
+		return
+	}
+	k.buf = append(k.buf[:0], buf...)
+	// The batch size depends on GOMAXPROCS at construction, so a state
+	// saved elsewhere may hold more than one batch of pending leaves.
+	if k.tree && len(k.buf) >= k.batch {
+		full := len(k.buf) / ktChunkSize * ktChunkSize
+		k.hashLeaves(k.buf[:full])
+		k.buf = append(k.buf[:0], k.buf[full:]...)
+	}
+}
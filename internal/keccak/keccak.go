@@ -1,6 +1,9 @@
 package keccak
 
-import "math/bits"
+import (
+	"encoding/binary"
+	"math/bits"
+)
 
 var roundConstants = [24]uint64{
 	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
@@ -21,7 +24,27 @@ var rotationOffsets = [5][5]uint{
 
 // keccakF1600 applies the Keccak-f[1600] permutation in-place on the state.
 func keccakF1600(a *[25]uint64) {
-	for round := 0; round < 24; round++ {
+	keccakP1600(a, 24)
+}
+
+// F1600 applies Keccak-f[1600] in-place to a state held as 200 bytes, with
+// lanes in little-endian order as in FIPS 202. Byte-oriented duplex
+// constructions such as STROBE use it.
+func F1600(b *[200]byte) {
+	var a [25]uint64
+	for i := range a {
+		a[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+	keccakF1600(&a)
+	for i := range a {
+		binary.LittleEndian.PutUint64(b[8*i:], a[i])
+	}
+}
+
+// keccakP1600 applies Keccak-p[1600, rounds], i.e. the last rounds rounds of
+// Keccak-f[1600], in-place on the state. TurboSHAKE uses 12 rounds.
+func keccakP1600(a *[25]uint64, rounds int) {
+	for round := 24 - rounds; round < 24; round++ {
 		var c [5]uint64
 		for x := 0; x < 5; x++ {
 			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
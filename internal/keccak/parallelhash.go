@@ -0,0 +1,186 @@
+package keccak
+
+import (
+	"math"
+	"runtime"
+	"sync"
+
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
+// parallelMinBytes is the smallest batch worth spreading across goroutines;
+// below it the scheduling overhead outweighs the per-block hashing cost.
+const parallelMinBytes = 64 << 10
+
+// ParallelHash incrementally computes ParallelHash or ParallelHashXOF
+// (SP 800-185). Input is split into blocks of blockSize bytes whose inner
+// digests are computed concurrently, batch by batch, and absorbed in order.
+type ParallelHash struct {
+	outer paramXOF
+	rate  int
+	// digestLen is the inner digest size, equal to the sponge capacity.
+	digestLen int
+	blockSize int
+	batch     int
+	workers   int
+	buf       []byte
+	blocks    uint64
+}
+
+// NewParallelHash returns a ParallelHash state for the given cSHAKE rate (168
+// for ParallelHash128, 136 for ParallelHash256). blockSize must be positive.
+func NewParallelHash(rate, blockSize int, customization []byte) *ParallelHash {
+	workers := runtime.GOMAXPROCS(0)
+	p := &ParallelHash{
+		outer:     *newParametrisedXOF(rate, []byte(parallelHashFn), customization),
+		rate:      rate,
+		digestLen: 200 - rate,
+		blockSize: blockSize,
+		workers:   workers,
+		batch:     blockSize * 2 * workers,
+	}
+	p.outer.Write(LeftEncode(uint64(blockSize)))
+	return p
+}
+
+// Reset discards all absorbed input.
+func (p *ParallelHash) Reset() {
+	p.outer.reset()
+	p.outer.Write(LeftEncode(uint64(p.blockSize)))
+	p.buf = p.buf[:0]
+	p.blocks = 0
+}
+
+// Write absorbs data. Complete batches of blocks are hashed immediately.
+func (p *ParallelHash) Write(data []byte) {
+	if len(p.buf) > 0 {
+		need := p.batch - len(p.buf)
+		if need > len(data) {
+			p.buf = append(p.buf, data...)
+			return
+		}
+		p.buf = append(p.buf, data[:need]...)
+		data = data[need:]
+		p.hashBlocks(p.buf)
+		p.buf = p.buf[:0]
+	}
+	if full := len(data) / p.batch * p.batch; full > 0 {
+		p.hashBlocks(data[:full])
+		data = data[full:]
+	}
+	p.buf = append(p.buf, data...)
+}
+
+// Sum writes len(out) bytes of ParallelHash output into out without modifying
+// the state.
+func (p *ParallelHash) Sum(out []byte) {
+	x := p.finish(uint64(len(out)) * 8)
+	x.Read(out)
+}
+
+// XOF returns a reader over the ParallelHashXOF output of the input absorbed
+// so far. The state remains usable.
+func (p *ParallelHash) XOF() *Reader {
+	x := p.finish(0)
+	return &Reader{sponge: x.sponge}
+}
+
+func (p *ParallelHash) finish(outBits uint64) paramXOF {
+	tmp := *p
+	if len(tmp.buf) > 0 {
+		full := len(tmp.buf) / tmp.blockSize * tmp.blockSize
+		if full > 0 {
+			tmp.hashBlocks(tmp.buf[:full])
+		}
+		if full < len(tmp.buf) {
+			tmp.hashBlocks(tmp.buf[full:])
+		}
+	}
+	tmp.outer.Write(RightEncode(tmp.blocks))
+	tmp.outer.Write(RightEncode(outBits))
+	return tmp.outer
+}
+
+// hashBlocks absorbs the inner digests of data split into blockSize chunks;
+// only the final chunk may be shorter than blockSize.
+func (p *ParallelHash) hashBlocks(data []byte) {
+	n := (len(data) + p.blockSize - 1) / p.blockSize
+	digests := make([]byte, n*p.digestLen)
+	workers := p.workers
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 || len(data) < parallelMinBytes {
+		p.hashRange(data, digests, 0, n)
+	} else {
+		per := (n + workers - 1) / workers
+		var wg sync.WaitGroup
+		for start := 0; start < n; start += per {
+			end := start + per
+			if end > n {
+				end = n
+			}
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				p.hashRange(data, digests, start, end)
+			}(start, end)
+		}
+		wg.Wait()
+	}
+	p.outer.Write(digests)
+	p.blocks += uint64(n)
+}
+
+// hashRange computes the inner digests of blocks [start, end). SP 800-185
+// defines each inner digest as cSHAKE(block, L, "", ""), which is plain SHAKE;
+// releases before the SP 800-185 streaming builders used cSHAKE with the
+// outer N and S here and produced non-conforming ParallelHash digests.
+func (p *ParallelHash) hashRange(data, digests []byte, start, end int) {
+	var s Sponge
+	for i := start; i < end; i++ {
+		lo := i * p.blockSize
+		hi := lo + p.blockSize
+		if hi > len(data) {
+			hi = len(data)
+		}
+		s.Init(p.rate, domainSHAKE)
+		s.Absorb(data[lo:hi])
+		s.Squeeze(digests[i*p.digestLen : (i+1)*p.digestLen])
+	}
+}
+
+// Clone returns an independent copy of the state.
+func (p *ParallelHash) Clone() *ParallelHash {
+	c := *p
+	c.buf = append([]byte(nil), p.buf...)
+	return &c
+}
+
+// Encode appends the configuration and running state to e.
+func (p *ParallelHash) Encode(e *marshal.Encoder) {
+	e.Uint64(uint64(p.blockSize))
+	p.outer.encode(e)
+	e.Uint64(p.blocks)
+	e.Bytes(p.buf)
+}
+
+// Decode restores a state written by Encode into p, which must have been
+// created with the same rate, block size and customization string.
+func (p *ParallelHash) Decode(d *marshal.Decoder) {
+	d.Expect(uint64(p.blockSize))
+	p.outer.decode(d)
+	p.blocks = d.Uint64()
+	buf := d.Bytes(math.MaxInt32)
+	if d.Err() != nil {
+		return
+	}
+	p.buf = append(p.buf[:0], buf...)
+	// The batch size depends on GOMAXPROCS at construction, so a state
+	// saved elsewhere may hold more than one batch of pending blocks.
+	if len(p.buf) >= p.batch {
+		full := len(p.buf) / p.blockSize * p.blockSize
+		p.hashBlocks(p.buf[:full])
+		p.buf = append(p.buf[:0], p.buf[full:]...)
+	}
+}
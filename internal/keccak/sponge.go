@@ -1,9 +1,17 @@
 package keccak
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
 
 const MaxRate = 168
 
+// TurboRounds is the number of Keccak-p rounds used by TurboSHAKE and
+// KangarooTwelve.
+const TurboRounds = 12
+
 type Sponge struct {
 	state     [25]uint64
 	buf       [MaxRate]byte
@@ -11,14 +19,32 @@ type Sponge struct {
 	off       int
 	ds        byte
 	squeezing bool
+	// rounds selects Keccak-p[1600, rounds]; zero means the full 24 rounds
+	// of Keccak-f[1600].
+	rounds int
 }
 
 func (s *Sponge) Init(rate int, ds byte) {
 	s.rate = rate
 	s.ds = ds
+	s.rounds = 0
 	s.Reset()
 }
 
+// InitRounds is like Init but selects a reduced-round Keccak-p permutation.
+func (s *Sponge) InitRounds(rate int, ds byte, rounds int) {
+	s.Init(rate, ds)
+	s.rounds = rounds
+}
+
+func (s *Sponge) permute() {
+	if s.rounds == 0 {
+		keccakF1600(&s.state)
+		return
+	}
+	keccakP1600(&s.state, s.rounds)
+}
+
 func (s *Sponge) Reset() {
 	for i := range s.state {
 		s.state[i] = 0
@@ -49,7 +75,7 @@ func (s *Sponge) absorbBlock() {
 	for i := 0; i < s.rate; i++ {
 		s.buf[i] = 0
 	}
-	keccakF1600(&s.state)
+	s.permute()
 	s.off = 0
 }
 
@@ -67,7 +93,7 @@ func (s *Sponge) finalize() {
 	for i := 0; i < s.rate; i++ {
 		buf[i] = 0
 	}
-	keccakF1600(&s.state)
+	s.permute()
 	s.off = 0
 	s.squeezing = true
 }
@@ -86,7 +112,7 @@ func (s *Sponge) Squeeze(out []byte) {
 		produced += n
 		s.off += n
 		if s.off == s.rate {
-			keccakF1600(&s.state)
+			s.permute()
 			s.off = 0
 		}
 	}
@@ -94,6 +120,17 @@ func (s *Sponge) Squeeze(out []byte) {
 
 func (s *Sponge) Rate() int { return s.rate }
 
+// Reader squeezes output from a sponge snapshot that no longer accepts input.
+type Reader struct {
+	sponge Sponge
+}
+
+// Read fills p with the next bytes of sponge output. It never returns an error.
+func (r *Reader) Read(p []byte) (int, error) {
+	r.sponge.Squeeze(p)
+	return len(p), nil
+}
+
 func xorIn(state *[25]uint64, buf []byte) {
 	for i := 0; i < len(buf)/8; i++ {
 		state[i] ^= binary.LittleEndian.Uint64(buf[i*8:])
@@ -115,3 +152,61 @@ func SumFixed(rate int, ds byte, out, msg []byte) {
 	s.Absorb(msg)
 	s.Squeeze(out)
 }
+
+// Encode appends the sponge configuration and running state to e.
+func (s *Sponge) Encode(e *marshal.Encoder) {
+	e.Uint64(uint64(s.rate))
+	e.Uint64(uint64(s.ds))
+	e.Uint64(uint64(s.rounds))
+	e.Uint64s(s.state[:])
+	e.Bytes(s.buf[:s.rate])
+	e.Uint32(uint32(s.off))
+	e.Bool(s.squeezing)
+}
+
+// Decode restores a state written by Encode. s must already be initialised
+// with the same rate, domain byte and round count; otherwise the decoder
+// records marshal.ErrMismatch.
+func (s *Sponge) Decode(d *marshal.Decoder) {
+	d.Expect(uint64(s.rate))
+	d.Expect(uint64(s.ds))
+	d.Expect(uint64(s.rounds))
+	s.decodeState(d)
+}
+
+func (s *Sponge) decodeState(d *marshal.Decoder) {
+	d.Uint64s(s.state[:])
+	buf := d.Bytes(s.rate)
+	off := d.Uint32()
+	s.squeezing = d.Bool()
+	d.Check(len(buf) == s.rate && off < uint32(s.rate), marshal.ErrInvalid)
+	if d.Err() != nil {
+		return
+	}
+	copy(s.buf[:], buf)
+	s.off = int(off)
+}
+
+// Encode appends the reader's sponge state to e.
+func (r *Reader) Encode(e *marshal.Encoder) { r.sponge.Encode(e) }
+
+// DecodeReader reads a reader written by Reader.Encode over a sponge with the
+// given rate.
+func DecodeReader(d *marshal.Decoder, rate int) *Reader {
+	r := &Reader{}
+	d.Expect(uint64(rate))
+	ds := d.Uint64()
+	rounds := d.Uint64()
+	d.Check(ds != 0 && ds <= 0xff && (rounds == 0 || rounds == TurboRounds), marshal.ErrInvalid)
+	r.sponge.rate = rate
+	r.sponge.ds = byte(ds)
+	r.sponge.rounds = int(rounds)
+	r.sponge.decodeState(d)
+	return r
+}
+
+// Clone returns an independent copy of the reader.
+func (r *Reader) Clone() *Reader {
+	c := *r
+	return &c
+}
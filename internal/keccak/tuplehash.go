@@ -1,8 +1,17 @@
 package keccak
 
-import "errors"
+import (
+	"bytes"
+	"errors"
+	"math"
 
-const domainCSHAKE = 0x04
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
+const (
+	domainCSHAKE = 0x04
+	domainSHAKE  = 0x1f
+)
 
 // TupleHash128 computes the TupleHash-128 digest over the provided tuple, using
 // the optional customization string. The output length is measured in bytes.
@@ -10,8 +19,7 @@ func TupleHash128(tuple [][]byte, customization []byte, outLen int) ([]byte, err
 	if outLen <= 0 {
 		return nil, errors.New("keccak: invalid TupleHash128 output length")
 	}
-	x := newParametrisedXOF(168, []byte(tupleHashFn), customization)
-	return tupleHash(x, tuple, outLen)
+	return tupleHash(NewTupleHash(168, customization), tuple, outLen, false), nil
 }
 
 // TupleHash256 computes the TupleHash-256 digest over the provided tuple.
@@ -19,18 +27,72 @@ func TupleHash256(tuple [][]byte, customization []byte, outLen int) ([]byte, err
 	if outLen <= 0 {
 		return nil, errors.New("keccak: invalid TupleHash256 output length")
 	}
-	x := newParametrisedXOF(136, []byte(tupleHashFn), customization)
-	return tupleHash(x, tuple, outLen)
+	return tupleHash(NewTupleHash(136, customization), tuple, outLen, false), nil
+}
+
+// TupleHashXOF128 computes outLen bytes of TupleHashXOF-128 output.
+func TupleHashXOF128(tuple [][]byte, customization []byte, outLen int) ([]byte, error) {
+	if outLen < 0 {
+		return nil, errors.New("keccak: invalid TupleHashXOF128 output length")
+	}
+	return tupleHash(NewTupleHash(168, customization), tuple, outLen, true), nil
+}
+
+// TupleHashXOF256 computes outLen bytes of TupleHashXOF-256 output.
+func TupleHashXOF256(tuple [][]byte, customization []byte, outLen int) ([]byte, error) {
+	if outLen < 0 {
+		return nil, errors.New("keccak: invalid TupleHashXOF256 output length")
+	}
+	return tupleHash(NewTupleHash(136, customization), tuple, outLen, true), nil
 }
 
-func tupleHash(x *paramXOF, tuple [][]byte, outLen int) ([]byte, error) {
+func tupleHash(t *TupleHash, tuple [][]byte, outLen int, xof bool) []byte {
 	for _, element := range tuple {
-		x.Write(EncodeString(element))
+		t.WriteElement(element)
 	}
-	x.Write(RightEncode(uint64(outLen * 8)))
 	out := make([]byte, outLen)
+	if xof {
+		_, _ = t.XOF().Read(out)
+	} else {
+		t.Sum(out)
+	}
+	return out
+}
+
+// TupleHash incrementally computes TupleHash or TupleHashXOF, absorbing one
+// tuple element at a time.
+type TupleHash struct {
+	x paramXOF
+}
+
+// NewTupleHash returns a TupleHash state for the given cSHAKE rate (168 for
+// TupleHash128, 136 for TupleHash256) and customization string.
+func NewTupleHash(rate int, customization []byte) *TupleHash {
+	return &TupleHash{x: *newParametrisedXOF(rate, []byte(tupleHashFn), customization)}
+}
+
+// WriteElement appends a complete tuple element.
+func (t *TupleHash) WriteElement(element []byte) {
+	t.x.Write(EncodeString(element))
+}
+
+// Reset discards all absorbed elements.
+func (t *TupleHash) Reset() { t.x.reset() }
+
+// Sum writes len(out) bytes of TupleHash output into out without modifying
+// the state.
+func (t *TupleHash) Sum(out []byte) {
+	x := t.x
+	x.Write(RightEncode(uint64(len(out)) * 8))
 	x.Read(out)
-	return out, nil
+}
+
+// XOF returns a reader over the TupleHashXOF output of the elements absorbed
+// so far. The state remains usable.
+func (t *TupleHash) XOF() *Reader {
+	x := t.x
+	x.Write(RightEncode(0))
+	return &Reader{sponge: x.sponge}
 }
 
 // ParallelHash128 computes ParallelHash-128 over msg using the given blockSize
@@ -42,7 +104,7 @@ func ParallelHash128(msg []byte, blockSize int, customization []byte, outLen int
 	if outLen <= 0 {
 		return nil, errors.New("keccak: invalid ParallelHash128 output length")
 	}
-	return parallelHash(168, 32, msg, blockSize, customization, outLen)
+	return parallelHash(NewParallelHash(168, blockSize, customization), msg, outLen, false), nil
 }
 
 // ParallelHash256 computes ParallelHash-256 over msg using the given blockSize
@@ -54,51 +116,40 @@ func ParallelHash256(msg []byte, blockSize int, customization []byte, outLen int
 	if outLen <= 0 {
 		return nil, errors.New("keccak: invalid ParallelHash256 output length")
 	}
-	return parallelHash(136, 64, msg, blockSize, customization, outLen)
+	return parallelHash(NewParallelHash(136, blockSize, customization), msg, outLen, false), nil
 }
 
-func parallelHash(rate, digestLen int, msg []byte, blockSize int, customization []byte, outLen int) ([]byte, error) {
-	blocks := splitBlocks(msg, blockSize)
-	if len(blocks) == 0 {
-		blocks = append(blocks, []byte{})
-	}
-	intermediates := make([][]byte, 0, len(blocks))
-	for _, block := range blocks {
-		inner := newParametrisedXOF(rate, []byte(parallelHashFn), customization)
-		inner.Write(EncodeString(block))
-		inner.Write(RightEncode(uint64(blockSize * 8)))
-		buf := make([]byte, digestLen)
-		inner.Read(buf)
-		intermediates = append(intermediates, buf)
+// ParallelHashXOF128 computes outLen bytes of ParallelHashXOF-128 output.
+func ParallelHashXOF128(msg []byte, blockSize int, customization []byte, outLen int) ([]byte, error) {
+	if blockSize <= 0 {
+		return nil, errors.New("keccak: invalid block size for ParallelHashXOF128")
 	}
-	outer := newParametrisedXOF(rate, []byte(parallelHashFn), customization)
-	for _, elem := range intermediates {
-		outer.Write(EncodeString(elem))
+	if outLen < 0 {
+		return nil, errors.New("keccak: invalid ParallelHashXOF128 output length")
 	}
-	outer.Write(RightEncode(uint64(len(intermediates))))
-	outer.Write(RightEncode(uint64(outLen * 8)))
-	out := make([]byte, outLen)
-	outer.Read(out)
-	return out, nil
+	return parallelHash(NewParallelHash(168, blockSize, customization), msg, outLen, true), nil
 }
 
-func splitBlocks(msg []byte, blockSize int) [][]byte {
-	if blockSize <= 0 || len(msg) == 0 {
-		return nil
+// ParallelHashXOF256 computes outLen bytes of ParallelHashXOF-256 output.
+func ParallelHashXOF256(msg []byte, blockSize int, customization []byte, outLen int) ([]byte, error) {
+	if blockSize <= 0 {
+		return nil, errors.New("keccak: invalid block size for ParallelHashXOF256")
+	}
+	if outLen < 0 {
+		return nil, errors.New("keccak: invalid ParallelHashXOF256 output length")
 	}
-	n := (len(msg) + blockSize - 1) / blockSize
-	blocks := make([][]byte, 0, n)
-	for i := 0; i < n; i++ {
-		start := i * blockSize
-		end := start + blockSize
-		if end > len(msg) {
-			end = len(msg)
-		}
-		dup := make([]byte, end-start)
-		copy(dup, msg[start:end])
-		blocks = append(blocks, dup)
+	return parallelHash(NewParallelHash(136, blockSize, customization), msg, outLen, true), nil
+}
+
+func parallelHash(p *ParallelHash, msg []byte, outLen int, xof bool) []byte {
+	p.Write(msg)
+	out := make([]byte, outLen)
+	if xof {
+		_, _ = p.XOF().Read(out)
+	} else {
+		p.Sum(out)
 	}
-	return blocks
+	return out
 }
 
 type paramXOF struct {
@@ -118,14 +169,17 @@ func newParametrisedXOF(rate int, functionName, customization []byte) *paramXOF
 	return p
 }
 
+// reset reinitialises the sponge. cSHAKE with empty N and S is defined to be
+// SHAKE (SP 800-185 section 3.3), so that case uses the SHAKE domain byte.
 func (p *paramXOF) reset() {
-	p.sponge.Init(p.rate, domainCSHAKE)
-	if len(p.functionName) != 0 || len(p.customization) != 0 {
-		prefix := EncodeString(p.functionName)
-		prefix = append(prefix, EncodeString(p.customization)...)
-		prefixed := Bytepad(prefix, p.rate)
-		p.sponge.Absorb(prefixed)
+	if len(p.functionName) == 0 && len(p.customization) == 0 {
+		p.sponge.Init(p.rate, domainSHAKE)
+		return
 	}
+	p.sponge.Init(p.rate, domainCSHAKE)
+	prefix := EncodeString(p.functionName)
+	prefix = append(prefix, EncodeString(p.customization)...)
+	p.sponge.Absorb(Bytepad(prefix, p.rate))
 }
 
 func (p *paramXOF) Write(data []byte) {
@@ -140,3 +194,27 @@ const (
 	tupleHashFn    = "TupleHash"
 	parallelHashFn = "ParallelHash"
 )
+
+func (p *paramXOF) encode(e *marshal.Encoder) {
+	e.Bytes(p.customization)
+	p.sponge.Encode(e)
+}
+
+func (p *paramXOF) decode(d *marshal.Decoder) {
+	customization := d.Bytes(math.MaxInt32)
+	d.Check(bytes.Equal(customization, p.customization), marshal.ErrMismatch)
+	p.sponge.Decode(d)
+}
+
+// Clone returns an independent copy of the state.
+func (t *TupleHash) Clone() *TupleHash {
+	c := *t
+	return &c
+}
+
+// Encode appends the customization string and sponge state to e.
+func (t *TupleHash) Encode(e *marshal.Encoder) { t.x.encode(e) }
+
+// Decode restores a state written by Encode into t, which must have been
+// created with the same rate and customization string.
+func (t *TupleHash) Decode(d *marshal.Decoder) { t.x.decode(d) }
@@ -0,0 +1,230 @@
+// Package marshal implements the envelope shared by the library's
+// encoding.BinaryMarshaler implementations: a magic prefix, a format version
+// and an algorithm tag, followed by big-endian fields.
+//
+//	"CNS" | version | len(tag) | tag | fields...
+//
+// Configuration that is not secret (digest size, rate, parameter block) is
+// encoded alongside the running state so that decoding into an instance with
+// a different configuration fails with ErrMismatch. Keys are never encoded:
+// a keyed state must be restored into an instance created with the same key.
+package marshal
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Version is the current encoding version.
+const Version = 1
+
+const magic = "CNS"
+
+var (
+	// ErrInvalid reports a truncated, corrupted or unsupported encoding.
+	ErrInvalid = errors.New("marshal: invalid state encoding")
+	// ErrMismatch reports an encoding produced by a different algorithm or
+	// configuration than the receiving instance.
+	ErrMismatch = errors.New("marshal: state belongs to a different algorithm or configuration")
+)
+
+// Encoder appends fields to an encoding.
+type Encoder struct {
+	b []byte
+}
+
+// NewEncoder starts an encoding tagged with alg.
+func NewEncoder(alg string) *Encoder {
+	b := make([]byte, 0, len(magic)+2+len(alg)+64)
+	b = append(b, magic...)
+	b = append(b, Version, byte(len(alg)))
+	b = append(b, alg...)
+	return &Encoder{b: b}
+}
+
+// Uint8 appends v.
+func (e *Encoder) Uint8(v uint8) { e.b = append(e.b, v) }
+
+// Bool appends v as a single byte.
+func (e *Encoder) Bool(v bool) {
+	if v {
+		e.b = append(e.b, 1)
+		return
+	}
+	e.b = append(e.b, 0)
+}
+
+// Uint32 appends v.
+func (e *Encoder) Uint32(v uint32) { e.b = binary.BigEndian.AppendUint32(e.b, v) }
+
+// Uint64 appends v.
+func (e *Encoder) Uint64(v uint64) { e.b = binary.BigEndian.AppendUint64(e.b, v) }
+
+// Uint32s appends every element of v; the count is implied by the format.
+func (e *Encoder) Uint32s(v []uint32) {
+	for _, w := range v {
+		e.Uint32(w)
+	}
+}
+
+// Uint64s appends every element of v; the count is implied by the format.
+func (e *Encoder) Uint64s(v []uint64) {
+	for _, w := range v {
+		e.Uint64(w)
+	}
+}
+
+// Bytes appends v with a 32-bit length prefix.
+func (e *Encoder) Bytes(v []byte) {
+	e.Uint32(uint32(len(v)))
+	e.b = append(e.b, v...)
+}
+
+// Data returns the encoding.
+func (e *Encoder) Data() []byte { return e.b }
+
+// Decoder reads fields from an encoding. Errors are sticky: once a read
+// fails, subsequent reads return zero values and Finish reports the first
+// error.
+type Decoder struct {
+	b   []byte
+	err error
+}
+
+// NewDecoder validates the envelope of b and checks that it is tagged with
+// alg.
+func NewDecoder(b []byte, alg string) (*Decoder, error) {
+	if len(b) < len(magic)+2 || string(b[:len(magic)]) != magic {
+		return nil, ErrInvalid
+	}
+	b = b[len(magic):]
+	if b[0] != Version {
+		return nil, ErrInvalid
+	}
+	n := int(b[1])
+	b = b[2:]
+	if len(b) < n {
+		return nil, ErrInvalid
+	}
+	if string(b[:n]) != alg {
+		return nil, ErrMismatch
+	}
+	return &Decoder{b: b[n:]}, nil
+}
+
+func (d *Decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.b) < n {
+		d.err = ErrInvalid
+		d.b = nil
+		return nil
+	}
+	v := d.b[:n]
+	d.b = d.b[n:]
+	return v
+}
+
+// Uint8 reads a byte.
+func (d *Decoder) Uint8() uint8 {
+	v := d.take(1)
+	if v == nil {
+		return 0
+	}
+	return v[0]
+}
+
+// Bool reads a byte that must be 0 or 1.
+func (d *Decoder) Bool() bool {
+	switch d.Uint8() {
+	case 0:
+		return false
+	case 1:
+		return true
+	default:
+		d.Fail(ErrInvalid)
+		return false
+	}
+}
+
+// Uint32 reads a 32-bit value.
+func (d *Decoder) Uint32() uint32 {
+	v := d.take(4)
+	if v == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(v)
+}
+
+// Uint64 reads a 64-bit value.
+func (d *Decoder) Uint64() uint64 {
+	v := d.take(8)
+	if v == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(v)
+}
+
+// Uint32s fills dst.
+func (d *Decoder) Uint32s(dst []uint32) {
+	for i := range dst {
+		dst[i] = d.Uint32()
+	}
+}
+
+// Uint64s fills dst.
+func (d *Decoder) Uint64s(dst []uint64) {
+	for i := range dst {
+		dst[i] = d.Uint64()
+	}
+}
+
+// Bytes reads a length-prefixed byte string of at most max bytes and returns
+// a copy.
+func (d *Decoder) Bytes(max int) []byte {
+	n := d.Uint32()
+	if uint64(n) > uint64(max) {
+		d.Fail(ErrInvalid)
+		return nil
+	}
+	v := d.take(int(n))
+	if v == nil {
+		return nil
+	}
+	return append([]byte(nil), v...)
+}
+
+// Expect reads a configuration value and records ErrMismatch unless it
+// equals want.
+func (d *Decoder) Expect(want uint64) {
+	if got := d.Uint64(); d.err == nil && got != want {
+		d.err = ErrMismatch
+	}
+}
+
+// Check records err unless ok holds.
+func (d *Decoder) Check(ok bool, err error) {
+	if !ok {
+		d.Fail(err)
+	}
+}
+
+// Fail records err if no error has been recorded yet.
+func (d *Decoder) Fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+// Err returns the first error encountered so far.
+func (d *Decoder) Err() error { return d.err }
+
+// Finish returns the first error encountered, or ErrInvalid if unread bytes
+// remain.
+func (d *Decoder) Finish() error {
+	if d.err == nil && len(d.b) != 0 {
+		d.err = ErrInvalid
+	}
+	return d.err
+}
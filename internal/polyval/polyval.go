@@ -0,0 +1,258 @@
+// Package polyval implements the POLYVAL and GHASH universal hash functions
+// over GF(2^128) shared by AES-GCM-SIV, GMAC and the exported mac helpers.
+package polyval
+
+import "encoding/binary"
+
+// BlockSize is the size, in bytes, of the field elements processed by
+// POLYVAL and GHASH. It is also the size of keys and digests.
+const BlockSize = 16
+
+type element struct {
+	lo uint64
+	hi uint64
+}
+
+// Polyval is the POLYVAL universal hash defined in RFC 8452. Data is absorbed
+// in 16-byte blocks; a trailing partial block is buffered until the next
+// Write, Pad or Sum call.
+type Polyval struct {
+	key element
+	acc element
+	buf [BlockSize]byte
+	n   int
+}
+
+// New returns a POLYVAL state keyed with the 16-byte field element key.
+func New(key *[BlockSize]byte) *Polyval {
+	p := &Polyval{}
+	p.Init(key)
+	return p
+}
+
+// Init (re)keys p and clears any absorbed data.
+func (p *Polyval) Init(key *[BlockSize]byte) {
+	p.key = load(key[:])
+	p.Reset()
+}
+
+// Reset clears absorbed data while keeping the key.
+func (p *Polyval) Reset() {
+	p.acc = element{}
+	p.buf = [BlockSize]byte{}
+	p.n = 0
+}
+
+// Write absorbs data, buffering any trailing partial block.
+func (p *Polyval) Write(data []byte) {
+	if p.n > 0 {
+		k := copy(p.buf[p.n:], data)
+		p.n += k
+		data = data[k:]
+		if p.n < BlockSize {
+			return
+		}
+		p.mixBlock(p.buf[:])
+		p.n = 0
+	}
+	for len(data) >= BlockSize {
+		p.mixBlock(data[:BlockSize])
+		data = data[BlockSize:]
+	}
+	if len(data) > 0 {
+		p.n = copy(p.buf[:], data)
+	}
+}
+
+// Pad completes a buffered partial block with zero bytes and absorbs it. It is
+// a no-op when the absorbed data is block aligned.
+func (p *Polyval) Pad() {
+	if p.n == 0 {
+		return
+	}
+	for i := p.n; i < BlockSize; i++ {
+		p.buf[i] = 0
+	}
+	p.mixBlock(p.buf[:])
+	p.n = 0
+}
+
+// Sum returns the current digest, zero-padding any buffered partial block,
+// without modifying the state.
+func (p *Polyval) Sum() [BlockSize]byte {
+	tmp := *p
+	tmp.Pad()
+	var out [BlockSize]byte
+	binary.LittleEndian.PutUint64(out[:8], tmp.acc.lo)
+	binary.LittleEndian.PutUint64(out[8:], tmp.acc.hi)
+	return out
+}
+
+func (p *Polyval) mixBlock(block []byte) {
+	x := load(block)
+	p.acc.lo ^= x.lo
+	p.acc.hi ^= x.hi
+	p.acc = mul(p.acc, p.key)
+}
+
+// GHASH is the GCM universal hash from NIST SP 800-38D, computed through the
+// POLYVAL mapping given in RFC 8452 Appendix A.
+type GHASH struct {
+	p   Polyval
+	buf [BlockSize]byte
+	n   int
+}
+
+// NewGHASH returns a GHASH state keyed with the hash subkey h.
+func NewGHASH(h *[BlockSize]byte) *GHASH {
+	g := &GHASH{}
+	g.Init(h)
+	return g
+}
+
+// Init (re)keys g and clears any absorbed data.
+func (g *GHASH) Init(h *[BlockSize]byte) {
+	var rev [BlockSize]byte
+	reverse(&rev, h[:])
+	key := mulX(load(rev[:]))
+	var k [BlockSize]byte
+	store(&k, key)
+	g.p.Init(&k)
+	g.Reset()
+}
+
+// Reset clears absorbed data while keeping the key.
+func (g *GHASH) Reset() {
+	g.p.Reset()
+	g.buf = [BlockSize]byte{}
+	g.n = 0
+}
+
+// Write absorbs data, buffering any trailing partial block.
+func (g *GHASH) Write(data []byte) {
+	if g.n > 0 {
+		k := copy(g.buf[g.n:], data)
+		g.n += k
+		data = data[k:]
+		if g.n < BlockSize {
+			return
+		}
+		g.mixBlock(g.buf[:])
+		g.n = 0
+	}
+	for len(data) >= BlockSize {
+		g.mixBlock(data[:BlockSize])
+		data = data[BlockSize:]
+	}
+	if len(data) > 0 {
+		g.n = copy(g.buf[:], data)
+	}
+}
+
+// Pad completes a buffered partial block with zero bytes and absorbs it.
+func (g *GHASH) Pad() {
+	if g.n == 0 {
+		return
+	}
+	for i := g.n; i < BlockSize; i++ {
+		g.buf[i] = 0
+	}
+	g.mixBlock(g.buf[:])
+	g.n = 0
+}
+
+// Sum returns the current digest, zero-padding any buffered partial block,
+// without modifying the state.
+func (g *GHASH) Sum() [BlockSize]byte {
+	tmp := *g
+	tmp.Pad()
+	sum := tmp.p.Sum()
+	var out [BlockSize]byte
+	reverse(&out, sum[:])
+	return out
+}
+
+func (g *GHASH) mixBlock(block []byte) {
+	var rev [BlockSize]byte
+	reverse(&rev, block)
+	g.p.mixBlock(rev[:])
+}
+
+func load(b []byte) element {
+	return element{
+		lo: binary.LittleEndian.Uint64(b[:8]),
+		hi: binary.LittleEndian.Uint64(b[8:]),
+	}
+}
+
+func store(out *[BlockSize]byte, e element) {
+	binary.LittleEndian.PutUint64(out[:8], e.lo)
+	binary.LittleEndian.PutUint64(out[8:], e.hi)
+}
+
+func reverse(dst *[BlockSize]byte, src []byte) {
+	for i := 0; i < BlockSize; i++ {
+		dst[i] = src[BlockSize-1-i]
+	}
+}
+
+// mulX multiplies e by x modulo x^128 + x^127 + x^126 + x^121 + 1.
+func mulX(e element) element {
+	carry := e.hi >> 63
+	e.hi = e.hi<<1 | e.lo>>63
+	e.lo <<= 1
+	mask := -carry
+	e.hi ^= 0xc200000000000000 & mask
+	e.lo ^= 1 & mask
+	return e
+}
+
+// mul computes the POLYVAL dot product a * b * x^-128.
+func mul(a, b element) element {
+	loProd := clMul64(a.lo, b.lo)
+	hiProd := clMul64(a.hi, b.hi)
+	mid := clMul64(a.lo^a.hi, b.lo^b.hi)
+	mid.lo ^= loProd.lo ^ hiProd.lo
+	mid.hi ^= loProd.hi ^ hiProd.hi
+	hiProd.lo ^= mid.hi
+	loProd.hi ^= mid.lo
+	loProd.hi ^= (loProd.lo << 63) ^ (loProd.lo << 62) ^ (loProd.lo << 57)
+	hiProd.lo ^= loProd.lo
+	hiProd.hi ^= loProd.hi
+	hiProd.lo ^= loProd.lo >> 1
+	hiProd.lo ^= loProd.hi << 63
+	hiProd.hi ^= loProd.hi >> 1
+	hiProd.lo ^= loProd.lo >> 2
+	hiProd.lo ^= loProd.hi << 62
+	hiProd.hi ^= loProd.hi >> 2
+	hiProd.lo ^= loProd.lo >> 7
+	hiProd.lo ^= loProd.hi << 57
+	hiProd.hi ^= loProd.hi >> 7
+	return hiProd
+}
+
+func clMul64(x, y uint64) element {
+	x0 := uint32(x)
+	x1 := uint32(x >> 32)
+	y0 := uint32(y)
+	y1 := uint32(y >> 32)
+	p0 := clMul32(x0, y0)
+	p1 := clMul32(x1, y1)
+	pMid := clMul32(x0^x1, y0^y1) ^ p0 ^ p1
+	return element{
+		lo: p0 ^ (pMid << 32),
+		hi: p1 ^ (pMid >> 32),
+	}
+}
+
+// clMul32 computes the carry-less product of x and y without branching on
+// either operand.
+func clMul32(x, y uint32) uint64 {
+	var result uint64
+	base := uint64(x)
+	for i := 0; i < 32; i++ {
+		mask := -(uint64(y>>i) & 1)
+		result ^= (base << i) & mask
+	}
+	return result
+}
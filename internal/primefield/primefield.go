@@ -0,0 +1,408 @@
+// Package primefield implements constant-time arithmetic modulo the odd
+// primes that hash2curve maps into: the P-256, P-384 and P-521 base fields,
+// GF(2^255 - 19) and GF(2^448 - 2^224 - 1).
+//
+// The ecdh package does not own field arithmetic for most of these curves:
+// P-256, P-384, P-521 and X25519 are wrappers around crypto/ecdh, which
+// keeps its field elements unexported, so RFC 9380's maps need a field layer
+// of their own. It uses Montgomery multiplication over 64-bit limbs for any
+// prime with p = 3 mod 4 or p = 5 mod 8. GF(2^448 - 2^224 - 1) is the
+// exception: NewP448 runs the same API on the internal/x448 arithmetic that
+// backs X448.
+//
+// Exponents (inversion, square roots, quadratic residuosity) are public, so
+// only the element values are protected against timing leaks.
+package primefield
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+
+	"github.com/AeonDave/cryptonite-go/internal/x448"
+)
+
+// maxLimbs is the number of 64-bit limbs of the largest supported field,
+// GF(2^521 - 1).
+const maxLimbs = 9
+
+// Element is a field element as little-endian 64-bit limbs, in the
+// representation of the Field it belongs to. Only the limbs needed for the
+// modulus are used and they always hold a value below p, so equal elements
+// have equal limbs.
+type Element [maxLimbs]uint64
+
+// arith is the representation-specific part of a Field. Every result is
+// reduced below p.
+type arith interface {
+	mul(z, x, y *Element)
+	add(z, x, y *Element)
+	sub(z, x, y *Element)
+	inv(z, x *Element)
+	// encode converts a canonical value below p into the representation
+	// and decode converts back.
+	encode(z, x *Element)
+	decode(z, x *Element)
+}
+
+// Field is a prime field. It is safe for concurrent use.
+type Field struct {
+	a    arith
+	n    int
+	size int
+	one  Element
+	// word is 2^64 in the field's representation, used by Reduce.
+	word Element
+	// sqrtM1 is a square root of -1, set when p = 5 mod 8.
+	sqrtM1 Element
+
+	modulus  *big.Int
+	legendre *big.Int
+	sqrtExp  *big.Int
+}
+
+// New returns the field of integers modulo p, an odd prime below 2^576 with
+// p = 3 mod 4 or p = 5 mod 8. It panics for other moduli.
+func New(p *big.Int) *Field {
+	n := (p.BitLen() + 63) / 64
+	m := &montgomery{p: limbs(p), n: n}
+	inv := uint64(1)
+	for i := 0; i < 6; i++ {
+		inv *= 2 - m.p[0]*inv
+	}
+	m.pInv = -inv
+	r := new(big.Int).Lsh(big.NewInt(1), uint(64*n))
+	m.one = limbs(new(big.Int).Mod(r, p))
+	m.r2 = limbs(new(big.Int).Mod(new(big.Int).Mul(r, r), p))
+	m.invExp = new(big.Int).Sub(p, big.NewInt(2))
+	return newField(p, m)
+}
+
+// NewP448 returns GF(2^448 - 2^224 - 1) backed by the internal/x448
+// arithmetic.
+func NewP448() *Field {
+	p := new(big.Int).Lsh(big.NewInt(1), 448)
+	p.Sub(p, new(big.Int).Lsh(big.NewInt(1), 224))
+	p.Sub(p, big.NewInt(1))
+	return newField(p, p448{})
+}
+
+func newField(p *big.Int, a arith) *Field {
+	f := &Field{
+		a:       a,
+		n:       (p.BitLen() + 63) / 64,
+		size:    (p.BitLen() + 7) / 8,
+		modulus: p,
+	}
+	f.one = f.FromBig(big.NewInt(1))
+	f.word = f.FromBig(new(big.Int).Lsh(big.NewInt(1), 64))
+	f.legendre = new(big.Int).Rsh(p, 1)
+	switch p.Bits()[0] & 7 {
+	case 3, 7:
+		// sqrt(x) = x^((p+1)/4)
+		f.sqrtExp = new(big.Int).Rsh(new(big.Int).Add(p, big.NewInt(1)), 2)
+	case 5:
+		// sqrt(x) = x^((p+3)/8), times sqrt(-1) when that squares to -x.
+		f.sqrtExp = new(big.Int).Rsh(new(big.Int).Add(p, big.NewInt(3)), 3)
+		e := new(big.Int).Rsh(new(big.Int).Sub(p, big.NewInt(1)), 2)
+		f.sqrtM1 = f.FromBig(new(big.Int).Exp(big.NewInt(2), e, p))
+	default:
+		panic("primefield: unsupported modulus")
+	}
+	return f
+}
+
+// limbs returns the little-endian limbs of a non-negative x < 2^(64*maxLimbs).
+func limbs(x *big.Int) Element {
+	var z Element
+	b := x.FillBytes(make([]byte, 8*maxLimbs))
+	for i := range z {
+		z[i] = binary.BigEndian.Uint64(b[len(b)-8*(i+1):])
+	}
+	return z
+}
+
+// Size returns the length in bytes of an encoded element.
+func (f *Field) Size() int { return f.size }
+
+// BitLen returns the bit length of the modulus.
+func (f *Field) BitLen() int { return f.modulus.BitLen() }
+
+// One returns the multiplicative identity, which must not be modified.
+func (f *Field) One() *Element { return &f.one }
+
+// FromBig returns x mod p. It is meant for public constants only.
+func (f *Field) FromBig(x *big.Int) Element {
+	v := limbs(new(big.Int).Mod(x, f.modulus))
+	var z Element
+	f.a.encode(&z, &v)
+	return z
+}
+
+// Mul sets z = x*y.
+func (f *Field) Mul(z, x, y *Element) { f.a.mul(z, x, y) }
+
+// Sqr sets z = x^2.
+func (f *Field) Sqr(z, x *Element) { f.a.mul(z, x, x) }
+
+// Add sets z = x+y.
+func (f *Field) Add(z, x, y *Element) { f.a.add(z, x, y) }
+
+// Sub sets z = x-y.
+func (f *Field) Sub(z, x, y *Element) { f.a.sub(z, x, y) }
+
+// Neg sets z = -x.
+func (f *Field) Neg(z, x *Element) {
+	var zero Element
+	f.a.sub(z, &zero, x)
+}
+
+// exp sets z = x^e for a public exponent e.
+func (f *Field) exp(z, x *Element, e *big.Int) {
+	r, b := f.one, *x
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		f.a.mul(&r, &r, &r)
+		if e.Bit(i) == 1 {
+			f.a.mul(&r, &r, &b)
+		}
+	}
+	*z = r
+}
+
+// Inv sets z = 1/x, or 0 if x is 0 (inv0 in RFC 9380).
+func (f *Field) Inv(z, x *Element) { f.a.inv(z, x) }
+
+// IsSquare returns 1 if x is a square (including 0) and 0 otherwise.
+func (f *Field) IsSquare(x *Element) uint64 {
+	var t Element
+	f.exp(&t, x, f.legendre)
+	return f.Equal(&t, &f.one) | f.IsZero(&t)
+}
+
+// Sqrt sets z to a square root of x, which must be a square.
+func (f *Field) Sqrt(z, x *Element) {
+	var r Element
+	f.exp(&r, x, f.sqrtExp)
+	if f.modulus.Bits()[0]&7 == 5 {
+		var r2, alt Element
+		f.a.mul(&r2, &r, &r)
+		f.a.mul(&alt, &r, &f.sqrtM1)
+		f.CMov(&r, &alt, 1^f.Equal(&r2, x))
+	}
+	*z = r
+}
+
+// CMov sets z = x if c is 1 and leaves it unchanged if c is 0.
+func (f *Field) CMov(z, x *Element, c uint64) {
+	mask := -c
+	for j := 0; j < f.n; j++ {
+		z[j] = z[j]&^mask | x[j]&mask
+	}
+}
+
+// IsZero returns 1 if x is 0 and 0 otherwise.
+func (f *Field) IsZero(x *Element) uint64 {
+	var acc uint64
+	for j := 0; j < f.n; j++ {
+		acc |= x[j]
+	}
+	return 1 ^ (acc|-acc)>>63
+}
+
+// Equal returns 1 if x and y are equal and 0 otherwise.
+func (f *Field) Equal(x, y *Element) uint64 {
+	var acc uint64
+	for j := 0; j < f.n; j++ {
+		acc |= x[j] ^ y[j]
+	}
+	return 1 ^ (acc|-acc)>>63
+}
+
+// Sgn0 returns the parity of x (RFC 9380, Section 4.1, m = 1).
+func (f *Field) Sgn0(x *Element) uint64 {
+	var z Element
+	f.a.decode(&z, x)
+	return z[0] & 1
+}
+
+// Reduce sets z = OS2IP(b) mod p in constant time, processing b as
+// big-endian 64-bit words with Horner's rule.
+func (f *Field) Reduce(z *Element, b []byte) {
+	buf := make([]byte, (len(b)+7)/8*8)
+	copy(buf[len(buf)-len(b):], b)
+	var acc Element
+	for i := 0; i < len(buf); i += 8 {
+		var v, w Element
+		v[0] = binary.BigEndian.Uint64(buf[i:])
+		f.a.encode(&w, &v)
+		f.a.mul(&acc, &acc, &f.word)
+		f.a.add(&acc, &acc, &w)
+	}
+	*z = acc
+}
+
+// Bytes returns the big-endian encoding of x in Size bytes.
+func (f *Field) Bytes(x *Element) []byte {
+	var z Element
+	f.a.decode(&z, x)
+	buf := make([]byte, 8*f.n)
+	for j := 0; j < f.n; j++ {
+		binary.BigEndian.PutUint64(buf[len(buf)-8*(j+1):], z[j])
+	}
+	return buf[len(buf)-f.size:]
+}
+
+// BytesLE returns the little-endian encoding of x in Size bytes.
+func (f *Field) BytesLE(x *Element) []byte {
+	b := f.Bytes(x)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
+
+// montgomery keeps elements in Montgomery form x*R mod p with R = 2^(64n).
+type montgomery struct {
+	p, one, r2 Element
+	n          int
+	// pInv is -p^-1 mod 2^64.
+	pInv   uint64
+	invExp *big.Int
+}
+
+// mul sets z = x*y/R mod p, that is the Montgomery product (CIOS).
+func (m *montgomery) mul(z, x, y *Element) {
+	var t [maxLimbs + 2]uint64
+	n := m.n
+	for i := 0; i < n; i++ {
+		var c, cc uint64
+		for j := 0; j < n; j++ {
+			hi, lo := bits.Mul64(x[j], y[i])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j], c = lo, hi
+		}
+		t[n], cc = bits.Add64(t[n], c, 0)
+		t[n+1] = cc
+
+		k := t[0] * m.pInv
+		hi, lo := bits.Mul64(k, m.p[0])
+		_, cc = bits.Add64(lo, t[0], 0)
+		c = hi + cc
+		for j := 1; j < n; j++ {
+			hi, lo = bits.Mul64(k, m.p[j])
+			lo, cc = bits.Add64(lo, t[j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[j-1], c = lo, hi
+		}
+		t[n-1], cc = bits.Add64(t[n], c, 0)
+		t[n] = t[n+1] + cc
+	}
+	var s Element
+	var b uint64
+	for j := 0; j < n; j++ {
+		s[j], b = bits.Sub64(t[j], m.p[j], b)
+	}
+	_, b = bits.Sub64(t[n], 0, b)
+	mask := -b
+	for j := 0; j < n; j++ {
+		z[j] = t[j]&mask | s[j]&^mask
+	}
+}
+
+func (m *montgomery) add(z, x, y *Element) {
+	var t, s Element
+	var c, b uint64
+	for j := 0; j < m.n; j++ {
+		t[j], c = bits.Add64(x[j], y[j], c)
+	}
+	for j := 0; j < m.n; j++ {
+		s[j], b = bits.Sub64(t[j], m.p[j], b)
+	}
+	_, b = bits.Sub64(c, 0, b)
+	mask := -b
+	for j := 0; j < m.n; j++ {
+		z[j] = t[j]&mask | s[j]&^mask
+	}
+}
+
+func (m *montgomery) sub(z, x, y *Element) {
+	var t Element
+	var b, c uint64
+	for j := 0; j < m.n; j++ {
+		t[j], b = bits.Sub64(x[j], y[j], b)
+	}
+	mask := -b
+	for j := 0; j < m.n; j++ {
+		z[j], c = bits.Add64(t[j], m.p[j]&mask, c)
+	}
+}
+
+// inv raises x to p-2 with a public square-and-multiply ladder.
+func (m *montgomery) inv(z, x *Element) {
+	r, b := m.one, *x
+	for i := m.invExp.BitLen() - 1; i >= 0; i-- {
+		m.mul(&r, &r, &r)
+		if m.invExp.Bit(i) == 1 {
+			m.mul(&r, &r, &b)
+		}
+	}
+	*z = r
+}
+
+func (m *montgomery) encode(z, x *Element) { m.mul(z, x, &m.r2) }
+
+func (m *montgomery) decode(z, x *Element) {
+	var one Element
+	one[0] = 1
+	m.mul(z, x, &one)
+}
+
+// p448 holds canonical values and runs the internal/x448 arithmetic on
+// their 56-byte little-endian encoding.
+type p448 struct{}
+
+func toX448(x *Element) *[x448.Size]byte {
+	var b [x448.Size]byte
+	for i := 0; i < x448.Size/8; i++ {
+		binary.LittleEndian.PutUint64(b[8*i:], x[i])
+	}
+	return &b
+}
+
+func fromX448(z *Element, b *[x448.Size]byte) {
+	for i := 0; i < x448.Size/8; i++ {
+		z[i] = binary.LittleEndian.Uint64(b[8*i:])
+	}
+}
+
+func (p448) mul(z, x, y *Element) {
+	var r [x448.Size]byte
+	x448.Mul(&r, toX448(x), toX448(y))
+	fromX448(z, &r)
+}
+
+func (p448) add(z, x, y *Element) {
+	var r [x448.Size]byte
+	x448.Add(&r, toX448(x), toX448(y))
+	fromX448(z, &r)
+}
+
+func (p448) sub(z, x, y *Element) {
+	var r [x448.Size]byte
+	x448.Sub(&r, toX448(x), toX448(y))
+	fromX448(z, &r)
+}
+
+func (p448) inv(z, x *Element) {
+	var r [x448.Size]byte
+	x448.Inv(&r, toX448(x))
+	fromX448(z, &r)
+}
+
+func (p448) encode(z, x *Element) { *z = *x }
+func (p448) decode(z, x *Element) { *z = *x }
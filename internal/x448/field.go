@@ -443,3 +443,32 @@ func fromBytes(out *fieldElement, in []byte) {
 	copy(out[:], in[:Size])
 	modp(out)
 }
+
+// Mul, Add, Sub and Inv expose the field arithmetic to other packages that
+// work over GF(2^448 - 2^224 - 1), such as the RFC 9380 maps in hash2curve.
+// Operands are little-endian values below p and results are reduced below p,
+// so equal elements have equal encodings. Inv maps 0 to 0.
+
+// Mul sets z = x*y mod p.
+func Mul(z, x, y *[Size]byte) {
+	mul((*fieldElement)(z), (*fieldElement)(x), (*fieldElement)(y))
+	modp((*fieldElement)(z))
+}
+
+// Add sets z = x+y mod p.
+func Add(z, x, y *[Size]byte) {
+	add((*fieldElement)(z), (*fieldElement)(x), (*fieldElement)(y))
+	modp((*fieldElement)(z))
+}
+
+// Sub sets z = x-y mod p.
+func Sub(z, x, y *[Size]byte) {
+	sub((*fieldElement)(z), (*fieldElement)(x), (*fieldElement)(y))
+	modp((*fieldElement)(z))
+}
+
+// Inv sets z = 1/x mod p, or 0 if x is 0.
+func Inv(z, x *[Size]byte) {
+	inv((*fieldElement)(z), (*fieldElement)(x))
+	modp((*fieldElement)(z))
+}
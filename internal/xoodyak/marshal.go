@@ -0,0 +1,27 @@
+package xoodyak
+
+import "github.com/AeonDave/cryptonite-go/internal/marshal"
+
+// Encode appends the Cyclist mode, phase, rates and Xoodoo state to e.
+func (x *Instance) Encode(e *marshal.Encoder) {
+	e.Uint64(uint64(x.mode))
+	e.Uint32(uint32(x.phase))
+	e.Uint32(uint32(x.rAbsorb))
+	e.Uint32(uint32(x.rSqueeze))
+	e.Uint32s(x.state.lanes[:])
+}
+
+// Decode restores a state written by Encode into x, which must be in the
+// same Cyclist mode.
+func (x *Instance) Decode(d *marshal.Decoder) {
+	d.Expect(uint64(x.mode))
+	phase := d.Uint32()
+	rAbsorb := d.Uint32()
+	rSqueeze := d.Uint32()
+	d.Uint32s(x.state.lanes[:])
+	d.Check((phase == phaseUp || phase == phaseDown) &&
+		rAbsorb > 0 && rAbsorb <= KeyRate && rSqueeze > 0 && rSqueeze <= KeyedSqueezeRate, marshal.ErrInvalid)
+	x.phase = int(phase)
+	x.rAbsorb = int(rAbsorb)
+	x.rSqueeze = int(rSqueeze)
+}
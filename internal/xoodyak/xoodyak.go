@@ -405,6 +405,28 @@ func (x *Instance) Crypt(in, out []byte, decrypt bool) {
 	}
 }
 
+// SqueezeKey squeezes key material with the Cyclist key-derivation
+// domain separator (0x20).
+func (x *Instance) SqueezeKey(dst []byte) {
+	x.SqueezeAny(dst, 0x20)
+}
+
+// Ratchet squeezes LRatchet bytes with the ratchet domain separator (0x10)
+// and absorbs them back, making earlier states unrecoverable.
+func (x *Instance) Ratchet() {
+	var buf [LRatchet]byte
+	x.SqueezeAny(buf[:], 0x10)
+	x.absorbAny(buf[:], x.rAbsorb, 0x00)
+	for i := range buf {
+		buf[i] = 0
+	}
+}
+
+// Keyed reports whether the instance runs in keyed mode.
+func (x *Instance) Keyed() bool {
+	return x.mode == modeKeyed
+}
+
 // Advance executes a zero-length Down step, preparing for additional squeezing.
 func (x *Instance) Advance() {
 	x.down(nil, 0, 0)
@@ -1,33 +1,65 @@
 package kdf
 
 // The implementation in this file is adapted from Go's golang.org/x/crypto/argon2
-// package (BSD-style license). It has been pared down to the Argon2id variant
-// and rewritten to avoid external dependencies while retaining RFC 9106
-// compliance.
+// package (BSD-style license). It has been rewritten to avoid external
+// dependencies while retaining RFC 9106 compliance.
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"hash"
 	"math"
+	"sync"
 
 	"github.com/AeonDave/cryptonite-go/internal/blake2b"
 )
 
 const (
-	argon2idVersion    = 0x13
+	argon2Version      = 0x13
 	argon2SyncPoints   = 4
 	argon2BlockLength  = 128 // block length in uint64 words (1 KiB)
 	argon2MinSaltBytes = 8
 
+	argon2ModeD  = 0
+	argon2ModeI  = 1
 	argon2ModeID = 2
 )
 
-// Argon2idDeriver implements the Deriver interface for Argon2id.
+// Argon2idDeriver implements the Deriver interface for Argon2id, the hybrid
+// variant RFC 9106 recommends for password hashing.
 type Argon2idDeriver struct {
 	MemoryKiB uint32 // total memory in KiB (default 64 MiB)
 	Time      uint32 // number of passes (default 1)
-	Threads   uint32 // lanes/parallelism (default 1)
+	Threads   uint32 // lanes/parallelism (default 1), processed concurrently
+	// Key is the optional RFC 9106 secret value K (a pepper kept apart from
+	// the stored hashes).
+	Key []byte
+	// AssociatedData is the optional RFC 9106 associated data X.
+	AssociatedData []byte
+}
+
+// Argon2iDeriver implements the Deriver interface for Argon2i, which uses
+// data-independent memory access throughout and resists cache-timing side
+// channels. Fields match Argon2idDeriver.
+type Argon2iDeriver struct {
+	MemoryKiB      uint32
+	Time           uint32
+	Threads        uint32
+	Key            []byte
+	AssociatedData []byte
+}
+
+// Argon2dDeriver implements the Deriver interface for Argon2d, which uses
+// data-dependent memory access. It offers the most resistance to GPU
+// cracking but must not be used where an attacker can observe cache timing.
+// Fields match Argon2idDeriver.
+type Argon2dDeriver struct {
+	MemoryKiB      uint32
+	Time           uint32
+	Threads        uint32
+	Key            []byte
+	AssociatedData []byte
 }
 
 // NewArgon2id returns a Deriver configured with RFC 9106's interactive defaults
@@ -50,14 +82,45 @@ func NewArgon2idWithParams(time, memoryKiB, threads uint32) Deriver {
 	}
 }
 
-// Argon2id is a single-shot helper mirroring Argon2idDeriver.
-func Argon2id(secret, salt []byte, time, memoryKiB, threads uint32, length int) ([]byte, error) {
-	d := Argon2idDeriver{
+// NewArgon2iWithParams returns an Argon2i Deriver with the given cost factors.
+func NewArgon2iWithParams(time, memoryKiB, threads uint32) Deriver {
+	return Argon2iDeriver{
 		MemoryKiB: memoryKiB,
 		Time:      time,
 		Threads:   threads,
 	}
-	return d.Derive(DeriveParams{
+}
+
+// NewArgon2dWithParams returns an Argon2d Deriver with the given cost factors.
+func NewArgon2dWithParams(time, memoryKiB, threads uint32) Deriver {
+	return Argon2dDeriver{
+		MemoryKiB: memoryKiB,
+		Time:      time,
+		Threads:   threads,
+	}
+}
+
+// Argon2id is a single-shot helper mirroring Argon2idDeriver.
+func Argon2id(secret, salt []byte, time, memoryKiB, threads uint32, length int) ([]byte, error) {
+	return Argon2idDeriver{MemoryKiB: memoryKiB, Time: time, Threads: threads}.Derive(DeriveParams{
+		Secret: secret,
+		Salt:   salt,
+		Length: length,
+	})
+}
+
+// Argon2i is a single-shot helper mirroring Argon2iDeriver.
+func Argon2i(secret, salt []byte, time, memoryKiB, threads uint32, length int) ([]byte, error) {
+	return Argon2iDeriver{MemoryKiB: memoryKiB, Time: time, Threads: threads}.Derive(DeriveParams{
+		Secret: secret,
+		Salt:   salt,
+		Length: length,
+	})
+}
+
+// Argon2d is a single-shot helper mirroring Argon2dDeriver.
+func Argon2d(secret, salt []byte, time, memoryKiB, threads uint32, length int) ([]byte, error) {
+	return Argon2dDeriver{MemoryKiB: memoryKiB, Time: time, Threads: threads}.Derive(DeriveParams{
 		Secret: secret,
 		Salt:   salt,
 		Length: length,
@@ -66,67 +129,94 @@ func Argon2id(secret, salt []byte, time, memoryKiB, threads uint32, length int)
 
 // Derive implements Deriver for Argon2id.
 func (a Argon2idDeriver) Derive(params DeriveParams) ([]byte, error) {
+	return a.DeriveContext(context.Background(), params)
+}
+
+// DeriveContext implements ContextDeriver for Argon2id.
+func (a Argon2idDeriver) DeriveContext(ctx context.Context, params DeriveParams) ([]byte, error) {
+	return argon2Derive(ctx, argon2ModeID, a.Time, a.MemoryKiB, a.Threads, a.Key, a.AssociatedData, params)
+}
+
+// Derive implements Deriver for Argon2i.
+func (a Argon2iDeriver) Derive(params DeriveParams) ([]byte, error) {
+	return a.DeriveContext(context.Background(), params)
+}
+
+// DeriveContext implements ContextDeriver for Argon2i.
+func (a Argon2iDeriver) DeriveContext(ctx context.Context, params DeriveParams) ([]byte, error) {
+	return argon2Derive(ctx, argon2ModeI, a.Time, a.MemoryKiB, a.Threads, a.Key, a.AssociatedData, params)
+}
+
+// Derive implements Deriver for Argon2d.
+func (a Argon2dDeriver) Derive(params DeriveParams) ([]byte, error) {
+	return a.DeriveContext(context.Background(), params)
+}
+
+// DeriveContext implements ContextDeriver for Argon2d.
+func (a Argon2dDeriver) DeriveContext(ctx context.Context, params DeriveParams) ([]byte, error) {
+	return argon2Derive(ctx, argon2ModeD, a.Time, a.MemoryKiB, a.Threads, a.Key, a.AssociatedData, params)
+}
+
+func argon2Derive(ctx context.Context, mode int, time, memory, threads uint32, key, data []byte, params DeriveParams) ([]byte, error) {
 	if len(params.Secret) == 0 {
-		return nil, errors.New("kdf: argon2id secret must be non-empty")
+		return nil, errors.New("kdf: argon2 secret must be non-empty")
 	}
 	if len(params.Salt) < argon2MinSaltBytes {
-		return nil, errors.New("kdf: argon2id salt must be at least 8 bytes")
+		return nil, errors.New("kdf: argon2 salt must be at least 8 bytes")
 	}
 	if params.Length <= 0 {
-		return nil, errors.New("kdf: argon2id output length must be positive")
+		return nil, errors.New("kdf: argon2 output length must be positive")
 	}
 	if params.Length > int(math.MaxUint32) {
-		return nil, errors.New("kdf: argon2id output length too large")
+		return nil, errors.New("kdf: argon2 output length too large")
 	}
 
-	time := a.Time
 	if time == 0 {
 		time = 1
 	}
-	memory := a.MemoryKiB
 	if memory == 0 {
 		memory = 64 * 1024
 	}
-	threads := a.Threads
 	if threads == 0 {
 		threads = 1
 	}
 
-	return argon2idKey(params.Secret, params.Salt, time, memory, threads, uint32(params.Length))
+	return argon2Key(ctx, mode, params.Secret, params.Salt, key, data, time, memory, threads, uint32(params.Length))
 }
 
 type argon2Block [argon2BlockLength]uint64
 
-func argon2idKey(password, salt []byte, time, memoryKiB, threads, keyLen uint32) ([]byte, error) {
+func argon2Key(ctx context.Context, mode int, password, salt, key, data []byte, time, memoryKiB, threads, keyLen uint32) ([]byte, error) {
 	if keyLen == 0 {
-		return nil, errors.New("kdf: argon2id output length must be positive")
+		return nil, errors.New("kdf: argon2 output length must be positive")
 	}
-	if len(password) > math.MaxUint32 {
-		return nil, errors.New("kdf: argon2id password too long")
+	if uint64(len(password)) > math.MaxUint32 || uint64(len(salt)) > math.MaxUint32 ||
+		uint64(len(key)) > math.MaxUint32 || uint64(len(data)) > math.MaxUint32 {
+		return nil, errors.New("kdf: argon2 input too long")
 	}
-	if len(salt) > math.MaxUint32 {
-		return nil, errors.New("kdf: argon2id salt too long")
-	}
-	if threads == 0 {
-		return nil, errors.New("kdf: argon2id requires at least one lane")
+	if threads == 0 || threads > 1<<24-1 {
+		return nil, errors.New("kdf: argon2 requires between 1 and 2^24-1 lanes")
 	}
 	if time == 0 {
-		return nil, errors.New("kdf: argon2id requires at least one iteration")
+		return nil, errors.New("kdf: argon2 requires at least one iteration")
 	}
 
+	// H0 binds the requested memory size; the working memory is rounded down
+	// to a multiple of lanes*syncPoints (at least two blocks per segment).
+	h0 := initArgon2Hash(password, salt, key, data, time, memoryKiB, threads, keyLen, mode)
 	unit := argon2SyncPoints * threads
-	if memoryKiB < 2*unit {
-		memoryKiB = 2 * unit
-	}
-	// Round memory down to a multiple of lanes*syncPoints to guarantee full segments.
 	memoryKiB = (memoryKiB / unit) * unit
 	if memoryKiB < 2*unit {
 		memoryKiB = 2 * unit
 	}
 
-	h0 := initArgon2Hash(password, salt, nil, nil, time, memoryKiB, threads, keyLen, argon2ModeID)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	blocks := initArgon2Blocks(&h0, memoryKiB, threads)
-	processArgon2Blocks(blocks, time, memoryKiB, threads, argon2ModeID)
+	if err := processArgon2Blocks(ctx, blocks, time, memoryKiB, threads, mode); err != nil {
+		return nil, err
+	}
 	return extractArgon2Key(blocks, memoryKiB, threads, keyLen), nil
 }
 
@@ -139,13 +229,13 @@ func initArgon2Hash(password, salt, key, data []byte, time, memoryKiB, threads,
 
 	digest, err := blake2b.New512(nil)
 	if err != nil {
-		panic("argon2id: failed to initialise blake2b-512")
+		panic("argon2: failed to initialise blake2b-512")
 	}
 	binary.LittleEndian.PutUint32(params[0:4], threads)
 	binary.LittleEndian.PutUint32(params[4:8], keyLen)
 	binary.LittleEndian.PutUint32(params[8:12], memoryKiB)
 	binary.LittleEndian.PutUint32(params[12:16], time)
-	binary.LittleEndian.PutUint32(params[16:20], argon2idVersion)
+	binary.LittleEndian.PutUint32(params[16:20], argon2Version)
 	binary.LittleEndian.PutUint32(params[20:24], uint32(mode))
 	digest.Write(params[:])
 
@@ -193,17 +283,35 @@ func initArgon2Blocks(h0 *[blake2b.Size + 8]byte, memoryKiB, threads uint32) []a
 	return B
 }
 
-func processArgon2Blocks(B []argon2Block, time, memoryKiB, threads uint32, mode int) {
+func processArgon2Blocks(ctx context.Context, B []argon2Block, time, memoryKiB, threads uint32, mode int) error {
 	lanes := memoryKiB / threads
 	segmentLength := lanes / argon2SyncPoints
 
+	// Segments of one slice only reference blocks outside that slice or in
+	// their own lane, so the lanes of a slice are processed concurrently and
+	// synchronise at each of the four sync points, where cancellation is
+	// checked.
 	for pass := uint32(0); pass < time; pass++ {
 		for slice := uint32(0); slice < argon2SyncPoints; slice++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if threads == 1 {
+				processArgon2Segment(B, pass, slice, 0, time, memoryKiB, threads, mode, lanes, segmentLength)
+				continue
+			}
+			var wg sync.WaitGroup
 			for lane := uint32(0); lane < threads; lane++ {
-				processArgon2Segment(B, pass, slice, lane, time, memoryKiB, threads, mode, lanes, segmentLength)
+				wg.Add(1)
+				go func(lane uint32) {
+					defer wg.Done()
+					processArgon2Segment(B, pass, slice, lane, time, memoryKiB, threads, mode, lanes, segmentLength)
+				}(lane)
 			}
+			wg.Wait()
 		}
 	}
+	return nil
 }
 
 func processArgon2Segment(B []argon2Block, pass, slice, lane, time, memoryKiB, threads uint32, mode int, lanes, segmentLength uint32) {
@@ -213,7 +321,8 @@ func processArgon2Segment(B []argon2Block, pass, slice, lane, time, memoryKiB, t
 		zero      argon2Block
 	)
 
-	if mode == argon2ModeID && pass == 0 && slice < argon2SyncPoints/2 {
+	dataIndependent := mode == argon2ModeI || (mode == argon2ModeID && pass == 0 && slice < argon2SyncPoints/2)
+	if dataIndependent {
 		input[0] = uint64(pass)
 		input[1] = uint64(lane)
 		input[2] = uint64(slice)
@@ -225,7 +334,7 @@ func processArgon2Segment(B []argon2Block, pass, slice, lane, time, memoryKiB, t
 	index := uint32(0)
 	if pass == 0 && slice == 0 {
 		index = 2 // first two blocks already generated
-		if mode == argon2ModeID {
+		if dataIndependent {
 			input[6]++
 			processBlock(&addresses, &input, &zero)
 			processBlock(&addresses, &addresses, &zero)
@@ -240,7 +349,7 @@ func processArgon2Segment(B []argon2Block, pass, slice, lane, time, memoryKiB, t
 		}
 
 		var random uint64
-		if mode == argon2ModeID && pass == 0 && slice < argon2SyncPoints/2 {
+		if dataIndependent {
 			if index%argon2BlockLength == 0 {
 				input[6]++
 				processBlock(&addresses, &input, &zero)
@@ -480,7 +589,7 @@ func blake2bHash(out, in []byte) {
 		d, err = blake2b.New512(nil)
 	}
 	if err != nil {
-		panic("argon2id: blake2b init failed")
+		panic("argon2: blake2b init failed")
 	}
 
 	var sizeBuf [4]byte
@@ -509,7 +618,7 @@ func blake2bHash(out, in []byte) {
 	if len(out) > 0 {
 		r, err := blake2b.New(len(out), nil)
 		if err != nil {
-			panic("argon2id: blake2b init failed")
+			panic("argon2: blake2b init failed")
 		}
 		r.Write(buffer[:])
 		r.Sum(out[:0])
@@ -0,0 +1,178 @@
+package kdf
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/AeonDave/cryptonite-go/internal/blowfish"
+)
+
+const (
+	// BcryptMinCost and BcryptMaxCost bound the log2 work factor.
+	BcryptMinCost = 4
+	BcryptMaxCost = 31
+	// BcryptDefaultCost is the work factor used when cost is zero.
+	BcryptDefaultCost = 10
+	// BcryptMaxPasswordLength is the number of password bytes bcrypt
+	// consumes. Longer passwords are rejected rather than silently truncated.
+	BcryptMaxPasswordLength = 72
+	// BcryptSaltSize is the size of a bcrypt salt in bytes.
+	BcryptSaltSize = 16
+	// BcryptHashSize is the size of the raw bcrypt output in bytes.
+	BcryptHashSize = 23
+
+	bcryptEncodedLength = 60
+)
+
+var (
+	// ErrBcryptPasswordTooLong is returned for passwords longer than
+	// BcryptMaxPasswordLength bytes.
+	ErrBcryptPasswordTooLong = errors.New("kdf: bcrypt password longer than 72 bytes")
+	// ErrBcryptMismatch is returned by BcryptVerify when the password does
+	// not match the hash.
+	ErrBcryptMismatch = errors.New("kdf: bcrypt hash does not match password")
+
+	errBcryptCost    = errors.New("kdf: bcrypt cost out of range")
+	errBcryptSalt    = errors.New("kdf: bcrypt salt must be 16 bytes")
+	errBcryptFormat  = errors.New("kdf: malformed bcrypt hash")
+	errBcryptVersion = errors.New("kdf: unsupported bcrypt version")
+)
+
+// bcryptEncoding is bcrypt's radix-64 alphabet, which orders "./" first and
+// does not pad.
+var bcryptEncoding = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").WithPadding(base64.NoPadding)
+
+// bcryptMagic is the 24-byte plaintext encrypted 64 times under the
+// EksBlowfish state.
+var bcryptMagic = []byte("OrpheanBeholderScryDoubt")
+
+// BcryptHash is a decoded modular-crypt-format bcrypt string of the form
+// $2b$<cost>$<22-char salt><31-char hash>.
+type BcryptHash struct {
+	Version string // "2a", "2b" or "2y"; all three compute the same hash
+	Cost    int
+	Salt    [BcryptSaltSize]byte
+	Hash    [BcryptHashSize]byte
+}
+
+// Bcrypt hashes password with a random salt at the given cost (zero selects
+// BcryptDefaultCost) and returns the $2b$ modular-crypt string.
+func Bcrypt(password []byte, cost int) (string, error) {
+	if cost == 0 {
+		cost = BcryptDefaultCost
+	}
+	h := BcryptHash{Version: "2b", Cost: cost}
+	if _, err := io.ReadFull(rand.Reader, h.Salt[:]); err != nil {
+		return "", err
+	}
+	key, err := BcryptKey(password, h.Salt[:], cost)
+	if err != nil {
+		return "", err
+	}
+	copy(h.Hash[:], key)
+	return h.String(), nil
+}
+
+// BcryptKey runs EksBlowfish over password and the 16-byte salt and returns
+// the raw 23-byte bcrypt output. The password is used with its trailing NUL
+// byte, as in the $2a$/$2b$/$2y$ variants.
+func BcryptKey(password, salt []byte, cost int) ([]byte, error) {
+	if cost < BcryptMinCost || cost > BcryptMaxCost {
+		return nil, errBcryptCost
+	}
+	if len(salt) != BcryptSaltSize {
+		return nil, errBcryptSalt
+	}
+	if len(password) > BcryptMaxPasswordLength {
+		return nil, ErrBcryptPasswordTooLong
+	}
+
+	key := make([]byte, len(password)+1)
+	copy(key, password)
+	c := blowfish.NewState()
+	c.ExpandKeyWithSalt(key, salt)
+	for i := uint64(0); i < 1<<uint(cost); i++ {
+		c.ExpandKey(key)
+		c.ExpandKey(salt)
+	}
+
+	out := make([]byte, len(bcryptMagic))
+	copy(out, bcryptMagic)
+	for i := 0; i < 64; i++ {
+		for j := 0; j < len(out); j += blowfish.BlockSize {
+			c.Encrypt(out[j:j+blowfish.BlockSize], out[j:j+blowfish.BlockSize])
+		}
+	}
+	c.Clear()
+	clear(key)
+	return out[:BcryptHashSize], nil
+}
+
+// BcryptVerify checks password against a $2a$, $2b$ or $2y$ string in
+// constant time. It returns ErrBcryptMismatch for a wrong password and
+// ErrBcryptPasswordTooLong for passwords over 72 bytes, which bcrypt
+// implementations that truncate would have accepted.
+func BcryptVerify(password []byte, encoded string) error {
+	h, err := ParseBcrypt(encoded)
+	if err != nil {
+		return err
+	}
+	got, err := BcryptKey(password, h.Salt[:], h.Cost)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(got, h.Hash[:]) != 1 {
+		return ErrBcryptMismatch
+	}
+	return nil
+}
+
+// ParseBcrypt decodes a modular-crypt-format bcrypt string.
+func ParseBcrypt(encoded string) (BcryptHash, error) {
+	var h BcryptHash
+	if len(encoded) != bcryptEncodedLength || encoded[0] != '$' || encoded[3] != '$' || encoded[6] != '$' {
+		return h, errBcryptFormat
+	}
+	switch v := encoded[1:3]; v {
+	case "2a", "2b", "2y":
+		h.Version = v
+	default:
+		return h, errBcryptVersion
+	}
+	if encoded[4] < '0' || encoded[4] > '9' || encoded[5] < '0' || encoded[5] > '9' {
+		return h, errBcryptFormat
+	}
+	h.Cost, _ = strconv.Atoi(encoded[4:6])
+	if h.Cost < BcryptMinCost || h.Cost > BcryptMaxCost {
+		return h, errBcryptCost
+	}
+	// The last salt character carries four unused bits, which are ignored.
+	salt, err := bcryptEncoding.DecodeString(encoded[7:29])
+	if err != nil || len(salt) != BcryptSaltSize {
+		return h, errBcryptFormat
+	}
+	hash, err := bcryptEncoding.DecodeString(encoded[29:])
+	if err != nil || len(hash) != BcryptHashSize {
+		return h, errBcryptFormat
+	}
+	copy(h.Salt[:], salt)
+	copy(h.Hash[:], hash)
+	return h, nil
+}
+
+// String encodes h in modular crypt format.
+func (h BcryptHash) String() string {
+	version := h.Version
+	if version == "" {
+		version = "2b"
+	}
+	cost := strconv.Itoa(h.Cost)
+	if h.Cost < 10 {
+		cost = "0" + cost
+	}
+	return "$" + version + "$" + cost + "$" + bcryptEncoding.EncodeToString(h.Salt[:]) + bcryptEncoding.EncodeToString(h.Hash[:])
+}
@@ -0,0 +1,182 @@
+package kdf
+
+import (
+	"errors"
+	"time"
+)
+
+// CalibrationTarget describes the per-derivation budget that the Calibrate
+// functions tune parameters for. Zero fields take the defaults noted below.
+type CalibrationTarget struct {
+	// Duration is the wall-clock time one derivation should take on this
+	// machine (default 500ms).
+	Duration time.Duration
+	// MemoryKiB caps the memory used by Argon2id and scrypt (default 64 MiB).
+	MemoryKiB uint32
+	// Threads is the Argon2id parallelism (default 1).
+	Threads uint32
+}
+
+// ScryptParams holds scrypt cost parameters for NewScrypt and Scrypt.
+type ScryptParams struct {
+	N, R, P int
+}
+
+// OWASP password storage cheat sheet minimums. For Argon2id each entry is
+// the minimum memory for a given number of passes; for scrypt each entry is
+// the minimum parallelism for a given log2(N) with r = 8.
+var (
+	owaspArgon2idMinMemoryKiB = []uint32{1: 47104, 2: 19456, 3: 12288, 4: 9216, 5: 7168}
+	owaspScryptMinP           = map[int]int{17: 1, 16: 2, 15: 3, 14: 5, 13: 10}
+)
+
+const (
+	owaspScryptMinLogN        = 13
+	owaspScryptR              = 8
+	owaspPBKDF2SHA1MinIter    = 1_300_000
+	owaspPBKDF2SHA256MinIter  = 600_000
+	calibrationDefaultLatency = 500 * time.Millisecond
+	calibrationDefaultMemory  = 64 * 1024
+	calibrationPBKDF2Probe    = 20 * time.Millisecond
+)
+
+var errCalibrationMemory = errors.New("kdf: calibration memory budget below the OWASP minimum")
+
+var (
+	calibrationPassword = []byte("cryptonite-go calibration password")
+	calibrationSalt     = []byte("calibration salt")
+)
+
+func (t CalibrationTarget) withDefaults() CalibrationTarget {
+	if t.Duration <= 0 {
+		t.Duration = calibrationDefaultLatency
+	}
+	if t.MemoryKiB == 0 {
+		t.MemoryKiB = calibrationDefaultMemory
+	}
+	if t.Threads == 0 {
+		t.Threads = 1
+	}
+	return t
+}
+
+// CalibrateArgon2id measures NewArgon2idWithParams on the current machine
+// and returns the costs that fit target. It uses as much of the memory
+// budget as the latency allows, halving memory only when a single pass is
+// already too slow, then adds passes until the next one would overshoot.
+// The result never falls below the OWASP Argon2id minimums (for example
+// m=19 MiB, t=2), even when that exceeds the target latency.
+func CalibrateArgon2id(target CalibrationTarget) (Argon2idDeriver, error) {
+	target = target.withDefaults()
+	minMemory := owaspArgon2idMinMemoryKiB[len(owaspArgon2idMinMemoryKiB)-1]
+	if target.MemoryKiB < minMemory {
+		return Argon2idDeriver{}, errCalibrationMemory
+	}
+	memory := target.MemoryKiB
+	for {
+		minTime := argon2idMinTime(memory)
+		elapsed, err := timeDerive(NewArgon2idWithParams(1, memory, target.Threads), DeriveParams{Length: 32})
+		if err != nil {
+			return Argon2idDeriver{}, err
+		}
+		passes := uint32(target.Duration / elapsed)
+		if passes >= minTime || memory/2 < minMemory {
+			return Argon2idDeriver{Time: max(passes, minTime), MemoryKiB: memory, Threads: target.Threads}, nil
+		}
+		memory /= 2
+	}
+}
+
+// argon2idMinTime returns the fewest passes OWASP accepts at memoryKiB.
+func argon2idMinTime(memoryKiB uint32) uint32 {
+	for t := 1; t < len(owaspArgon2idMinMemoryKiB); t++ {
+		if memoryKiB >= owaspArgon2idMinMemoryKiB[t] {
+			return uint32(t)
+		}
+	}
+	return uint32(len(owaspArgon2idMinMemoryKiB) - 1)
+}
+
+// CalibrateScrypt measures NewScrypt on the current machine and returns
+// parameters that fit target with r = 8. N is the largest power of two
+// whose 128*N*r bytes fit the memory budget and whose single run fits the
+// latency; P then grows to use the remaining time. The result never falls
+// below the OWASP scrypt minimums (N=2^17 with p=1 down to N=2^13 with
+// p=10).
+func CalibrateScrypt(target CalibrationTarget) (ScryptParams, error) {
+	target = target.withDefaults()
+	logN := 0
+	for uint64(128*owaspScryptR)<<uint(logN+1) <= uint64(target.MemoryKiB)*1024 {
+		logN++
+	}
+	if logN < owaspScryptMinLogN {
+		return ScryptParams{}, errCalibrationMemory
+	}
+	for {
+		minP := scryptMinP(logN)
+		d, err := NewScrypt(1<<uint(logN), owaspScryptR, 1)
+		if err != nil {
+			return ScryptParams{}, err
+		}
+		elapsed, err := timeDerive(d, DeriveParams{Length: 32})
+		if err != nil {
+			return ScryptParams{}, err
+		}
+		p := int(target.Duration / elapsed)
+		if p >= minP || logN == owaspScryptMinLogN {
+			return ScryptParams{N: 1 << uint(logN), R: owaspScryptR, P: max(p, minP)}, nil
+		}
+		logN--
+	}
+}
+
+// scryptMinP returns the smallest parallelism OWASP accepts at logN.
+func scryptMinP(logN int) int {
+	if p, ok := owaspScryptMinP[logN]; ok {
+		return p
+	}
+	return 1
+}
+
+// CalibratePBKDF2SHA256 measures NewPBKDF2SHA256 on the current machine and
+// returns the iteration count that fits target.Duration, rounded down to a
+// multiple of 1000 and never below the OWASP minimum of 600000.
+func CalibratePBKDF2SHA256(target CalibrationTarget) (int, error) {
+	return calibratePBKDF2(NewPBKDF2SHA256(), target, owaspPBKDF2SHA256MinIter)
+}
+
+// CalibratePBKDF2SHA1 is CalibratePBKDF2SHA256 for NewPBKDF2SHA1, with the
+// OWASP minimum of 1300000 iterations.
+func CalibratePBKDF2SHA1(target CalibrationTarget) (int, error) {
+	return calibratePBKDF2(NewPBKDF2SHA1(), target, owaspPBKDF2SHA1MinIter)
+}
+
+func calibratePBKDF2(d Deriver, target CalibrationTarget, minIterations int) (int, error) {
+	target = target.withDefaults()
+	// Double the probe until it runs long enough to time reliably.
+	iterations := 1000
+	var elapsed time.Duration
+	for {
+		var err error
+		if elapsed, err = timeDerive(d, DeriveParams{Iterations: iterations, Length: 32}); err != nil {
+			return 0, err
+		}
+		if elapsed >= calibrationPBKDF2Probe || iterations >= minIterations {
+			break
+		}
+		iterations *= 2
+	}
+	n := int(float64(iterations) * float64(target.Duration) / float64(elapsed))
+	return max(n/1000*1000, minIterations), nil
+}
+
+// timeDerive times a single derivation with a fixed password and salt.
+func timeDerive(d Deriver, params DeriveParams) (time.Duration, error) {
+	params.Secret = calibrationPassword
+	params.Salt = calibrationSalt
+	start := time.Now()
+	if _, err := d.Derive(params); err != nil {
+		return 0, err
+	}
+	return max(time.Since(start), time.Nanosecond), nil
+}
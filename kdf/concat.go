@@ -0,0 +1,183 @@
+package kdf
+
+import (
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	stdhash "hash"
+
+	"github.com/AeonDave/cryptonite-go/mac"
+)
+
+var (
+	errConcatSecret   = errors.New("kdf: shared secret must be non-empty")
+	errConcatLength   = errors.New("kdf: output length must be positive")
+	errConcatTooLong  = errors.New("kdf: output length exceeds the counter range")
+	errConcatSecurity = errors.New("kdf: KMAC security level must be 128 or 256")
+)
+
+// kmacCustomization is the KMAC customization string fixed by SP 800-56C.
+var kmacCustomization = []byte("KDF")
+
+type (
+	concatHashDeriver struct{ newHash func() stdhash.Hash }
+	concatHMACDeriver struct{ newHash func() stdhash.Hash }
+	concatKMACDeriver struct{ security int }
+	x963Deriver       struct{ newHash func() stdhash.Hash }
+)
+
+// NewConcatKDF returns the SP 800-56C one-step KDF with a hash as the
+// auxiliary function (the JWE ECDH-ES "Concat KDF"). Derive takes the shared
+// secret Z from Secret and FixedInfo/OtherInfo from Info.
+func NewConcatKDF(newHash func() stdhash.Hash) Deriver {
+	return concatHashDeriver{newHash: newHash}
+}
+
+// NewConcatKDFHMAC returns the SP 800-56C one-step KDF with HMAC as the
+// auxiliary function. Derive reads the salt from Salt; an empty salt selects
+// the default all-zero salt of the hash block size.
+func NewConcatKDFHMAC(newHash func() stdhash.Hash) Deriver {
+	return concatHMACDeriver{newHash: newHash}
+}
+
+// NewConcatKDFKMAC returns the SP 800-56C one-step KDF with KMAC128 or
+// KMAC256 as the auxiliary function. Derive reads the salt from Salt; an
+// empty salt selects the default all-zero salt.
+func NewConcatKDFKMAC(security int) (Deriver, error) {
+	if security != 128 && security != 256 {
+		return nil, errConcatSecurity
+	}
+	return concatKMACDeriver{security: security}, nil
+}
+
+// NewX963KDF returns the ANSI X9.63 KDF used by ECIES and CMS. Derive takes
+// the shared secret Z from Secret and SharedInfo from Info.
+func NewX963KDF(newHash func() stdhash.Hash) Deriver {
+	return x963Deriver{newHash: newHash}
+}
+
+func (d concatHashDeriver) Derive(params DeriveParams) ([]byte, error) {
+	return ConcatKDF(d.newHash, params.Secret, params.Info, params.Length)
+}
+
+func (d concatHMACDeriver) Derive(params DeriveParams) ([]byte, error) {
+	return ConcatKDFHMAC(d.newHash, params.Secret, params.Salt, params.Info, params.Length)
+}
+
+func (d concatKMACDeriver) Derive(params DeriveParams) ([]byte, error) {
+	if d.security == 128 {
+		return ConcatKDFKMAC128(params.Secret, params.Salt, params.Info, params.Length)
+	}
+	return ConcatKDFKMAC256(params.Secret, params.Salt, params.Info, params.Length)
+}
+
+func (d x963Deriver) Derive(params DeriveParams) ([]byte, error) {
+	return X963KDF(d.newHash, params.Secret, params.Info, params.Length)
+}
+
+// ConcatKDF derives length bytes as H(counter || Z || otherInfo) for
+// counter = 1, 2, ... (SP 800-56C one-step KDF, hash option).
+func ConcatKDF(newHash func() stdhash.Hash, z, otherInfo []byte, length int) ([]byte, error) {
+	h := newHash()
+	return counterKDF(h, z, otherInfo, length, false)
+}
+
+// ConcatKDFHMAC derives length bytes as HMAC(salt, counter || Z ||
+// otherInfo) (SP 800-56C one-step KDF, HMAC option). A nil or empty salt is
+// replaced by BlockSize zero bytes.
+func ConcatKDFHMAC(newHash func() stdhash.Hash, z, salt, otherInfo []byte, length int) ([]byte, error) {
+	if len(salt) == 0 {
+		salt = make([]byte, newHash().BlockSize())
+	}
+	return counterKDF(hmac.New(newHash, salt), z, otherInfo, length, false)
+}
+
+// ConcatKDFKMAC128 derives length bytes as KMAC128(salt, 1 || Z ||
+// otherInfo, L, "KDF") (SP 800-56C one-step KDF, KMAC option). A nil or
+// empty salt is replaced by 164 zero bytes.
+func ConcatKDFKMAC128(z, salt, otherInfo []byte, length int) ([]byte, error) {
+	return concatKMAC(mac.KMAC128, 164, z, salt, otherInfo, length)
+}
+
+// ConcatKDFKMAC256 is ConcatKDFKMAC128 with KMAC256; the default salt is
+// 132 zero bytes.
+func ConcatKDFKMAC256(z, salt, otherInfo []byte, length int) ([]byte, error) {
+	return concatKMAC(mac.KMAC256, 132, z, salt, otherInfo, length)
+}
+
+func concatKMAC(kmac func(key, customization, msg []byte, outLen int) []byte, saltSize int, z, salt, otherInfo []byte, length int) ([]byte, error) {
+	if len(z) == 0 {
+		return nil, errConcatSecret
+	}
+	if length <= 0 {
+		return nil, errConcatLength
+	}
+	if len(salt) == 0 {
+		salt = make([]byte, saltSize)
+	}
+	msg := make([]byte, 0, 4+len(z)+len(otherInfo))
+	msg = binary.BigEndian.AppendUint32(msg, 1)
+	msg = append(msg, z...)
+	msg = append(msg, otherInfo...)
+	return kmac(salt, kmacCustomization, msg, length), nil
+}
+
+// X963KDF derives length bytes as H(Z || counter || sharedInfo) for
+// counter = 1, 2, ... (ANSI X9.63 / SEC 1 KDF).
+func X963KDF(newHash func() stdhash.Hash, z, sharedInfo []byte, length int) ([]byte, error) {
+	return counterKDF(newHash(), z, sharedInfo, length, true)
+}
+
+// counterKDF concatenates h(counter || z || info) blocks, or
+// h(z || counter || info) when counterAfterSecret is set, with a 32-bit
+// big-endian counter starting at 1.
+func counterKDF(h stdhash.Hash, z, info []byte, length int, counterAfterSecret bool) ([]byte, error) {
+	if len(z) == 0 {
+		return nil, errConcatSecret
+	}
+	if length <= 0 {
+		return nil, errConcatLength
+	}
+	size := h.Size()
+	reps := (uint64(length) + uint64(size) - 1) / uint64(size)
+	if reps > 1<<32-1 {
+		return nil, errConcatTooLong
+	}
+	out := make([]byte, 0, int(reps)*size)
+	var counter [4]byte
+	for i := uint64(1); i <= reps; i++ {
+		binary.BigEndian.PutUint32(counter[:], uint32(i))
+		h.Reset()
+		if counterAfterSecret {
+			h.Write(z)
+			h.Write(counter[:])
+		} else {
+			h.Write(counter[:])
+			h.Write(z)
+		}
+		h.Write(info)
+		out = h.Sum(out)
+	}
+	return out[:length], nil
+}
+
+// OtherInfo encodes the SP 800-56A FixedInfo / JWE OtherInfo structure:
+// AlgorithmID, PartyUInfo and PartyVInfo each prefixed with their 32-bit
+// big-endian byte length, followed by SuppPubInfo and SuppPrivInfo verbatim.
+// For JWE ECDH-ES, suppPubInfo is the key length in bits as 4 big-endian
+// bytes (see OtherInfoKeyLength).
+func OtherInfo(algorithmID, partyUInfo, partyVInfo, suppPubInfo, suppPrivInfo []byte) []byte {
+	out := make([]byte, 0, 12+len(algorithmID)+len(partyUInfo)+len(partyVInfo)+len(suppPubInfo)+len(suppPrivInfo))
+	for _, field := range [][]byte{algorithmID, partyUInfo, partyVInfo} {
+		out = binary.BigEndian.AppendUint32(out, uint32(len(field)))
+		out = append(out, field...)
+	}
+	out = append(out, suppPubInfo...)
+	return append(out, suppPrivInfo...)
+}
+
+// OtherInfoKeyLength encodes a key length in bytes as the 32-bit big-endian
+// bit count used for SuppPubInfo.
+func OtherInfoKeyLength(length int) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(length)*8)
+}
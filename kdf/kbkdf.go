@@ -0,0 +1,248 @@
+package kdf
+
+import (
+	"crypto/aes"
+	"crypto/hmac"
+	"encoding/binary"
+	"errors"
+	stdhash "hash"
+
+	"github.com/AeonDave/cryptonite-go/internal/cmac"
+	"github.com/AeonDave/cryptonite-go/mac"
+)
+
+// KBKDFMode selects the SP 800-108 iteration mode.
+type KBKDFMode int
+
+const (
+	// KBKDFCounter computes K(i) = PRF(KI, [i] || fixed).
+	KBKDFCounter KBKDFMode = iota
+	// KBKDFFeedback computes K(i) = PRF(KI, K(i-1) || [i] || fixed) with
+	// K(0) taken from DeriveParams.Salt (the IV, possibly empty).
+	KBKDFFeedback
+	// KBKDFDoublePipeline computes A(i) = PRF(KI, A(i-1)) with A(0) = fixed
+	// and K(i) = PRF(KI, A(i) || [i] || fixed).
+	KBKDFDoublePipeline
+)
+
+// KBKDFCounterLocation places the counter [i] within the PRF input.
+type KBKDFCounterLocation int
+
+const (
+	// CounterBeforeFixed places the counter immediately before the fixed
+	// input data, after the iteration variable in feedback and
+	// double-pipeline modes. It is the SP 800-108 default for every mode.
+	CounterBeforeFixed KBKDFCounterLocation = iota
+	// CounterAfterFixed places the counter after the fixed input data.
+	CounterAfterFixed
+	// CounterMiddleFixed splits the fixed input data at CounterOffset bytes
+	// and places the counter between the two parts.
+	CounterMiddleFixed
+	// CounterBeforeIteration places the counter before the iteration
+	// variable; feedback and double-pipeline modes only.
+	CounterBeforeIteration
+)
+
+// KBKDFOptions configures the HMAC and CMAC based SP 800-108 derivers. The
+// zero value is counter mode with a 32-bit counter before the fixed input.
+type KBKDFOptions struct {
+	Mode            KBKDFMode
+	CounterLocation KBKDFCounterLocation
+	// CounterBits is the counter width r: 8, 16, 24 or 32 (default 32).
+	CounterBits int
+	// CounterOffset is the number of fixed input bytes preceding the counter
+	// for CounterMiddleFixed.
+	CounterOffset int
+	// OmitCounter drops the counter entirely; feedback and double-pipeline
+	// modes only.
+	OmitCounter bool
+}
+
+var (
+	errKBKDFKey      = errors.New("kdf: kbkdf key must be non-empty")
+	errKBKDFLength   = errors.New("kdf: kbkdf output length must be positive")
+	errKBKDFTooLong  = errors.New("kdf: kbkdf output length exceeds the counter range")
+	errKBKDFOptions  = errors.New("kdf: invalid kbkdf options")
+	errKBKDFCMACKey  = errors.New("kdf: kbkdf CMAC key must be 16, 24 or 32 bytes")
+	errKBKDFKMACSize = errors.New("kdf: kbkdf KMAC security level must be 128 or 256")
+)
+
+type kbkdfDeriver struct {
+	opts    KBKDFOptions
+	newPRF  func(key []byte) (kbkdfPRF, error)
+	prfSize int
+}
+
+// kbkdfPRF is one keyed PRF instance, evaluated over the concatenation of
+// parts.
+type kbkdfPRF interface {
+	sum(dst []byte, parts ...[]byte) []byte
+}
+
+// NewKBKDFHMAC returns an SP 800-108r1 KBKDF using HMAC over newHash as the
+// PRF. Derive takes the key-derivation key from Secret and the fixed input
+// data (label and context, see KBKDFFixedInput) from Info; feedback mode
+// reads its IV from Salt.
+func NewKBKDFHMAC(newHash func() stdhash.Hash, opts KBKDFOptions) Deriver {
+	return kbkdfDeriver{
+		opts: opts,
+		newPRF: func(key []byte) (kbkdfPRF, error) {
+			return hmacPRF{hmac.New(newHash, key)}, nil
+		},
+		prfSize: newHash().Size(),
+	}
+}
+
+// NewKBKDFCMAC returns an SP 800-108r1 KBKDF using AES-CMAC as the PRF. The
+// key-derivation key selects AES-128, AES-192 or AES-256. Inputs are read as
+// for NewKBKDFHMAC.
+func NewKBKDFCMAC(opts KBKDFOptions) Deriver {
+	return kbkdfDeriver{
+		opts: opts,
+		newPRF: func(key []byte) (kbkdfPRF, error) {
+			if n := len(key); n != 16 && n != 24 && n != 32 {
+				return nil, errKBKDFCMACKey
+			}
+			block, err := aes.NewCipher(key)
+			if err != nil {
+				return nil, err
+			}
+			return cmacPRF{cmac.New(block)}, nil
+		},
+		prfSize: aes.BlockSize,
+	}
+}
+
+// KBKDFFixedInput encodes the SP 800-108 fixed input data
+// Label || 0x00 || Context || [L]_32, where L is the output length in bits.
+func KBKDFFixedInput(label, context []byte, length int) []byte {
+	out := make([]byte, 0, len(label)+1+len(context)+4)
+	out = append(out, label...)
+	out = append(out, 0)
+	out = append(out, context...)
+	return binary.BigEndian.AppendUint32(out, uint32(length)*8)
+}
+
+func (d kbkdfDeriver) Derive(params DeriveParams) ([]byte, error) {
+	return d.derive(params.Secret, params.Salt, params.Info, params.Length)
+}
+
+func (d kbkdfDeriver) derive(key, iv, fixed []byte, length int) ([]byte, error) {
+	o := d.opts
+	if o.CounterBits == 0 {
+		o.CounterBits = 32
+	}
+	if o.CounterBits%8 != 0 || o.CounterBits < 8 || o.CounterBits > 32 ||
+		o.Mode < KBKDFCounter || o.Mode > KBKDFDoublePipeline ||
+		o.CounterLocation < CounterBeforeFixed || o.CounterLocation > CounterBeforeIteration ||
+		(o.Mode == KBKDFCounter && (o.OmitCounter || o.CounterLocation == CounterBeforeIteration)) ||
+		(o.CounterLocation == CounterMiddleFixed && (o.CounterOffset < 0 || o.CounterOffset > len(fixed))) {
+		return nil, errKBKDFOptions
+	}
+	if len(key) == 0 {
+		return nil, errKBKDFKey
+	}
+	if length <= 0 {
+		return nil, errKBKDFLength
+	}
+	n := (uint64(length) + uint64(d.prfSize) - 1) / uint64(d.prfSize)
+	limit := uint64(1)<<32 - 1
+	if !o.OmitCounter {
+		limit = uint64(1)<<uint(o.CounterBits) - 1
+	}
+	if n > limit {
+		return nil, errKBKDFTooLong
+	}
+	prf, err := d.newPRF(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var before, after []byte
+	switch o.CounterLocation {
+	case CounterAfterFixed:
+		before = fixed
+	case CounterMiddleFixed:
+		before, after = fixed[:o.CounterOffset], fixed[o.CounterOffset:]
+	default:
+		after = fixed
+	}
+
+	out := make([]byte, 0, int(n)*d.prfSize)
+	var ctr [4]byte
+	var chain, k []byte
+	switch o.Mode {
+	case KBKDFFeedback:
+		k = iv
+	case KBKDFDoublePipeline:
+		chain = fixed
+	}
+	for i := uint64(1); i <= n; i++ {
+		binary.BigEndian.PutUint32(ctr[:], uint32(i))
+		counter := ctr[4-o.CounterBits/8:]
+		if o.OmitCounter {
+			counter = nil
+		}
+		var iter []byte
+		switch o.Mode {
+		case KBKDFFeedback:
+			iter = k
+		case KBKDFDoublePipeline:
+			chain = prf.sum(nil, chain)
+			iter = chain
+		}
+		if o.CounterLocation == CounterBeforeIteration {
+			k = prf.sum(nil, counter, iter, fixed)
+		} else {
+			k = prf.sum(nil, iter, before, counter, after)
+		}
+		out = append(out, k...)
+	}
+	return out[:length], nil
+}
+
+type hmacPRF struct{ h stdhash.Hash }
+
+func (p hmacPRF) sum(dst []byte, parts ...[]byte) []byte {
+	p.h.Reset()
+	for _, part := range parts {
+		p.h.Write(part)
+	}
+	return p.h.Sum(dst)
+}
+
+// cmacPRF is AES-CMAC (SP 800-38B).
+type cmacPRF struct{ c *cmac.CMAC }
+
+func (p cmacPRF) sum(dst []byte, parts ...[]byte) []byte {
+	tag := p.c.Sum(parts...)
+	return append(dst, tag[:]...)
+}
+
+type kbkdfKMACDeriver struct {
+	security int
+	label    []byte
+}
+
+// NewKBKDFKMAC returns the SP 800-108r1 KMAC-based KDF
+// KMAC(KI, Context, L, Label) for security 128 or 256. Derive takes KI from
+// Secret and the context from Info; label is the KMAC customization string.
+func NewKBKDFKMAC(security int, label []byte) (Deriver, error) {
+	if security != 128 && security != 256 {
+		return nil, errKBKDFKMACSize
+	}
+	return kbkdfKMACDeriver{security: security, label: append([]byte(nil), label...)}, nil
+}
+
+func (d kbkdfKMACDeriver) Derive(params DeriveParams) ([]byte, error) {
+	if len(params.Secret) == 0 {
+		return nil, errKBKDFKey
+	}
+	if params.Length <= 0 {
+		return nil, errKBKDFLength
+	}
+	if d.security == 128 {
+		return mac.KMAC128(params.Secret, d.label, params.Info, params.Length), nil
+	}
+	return mac.KMAC256(params.Secret, d.label, params.Info, params.Length), nil
+}
@@ -1,5 +1,7 @@
 package kdf
 
+import "context"
+
 // DeriveParams captures the inputs required by the various key-derivation
 // helpers exposed by the library. Individual schemes may interpret fields
 // differently and ignore those they do not require.
@@ -21,3 +23,25 @@ type DeriveParams struct {
 type Deriver interface {
 	Derive(params DeriveParams) ([]byte, error)
 }
+
+// ContextDeriver is implemented by the memory-hard and iterative derivers
+// (Argon2, scrypt and PBKDF2). DeriveContext stops between passes or blocks
+// once ctx is done, returning ctx.Err() and dropping its working memory.
+type ContextDeriver interface {
+	Deriver
+	DeriveContext(ctx context.Context, params DeriveParams) ([]byte, error)
+}
+
+// DeriveContext runs d.DeriveContext when d implements ContextDeriver, so
+// derivers returned as a plain Deriver (such as NewScrypt and
+// NewPBKDF2SHA256) can still be cancelled. Other derivers run to completion
+// once ctx has been checked.
+func DeriveContext(ctx context.Context, d Deriver, params DeriveParams) ([]byte, error) {
+	if cd, ok := d.(ContextDeriver); ok {
+		return cd.DeriveContext(ctx, params)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return d.Derive(params)
+}
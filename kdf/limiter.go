@@ -0,0 +1,121 @@
+package kdf
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var errLimiterCost = errors.New("kdf: limiter cost exceeds capacity")
+
+// Limiter bounds the resources held by concurrent derivations, so a burst of
+// logins queues instead of exhausting RAM. Capacity and costs are in units
+// chosen by the caller: KiB of working memory to cap total memory, or 1 per
+// derivation to cap concurrency. Waiters are admitted in FIFO order.
+type Limiter struct {
+	mu       sync.Mutex
+	capacity int64
+	used     int64
+	waiters  []*limiterWaiter
+}
+
+type limiterWaiter struct {
+	cost  int64
+	ready chan struct{}
+}
+
+// NewLimiter returns a Limiter admitting derivations whose costs sum to at
+// most capacity.
+func NewLimiter(capacity int64) *Limiter {
+	return &Limiter{capacity: capacity}
+}
+
+// Acquire blocks until cost units are available or ctx is done, in which
+// case it returns ctx.Err(). Costs larger than the capacity are rejected.
+func (l *Limiter) Acquire(ctx context.Context, cost int64) error {
+	if cost <= 0 || cost > l.capacity {
+		return errLimiterCost
+	}
+	l.mu.Lock()
+	if len(l.waiters) == 0 && l.used+cost <= l.capacity {
+		l.used += cost
+		l.mu.Unlock()
+		return nil
+	}
+	w := &limiterWaiter{cost: cost, ready: make(chan struct{})}
+	l.waiters = append(l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	l.mu.Lock()
+	select {
+	case <-w.ready:
+		// Admitted while giving up; hand the units back.
+		l.used -= cost
+	default:
+		for i, other := range l.waiters {
+			if other == w {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				break
+			}
+		}
+	}
+	l.notify()
+	l.mu.Unlock()
+	return ctx.Err()
+}
+
+// Release returns cost units acquired with Acquire.
+func (l *Limiter) Release(cost int64) {
+	l.mu.Lock()
+	l.used -= cost
+	if l.used < 0 {
+		l.mu.Unlock()
+		panic("kdf: limiter released more than acquired")
+	}
+	l.notify()
+	l.mu.Unlock()
+}
+
+// notify admits queued waiters in order while they fit.
+func (l *Limiter) notify() {
+	for len(l.waiters) > 0 {
+		w := l.waiters[0]
+		if l.used+w.cost > l.capacity {
+			return
+		}
+		l.used += w.cost
+		close(w.ready)
+		l.waiters[0] = nil
+		l.waiters = l.waiters[1:]
+	}
+}
+
+// Limit wraps d so each derivation holds cost units of l while it runs.
+// Derive waits without a deadline; DeriveContext gives up waiting when ctx
+// is done and cancels d if it implements ContextDeriver.
+func (l *Limiter) Limit(d Deriver, cost int64) ContextDeriver {
+	return limitedDeriver{limiter: l, d: d, cost: cost}
+}
+
+type limitedDeriver struct {
+	limiter *Limiter
+	d       Deriver
+	cost    int64
+}
+
+func (d limitedDeriver) Derive(params DeriveParams) ([]byte, error) {
+	return d.DeriveContext(context.Background(), params)
+}
+
+func (d limitedDeriver) DeriveContext(ctx context.Context, params DeriveParams) ([]byte, error) {
+	if err := d.limiter.Acquire(ctx, d.cost); err != nil {
+		return nil, err
+	}
+	defer d.limiter.Release(d.cost)
+	return DeriveContext(ctx, d.d, params)
+}
@@ -1,6 +1,7 @@
 package kdf
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"crypto/sha256"
@@ -31,6 +32,18 @@ func (pbkdf2SHA256Deriver) Derive(params DeriveParams) ([]byte, error) {
 	return pbkdf2(params.Secret, params.Salt, params.Iterations, params.Length, sha256.New)
 }
 
+// DeriveContext is Derive with cancellation, checked every
+// pbkdf2CheckInterval iterations.
+func (pbkdf2SHA1Deriver) DeriveContext(ctx context.Context, params DeriveParams) ([]byte, error) {
+	return pbkdf2Into(ctx, params.Secret, params.Salt, params.Iterations, params.Length, nil, sha1.New)
+}
+
+// DeriveContext is Derive with cancellation, checked every
+// pbkdf2CheckInterval iterations.
+func (pbkdf2SHA256Deriver) DeriveContext(ctx context.Context, params DeriveParams) ([]byte, error) {
+	return pbkdf2Into(ctx, params.Secret, params.Salt, params.Iterations, params.Length, nil, sha256.New)
+}
+
 // PBKDF2SHA1 derives key material using PBKDF2-HMAC-SHA1 (RFC 2898).
 func PBKDF2SHA1(password, salt []byte, iterations, keyLen int) ([]byte, error) {
 	return pbkdf2(password, salt, iterations, keyLen, sha1.New)
@@ -43,12 +56,12 @@ func PBKDF2SHA256(password, salt []byte, iterations, keyLen int) ([]byte, error)
 
 // PBKDF2SHA1Into derives key material into dst using PBKDF2-HMAC-SHA1.
 func PBKDF2SHA1Into(password, salt []byte, iterations int, dst []byte) ([]byte, error) {
-	return pbkdf2Into(password, salt, iterations, len(dst), dst, sha1.New)
+	return pbkdf2Into(context.Background(), password, salt, iterations, len(dst), dst, sha1.New)
 }
 
 // PBKDF2SHA256Into derives key material into dst using PBKDF2-HMAC-SHA256.
 func PBKDF2SHA256Into(password, salt []byte, iterations int, dst []byte) ([]byte, error) {
-	return pbkdf2Into(password, salt, iterations, len(dst), dst, sha256.New)
+	return pbkdf2Into(context.Background(), password, salt, iterations, len(dst), dst, sha256.New)
 }
 
 // CheckParams validates PBKDF2 derivation parameters against the supplied minimums.
@@ -66,10 +79,14 @@ func CheckParams(params DeriveParams, minIter, minSalt int) error {
 }
 
 func pbkdf2(password, salt []byte, iterations, keyLen int, newHash func() hash.Hash) ([]byte, error) {
-	return pbkdf2Into(password, salt, iterations, keyLen, nil, newHash)
+	return pbkdf2Into(context.Background(), password, salt, iterations, keyLen, nil, newHash)
 }
 
-func pbkdf2Into(password, salt []byte, iterations, keyLen int, dst []byte, newHash func() hash.Hash) ([]byte, error) {
+// pbkdf2CheckInterval is how many HMAC iterations run between cancellation
+// checks.
+const pbkdf2CheckInterval = 4096
+
+func pbkdf2Into(ctx context.Context, password, salt []byte, iterations, keyLen int, dst []byte, newHash func() hash.Hash) ([]byte, error) {
 	if iterations <= 0 {
 		return nil, errors.New("pbkdf2: iterations must be > 0")
 	}
@@ -90,6 +107,11 @@ func pbkdf2Into(password, salt []byte, iterations, keyLen int, dst []byte, newHa
 		}
 		output = dst[:keyLen]
 	}
+	// Check before the first block as well: derivations shorter than
+	// pbkdf2CheckInterval iterations never reach the check in the loop.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	mac := hmac.New(newHash, password)
 	blockBuf := make([]byte, len(salt)+4)
 	copy(blockBuf, salt)
@@ -103,6 +125,11 @@ func pbkdf2Into(password, salt []byte, iterations, keyLen int, dst []byte, newHa
 		mac.Sum(u[:0])
 		copy(t, u)
 		for i := 1; i < iterations; i++ {
+			if i%pbkdf2CheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
 			mac.Reset()
 			mac.Write(u)
 			mac.Sum(u[:0])
@@ -1,6 +1,7 @@
 package kdf
 
 import (
+	"context"
 	"encoding/binary"
 	"errors"
 	"math/bits"
@@ -37,9 +38,19 @@ func (d scryptDeriver) Derive(params DeriveParams) ([]byte, error) {
 	return Scrypt(params.Secret, params.Salt, d.n, d.r, d.p, params.Length)
 }
 
+// DeriveContext is Derive with cancellation, checked every
+// scryptCheckInterval ROMix iterations.
+func (d scryptDeriver) DeriveContext(ctx context.Context, params DeriveParams) ([]byte, error) {
+	return scrypt(ctx, params.Secret, params.Salt, d.n, d.r, d.p, params.Length)
+}
+
 // Scrypt derives keyLen bytes from password and salt using the scrypt KDF as
 // specified by RFC 7914.
 func Scrypt(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
+	return scrypt(context.Background(), password, salt, N, r, p, keyLen)
+}
+
+func scrypt(ctx context.Context, password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
 	if N <= 1 || N&(N-1) != 0 {
 		return nil, errors.New("scrypt: N must be > 1 and a power of 2")
 	}
@@ -57,7 +68,9 @@ func Scrypt(password, salt []byte, N, r, p, keyLen int) ([]byte, error) {
 		return nil, err
 	}
 	for i := 0; i < p; i++ {
-		smix(b[i*128*r:], r, N, v, xy)
+		if err := smix(ctx, b[i*128*r:], r, N, v, xy); err != nil {
+			return nil, err
+		}
 	}
 	// Second PBKDF2 pass to produce the final derived key.
 	return PBKDF2SHA256(password, b, 1, keyLen)
@@ -186,7 +199,11 @@ func integer(b []uint32, r int) uint64 {
 	return uint64(b[j]) | uint64(b[j+1])<<32
 }
 
-func smix(b []byte, r, N int, v, xy []uint32) {
+// scryptCheckInterval is how many ROMix iterations run between
+// cancellation checks.
+const scryptCheckInterval = 1024
+
+func smix(ctx context.Context, b []byte, r, N int, v, xy []uint32) error {
 	var tmp [16]uint32
 	R := 32 * r
 	x := xy
@@ -198,6 +215,11 @@ func smix(b []byte, r, N int, v, xy []uint32) {
 		j += 4
 	}
 	for i := 0; i < N; i += 2 {
+		if i%scryptCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
 		blockCopy(v[i*R:], x, R)
 		blockMix(&tmp, x, y, r)
 
@@ -205,6 +227,11 @@ func smix(b []byte, r, N int, v, xy []uint32) {
 		blockMix(&tmp, y, x, r)
 	}
 	for i := 0; i < N; i += 2 {
+		if i%scryptCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
 		j := int(integer(x, r) & uint64(N-1))
 		blockXOR(x, v[j*R:], R)
 		blockMix(&tmp, x, y, r)
@@ -218,4 +245,5 @@ func smix(b []byte, r, N int, v, xy []uint32) {
 		binary.LittleEndian.PutUint32(b[j:], v)
 		j += 4
 	}
+	return nil
 }
@@ -0,0 +1,44 @@
+package kdf
+
+import (
+	"errors"
+	stdhash "hash"
+)
+
+const tls13LabelPrefix = "tls13 "
+
+var (
+	errExpandLabelLength  = errors.New("kdf: HKDF-Expand-Label length must be between 1 and 65535")
+	errExpandLabelLabel   = errors.New("kdf: HKDF-Expand-Label label too long")
+	errExpandLabelContext = errors.New("kdf: HKDF-Expand-Label context too long")
+)
+
+// HKDFExpandLabel implements HKDF-Expand-Label from RFC 8446 section 7.1:
+// HKDF-Expand(secret, HkdfLabel, length) where HkdfLabel encodes length,
+// "tls13 " || label and context. QUIC (RFC 9001) uses the same function.
+func HKDFExpandLabel(newHash func() stdhash.Hash, secret []byte, label string, context []byte, length int) ([]byte, error) {
+	if length <= 0 || length > 0xffff {
+		return nil, errExpandLabelLength
+	}
+	if len(tls13LabelPrefix)+len(label) > 255 {
+		return nil, errExpandLabelLabel
+	}
+	if len(context) > 255 {
+		return nil, errExpandLabelContext
+	}
+	info := make([]byte, 0, 4+len(tls13LabelPrefix)+len(label)+len(context))
+	info = append(info, byte(length>>8), byte(length))
+	info = append(info, byte(len(tls13LabelPrefix)+len(label)))
+	info = append(info, tls13LabelPrefix...)
+	info = append(info, label...)
+	info = append(info, byte(len(context)))
+	info = append(info, context...)
+	return HKDFExpandWith(newHash, secret, info, length)
+}
+
+// DeriveSecret implements Derive-Secret from RFC 8446 section 7.1. It takes
+// the transcript hash of the handshake messages rather than the messages
+// themselves; pass the hash of the empty string for Derive-Secret(., ., "").
+func DeriveSecret(newHash func() stdhash.Hash, secret []byte, label string, transcriptHash []byte) ([]byte, error) {
+	return HKDFExpandLabel(newHash, secret, label, transcriptHash, newHash().Size())
+}
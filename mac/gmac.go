@@ -0,0 +1,121 @@
+package mac
+
+import (
+	"crypto/aes"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	stdhash "hash"
+
+	"github.com/AeonDave/cryptonite-go/internal/polyval"
+)
+
+// GMACTagSize is the size in bytes of an AES-GMAC authenticator.
+const GMACTagSize = 16
+
+// GMAC implements AES-GMAC, the authentication-only mode of GCM defined in
+// NIST SP 800-38D. Data written to the MAC is authenticated as GCM additional
+// data with an empty plaintext, so tags match AES-GCM Seal(nil, nonce, nil, data).
+//
+// Each (key, nonce) pair must authenticate a single message; reusing a nonce
+// reveals the GHASH key and allows forgeries.
+type GMAC struct {
+	ghash polyval.GHASH
+	mask  [GMACTagSize]byte
+	n     uint64
+}
+
+// NewGMAC returns a streaming AES-GMAC instance. The key must be 16, 24 or 32
+// bytes and the nonce must be non-empty; 12-byte nonces are recommended.
+func NewGMAC(key, nonce []byte) (*GMAC, error) {
+	if len(nonce) == 0 {
+		return nil, errors.New("mac: GMAC nonce must not be empty")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	var h [GMACTagSize]byte
+	block.Encrypt(h[:], h[:])
+	g := &GMAC{}
+	g.ghash.Init(&h)
+
+	var j0 [GMACTagSize]byte
+	if len(nonce) == 12 {
+		copy(j0[:], nonce)
+		j0[GMACTagSize-1] = 1
+	} else {
+		g.ghash.Write(nonce)
+		g.ghash.Pad()
+		var lengths [GMACTagSize]byte
+		binary.BigEndian.PutUint64(lengths[8:], uint64(len(nonce))*8)
+		g.ghash.Write(lengths[:])
+		j0 = g.ghash.Sum()
+		g.ghash.Reset()
+	}
+	block.Encrypt(g.mask[:], j0[:])
+	return g, nil
+}
+
+// Write absorbs authenticated data. It never returns an error.
+func (g *GMAC) Write(p []byte) (int, error) {
+	g.ghash.Write(p)
+	g.n += uint64(len(p))
+	return len(p), nil
+}
+
+// Sum appends the 16-byte tag to b without modifying the running state.
+func (g *GMAC) Sum(b []byte) []byte {
+	tmp := g.ghash
+	tmp.Pad()
+	var lengths [GMACTagSize]byte
+	binary.BigEndian.PutUint64(lengths[:8], g.n*8)
+	tmp.Write(lengths[:])
+	tag := tmp.Sum()
+	for i := range tag {
+		tag[i] ^= g.mask[i]
+	}
+	return append(b, tag[:]...)
+}
+
+// Verify computes the tag over the absorbed data and compares it with
+// expected in constant time.
+func (g *GMAC) Verify(expected []byte) bool {
+	return subtle.ConstantTimeCompare(g.Sum(nil), expected) == 1
+}
+
+// Reset discards the absorbed data. The key and nonce are retained, so the
+// instance must not be used to authenticate a different message afterwards.
+func (g *GMAC) Reset() {
+	g.ghash.Reset()
+	g.n = 0
+}
+
+// Size returns the tag size in bytes.
+func (g *GMAC) Size() int { return GMACTagSize }
+
+// BlockSize returns the GHASH block size in bytes.
+func (g *GMAC) BlockSize() int { return polyval.BlockSize }
+
+// SumGMAC computes the AES-GMAC tag of data under key and nonce.
+func SumGMAC(key, nonce, data []byte) ([]byte, error) {
+	g, err := NewGMAC(key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = g.Write(data)
+	return g.Sum(nil), nil
+}
+
+// VerifyGMAC recomputes the AES-GMAC tag of data and compares it with tag in
+// constant time.
+func VerifyGMAC(key, nonce, data, tag []byte) (bool, error) {
+	g, err := NewGMAC(key, nonce)
+	if err != nil {
+		return false, err
+	}
+	_, _ = g.Write(data)
+	return g.Verify(tag), nil
+}
+
+var _ stdhash.Hash = (*GMAC)(nil)
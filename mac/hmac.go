@@ -0,0 +1,136 @@
+package mac
+
+import (
+	"crypto/hmac"
+	"crypto/subtle"
+	"errors"
+	stdhash "hash"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+)
+
+// HMACMinTagSize is the shortest truncated HMAC tag accepted, following the
+// 80-bit lower bound from RFC 2104 section 5.
+const HMACMinTagSize = 10
+
+// HMAC computes RFC 2104 HMAC over any streaming hash constructor, optionally
+// truncating tags to their leftmost bytes.
+//
+// HMAC implements hash.Hash; Size reports the (possibly truncated) tag length.
+type HMAC struct {
+	inner   stdhash.Hash
+	tagSize int
+}
+
+// NewHMAC returns an HMAC keyed with key over the hash produced by newHash,
+// emitting full-length tags.
+func NewHMAC(newHash func() stdhash.Hash, key []byte) *HMAC {
+	inner := hmac.New(newHash, key)
+	return &HMAC{inner: inner, tagSize: inner.Size()}
+}
+
+// NewHMACTruncated returns an HMAC whose tags are truncated to tagSize bytes.
+// tagSize must be at least HMACMinTagSize and at most the digest size.
+func NewHMACTruncated(newHash func() stdhash.Hash, key []byte, tagSize int) (*HMAC, error) {
+	h := NewHMAC(newHash, key)
+	if tagSize < HMACMinTagSize || tagSize > h.inner.Size() {
+		return nil, errors.New("mac: invalid HMAC tag size")
+	}
+	h.tagSize = tagSize
+	return h, nil
+}
+
+// Write absorbs data into the running MAC. It never returns an error.
+func (h *HMAC) Write(p []byte) (int, error) { return h.inner.Write(p) }
+
+// Sum appends the (possibly truncated) tag to b without modifying the running
+// state.
+func (h *HMAC) Sum(b []byte) []byte {
+	full := h.inner.Sum(nil)
+	return append(b, full[:h.tagSize]...)
+}
+
+// Verify computes the tag over the absorbed data and compares it with
+// expected in constant time. Tags whose length differs from Size are rejected.
+func (h *HMAC) Verify(expected []byte) bool {
+	return subtle.ConstantTimeCompare(h.Sum(nil), expected) == 1
+}
+
+// Reset restores the keyed initial state.
+func (h *HMAC) Reset() { h.inner.Reset() }
+
+// Size returns the tag length in bytes.
+func (h *HMAC) Size() int { return h.tagSize }
+
+// BlockSize returns the block size of the underlying hash.
+func (h *HMAC) BlockSize() int { return h.inner.BlockSize() }
+
+var _ stdhash.Hash = (*HMAC)(nil)
+
+// SumHMAC computes the full-length HMAC of data under key using newHash.
+func SumHMAC(newHash func() stdhash.Hash, key, data []byte) []byte {
+	h := NewHMAC(newHash, key)
+	_, _ = h.Write(data)
+	return h.Sum(nil)
+}
+
+// VerifyHMAC recomputes the full-length HMAC of data and compares it with tag
+// in constant time. Truncated tags must be checked through NewHMACTruncated.
+func VerifyHMAC(newHash func() stdhash.Hash, key, data, tag []byte) bool {
+	h := NewHMAC(newHash, key)
+	_, _ = h.Write(data)
+	return h.Verify(tag)
+}
+
+// NewHMACSHA224 returns HMAC-SHA-224 keyed with key.
+func NewHMACSHA224(key []byte) *HMAC { return NewHMAC(cryptohash.NewSHA224, key) }
+
+// NewHMACSHA256 returns HMAC-SHA-256 keyed with key.
+func NewHMACSHA256(key []byte) *HMAC { return NewHMAC(cryptohash.NewSHA256, key) }
+
+// NewHMACSHA384 returns HMAC-SHA-384 keyed with key.
+func NewHMACSHA384(key []byte) *HMAC { return NewHMAC(cryptohash.NewSHA384, key) }
+
+// NewHMACSHA512 returns HMAC-SHA-512 keyed with key.
+func NewHMACSHA512(key []byte) *HMAC { return NewHMAC(cryptohash.NewSHA512, key) }
+
+// NewHMACSHA3224 returns HMAC-SHA3-224 keyed with key.
+func NewHMACSHA3224(key []byte) *HMAC { return NewHMAC(cryptohash.NewSHA3224, key) }
+
+// NewHMACSHA3256 returns HMAC-SHA3-256 keyed with key.
+func NewHMACSHA3256(key []byte) *HMAC { return NewHMAC(cryptohash.NewSHA3256, key) }
+
+// NewHMACSHA3384 returns HMAC-SHA3-384 keyed with key.
+func NewHMACSHA3384(key []byte) *HMAC { return NewHMAC(cryptohash.NewSHA3384, key) }
+
+// NewHMACSHA3512 returns HMAC-SHA3-512 keyed with key.
+func NewHMACSHA3512(key []byte) *HMAC { return NewHMAC(cryptohash.NewSHA3512, key) }
+
+// NewHMACBlake2b returns HMAC over unkeyed 64-byte BLAKE2b. BLAKE2b's native
+// keyed mode (hash.NewBlake2b with a key) is cheaper when HMAC interop is not
+// required.
+func NewHMACBlake2b(key []byte) *HMAC { return NewHMAC(newBlake2b512, key) }
+
+// NewHMACBlake2s returns HMAC over unkeyed 32-byte BLAKE2s.
+func NewHMACBlake2s(key []byte) *HMAC { return NewHMAC(newBlake2s256, key) }
+
+// NewHMACBLAKE3 returns HMAC over the 32-byte BLAKE3 hash. BLAKE3's native
+// keyed mode (hash.NewBLAKE3Keyed) is preferable when HMAC interop is not
+// required.
+func NewHMACBLAKE3(key []byte) *HMAC { return NewHMAC(cryptohash.NewBLAKE3, key) }
+
+func newBlake2b512() stdhash.Hash {
+	h, err := cryptohash.NewBlake2b(64, nil)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func newBlake2s256() stdhash.Hash {
+	h, err := cryptohash.NewBlake2s(32, nil)
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
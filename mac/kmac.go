@@ -1,9 +1,14 @@
 package mac
 
 import (
+	"bytes"
+	"errors"
 	stdhash "hash"
+	"math"
 
 	"github.com/AeonDave/cryptonite-go/internal/keccak"
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+	"github.com/AeonDave/cryptonite-go/xof"
 )
 
 const (
@@ -63,6 +68,59 @@ func (k *KMAC) Size() int { return k.outLen }
 // BlockSize returns the rate of the underlying cSHAKE permutation.
 func (k *KMAC) BlockSize() int { return k.rate }
 
+func (k *KMAC) alg(name string) string {
+	if k.rate == kmac128Rate {
+		return name + "128"
+	}
+	return name + "256"
+}
+
+func (k *KMAC) encode(e *marshal.Encoder) {
+	e.Uint64(uint64(k.outLen))
+	e.Bytes(k.prefix)
+	k.sponge.Encode(e)
+}
+
+func (k *KMAC) decode(d *marshal.Decoder) {
+	d.Expect(uint64(k.outLen))
+	prefix := d.Bytes(math.MaxInt32)
+	d.Check(bytes.Equal(prefix, k.prefix), marshal.ErrMismatch)
+	k.sponge.Decode(d)
+}
+
+// MarshalBinary encodes the output length, customization string and running
+// state. The key is not included, but the state is derived from it and should
+// be protected accordingly.
+func (k *KMAC) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder(k.alg("KMAC"))
+	k.encode(e)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary. k must have been
+// created with the same key, customization string and output length; a
+// different key is not detected and yields unrelated MACs. Failures are
+// reported as xof.ErrInvalidState or xof.ErrStateMismatch.
+func (k *KMAC) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, k.alg("KMAC"))
+	if err != nil {
+		return err
+	}
+	t := *k
+	t.decode(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*k = t
+	return nil
+}
+
+// Clone returns an independent copy of the MAC state.
+func (k *KMAC) Clone() stdhash.Hash {
+	c := *k
+	return &c
+}
+
 // NewKMAC128 constructs a streaming KMAC128 instance producing a 32-byte MAC.
 func NewKMAC128(key, customization []byte) stdhash.Hash {
 	return newKMAC(kmac128Rate, cloneBytes(key), cloneBytes(customization), kmac128Size)
@@ -97,6 +155,107 @@ func KMAC256(key, customization, msg []byte, outLen int) []byte {
 	return h.Sum(nil)
 }
 
+// kmacXOF implements KMACXOF128/256, the arbitrary-length variant of KMAC
+// that encodes an output length of zero.
+type kmacXOF struct {
+	kmac    *KMAC
+	reading bool
+}
+
+// Reset restores the initial keyed state so a new message can be absorbed.
+func (x *kmacXOF) Reset() {
+	x.kmac.Reset()
+	x.reading = false
+}
+
+// Write absorbs message bytes. It fails once output has been read; call
+// Reset to start a new message.
+func (x *kmacXOF) Write(p []byte) (int, error) {
+	if x.reading {
+		return 0, errKMACXOFWriteAfterRead
+	}
+	x.kmac.sponge.Absorb(p)
+	return len(p), nil
+}
+
+// Read squeezes the next len(p) bytes of output.
+func (x *kmacXOF) Read(p []byte) (int, error) {
+	if !x.reading {
+		x.kmac.sponge.Absorb(keccak.RightEncode(0))
+		x.reading = true
+	}
+	x.kmac.sponge.Squeeze(p)
+	return len(p), nil
+}
+
+// MarshalBinary encodes the running state, including the output position
+// once reading has started. The key is not included.
+func (x *kmacXOF) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder(x.kmac.alg("KMACXOF"))
+	x.kmac.encode(e)
+	e.Bool(x.reading)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on an instance
+// created with the same key and customization string.
+func (x *kmacXOF) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, x.kmac.alg("KMACXOF"))
+	if err != nil {
+		return err
+	}
+	k := *x.kmac
+	k.decode(d)
+	reading := d.Bool()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	*x.kmac, x.reading = k, reading
+	return nil
+}
+
+// Clone returns an independent copy of the XOF, including its output
+// position.
+func (x *kmacXOF) Clone() xof.XOF {
+	k := *x.kmac
+	return &kmacXOF{kmac: &k, reading: x.reading}
+}
+
+var errKMACXOFWriteAfterRead = errors.New("mac: KMACXOF write after read")
+
+var _ xof.Cloner = (*kmacXOF)(nil)
+
+// NewKMACXOF128 constructs a streaming KMACXOF128 instance whose output can be
+// read to any length.
+func NewKMACXOF128(key, customization []byte) xof.XOF {
+	return &kmacXOF{kmac: newKMAC(kmac128Rate, cloneBytes(key), cloneBytes(customization), 0)}
+}
+
+// NewKMACXOF256 constructs a streaming KMACXOF256 instance whose output can be
+// read to any length.
+func NewKMACXOF256(key, customization []byte) xof.XOF {
+	return &kmacXOF{kmac: newKMAC(kmac256Rate, cloneBytes(key), cloneBytes(customization), 0)}
+}
+
+// KMACXOF128 computes outLen bytes of KMACXOF128 output for msg. A negative
+// outLen yields an empty result.
+func KMACXOF128(key, customization, msg []byte, outLen int) []byte {
+	return sumKMACXOF(NewKMACXOF128(key, customization), msg, outLen)
+}
+
+// KMACXOF256 computes outLen bytes of KMACXOF256 output for msg. A negative
+// outLen yields an empty result.
+func KMACXOF256(key, customization, msg []byte, outLen int) []byte {
+	return sumKMACXOF(NewKMACXOF256(key, customization), msg, outLen)
+}
+
+func sumKMACXOF(x xof.XOF, msg []byte, outLen int) []byte {
+	_, _ = x.Write(msg)
+	out := make([]byte, max(outLen, 0))
+	_, _ = x.Read(out)
+	return out
+}
+
 func cloneBytes(in []byte) []byte {
 	if len(in) == 0 {
 		return nil
@@ -0,0 +1,59 @@
+package mac
+
+import (
+	"crypto/aes"
+	"errors"
+)
+
+const (
+	// Poly1305AESKeySize is the size in bytes of a Poly1305-AES key: a 16-byte
+	// AES key k followed by the 16-byte Poly1305 multiplier r.
+	Poly1305AESKeySize = 32
+	// Poly1305AESNonceSize is the size in bytes of a Poly1305-AES nonce.
+	Poly1305AESNonceSize = 16
+)
+
+// NewPoly1305AES returns a Poly1305 helper for Bernstein's Poly1305-AES, where
+// the one-time pad is AES_k(nonce). Unlike plain Poly1305 the key may be
+// reused, provided every message is authenticated under a distinct nonce.
+func NewPoly1305AES(key, nonce []byte) (*Poly1305, error) {
+	if len(key) != Poly1305AESKeySize {
+		return nil, errors.New("mac: invalid Poly1305-AES key length")
+	}
+	if len(nonce) != Poly1305AESNonceSize {
+		return nil, errors.New("mac: invalid Poly1305-AES nonce length")
+	}
+	block, err := aes.NewCipher(key[:16])
+	if err != nil {
+		return nil, err
+	}
+	var oneTime [Poly1305KeySize]byte
+	copy(oneTime[:16], key[16:])
+	block.Encrypt(oneTime[16:], nonce)
+	p, err := NewPoly1305(oneTime[:])
+	for i := range oneTime {
+		oneTime[i] = 0
+	}
+	return p, err
+}
+
+// SumPoly1305AES computes the 16-byte Poly1305-AES authenticator of msg.
+func SumPoly1305AES(key, nonce, msg []byte) ([]byte, error) {
+	poly, err := NewPoly1305AES(key, nonce)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = poly.Write(msg)
+	return poly.Sum(), nil
+}
+
+// VerifyPoly1305AES recomputes the Poly1305-AES authenticator of msg and
+// compares it with tag in constant time.
+func VerifyPoly1305AES(key, nonce, msg, tag []byte) (bool, error) {
+	poly, err := NewPoly1305AES(key, nonce)
+	if err != nil {
+		return false, err
+	}
+	_, _ = poly.Write(msg)
+	return poly.Verify(tag), nil
+}
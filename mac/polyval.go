@@ -0,0 +1,110 @@
+package mac
+
+import (
+	"errors"
+	stdhash "hash"
+
+	"github.com/AeonDave/cryptonite-go/internal/polyval"
+)
+
+// UniversalHashSize is the key, block and digest size in bytes of the POLYVAL
+// and GHASH universal hashes.
+const UniversalHashSize = polyval.BlockSize
+
+// POLYVAL exposes the RFC 8452 universal hash used by AES-GCM-SIV.
+//
+// POLYVAL is not a MAC on its own: its output is linear in the message and it
+// must be combined with a secret mask (for example a block-cipher encryption
+// of a nonce) before being used to authenticate data. Writes are buffered in
+// 16-byte blocks; Pad zero-fills a partial block so independent fields can be
+// absorbed with the block alignment required by GCM-like constructions.
+type POLYVAL struct {
+	state polyval.Polyval
+}
+
+// NewPOLYVAL returns a POLYVAL instance keyed with the 16-byte field element key.
+func NewPOLYVAL(key []byte) (*POLYVAL, error) {
+	if len(key) != UniversalHashSize {
+		return nil, errors.New("mac: invalid POLYVAL key length")
+	}
+	var k [UniversalHashSize]byte
+	copy(k[:], key)
+	p := &POLYVAL{}
+	p.state.Init(&k)
+	return p, nil
+}
+
+// Write absorbs data into the running hash. It never returns an error.
+func (p *POLYVAL) Write(data []byte) (int, error) {
+	p.state.Write(data)
+	return len(data), nil
+}
+
+// Pad zero-fills and absorbs any buffered partial block.
+func (p *POLYVAL) Pad() { p.state.Pad() }
+
+// Sum appends the 16-byte digest to b. A trailing partial block is implicitly
+// zero-padded; the running state is left unchanged.
+func (p *POLYVAL) Sum(b []byte) []byte {
+	sum := p.state.Sum()
+	return append(b, sum[:]...)
+}
+
+// Reset clears the absorbed data while retaining the key.
+func (p *POLYVAL) Reset() { p.state.Reset() }
+
+// Size returns the digest size in bytes.
+func (p *POLYVAL) Size() int { return UniversalHashSize }
+
+// BlockSize returns the block size in bytes.
+func (p *POLYVAL) BlockSize() int { return UniversalHashSize }
+
+// GHASH exposes the GCM universal hash from NIST SP 800-38D.
+//
+// Like POLYVAL, GHASH must be masked before its output can serve as an
+// authenticator. The key is the hash subkey H, typically E_K(0^128).
+type GHASH struct {
+	state polyval.GHASH
+}
+
+// NewGHASH returns a GHASH instance keyed with the 16-byte hash subkey h.
+func NewGHASH(h []byte) (*GHASH, error) {
+	if len(h) != UniversalHashSize {
+		return nil, errors.New("mac: invalid GHASH key length")
+	}
+	var k [UniversalHashSize]byte
+	copy(k[:], h)
+	g := &GHASH{}
+	g.state.Init(&k)
+	return g, nil
+}
+
+// Write absorbs data into the running hash. It never returns an error.
+func (g *GHASH) Write(data []byte) (int, error) {
+	g.state.Write(data)
+	return len(data), nil
+}
+
+// Pad zero-fills and absorbs any buffered partial block.
+func (g *GHASH) Pad() { g.state.Pad() }
+
+// Sum appends the 16-byte digest to b. A trailing partial block is implicitly
+// zero-padded; the running state is left unchanged.
+func (g *GHASH) Sum(b []byte) []byte {
+	sum := g.state.Sum()
+	return append(b, sum[:]...)
+}
+
+// Reset clears the absorbed data while retaining the key.
+func (g *GHASH) Reset() { g.state.Reset() }
+
+// Size returns the digest size in bytes.
+func (g *GHASH) Size() int { return UniversalHashSize }
+
+// BlockSize returns the block size in bytes.
+func (g *GHASH) BlockSize() int { return UniversalHashSize }
+
+var (
+	_ stdhash.Hash = (*POLYVAL)(nil)
+	_ stdhash.Hash = (*GHASH)(nil)
+)
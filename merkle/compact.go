@@ -0,0 +1,141 @@
+package merkle
+
+import (
+	"errors"
+	"math/bits"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+)
+
+var (
+	errRangeShape    = errors.New("merkle: hash count does not match the compact range")
+	errRangeAdjacent = errors.New("merkle: compact ranges are not adjacent")
+	errRangeRoot     = errors.New("merkle: root requires a compact range starting at leaf 0")
+)
+
+// Range is a compact range: the hashes of the fewest complete subtrees that
+// exactly cover the leaves [Begin, End), ordered left to right. A range that
+// starts at leaf 0 holds at most one hash per bit of End and determines the
+// root of the tree of End leaves, so a streaming log can follow its root
+// while storing O(log n) hashes. Adjacent ranges can be merged, which lets
+// independent workers hash disjoint segments of a log.
+type Range struct {
+	h      cryptohash.Hasher
+	begin  uint64
+	end    uint64
+	hashes [][]byte
+}
+
+// NewRange returns an empty compact range positioned at leaf begin.
+func NewRange(h cryptohash.Hasher, begin uint64) *Range {
+	return &Range{h: h, begin: begin, end: begin}
+}
+
+// RestoreRange rebuilds a compact range from the hashes returned by
+// Range.Hashes, for example after loading them from storage.
+func RestoreRange(h cryptohash.Hasher, begin, end uint64, hashes [][]byte) (*Range, error) {
+	if end < begin || len(decompose(begin, end)) != len(hashes) {
+		return nil, errRangeShape
+	}
+	r := &Range{h: h, begin: begin, end: end, hashes: make([][]byte, len(hashes))}
+	for i, node := range hashes {
+		if len(node) != h.Size() {
+			return nil, errRangeShape
+		}
+		r.hashes[i] = clone(node)
+	}
+	return r, nil
+}
+
+// Range returns the compact range covering the leaves [begin, end) of the
+// tree.
+func (t *Tree) Range(begin, end uint64) (*Range, error) {
+	if end > t.Size() || begin > end {
+		return nil, errSizeRange
+	}
+	r := NewRange(t.h, begin)
+	for _, level := range decompose(begin, end) {
+		r.hashes = append(r.hashes, clone(t.levels[level][r.end>>level]))
+		r.end += 1 << level
+	}
+	return r, nil
+}
+
+// Begin returns the index of the first leaf covered by the range.
+func (r *Range) Begin() uint64 { return r.begin }
+
+// End returns the index one past the last leaf covered by the range.
+func (r *Range) End() uint64 { return r.end }
+
+// Hashes returns a copy of the subtree hashes, ordered left to right.
+func (r *Range) Hashes() [][]byte {
+	out := make([][]byte, len(r.hashes))
+	for i, node := range r.hashes {
+		out[i] = clone(node)
+	}
+	return out
+}
+
+// Append extends the range by one leaf whose hash was computed with HashLeaf.
+func (r *Range) Append(leafHash []byte) {
+	r.appendNode(0, clone(leafHash))
+}
+
+// AppendRange extends r with other, which must begin where r ends.
+func (r *Range) AppendRange(other *Range) error {
+	if other.begin != r.end {
+		return errRangeAdjacent
+	}
+	for i, level := range decompose(other.begin, other.end) {
+		r.appendNode(level, clone(other.hashes[i]))
+	}
+	return nil
+}
+
+// Root returns the root of the tree of End leaves. It requires a range that
+// begins at leaf 0.
+func (r *Range) Root() ([]byte, error) {
+	if r.begin != 0 {
+		return nil, errRangeRoot
+	}
+	if len(r.hashes) == 0 {
+		return EmptyRoot(r.h), nil
+	}
+	root := r.hashes[len(r.hashes)-1]
+	for i := len(r.hashes) - 2; i >= 0; i-- {
+		root = HashChildren(r.h, r.hashes[i], root)
+	}
+	return clone(root), nil
+}
+
+// appendNode appends the complete subtree of 2^level leaves starting at
+// r.end, then merges it with its left siblings while they are complete
+// subtrees of the same size inside the range.
+func (r *Range) appendNode(level int, node []byte) {
+	pos := r.end
+	r.hashes = append(r.hashes, node)
+	r.end += 1 << level
+	for (pos>>level)&1 == 1 && pos-r.begin >= 1<<level {
+		n := len(r.hashes)
+		r.hashes[n-2] = HashChildren(r.h, r.hashes[n-2], r.hashes[n-1])
+		r.hashes = r.hashes[:n-1]
+		pos -= 1 << level
+		level++
+	}
+}
+
+// decompose returns the levels of the complete subtrees that cover
+// [begin, end), left to right: each is the largest subtree aligned at the
+// current position that does not extend past end.
+func decompose(begin, end uint64) []int {
+	var levels []int
+	for pos := begin; pos < end; {
+		level := bits.Len64(end-pos) - 1
+		if pos != 0 {
+			level = min(level, bits.TrailingZeros64(pos))
+		}
+		levels = append(levels, level)
+		pos += 1 << level
+	}
+	return levels
+}
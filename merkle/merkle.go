@@ -0,0 +1,191 @@
+// Package merkle implements the append-only Merkle trees of RFC 6962 and
+// RFC 9162 (Certificate Transparency) over any hash.Hasher: incremental roots,
+// inclusion and consistency proofs and their verification, and compact ranges
+// that let a streaming log track its root without storing every leaf.
+//
+// Leaves are hashed as HASH(0x00 || data) and interior nodes as
+// HASH(0x01 || left || right); the tree over n leaves splits at the largest
+// power of two smaller than n. With SHA-256 the roots and proofs are those of
+// Certificate Transparency logs.
+package merkle
+
+import (
+	"errors"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+)
+
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+var (
+	// ErrInvalidProof reports a proof that does not authenticate the claimed
+	// leaf or tree against the given root.
+	ErrInvalidProof = errors.New("merkle: invalid proof")
+
+	errIndexRange = errors.New("merkle: leaf index out of range")
+	errSizeRange  = errors.New("merkle: tree size out of range")
+)
+
+// EmptyRoot returns the root of the empty tree, HASH("").
+func EmptyRoot(h cryptohash.Hasher) []byte { return h.Hash(nil) }
+
+// HashLeaf returns the leaf hash HASH(0x00 || data).
+func HashLeaf(h cryptohash.Hasher, data []byte) []byte {
+	buf := make([]byte, 1+len(data))
+	buf[0] = leafPrefix
+	copy(buf[1:], data)
+	return h.Hash(buf)
+}
+
+// HashChildren returns the interior node hash HASH(0x01 || left || right).
+func HashChildren(h cryptohash.Hasher, left, right []byte) []byte {
+	buf := make([]byte, 1+len(left)+len(right))
+	buf[0] = nodePrefix
+	copy(buf[1:], left)
+	copy(buf[1+len(left):], right)
+	return h.Hash(buf)
+}
+
+// Tree is an in-memory append-only Merkle tree. It keeps the hash of every
+// complete subtree, so appending a leaf costs O(log n) hash computations and
+// roots and proofs for any earlier tree size are available without
+// rehashing. A Tree is not safe for concurrent use.
+type Tree struct {
+	h cryptohash.Hasher
+	// levels[k][i] is the hash of the complete subtree of 2^k leaves
+	// starting at leaf i<<k; levels[0] holds the leaf hashes.
+	levels [][][]byte
+}
+
+// New returns an empty tree hashing with h, for example
+// hash.NewSHA256Hasher() for Certificate Transparency compatible trees.
+func New(h cryptohash.Hasher) *Tree {
+	return &Tree{h: h, levels: [][][]byte{nil}}
+}
+
+// Hasher returns the hash function of the tree.
+func (t *Tree) Hasher() cryptohash.Hasher { return t.h }
+
+// Size returns the number of leaves.
+func (t *Tree) Size() uint64 { return uint64(len(t.levels[0])) }
+
+// Append hashes data as a leaf, appends it and returns its index.
+func (t *Tree) Append(data []byte) uint64 {
+	return t.AppendLeafHash(HashLeaf(t.h, data))
+}
+
+// AppendLeafHash appends a leaf whose hash was computed with HashLeaf and
+// returns its index.
+func (t *Tree) AppendLeafHash(leafHash []byte) uint64 {
+	index := t.Size()
+	t.levels[0] = append(t.levels[0], clone(leafHash))
+	node := t.levels[0][index]
+	for k, i := 0, index; i&1 == 1; k, i = k+1, i>>1 {
+		node = HashChildren(t.h, t.levels[k][i-1], node)
+		if len(t.levels) == k+1 {
+			t.levels = append(t.levels, nil)
+		}
+		t.levels[k+1] = append(t.levels[k+1], node)
+	}
+	return index
+}
+
+// LeafHash returns the hash of the leaf at index.
+func (t *Tree) LeafHash(index uint64) ([]byte, error) {
+	if index >= t.Size() {
+		return nil, errIndexRange
+	}
+	return clone(t.levels[0][index]), nil
+}
+
+// Root returns the root of the current tree.
+func (t *Tree) Root() []byte {
+	root, _ := t.RootAt(t.Size())
+	return root
+}
+
+// RootAt returns the root the tree had when it contained size leaves.
+func (t *Tree) RootAt(size uint64) ([]byte, error) {
+	if size > t.Size() {
+		return nil, errSizeRange
+	}
+	if size == 0 {
+		return EmptyRoot(t.h), nil
+	}
+	return clone(t.subtree(0, size)), nil
+}
+
+// InclusionProof returns the audit path proving that the leaf at index is
+// part of the tree of the given size (RFC 9162, Section 2.1.3.1), ordered
+// from the leaf towards the root.
+func (t *Tree) InclusionProof(index, size uint64) ([][]byte, error) {
+	if size > t.Size() {
+		return nil, errSizeRange
+	}
+	if index >= size {
+		return nil, errIndexRange
+	}
+	return t.path(index, 0, size, [][]byte{}), nil
+}
+
+// ConsistencyProof returns the proof that the tree of newSize leaves extends
+// the tree of oldSize leaves (RFC 9162, Section 2.1.4.1). The proof is empty
+// when oldSize is 0 or equal to newSize.
+func (t *Tree) ConsistencyProof(oldSize, newSize uint64) ([][]byte, error) {
+	if newSize > t.Size() || oldSize > newSize {
+		return nil, errSizeRange
+	}
+	if oldSize == 0 || oldSize == newSize {
+		return [][]byte{}, nil
+	}
+	return t.subproof(oldSize, 0, newSize, true, [][]byte{}), nil
+}
+
+// subtree returns MTH(D[lo:hi]). Every subtree visited by the RFC 9162
+// recursion whose size is a power of two starts at a multiple of that size,
+// so it is one of the stored complete subtrees.
+func (t *Tree) subtree(lo, hi uint64) []byte {
+	n := hi - lo
+	if n&(n-1) == 0 {
+		k := log2(n)
+		return t.levels[k][lo>>k]
+	}
+	k := split(n)
+	return HashChildren(t.h, t.subtree(lo, lo+k), t.subtree(lo+k, hi))
+}
+
+// path appends PATH(m, D[lo:hi]) to proof.
+func (t *Tree) path(m, lo, hi uint64, proof [][]byte) [][]byte {
+	n := hi - lo
+	if n <= 1 {
+		return proof
+	}
+	k := split(n)
+	if m < k {
+		proof = t.path(m, lo, lo+k, proof)
+		return append(proof, clone(t.subtree(lo+k, hi)))
+	}
+	proof = t.path(m-k, lo+k, hi, proof)
+	return append(proof, clone(t.subtree(lo, lo+k)))
+}
+
+// subproof appends SUBPROOF(m, D[lo:hi], b) to proof.
+func (t *Tree) subproof(m, lo, hi uint64, b bool, proof [][]byte) [][]byte {
+	n := hi - lo
+	if m == n {
+		if b {
+			return proof
+		}
+		return append(proof, clone(t.subtree(lo, hi)))
+	}
+	k := split(n)
+	if m <= k {
+		proof = t.subproof(m, lo, lo+k, b, proof)
+		return append(proof, clone(t.subtree(lo+k, hi)))
+	}
+	proof = t.subproof(m-k, lo+k, hi, false, proof)
+	return append(proof, clone(t.subtree(lo, lo+k)))
+}
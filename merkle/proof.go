@@ -0,0 +1,120 @@
+package merkle
+
+import (
+	"crypto/subtle"
+	"math/bits"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+)
+
+// VerifyInclusion checks that leafHash is the leaf at index in the tree of
+// the given size with the given root, following RFC 9162, Section 2.1.3.2.
+// It returns ErrInvalidProof if the proof does not authenticate the leaf.
+func VerifyInclusion(h cryptohash.Hasher, index, size uint64, leafHash []byte, proof [][]byte, root []byte) error {
+	got, err := RootFromInclusionProof(h, index, size, leafHash, proof)
+	if err != nil {
+		return err
+	}
+	if !equal(got, root) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+// RootFromInclusionProof returns the root implied by an inclusion proof for
+// leafHash at index in a tree of the given size. It fails with
+// ErrInvalidProof if the proof has the wrong shape for index and size.
+func RootFromInclusionProof(h cryptohash.Hasher, index, size uint64, leafHash []byte, proof [][]byte) ([]byte, error) {
+	if index >= size {
+		return nil, ErrInvalidProof
+	}
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return nil, ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			r = HashChildren(h, p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = HashChildren(h, r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 {
+		return nil, ErrInvalidProof
+	}
+	return clone(r), nil
+}
+
+// VerifyConsistency checks that the tree of newSize leaves with root newRoot
+// is an append-only extension of the tree of oldSize leaves with root
+// oldRoot, following RFC 9162, Section 2.1.4.2. An empty old tree is
+// consistent with every tree, and equal sizes require equal roots and an
+// empty proof.
+func VerifyConsistency(h cryptohash.Hasher, oldSize, newSize uint64, oldRoot, newRoot []byte, proof [][]byte) error {
+	switch {
+	case oldSize > newSize:
+		return ErrInvalidProof
+	case oldSize == newSize:
+		if len(proof) != 0 || !equal(oldRoot, newRoot) {
+			return ErrInvalidProof
+		}
+		return nil
+	case oldSize == 0:
+		if len(proof) != 0 {
+			return ErrInvalidProof
+		}
+		return nil
+	}
+	if len(proof) == 0 {
+		return ErrInvalidProof
+	}
+	if oldSize&(oldSize-1) == 0 {
+		proof = append([][]byte{oldRoot}, proof...)
+	}
+	fn, sn := oldSize-1, newSize-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return ErrInvalidProof
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = HashChildren(h, c, fr)
+			sr = HashChildren(h, c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = HashChildren(h, sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+	if sn != 0 || !equal(fr, oldRoot) || !equal(sr, newRoot) {
+		return ErrInvalidProof
+	}
+	return nil
+}
+
+func equal(a, b []byte) bool {
+	return len(a) == len(b) && subtle.ConstantTimeCompare(a, b) == 1
+}
+
+func clone(b []byte) []byte { return append([]byte(nil), b...) }
+
+// split returns the largest power of two smaller than n, for n > 1.
+func split(n uint64) uint64 { return 1 << (bits.Len64(n-1) - 1) }
+
+// log2 returns the base-2 logarithm of the power of two n.
+func log2(n uint64) int { return bits.TrailingZeros64(n) }
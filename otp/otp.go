@@ -0,0 +1,295 @@
+// Package otp implements HMAC-based (RFC 4226) and time-based (RFC 6238)
+// one-time passwords on top of the mac package's HMAC, together with
+// otpauth:// key URIs as understood by common authenticator apps.
+package otp
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	stdhash "hash"
+	"strings"
+	"time"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	"github.com/AeonDave/cryptonite-go/mac"
+)
+
+// Algorithm selects the HMAC hash used to compute codes.
+type Algorithm int
+
+const (
+	// SHA1 is the RFC 4226 default and the only algorithm supported by many
+	// authenticator apps.
+	SHA1 Algorithm = iota
+	// SHA256 selects HMAC-SHA-256 (RFC 6238).
+	SHA256
+	// SHA512 selects HMAC-SHA-512 (RFC 6238).
+	SHA512
+)
+
+const (
+	// DefaultDigits is the code length used when Params.Digits is zero.
+	DefaultDigits = 6
+	// DefaultPeriod is the TOTP time step used when Params.Period is zero.
+	DefaultPeriod = 30 * time.Second
+	// DefaultSecretSize is the secret length in bytes produced by
+	// GenerateSecret when size is zero; 20 bytes matches HMAC-SHA-1's output.
+	DefaultSecretSize = 20
+
+	minDigits = 6
+	maxDigits = 10
+	// minGeneratedSecretSize is the RFC 4226 minimum (128 bits) enforced for
+	// new secrets. Existing secrets of any length are accepted: 80-bit keys
+	// are common in deployed authenticator enrolments.
+	minGeneratedSecretSize = 16
+	maxResyncWindow        = 1000
+)
+
+var (
+	errInvalidDigits = errors.New("otp: digits must be between 6 and 10")
+	errInvalidPeriod = errors.New("otp: period must be a positive whole number of seconds")
+	errInvalidWindow = errors.New("otp: window must not be negative")
+	errShortSecret   = errors.New("otp: secret shorter than 128 bits")
+	errEmptySecret   = errors.New("otp: secret must not be empty")
+	errUnknownAlg    = errors.New("otp: unknown algorithm")
+)
+
+// String returns the otpauth:// name of the algorithm.
+func (a Algorithm) String() string {
+	switch a {
+	case SHA1:
+		return "SHA1"
+	case SHA256:
+		return "SHA256"
+	case SHA512:
+		return "SHA512"
+	default:
+		return "unknown"
+	}
+}
+
+func (a Algorithm) newHash() (func() stdhash.Hash, error) {
+	switch a {
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return cryptohash.NewSHA256, nil
+	case SHA512:
+		return cryptohash.NewSHA512, nil
+	default:
+		return nil, errUnknownAlg
+	}
+}
+
+// Params configures code generation and verification. Zero values select
+// the RFC defaults: SHA-1, six digits, a 30-second period and no window.
+type Params struct {
+	// Algorithm selects the HMAC hash.
+	Algorithm Algorithm
+	// Digits is the code length (6 to 10).
+	Digits int
+	// Period is the TOTP time step; it is ignored by HOTP.
+	Period time.Duration
+	// Window bounds verification: for HOTP it is the number of counters
+	// checked after the expected one (look-ahead); for TOTP it is the number
+	// of time steps tolerated on either side of the current one.
+	Window int
+}
+
+func (p Params) withDefaults() (Params, error) {
+	if p.Digits == 0 {
+		p.Digits = DefaultDigits
+	}
+	if p.Period == 0 {
+		p.Period = DefaultPeriod
+	}
+	if p.Digits < minDigits || p.Digits > maxDigits {
+		return p, errInvalidDigits
+	}
+	if p.Period < time.Second || p.Period%time.Second != 0 {
+		return p, errInvalidPeriod
+	}
+	if p.Window < 0 {
+		return p, errInvalidWindow
+	}
+	if _, err := p.Algorithm.newHash(); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// GenerateSecret returns size random bytes from crypto/rand suitable as a
+// shared OTP secret. A size of zero selects DefaultSecretSize.
+func GenerateSecret(size int) ([]byte, error) {
+	if size == 0 {
+		size = DefaultSecretSize
+	}
+	if size < minGeneratedSecretSize {
+		return nil, errShortSecret
+	}
+	secret := make([]byte, size)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// HOTP generates and verifies counter-based one-time passwords (RFC 4226).
+type HOTP struct {
+	secret  []byte
+	params  Params
+	newHash func() stdhash.Hash
+}
+
+// NewHOTP returns an HOTP instance for the shared secret. The secret is
+// copied and must not be empty; shorter secrets than the 128 bits that
+// GenerateSecret enforces are accepted so existing enrolments keep working.
+func NewHOTP(secret []byte, params Params) (*HOTP, error) {
+	if len(secret) == 0 {
+		return nil, errEmptySecret
+	}
+	p, err := params.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+	newHash, _ := p.Algorithm.newHash()
+	return &HOTP{secret: append([]byte(nil), secret...), params: p, newHash: newHash}, nil
+}
+
+// Params returns the effective parameters, with defaults applied.
+func (h *HOTP) Params() Params { return h.params }
+
+// Generate returns the code for counter, zero-padded to the configured length.
+func (h *HOTP) Generate(counter uint64) string {
+	return formatCode(h.value(counter), h.params.Digits)
+}
+
+// Verify checks code against the counters counter through
+// counter+Params.Window. On success it returns the counter the server must
+// store for the next attempt (the matching counter plus one); otherwise it
+// returns counter unchanged. Every candidate is compared in constant time.
+func (h *HOTP) Verify(code string, counter uint64) (uint64, bool) {
+	return h.verifyRange(code, counter, h.params.Window)
+}
+
+// Resync implements the RFC 4226 section 7.4 resynchronisation protocol: it
+// searches up to window counters past counter for two consecutive codes
+// matching code1 and code2. On success it returns the counter to store
+// (the one following code2's counter).
+func (h *HOTP) Resync(code1, code2 string, counter uint64, window int) (uint64, bool) {
+	if window < 0 || window > maxResyncWindow {
+		return counter, false
+	}
+	next, ok := h.verifyRange(code1, counter, window)
+	if !ok {
+		return counter, false
+	}
+	if subtle.ConstantTimeCompare([]byte(h.Generate(next)), []byte(normalizeCode(code2))) != 1 {
+		return counter, false
+	}
+	return next + 1, true
+}
+
+func (h *HOTP) verifyRange(code string, counter uint64, window int) (uint64, bool) {
+	code = normalizeCode(code)
+	if len(code) != h.params.Digits {
+		return counter, false
+	}
+	matched := 0
+	next := counter
+	for i := 0; i <= window; i++ {
+		c := counter + uint64(i)
+		if c < counter {
+			break
+		}
+		eq := subtle.ConstantTimeCompare([]byte(h.Generate(c)), []byte(code))
+		first := eq & (1 ^ matched)
+		next = uint64(first)*(c+1) + uint64(1-first)*next
+		matched |= eq
+	}
+	return next, matched == 1
+}
+
+func (h *HOTP) value(counter uint64) uint32 {
+	m := mac.NewHMAC(h.newHash, h.secret)
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	_, _ = m.Write(msg[:])
+	sum := m.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+}
+
+func formatCode(value uint32, digits int) string {
+	var mod uint64 = 1
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	v := uint64(value) % mod
+	buf := make([]byte, digits)
+	for i := digits - 1; i >= 0; i-- {
+		buf[i] = byte('0' + v%10)
+		v /= 10
+	}
+	return string(buf)
+}
+
+// TOTP generates and verifies time-based one-time passwords (RFC 6238) with
+// the Unix epoch as T0.
+type TOTP struct {
+	hotp HOTP
+}
+
+// NewTOTP returns a TOTP instance for the shared secret. The secret is copied
+// and must not be empty.
+func NewTOTP(secret []byte, params Params) (*TOTP, error) {
+	h, err := NewHOTP(secret, params)
+	if err != nil {
+		return nil, err
+	}
+	return &TOTP{hotp: *h}, nil
+}
+
+// Params returns the effective parameters, with defaults applied.
+func (t *TOTP) Params() Params { return t.hotp.params }
+
+// Step returns the time-step counter for at.
+func (t *TOTP) Step(at time.Time) uint64 {
+	secs := at.Unix()
+	if secs < 0 {
+		return 0
+	}
+	return uint64(secs) / uint64(t.hotp.params.Period/time.Second)
+}
+
+// Generate returns the code valid at the given time.
+func (t *TOTP) Generate(at time.Time) string {
+	return t.hotp.Generate(t.Step(at))
+}
+
+// Verify checks code against the time steps within Params.Window of at and
+// returns the matching step. Callers should persist the step and reject any
+// later code whose step is not greater, as RFC 6238 section 5.2 requires that
+// a code is accepted only once.
+func (t *TOTP) Verify(code string, at time.Time) (uint64, bool) {
+	current := t.Step(at)
+	window := uint64(t.hotp.params.Window)
+	start := uint64(0)
+	if current > window {
+		start = current - window
+	}
+	next, ok := t.hotp.verifyRange(code, start, int(current+window-start))
+	if !ok {
+		return 0, false
+	}
+	return next - 1, true
+}
+
+// normalizeCode strips the spaces authenticator apps commonly insert between
+// digit groups.
+func normalizeCode(code string) string {
+	return strings.ReplaceAll(code, " ", "")
+}
@@ -0,0 +1,192 @@
+package otp
+
+import (
+	"encoding/base32"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind distinguishes counter-based and time-based keys in otpauth:// URIs.
+type Kind string
+
+const (
+	// KindHOTP marks a counter-based key (otpauth://hotp/...).
+	KindHOTP Kind = "hotp"
+	// KindTOTP marks a time-based key (otpauth://totp/...).
+	KindTOTP Kind = "totp"
+)
+
+var (
+	errInvalidURI     = errors.New("otp: invalid otpauth URI")
+	errInvalidKind    = errors.New("otp: unsupported OTP type")
+	errInvalidSecret  = errors.New("otp: invalid base32 secret")
+	errMissingSecret  = errors.New("otp: missing secret")
+	errIssuerMismatch = errors.New("otp: issuer parameter does not match label prefix")
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Key describes a provisioned OTP secret in the otpauth:// key URI format
+// popularised by Google Authenticator.
+type Key struct {
+	// Kind is KindHOTP or KindTOTP.
+	Kind Kind
+	// Issuer names the provider; it is emitted both as the label prefix and
+	// as the issuer parameter.
+	Issuer string
+	// Account names the user, for example an email address.
+	Account string
+	// Secret is the raw shared secret.
+	Secret []byte
+	// Params holds the algorithm, digits and (TOTP only) period. Window is
+	// not part of the URI format and is ignored.
+	Params Params
+	// Counter is the initial HOTP counter; it is ignored for TOTP keys.
+	Counter uint64
+}
+
+// EncodeSecret returns the unpadded base32 form of secret used in URIs and
+// for manual entry.
+func EncodeSecret(secret []byte) string {
+	return secretEncoding.EncodeToString(secret)
+}
+
+// DecodeSecret parses a base32 secret, tolerating lower case, spaces and
+// trailing padding.
+func DecodeSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	s = strings.TrimRight(s, "=")
+	secret, err := secretEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidSecret
+	}
+	return secret, nil
+}
+
+// URI returns the otpauth:// representation of k. Parameters left at their
+// defaults are still written so that every authenticator interprets the key
+// identically.
+func (k *Key) URI() (string, error) {
+	if k.Kind != KindHOTP && k.Kind != KindTOTP {
+		return "", errInvalidKind
+	}
+	if len(k.Secret) == 0 {
+		return "", errMissingSecret
+	}
+	p, err := k.Params.withDefaults()
+	if err != nil {
+		return "", err
+	}
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+	q := make([]string, 0, 5)
+	q = append(q, "secret="+EncodeSecret(k.Secret))
+	if k.Issuer != "" {
+		q = append(q, "issuer="+url.QueryEscape(k.Issuer))
+	}
+	q = append(q, "algorithm="+p.Algorithm.String(), "digits="+strconv.Itoa(p.Digits))
+	if k.Kind == KindHOTP {
+		q = append(q, "counter="+strconv.FormatUint(k.Counter, 10))
+	} else {
+		q = append(q, "period="+strconv.FormatInt(int64(p.Period/time.Second), 10))
+	}
+	u := url.URL{Scheme: "otpauth", Host: string(k.Kind), Path: "/" + label}
+	return u.String() + "?" + strings.Join(q, "&"), nil
+}
+
+// ParseURI parses an otpauth:// key URI. Missing algorithm, digits and period
+// parameters take the RFC defaults; an issuer parameter, when present, must
+// agree with the label prefix.
+func ParseURI(uri string) (*Key, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "otpauth" {
+		return nil, errInvalidURI
+	}
+	k := &Key{Kind: Kind(strings.ToLower(u.Host))}
+	if k.Kind != KindHOTP && k.Kind != KindTOTP {
+		return nil, errInvalidKind
+	}
+	label := strings.TrimPrefix(u.Path, "/")
+	if i := strings.Index(label, ":"); i >= 0 {
+		k.Issuer = strings.TrimSpace(label[:i])
+		k.Account = strings.TrimSpace(label[i+1:])
+	} else {
+		k.Account = label
+	}
+	q := u.Query()
+	if issuer := q.Get("issuer"); issuer != "" {
+		if k.Issuer != "" && k.Issuer != issuer {
+			return nil, errIssuerMismatch
+		}
+		k.Issuer = issuer
+	}
+	raw := q.Get("secret")
+	if raw == "" {
+		return nil, errMissingSecret
+	}
+	if k.Secret, err = DecodeSecret(raw); err != nil {
+		return nil, err
+	}
+	switch strings.ToUpper(q.Get("algorithm")) {
+	case "", "SHA1":
+		k.Params.Algorithm = SHA1
+	case "SHA256":
+		k.Params.Algorithm = SHA256
+	case "SHA512":
+		k.Params.Algorithm = SHA512
+	default:
+		return nil, errUnknownAlg
+	}
+	if v := q.Get("digits"); v != "" {
+		if k.Params.Digits, err = strconv.Atoi(v); err != nil {
+			return nil, errInvalidDigits
+		}
+	}
+	if v := q.Get("period"); v != "" && k.Kind == KindTOTP {
+		secs, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return nil, errInvalidPeriod
+		}
+		k.Params.Period = time.Duration(secs) * time.Second
+	}
+	if k.Kind == KindHOTP {
+		v := q.Get("counter")
+		if v == "" {
+			return nil, errInvalidURI
+		}
+		if k.Counter, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, errInvalidURI
+		}
+	}
+	if k.Params, err = k.Params.withDefaults(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// HOTP returns an HOTP instance for a counter-based key. window sets the
+// look-ahead used by Verify.
+func (k *Key) HOTP(window int) (*HOTP, error) {
+	if k.Kind != KindHOTP {
+		return nil, errInvalidKind
+	}
+	p := k.Params
+	p.Window = window
+	return NewHOTP(k.Secret, p)
+}
+
+// TOTP returns a TOTP instance for a time-based key. window sets the number
+// of steps tolerated on either side of the current one.
+func (k *Key) TOTP(window int) (*TOTP, error) {
+	if k.Kind != KindTOTP {
+		return nil, errInvalidKind
+	}
+	p := k.Params
+	p.Window = window
+	return NewTOTP(k.Secret, p)
+}
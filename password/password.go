@@ -0,0 +1,399 @@
+// Package password hashes and verifies passwords for storage using PHC
+// string encodings, so a single string carries the algorithm, its cost
+// parameters, the salt and the hash.
+//
+// Hash and Policy.Hash produce strings in the formats used by the argon2
+// reference CLI, libsodium and passlib:
+//
+//	$argon2id$v=19$m=65536,t=2,p=1$<salt>$<hash>
+//	$scrypt$ln=17,r=8,p=1$<salt>$<hash>
+//	$pbkdf2-sha256$600000$<salt>$<hash>
+//
+// Verify accepts any of these (plus $argon2i$ and $argon2d$, and legacy
+// $2a$/$2b$/$2y$ bcrypt strings) and compares the recomputed hash in
+// constant time. NeedsRehash reports whether a stored string was produced
+// with a different algorithm or different parameters than the policy, so
+// applications can upgrade hashes transparently after a successful login;
+// bcrypt strings always need a rehash.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/AeonDave/cryptonite-go/kdf"
+)
+
+// Algorithm names a password hashing function by its PHC identifier.
+type Algorithm string
+
+const (
+	Argon2id     Algorithm = "argon2id"
+	Argon2i      Algorithm = "argon2i"
+	Argon2d      Algorithm = "argon2d"
+	Scrypt       Algorithm = "scrypt"
+	PBKDF2SHA256 Algorithm = "pbkdf2-sha256"
+)
+
+const (
+	argon2Version = 19
+
+	defaultSaltLength = 16
+	defaultKeyLength  = 32
+	minSaltLength     = 8
+	minKeyLength      = 4
+	maxScryptLogN     = 31
+)
+
+var (
+	errEmptyPassword = errors.New("password: password must not be empty")
+	errAlgorithm     = errors.New("password: unsupported algorithm")
+	errFormat        = errors.New("password: malformed hash string")
+	errVersion       = errors.New("password: unsupported argon2 version")
+	errParams        = errors.New("password: invalid cost parameters")
+)
+
+// Policy selects the algorithm and cost parameters used to hash new
+// passwords. Zero fields take the defaults noted below; only the fields of
+// the selected algorithm are used.
+type Policy struct {
+	// Algorithm defaults to Argon2id.
+	Algorithm Algorithm
+
+	// Time, MemoryKiB and Threads are the Argon2 costs (defaults 2, 64 MiB
+	// and 1).
+	Time      uint32
+	MemoryKiB uint32
+	Threads   uint32
+
+	// ScryptLogN is log2 of the scrypt cost N (default 17); ScryptR and
+	// ScryptP default to 8 and 1.
+	ScryptLogN uint8
+	ScryptR    int
+	ScryptP    int
+
+	// Iterations is the PBKDF2 iteration count (default 600000).
+	Iterations int
+
+	// SaltLength and KeyLength are the salt and hash sizes in bytes
+	// (defaults 16 and 32).
+	SaltLength int
+	KeyLength  int
+}
+
+// Policy presets. Interactive, Moderate and Sensitive match libsodium's
+// crypto_pwhash Argon2id limits; the scrypt and PBKDF2 presets follow the
+// OWASP password storage recommendations.
+var (
+	Interactive = Policy{Algorithm: Argon2id, Time: 2, MemoryKiB: 64 * 1024, Threads: 1}
+	Moderate    = Policy{Algorithm: Argon2id, Time: 3, MemoryKiB: 256 * 1024, Threads: 1}
+	Sensitive   = Policy{Algorithm: Argon2id, Time: 4, MemoryKiB: 1024 * 1024, Threads: 1}
+
+	ScryptDefault       = Policy{Algorithm: Scrypt, ScryptLogN: 17, ScryptR: 8, ScryptP: 1}
+	PBKDF2SHA256Default = Policy{Algorithm: PBKDF2SHA256, Iterations: 600_000}
+)
+
+// DefaultPolicy is the policy used by the package-level Hash and
+// NeedsRehash.
+var DefaultPolicy = Interactive
+
+// Hash hashes password with DefaultPolicy and a random salt.
+func Hash(password []byte) (string, error) {
+	return DefaultPolicy.Hash(password)
+}
+
+// NeedsRehash reports whether encoded differs from DefaultPolicy.
+func NeedsRehash(encoded string) bool {
+	return DefaultPolicy.NeedsRehash(encoded)
+}
+
+// Verify reports whether password matches the PHC string encoded. Malformed
+// or unsupported strings never match.
+func Verify(password []byte, encoded string) bool {
+	if strings.HasPrefix(encoded, "$2") {
+		return kdf.BcryptVerify(password, encoded) == nil
+	}
+	h, err := decode(encoded)
+	if err != nil {
+		return false
+	}
+	got, err := h.derive(password, len(h.key))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, h.key) == 1
+}
+
+// Hash hashes password under p with a fresh random salt and returns the PHC
+// string.
+func (p Policy) Hash(password []byte) (string, error) {
+	if len(password) == 0 {
+		return "", errEmptyPassword
+	}
+	h, err := p.withDefaults().params()
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(rand.Reader, h.salt); err != nil {
+		return "", err
+	}
+	if h.key, err = h.derive(password, len(h.key)); err != nil {
+		return "", err
+	}
+	return h.encode(), nil
+}
+
+// NeedsRehash reports whether encoded should be recomputed under p: it is
+// malformed, uses another algorithm or version, or its cost parameters, salt
+// or hash length differ from the policy.
+func (p Policy) NeedsRehash(encoded string) bool {
+	h, err := decode(encoded)
+	if err != nil {
+		return true
+	}
+	want, err := p.withDefaults().params()
+	if err != nil {
+		return true
+	}
+	return h.alg != want.alg || h.time != want.time || h.memory != want.memory ||
+		h.threads != want.threads || h.logN != want.logN || h.r != want.r || h.p != want.p ||
+		h.iterations != want.iterations || len(h.salt) != len(want.salt) || len(h.key) != len(want.key)
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.Algorithm == "" {
+		p.Algorithm = Argon2id
+	}
+	if p.Time == 0 {
+		p.Time = 2
+	}
+	if p.MemoryKiB == 0 {
+		p.MemoryKiB = 64 * 1024
+	}
+	if p.Threads == 0 {
+		p.Threads = 1
+	}
+	if p.ScryptLogN == 0 {
+		p.ScryptLogN = 17
+	}
+	if p.ScryptR == 0 {
+		p.ScryptR = 8
+	}
+	if p.ScryptP == 0 {
+		p.ScryptP = 1
+	}
+	if p.Iterations == 0 {
+		p.Iterations = 600_000
+	}
+	if p.SaltLength == 0 {
+		p.SaltLength = defaultSaltLength
+	}
+	if p.KeyLength == 0 {
+		p.KeyLength = defaultKeyLength
+	}
+	return p
+}
+
+// params converts p into a hash description with zeroed salt and key
+// buffers of the configured sizes.
+func (p Policy) params() (*phcHash, error) {
+	if p.SaltLength < minSaltLength || p.KeyLength < minKeyLength {
+		return nil, errParams
+	}
+	h := &phcHash{alg: p.Algorithm, salt: make([]byte, p.SaltLength), key: make([]byte, p.KeyLength)}
+	switch p.Algorithm {
+	case Argon2id, Argon2i, Argon2d:
+		h.version, h.time, h.memory, h.threads = argon2Version, p.Time, p.MemoryKiB, p.Threads
+	case Scrypt:
+		h.logN, h.r, h.p = p.ScryptLogN, p.ScryptR, p.ScryptP
+	case PBKDF2SHA256:
+		h.iterations = p.Iterations
+	default:
+		return nil, errAlgorithm
+	}
+	return h, h.validate()
+}
+
+// phcHash is a parsed PHC string. Only the fields of alg are set.
+type phcHash struct {
+	alg Algorithm
+
+	version               int
+	time, memory, threads uint32
+
+	logN uint8
+	r, p int
+
+	iterations int
+
+	salt, key []byte
+}
+
+func (h *phcHash) validate() error {
+	switch h.alg {
+	case Argon2id, Argon2i, Argon2d:
+		if h.version != argon2Version {
+			return errVersion
+		}
+		if h.time == 0 || h.threads == 0 || h.threads > 1<<24-1 || h.memory < 8*h.threads {
+			return errParams
+		}
+	case Scrypt:
+		if h.logN == 0 || h.logN > maxScryptLogN || h.r <= 0 || h.p <= 0 || uint64(h.r)*uint64(h.p) >= 1<<30 {
+			return errParams
+		}
+	case PBKDF2SHA256:
+		if h.iterations <= 0 {
+			return errParams
+		}
+	default:
+		return errAlgorithm
+	}
+	return nil
+}
+
+func (h *phcHash) derive(password []byte, keyLen int) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, errEmptyPassword
+	}
+	switch h.alg {
+	case Argon2id:
+		return kdf.Argon2id(password, h.salt, h.time, h.memory, h.threads, keyLen)
+	case Argon2i:
+		return kdf.Argon2i(password, h.salt, h.time, h.memory, h.threads, keyLen)
+	case Argon2d:
+		return kdf.Argon2d(password, h.salt, h.time, h.memory, h.threads, keyLen)
+	case Scrypt:
+		return kdf.Scrypt(password, h.salt, 1<<h.logN, h.r, h.p, keyLen)
+	case PBKDF2SHA256:
+		return kdf.PBKDF2SHA256(password, h.salt, h.iterations, keyLen)
+	}
+	return nil, errAlgorithm
+}
+
+// b64 is the PHC encoding: standard alphabet without padding. passlib's
+// pbkdf2 hashes substitute '.' for '+'.
+var b64 = base64.RawStdEncoding
+
+func (h *phcHash) encode() string {
+	var b strings.Builder
+	b.WriteString("$" + string(h.alg) + "$")
+	salt, key := b64.EncodeToString(h.salt), b64.EncodeToString(h.key)
+	switch h.alg {
+	case Argon2id, Argon2i, Argon2d:
+		b.WriteString("v=" + strconv.Itoa(h.version) + "$m=" + strconv.FormatUint(uint64(h.memory), 10) +
+			",t=" + strconv.FormatUint(uint64(h.time), 10) + ",p=" + strconv.FormatUint(uint64(h.threads), 10))
+	case Scrypt:
+		b.WriteString("ln=" + strconv.Itoa(int(h.logN)) + ",r=" + strconv.Itoa(h.r) + ",p=" + strconv.Itoa(h.p))
+	case PBKDF2SHA256:
+		b.WriteString(strconv.Itoa(h.iterations))
+		salt, key = strings.ReplaceAll(salt, "+", "."), strings.ReplaceAll(key, "+", ".")
+	}
+	b.WriteString("$" + salt + "$" + key)
+	return b.String()
+}
+
+func decode(encoded string) (*phcHash, error) {
+	fields := strings.Split(encoded, "$")
+	if len(fields) < 5 || fields[0] != "" {
+		return nil, errFormat
+	}
+	h := &phcHash{alg: Algorithm(fields[1])}
+	var params map[string]uint64
+	var err error
+	switch h.alg {
+	case Argon2id, Argon2i, Argon2d:
+		if len(fields) != 6 || !strings.HasPrefix(fields[2], "v=") {
+			return nil, errFormat
+		}
+		v, err := parseUint(strings.TrimPrefix(fields[2], "v="), 1<<31-1)
+		if err != nil {
+			return nil, err
+		}
+		h.version = int(v)
+		if params, err = parseParams(fields[3], "m", "t", "p"); err != nil {
+			return nil, err
+		}
+		h.memory, h.time, h.threads = uint32(params["m"]), uint32(params["t"]), uint32(params["p"])
+	case Scrypt:
+		if len(fields) != 5 {
+			return nil, errFormat
+		}
+		if params, err = parseParams(fields[2], "ln", "r", "p"); err != nil {
+			return nil, err
+		}
+		if params["ln"] > maxScryptLogN {
+			return nil, errParams
+		}
+		h.logN, h.r, h.p = uint8(params["ln"]), int(params["r"]), int(params["p"])
+	case PBKDF2SHA256:
+		if len(fields) != 5 {
+			return nil, errFormat
+		}
+		n, err := parseUint(fields[2], 1<<31-1)
+		if err != nil {
+			return nil, err
+		}
+		h.iterations = int(n)
+	default:
+		return nil, errAlgorithm
+	}
+	n := len(fields)
+	if h.salt, err = decodeB64(fields[n-2]); err != nil {
+		return nil, err
+	}
+	if h.key, err = decodeB64(fields[n-1]); err != nil {
+		return nil, err
+	}
+	if len(h.salt) < minSaltLength || len(h.key) < minKeyLength {
+		return nil, errFormat
+	}
+	return h, h.validate()
+}
+
+// parseParams parses a comma-separated k=v list that must contain exactly
+// the given keys, in that order.
+func parseParams(s string, keys ...string) (map[string]uint64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != len(keys) {
+		return nil, errFormat
+	}
+	out := make(map[string]uint64, len(keys))
+	for i, part := range parts {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok || k != keys[i] {
+			return nil, errFormat
+		}
+		n, err := parseUint(v, 1<<32-1)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = n
+	}
+	return out, nil
+}
+
+// parseUint parses a decimal without sign or leading zeros.
+func parseUint(s string, limit uint64) (uint64, error) {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return 0, errFormat
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n > limit {
+		return 0, errFormat
+	}
+	return n, nil
+}
+
+func decodeB64(s string) ([]byte, error) {
+	b, err := b64.Strict().DecodeString(strings.ReplaceAll(s, ".", "+"))
+	if err != nil {
+		return nil, errFormat
+	}
+	return b, nil
+}
@@ -0,0 +1,54 @@
+package csprng_test
+
+import (
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/csprng"
+)
+
+func makeBytes(length int, seed byte) []byte {
+	buf := make([]byte, length)
+	for i := range buf {
+		buf[i] = seed + byte(i)
+	}
+	return buf
+}
+
+func BenchmarkRead(b *testing.B) {
+	var seed [csprng.SeedSize]byte
+	copy(seed[:], makeBytes(csprng.SeedSize, 0x10))
+	for _, bc := range []struct {
+		name string
+		r    io.Reader
+	}{
+		{"Reader", csprng.Reader},
+		{"Seeded", csprng.NewSeeded(seed)},
+		{"crypto/rand", rand.Reader},
+	} {
+		for _, size := range []struct {
+			name string
+			n    int
+		}{{"16B", 16}, {"4KB", 4096}} {
+			buf := make([]byte, size.n)
+			b.Run(bc.name+"/"+size.name, func(b *testing.B) {
+				b.ReportAllocs()
+				b.SetBytes(int64(size.n))
+				for i := 0; i < b.N; i++ {
+					if _, err := bc.r.Read(buf); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkUint64(b *testing.B) {
+	g := csprng.NewSeeded([csprng.SeedSize]byte{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = g.Uint64()
+	}
+}
@@ -0,0 +1,137 @@
+package csprng_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/csprng"
+	"github.com/AeonDave/cryptonite-go/stream"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+// referenceStream models fast key erasure directly on top of the stream
+// package: each 768-byte ChaCha20 keystream under a zero nonce yields the
+// next key (first 32 bytes) and 736 output bytes.
+func referenceStream(t *testing.T, seed [csprng.SeedSize]byte, n int) []byte {
+	t.Helper()
+	key := seed[:]
+	var out []byte
+	for len(out) < n {
+		c, err := stream.NewChaCha20(key, make([]byte, 12), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 768)
+		c.KeyStream(buf)
+		key = buf[:32]
+		out = append(out, buf[32:]...)
+	}
+	return out[:n]
+}
+
+func TestSeededKnownAnswer(t *testing.T) {
+	// RFC 8439 Section A.1 test vector #1 (all-zero key and nonce), block 0
+	// bytes 32..63: the first output after the key is replaced.
+	want := testutil.MustHex(t, "da41597c5157488d7724e03fb8d84a376a43b8f41518a11cc387b669b2ee6586")
+	got := make([]byte, len(want))
+	csprng.NewSeeded([csprng.SeedSize]byte{}).Read(got)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("first output %x, want %x", got, want)
+	}
+}
+
+func TestSeededMatchesReference(t *testing.T) {
+	var seed [csprng.SeedSize]byte
+	copy(seed[:], "fast-key-erasure reference seed")
+	want := referenceStream(t, seed, 5000)
+
+	// Odd read sizes must not change the stream.
+	g := csprng.NewSeeded(seed)
+	got := make([]byte, 0, len(want))
+	for size := 1; len(got) < len(want); size = size*3 + 1 {
+		chunk := make([]byte, min(size, len(want)-len(got)))
+		if _, err := io.ReadFull(g, chunk); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, chunk...)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("seeded stream does not match the fast-key-erasure reference")
+	}
+
+	g.Seed(seed)
+	for i := 0; i < 10; i++ {
+		if v := g.Uint64(); v != binary.LittleEndian.Uint64(want[8*i:]) {
+			t.Fatalf("Uint64 #%d = %#x, want %#x", i, v, binary.LittleEndian.Uint64(want[8*i:]))
+		}
+	}
+}
+
+func TestMathRandSource(t *testing.T) {
+	seed := [csprng.SeedSize]byte{1, 2, 3}
+	a := rand.New(csprng.NewSeeded(seed))
+	b := rand.New(csprng.NewSeeded(seed))
+	for i := 0; i < 100; i++ {
+		if x, y := a.IntN(1000), b.IntN(1000); x != y {
+			t.Fatalf("draw %d: %d != %d", i, x, y)
+		}
+	}
+	c := rand.New(csprng.NewSeeded([csprng.SeedSize]byte{4, 5, 6}))
+	if a.Uint64() == c.Uint64() && a.Uint64() == c.Uint64() {
+		t.Fatal("different seeds produced the same stream")
+	}
+}
+
+func TestReseedingGenerators(t *testing.T) {
+	a, b := csprng.New(), csprng.New()
+	x, y := make([]byte, 64), make([]byte, 64)
+	if _, err := a.Read(x); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Read(y); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(x, y) {
+		t.Fatal("independently seeded generators produced the same output")
+	}
+	// Crossing the reseed interval keeps producing output.
+	big := make([]byte, csprng.ReseedInterval+4096)
+	if _, err := a.Read(big); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(big[len(big)-64:], make([]byte, 64)) {
+		t.Fatal("output after reseed is zero")
+	}
+}
+
+func TestReaderConcurrent(t *testing.T) {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = map[[16]byte]bool{}
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				var nonce [16]byte
+				if _, err := csprng.Read(nonce[:]); err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				if seen[nonce] {
+					t.Errorf("repeated output %x", nonce)
+				}
+				seen[nonce] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,44 @@
+package drbg_test
+
+import (
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/drbg"
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+)
+
+func makeBytes(length int, seed byte) []byte {
+	buf := make([]byte, length)
+	for i := range buf {
+		buf[i] = seed + byte(i)
+	}
+	return buf
+}
+
+func BenchmarkDRBGRead(b *testing.B) {
+	p := drbg.Params{EntropyInput: makeBytes(48, 0x42), Nonce: makeBytes(16, 0x99)}
+	hmacDRBG, _ := drbg.NewHMAC(cryptohash.NewSHA256, p)
+	hashDRBG, _ := drbg.NewHash(cryptohash.NewSHA256, p)
+	ctrDRBG, _ := drbg.NewCTR(p)
+	ctrNoDF, _ := drbg.NewCTRNoDF(drbg.Params{EntropyInput: makeBytes(48, 0x42)})
+	for _, bc := range []struct {
+		name string
+		d    drbg.DRBG
+	}{
+		{"HMAC-SHA256", hmacDRBG},
+		{"Hash-SHA256", hashDRBG},
+		{"CTR-AES256", ctrDRBG},
+		{"CTR-AES256-NoDF", ctrNoDF},
+	} {
+		buf := make([]byte, 4096)
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(buf)))
+			for i := 0; i < b.N; i++ {
+				if _, err := bc.d.Read(buf); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package duplex_test
+
+import (
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/duplex"
+)
+
+func makeBytes(length int, seed byte) []byte {
+	buf := make([]byte, length)
+	for i := range buf {
+		buf[i] = seed + byte(i)
+	}
+	return buf
+}
+
+func BenchmarkCyclist(b *testing.B) {
+	msg := makeBytes(1024, 0x33)
+	out := make([]byte, len(msg))
+	c, _ := duplex.NewCyclist(makeBytes(16, 0x01), nil, nil)
+	b.Run("Encrypt/1KB", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			if err := c.Encrypt(out, msg); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Absorb/1KB", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			c.Absorb(msg)
+		}
+	})
+	b.Run("Squeeze/1KB", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(out)))
+		for i := 0; i < b.N; i++ {
+			c.Squeeze(out)
+		}
+	})
+	b.Run("Ratchet", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := c.Ratchet(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkStrobe(b *testing.B) {
+	msg := makeBytes(1024, 0x44)
+	s := duplex.NewStrobe128([]byte("benchmark"))
+	s.Key(makeBytes(32, 0x01), false)
+	b.Run("SendENC/1KB", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			s.SendENC(msg, false)
+		}
+	})
+	b.Run("PRF/1KB", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			s.PRF(msg, false)
+		}
+	})
+}
+
+func BenchmarkMerlinChallenge(b *testing.B) {
+	msg := makeBytes(64, 0x55)
+	challenge := make([]byte, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tr := duplex.NewTranscript([]byte("benchmark"))
+		tr.AppendMessage([]byte("commitment"), msg)
+		tr.ChallengeBytes([]byte("challenge"), challenge)
+	}
+}
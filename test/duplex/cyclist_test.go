@@ -0,0 +1,190 @@
+package duplex_test
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/aead"
+	"github.com/AeonDave/cryptonite-go/duplex"
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+// cyclist_kat.json holds Cyclist session transcripts generated with an
+// independent Python transcription of the Xoodyak specification, which
+// reproduces every Xoodyak AEAD and hash LWC known-answer vector.
+//
+//go:embed testdata/cyclist_kat.json
+var cyclistKAT []byte
+
+type cyclistOp struct {
+	Op     string `json:"op"`
+	Input  string `json:"input"`
+	Output string `json:"output"`
+	Length int    `json:"length"`
+}
+
+type cyclistSession struct {
+	Comment string      `json:"comment"`
+	Key     string      `json:"key"`
+	ID      string      `json:"id"`
+	Counter string      `json:"counter"`
+	Ops     []cyclistOp `json:"ops"`
+}
+
+func TestCyclistSessions(t *testing.T) {
+	var kat struct {
+		Sessions []cyclistSession `json:"sessions"`
+	}
+	if err := json.Unmarshal(cyclistKAT, &kat); err != nil {
+		t.Fatalf("parse vectors: %v", err)
+	}
+	for _, s := range kat.Sessions {
+		c := duplex.NewCyclistHash()
+		if key := testutil.MustHex(t, s.Key); len(key) > 0 {
+			var err error
+			c, err = duplex.NewCyclist(key, testutil.MustHex(t, s.ID), testutil.MustHex(t, s.Counter))
+			if err != nil {
+				t.Fatalf("%s: %v", s.Comment, err)
+			}
+		}
+		for i, op := range s.Ops {
+			in := testutil.MustHex(t, op.Input)
+			want := testutil.MustHex(t, op.Output)
+			var got []byte
+			var err error
+			switch op.Op {
+			case "absorb":
+				c.Absorb(in)
+			case "encrypt":
+				got = make([]byte, len(in))
+				err = c.Encrypt(got, in)
+			case "decrypt":
+				got = make([]byte, len(in))
+				err = c.Decrypt(got, in)
+			case "squeeze":
+				got = make([]byte, op.Length)
+				c.Squeeze(got)
+			case "squeeze_key":
+				got = make([]byte, op.Length)
+				err = c.SqueezeKey(got)
+			case "ratchet":
+				err = c.Ratchet()
+			default:
+				t.Fatalf("%s: unknown op %q", s.Comment, op.Op)
+			}
+			if err != nil {
+				t.Fatalf("%s op %d (%s): %v", s.Comment, i, op.Op, err)
+			}
+			if len(want) > 0 && !bytes.Equal(got, want) {
+				t.Fatalf("%s op %d (%s): got %x, want %x", s.Comment, i, op.Op, got, want)
+			}
+		}
+	}
+}
+
+func TestCyclistMatchesXoodyakModes(t *testing.T) {
+	key := makeBytes(16, 0x01)
+	nonce := makeBytes(16, 0x21)
+	ad := []byte("associated data")
+	pt := makeBytes(100, 0x41)
+
+	c, err := duplex.NewCyclist(key, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Absorb(nonce)
+	c.Absorb(ad)
+	got := make([]byte, len(pt)+16)
+	if err := c.Encrypt(got[:len(pt)], pt); err != nil {
+		t.Fatal(err)
+	}
+	c.Squeeze(got[len(pt):])
+	want, err := aead.NewXoodyak().Encrypt(key, nonce, ad, pt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("keyed Cyclist does not reproduce Xoodyak AEAD")
+	}
+
+	d, _ := duplex.NewCyclist(key, nil, nil)
+	d.Absorb(nonce)
+	d.Absorb(ad)
+	// In-place decryption.
+	buf := append([]byte(nil), got[:len(pt)]...)
+	if err := d.Decrypt(buf, buf); err != nil {
+		t.Fatal(err)
+	}
+	tag := make([]byte, 16)
+	d.Squeeze(tag)
+	if !bytes.Equal(buf, pt) || !bytes.Equal(tag, got[len(pt):]) {
+		t.Fatal("decryption does not mirror encryption")
+	}
+
+	h := duplex.NewCyclistHash()
+	h.Absorb(pt)
+	digest := make([]byte, 32)
+	h.Squeeze(digest)
+	hasher := cryptohash.NewXoodyak()
+	hasher.Write(pt)
+	if !bytes.Equal(digest, hasher.Sum(nil)) {
+		t.Fatal("unkeyed Cyclist does not reproduce Xoodyak-Hash")
+	}
+}
+
+func TestCyclistClone(t *testing.T) {
+	c, _ := duplex.NewCyclist(makeBytes(16, 0x10), []byte("id"), nil)
+	c.Absorb([]byte("shared prefix"))
+	fork := c.Clone()
+
+	a, b := make([]byte, 16), make([]byte, 16)
+	c.Squeeze(a)
+	fork.Squeeze(b)
+	if !bytes.Equal(a, b) {
+		t.Fatal("clone diverged before any new input")
+	}
+	c.Absorb([]byte("left"))
+	fork.Absorb([]byte("right"))
+	c.Squeeze(a)
+	fork.Squeeze(b)
+	if bytes.Equal(a, b) {
+		t.Fatal("clone shares state with the original")
+	}
+}
+
+func TestCyclistErrors(t *testing.T) {
+	if _, err := duplex.NewCyclist(nil, nil, nil); err == nil {
+		t.Fatal("expected error for empty key")
+	}
+	if _, err := duplex.NewCyclist(make([]byte, 40), make([]byte, 4), nil); err == nil {
+		t.Fatal("expected error for oversized key and id")
+	}
+	h := duplex.NewCyclistHash()
+	if h.Keyed() {
+		t.Fatal("hash Cyclist reports keyed mode")
+	}
+	buf := make([]byte, 8)
+	if err := h.Encrypt(buf, buf); err == nil {
+		t.Fatal("expected error encrypting in hash mode")
+	}
+	if err := h.Decrypt(buf, buf); err == nil {
+		t.Fatal("expected error decrypting in hash mode")
+	}
+	if err := h.SqueezeKey(buf); err == nil {
+		t.Fatal("expected error deriving keys in hash mode")
+	}
+	if err := h.Ratchet(); err == nil {
+		t.Fatal("expected error ratcheting in hash mode")
+	}
+	k, _ := duplex.NewCyclist(make([]byte, 16), nil, nil)
+	if err := k.Encrypt(buf[:4], buf); err == nil {
+		t.Fatal("expected error for mismatched lengths")
+	}
+	k.Clear()
+	if k.Keyed() {
+		t.Fatal("Clear left the Cyclist keyed")
+	}
+}
@@ -0,0 +1,106 @@
+package duplex_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"testing/iotest"
+
+	"github.com/AeonDave/cryptonite-go/duplex"
+)
+
+// The simple and complex transcripts are the equivalence tests of the Rust
+// merlin crate; the u64 and RNG expectations were generated with the
+// curve25519-voi Go port of merlin.
+
+func TestMerlinSimpleTranscript(t *testing.T) {
+	tr := duplex.NewTranscript([]byte("test protocol"))
+	tr.AppendMessage([]byte("some label"), []byte("some data"))
+	c := make([]byte, 32)
+	tr.ChallengeBytes([]byte("challenge"), c)
+	if got := hex.EncodeToString(c); got != "d5a21972d0d5fe320c0d263fac7fffb8145aa640af6e9bca177c03c7efcf0615" {
+		t.Fatalf("challenge %s", got)
+	}
+}
+
+func TestMerlinComplexTranscript(t *testing.T) {
+	tr := duplex.NewTranscript([]byte("test protocol"))
+	tr.AppendMessage([]byte("step1"), []byte("some data"))
+	data := bytes.Repeat([]byte{99}, 1024)
+	c := make([]byte, 32)
+	for i := 0; i < 32; i++ {
+		tr.ChallengeBytes([]byte("challenge"), c)
+		tr.AppendMessage([]byte("bigdata"), data)
+		tr.AppendMessage([]byte("challengedata"), c)
+	}
+	if got := hex.EncodeToString(c); got != "a8c933f54fae76e3f9bea93648c1308e7dfa2152dd51674ff3ca438351cf003c" {
+		t.Fatalf("challenge %s", got)
+	}
+}
+
+func TestMerlinUint64AndRNG(t *testing.T) {
+	tr := duplex.NewTranscript([]byte("test protocol"))
+	tr.AppendMessage([]byte("com"), []byte("commitment data"))
+	tr.AppendUint64([]byte("u64"), 42)
+	c := make([]byte, 32)
+	tr.ChallengeBytes([]byte("challenge"), c)
+	if got := hex.EncodeToString(c); got != "552f1e5633aaf9500b6ed40224735c4f6d6ac47a020281771f73787f0e0b873a" {
+		t.Fatalf("challenge %s", got)
+	}
+
+	fork := tr.Clone()
+	rng, err := tr.BuildRNG().
+		RekeyWithWitnessBytes([]byte("witness"), []byte("witness data")).
+		RekeyWithWitnessBytes([]byte("nonce"), []byte("second")).
+		Finalize(bytes.NewReader(makeBytes(32, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := make([]byte, 96)
+	rng.Read(out[:32])
+	rng.Read(out[32:])
+	want := "4091075f3f82fdcc43ae71173ed3ecec2df7421e93ae8ab11c6be176683b4d33" +
+		"2b2401a232a08e14b2fffa20030bd29a57cb6fa62a34c77089cf857555be82df" +
+		"1b5a8d3a7706cb012faa23f00864878c18993889b0885604100dbe5cd97240c2"
+	if got := hex.EncodeToString(out); got != want {
+		t.Fatalf("rng output %s", got)
+	}
+
+	// Building the RNG forks the transcript without modifying it.
+	a, b := make([]byte, 32), make([]byte, 32)
+	tr.ChallengeBytes([]byte("next"), a)
+	fork.ChallengeBytes([]byte("next"), b)
+	if !bytes.Equal(a, b) {
+		t.Fatal("BuildRNG modified the transcript")
+	}
+}
+
+func TestMerlinRNGBinding(t *testing.T) {
+	build := func(commitment, witness []byte) []byte {
+		tr := duplex.NewTranscript([]byte("rng binding"))
+		tr.AppendMessage([]byte("com"), commitment)
+		rng, err := tr.BuildRNG().RekeyWithWitnessBytes([]byte("witness"), witness).Finalize(bytes.NewReader(make([]byte, 32)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := make([]byte, 32)
+		rng.Read(out)
+		return out
+	}
+	s1 := build([]byte("commitment 1"), []byte("witness 1"))
+	s2 := build([]byte("commitment 2"), []byte("witness 1"))
+	s3 := build([]byte("commitment 2"), []byte("witness 2"))
+	s4 := build([]byte("commitment 2"), []byte("witness 2"))
+	if bytes.Equal(s1, s2) || bytes.Equal(s2, s3) || !bytes.Equal(s3, s4) {
+		t.Fatal("RNG output not bound to transcript and witness")
+	}
+
+	tr := duplex.NewTranscript([]byte("rng binding"))
+	if _, err := tr.BuildRNG().Finalize(iotest.ErrReader(errors.New("no entropy"))); err == nil {
+		t.Fatal("expected error from the external RNG")
+	}
+	if _, err := tr.BuildRNG().Finalize(nil); err != nil {
+		t.Fatal(err)
+	}
+}
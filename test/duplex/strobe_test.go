@@ -0,0 +1,168 @@
+package duplex_test
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/duplex"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+// strobe_kat.json converts the STROBE v1.0.2 known-answer transcripts used
+// by StrobeGo and strobe-rs (simple, meta, streaming and boundary tests) for
+// STROBE-128, and the same transcripts replayed through StrobeGo at the
+// 256-bit security level.
+//
+//go:embed testdata/strobe_kat.json
+var strobeKAT []byte
+
+type strobeOp struct {
+	Op       string `json:"op"`
+	Meta     bool   `json:"meta"`
+	More     bool   `json:"more"`
+	Input    string `json:"input"`
+	Length   int    `json:"length"`
+	Output   string `json:"output"`
+	MACValid *bool  `json:"mac_valid"`
+}
+
+type strobeVector struct {
+	Name       string     `json:"name"`
+	Security   int        `json:"security"`
+	Protocol   string     `json:"protocol"`
+	Operations []strobeOp `json:"operations"`
+}
+
+var strobeOps = map[string]duplex.Flags{
+	"AD":       duplex.OpAD,
+	"KEY":      duplex.OpKey,
+	"PRF":      duplex.OpPRF,
+	"send_CLR": duplex.OpSendCLR,
+	"recv_CLR": duplex.OpRecvCLR,
+	"send_ENC": duplex.OpSendENC,
+	"recv_ENC": duplex.OpRecvENC,
+	"send_MAC": duplex.OpSendMAC,
+	"recv_MAC": duplex.OpRecvMAC,
+	"RATCHET":  duplex.OpRatchet,
+}
+
+func TestStrobeVectors(t *testing.T) {
+	var kat struct {
+		Vectors []strobeVector `json:"vectors"`
+	}
+	if err := json.Unmarshal(strobeKAT, &kat); err != nil {
+		t.Fatalf("parse vectors: %v", err)
+	}
+	for _, v := range kat.Vectors {
+		s := duplex.NewStrobe128([]byte(v.Protocol))
+		if v.Security == 256 {
+			s = duplex.NewStrobe256([]byte(v.Protocol))
+		}
+		for i, op := range v.Operations {
+			flags, ok := strobeOps[op.Op]
+			if !ok {
+				t.Fatalf("%s/%d: unknown op %q", v.Name, v.Security, op.Op)
+			}
+			if op.Meta {
+				flags |= duplex.FlagM
+			}
+			data := testutil.MustHex(t, op.Input)
+			if op.Length > 0 {
+				data = make([]byte, op.Length)
+			}
+			err := s.Operate(flags, data, op.More)
+			if op.MACValid != nil {
+				if (err == nil) != *op.MACValid {
+					t.Fatalf("%s/%d op %d: recv_MAC err = %v, want valid=%v", v.Name, v.Security, i, err, *op.MACValid)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s/%d op %d (%s): %v", v.Name, v.Security, i, op.Op, err)
+			}
+			if want := testutil.MustHex(t, op.Output); len(want) > 0 && !bytes.Equal(data, want) {
+				t.Fatalf("%s/%d op %d (%s): got %x, want %x", v.Name, v.Security, i, op.Op, data, want)
+			}
+		}
+	}
+}
+
+func TestStrobeSession(t *testing.T) {
+	for _, newStrobe := range []func([]byte) *duplex.Strobe{duplex.NewStrobe128, duplex.NewStrobe256} {
+		alice, bob := newStrobe([]byte("session test")), newStrobe([]byte("session test"))
+		key := makeBytes(32, 0x07)
+		alice.Key(key, false)
+		bob.Key(key, false)
+		if !bytes.Equal(key, makeBytes(32, 0x07)) {
+			t.Fatal("Key modified its input")
+		}
+
+		msg := []byte("a message streamed in two pieces")
+		wire := append([]byte(nil), msg...)
+		alice.SendENC(wire[:10], false)
+		alice.SendENC(wire[10:], true)
+		mac := make([]byte, 16)
+		alice.SendMAC(mac, false)
+
+		got := append([]byte(nil), wire...)
+		bob.RecvENC(got, false)
+		if !bytes.Equal(got, msg) {
+			t.Fatal("RecvENC did not recover the plaintext")
+		}
+		if err := bob.RecvMAC(mac); err != nil {
+			t.Fatalf("RecvMAC: %v", err)
+		}
+
+		// Roles are fixed by the first transport operation, so the reply
+		// flows the other way.
+		reply := []byte("ack")
+		bob.SendCLR(reply, false)
+		alice.RecvCLR(reply, false)
+		a, b := make([]byte, 32), make([]byte, 32)
+		alice.PRF(a, false)
+		bob.PRF(b, false)
+		if !bytes.Equal(a, b) {
+			t.Fatal("peers diverged after a cleartext exchange")
+		}
+
+		fork := alice.Clone()
+		alice.Ratchet(32, false)
+		fork.Ratchet(16, false)
+		alice.SendMAC(a, false)
+		fork.SendMAC(b, false)
+		if bytes.Equal(a, b) {
+			t.Fatal("clone shares state with the original")
+		}
+
+		bad := append([]byte(nil), mac...)
+		bad[0] ^= 1
+		c := newStrobe([]byte("session test"))
+		c.Key(key, false)
+		c.RecvENC(append([]byte(nil), wire...), false)
+		if err := c.RecvMAC(bad); err == nil {
+			t.Fatal("RecvMAC accepted a forged MAC")
+		}
+	}
+}
+
+func TestStrobeMisuse(t *testing.T) {
+	s := duplex.NewStrobe128([]byte("misuse"))
+	if err := s.Operate(duplex.FlagI, nil, false); err == nil {
+		t.Fatal("expected error for an unknown operation")
+	}
+	s.AD([]byte("x"), false)
+	if err := s.Operate(duplex.OpKey, []byte("y"), true); err == nil {
+		t.Fatal("expected error continuing with different flags")
+	}
+	if err := s.Operate(duplex.OpRecvMAC, make([]byte, 16), true); err == nil {
+		t.Fatal("expected error continuing a MAC check")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic continuing with different flags")
+		}
+	}()
+	s.PRF(make([]byte, 8), true)
+}
@@ -0,0 +1,199 @@
+package xoodyak_test
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+	"github.com/AeonDave/cryptonite-go/xof"
+)
+
+//go:embed testdata/bao_kat.txt
+var baoKAT string
+
+type baoEncodingVector struct {
+	n        int
+	root     []byte
+	combined []byte
+	outboard []byte
+}
+
+type baoSliceVector struct {
+	offset, length uint64
+	slice          []byte
+}
+
+func parseBaoKAT(t *testing.T) ([]baoEncodingVector, []baoSliceVector) {
+	t.Helper()
+	var encs []baoEncodingVector
+	var slices []baoSliceVector
+	for _, block := range strings.Split(strings.TrimSpace(baoKAT), "\n\n") {
+		fields := map[string]string{}
+		for _, line := range strings.Split(block, "\n") {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				t.Fatalf("invalid line %q", line)
+			}
+			fields[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		atoi := func(key string) uint64 {
+			v, err := strconv.ParseUint(fields[key], 10, 64)
+			if err != nil {
+				t.Fatalf("invalid %s: %v", key, err)
+			}
+			return v
+		}
+		if _, ok := fields["Len"]; ok {
+			encs = append(encs, baoEncodingVector{
+				n:        int(atoi("Len")),
+				root:     testutil.MustHex(t, fields["Root"]),
+				combined: testutil.MustHex(t, fields["Combined"]),
+				outboard: testutil.MustHex(t, fields["Outboard"]),
+			})
+			continue
+		}
+		slices = append(slices, baoSliceVector{
+			offset: atoi("SliceOffset"),
+			length: atoi("SliceLen"),
+			slice:  testutil.MustHex(t, fields["Slice"]),
+		})
+	}
+	return encs, slices
+}
+
+func TestBaoEncodingKAT(t *testing.T) {
+	encs, _ := parseBaoKAT(t)
+	for _, v := range encs {
+		input := blake3Input(v.n)
+		combined, root := cryptohash.BaoEncodeBuf(input, false)
+		if !bytes.Equal(root[:], v.root) || !bytes.Equal(root[:], cryptohash.SumBLAKE3(input)) {
+			t.Fatalf("len=%d root mismatch: %x", v.n, root)
+		}
+		if got := cryptohash.SumSHA256(combined); !bytes.Equal(got[:], v.combined) {
+			t.Fatalf("len=%d combined encoding mismatch", v.n)
+		}
+		outboard, _ := cryptohash.BaoEncodeBuf(input, true)
+		if got := cryptohash.SumSHA256(outboard); !bytes.Equal(got[:], v.outboard) {
+			t.Fatalf("len=%d outboard encoding mismatch", v.n)
+		}
+		if int64(len(combined)) != cryptohash.BaoEncodedSize(int64(v.n), false) {
+			t.Fatalf("len=%d unexpected combined size %d", v.n, len(combined))
+		}
+
+		decoded, err := io.ReadAll(cryptohash.NewBaoReader(bytes.NewReader(combined), root))
+		if err != nil || !bytes.Equal(decoded, input) {
+			t.Fatalf("len=%d combined decode failed: %v", v.n, err)
+		}
+		decoded, err = io.ReadAll(cryptohash.NewBaoOutboardReader(bytes.NewReader(input), bytes.NewReader(outboard), root))
+		if err != nil || !bytes.Equal(decoded, input) {
+			t.Fatalf("len=%d outboard decode failed: %v", v.n, err)
+		}
+	}
+}
+
+func TestBaoSliceKAT(t *testing.T) {
+	_, slices := parseBaoKAT(t)
+	input := blake3Input(100000)
+	combined, root := cryptohash.BaoEncodeBuf(input, false)
+	outboard, _ := cryptohash.BaoEncodeBuf(input, true)
+	for _, v := range slices {
+		var slice bytes.Buffer
+		if err := cryptohash.BaoExtractSlice(&slice, bytes.NewReader(combined), v.offset, v.length); err != nil {
+			t.Fatalf("extract %d+%d failed: %v", v.offset, v.length, err)
+		}
+		if got := cryptohash.SumSHA256(slice.Bytes()); !bytes.Equal(got[:], v.slice) {
+			t.Fatalf("slice %d+%d mismatch", v.offset, v.length)
+		}
+		var fromOutboard bytes.Buffer
+		if err := cryptohash.BaoExtractSliceOutboard(&fromOutboard, bytes.NewReader(input), bytes.NewReader(outboard), v.offset, v.length); err != nil {
+			t.Fatalf("outboard extract %d+%d failed: %v", v.offset, v.length, err)
+		}
+		if !bytes.Equal(fromOutboard.Bytes(), slice.Bytes()) {
+			t.Fatalf("outboard slice %d+%d differs from combined slice", v.offset, v.length)
+		}
+		got, err := io.ReadAll(cryptohash.NewBaoSliceReader(bytes.NewReader(slice.Bytes()), root, v.offset, v.length))
+		if err != nil || !bytes.Equal(got, input[v.offset:v.offset+v.length]) {
+			t.Fatalf("slice %d+%d verify failed: %v", v.offset, v.length, err)
+		}
+	}
+}
+
+func TestBaoRejectsCorruption(t *testing.T) {
+	// Pseudorandom content so every chunk can be located in the encoding.
+	input := make([]byte, 10*1024)
+	x := xof.BLAKE3()
+	_, _ = x.Read(input)
+	combined, root := cryptohash.BaoEncodeBuf(input, false)
+
+	// Flip a byte inside the fourth chunk: the first three chunks are
+	// released, then the reader fails before returning any corrupt byte.
+	corrupt := append([]byte(nil), combined...)
+	pos := bytes.Index(corrupt, input[3*1024:3*1024+64])
+	corrupt[pos+10] ^= 1
+	got, err := io.ReadAll(cryptohash.NewBaoReader(bytes.NewReader(corrupt), root))
+	if !errors.Is(err, cryptohash.ErrBaoVerify) {
+		t.Fatalf("expected ErrBaoVerify, got %v", err)
+	}
+	if !bytes.Equal(got, input[:3*1024]) {
+		t.Fatalf("released %d bytes before the corrupt chunk", len(got))
+	}
+
+	// A corrupt parent node is rejected before any content is released.
+	corrupt = append([]byte(nil), combined...)
+	corrupt[8] ^= 1
+	if got, err := io.ReadAll(cryptohash.NewBaoReader(bytes.NewReader(corrupt), root)); !errors.Is(err, cryptohash.ErrBaoVerify) || len(got) != 0 {
+		t.Fatalf("corrupt parent: got %d bytes, err %v", len(got), err)
+	}
+
+	// A forged length header changes the tree shape.
+	corrupt = append([]byte(nil), combined...)
+	corrupt[0]--
+	if _, err := io.ReadAll(cryptohash.NewBaoReader(bytes.NewReader(corrupt), root)); err == nil {
+		t.Fatal("forged length accepted")
+	}
+
+	if _, err := io.ReadAll(cryptohash.NewBaoReader(bytes.NewReader(combined[:len(combined)-1]), root)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected ErrUnexpectedEOF for truncated encoding, got %v", err)
+	}
+
+	wrongRoot := root
+	wrongRoot[0] ^= 1
+	if _, err := io.ReadAll(cryptohash.NewBaoReader(bytes.NewReader(combined), wrongRoot)); !errors.Is(err, cryptohash.ErrBaoVerify) {
+		t.Fatalf("expected ErrBaoVerify for wrong root, got %v", err)
+	}
+}
+
+func TestBaoZeroLengthSlice(t *testing.T) {
+	input := blake3Input(5000)
+	combined, root := cryptohash.BaoEncodeBuf(input, false)
+	for _, offset := range []uint64{0, 2500, 5000} {
+		var slice bytes.Buffer
+		if err := cryptohash.BaoExtractSlice(&slice, bytes.NewReader(combined), offset, 0); err != nil {
+			t.Fatalf("extract at %d failed: %v", offset, err)
+		}
+		// The chunk holding offset is carried to authenticate the length.
+		if slice.Len() <= 8 {
+			t.Fatalf("zero-length slice at %d carries no chunk", offset)
+		}
+		got, err := io.ReadAll(cryptohash.NewBaoSliceReader(&slice, root, offset, 0))
+		if err != nil || len(got) != 0 {
+			t.Fatalf("verify at %d: %d bytes, %v", offset, len(got), err)
+		}
+	}
+	var slice bytes.Buffer
+	if err := cryptohash.BaoExtractSlice(&slice, bytes.NewReader(combined), 4990, 11); err == nil {
+		t.Fatal("slice past the end accepted")
+	}
+}
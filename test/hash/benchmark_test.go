@@ -1,6 +1,9 @@
 package xoodyak_test
 
 import (
+	"bytes"
+	stdhash "hash"
+	"io"
 	"testing"
 
 	cryptohash "github.com/AeonDave/cryptonite-go/hash"
@@ -44,13 +47,16 @@ func BenchmarkHashers(b *testing.B) {
 		{"SHA-256", func() cryptohash.Hasher { return cryptohash.NewSHA256Hasher() }},
 		{"SHA-384", func() cryptohash.Hasher { return cryptohash.NewSHA384Hasher() }},
 		{"SHA-512", func() cryptohash.Hasher { return cryptohash.NewSHA512Hasher() }},
+		{"SHA-512/256", func() cryptohash.Hasher { return cryptohash.NewSHA512_256Hasher() }},
 		{"SHA3-224", func() cryptohash.Hasher { return cryptohash.NewSHA3224Hasher() }},
 		{"SHA3-256", func() cryptohash.Hasher { return cryptohash.NewSHA3256Hasher() }},
 		{"SHA3-384", func() cryptohash.Hasher { return cryptohash.NewSHA3384Hasher() }},
 		{"SHA3-512", func() cryptohash.Hasher { return cryptohash.NewSHA3512Hasher() }},
+		{"Keccak-256", func() cryptohash.Hasher { return cryptohash.NewKeccak256Hasher() }},
 		{"BLAKE2b-512", mustBlake2bHasher(64)},
 		{"BLAKE2s-256", mustBlake2sHasher(32)},
 		{"XoodyakHash", func() cryptohash.Hasher { return cryptohash.NewXoodyakHasher() }},
+		{"BLAKE3", cryptohash.NewBLAKE3Hasher},
 	}
 	for _, spec := range specs {
 		spec := spec
@@ -112,3 +118,96 @@ func BenchmarkSP800185(b *testing.B) {
 		}
 	})
 }
+
+func BenchmarkBLAKE3Large(b *testing.B) {
+	// 4 MiB inputs take the multi-goroutine chunk path.
+	msg := makeBytes(4<<20, 0x61)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(msg)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cryptohash.SumBLAKE3(msg)
+	}
+}
+
+func BenchmarkBlake2ParallelLarge(b *testing.B) {
+	// 4 MiB inputs are split into 64 KiB batches whose leaves run concurrently.
+	msg := makeBytes(4<<20, 0x65)
+	specs := []struct {
+		name string
+		ctor func(int, []byte) (cryptohash.Hasher, error)
+		size int
+	}{
+		{"BLAKE2b-512", cryptohash.NewBlake2bHasher, 64},
+		{"BLAKE2bp-512", cryptohash.NewBlake2bpHasher, 64},
+		{"BLAKE2s-256", cryptohash.NewBlake2sHasher, 32},
+		{"BLAKE2sp-256", cryptohash.NewBlake2spHasher, 32},
+	}
+	for _, spec := range specs {
+		spec := spec
+		b.Run(spec.name, func(b *testing.B) {
+			hasher, err := spec.ctor(spec.size, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.SetBytes(int64(len(msg)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = hasher.Hash(msg)
+			}
+		})
+	}
+}
+
+func BenchmarkBao(b *testing.B) {
+	msg := makeBytes(1<<20, 0x71)
+	enc, root := cryptohash.BaoEncodeBuf(msg, false)
+	b.Run("Encode", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			_, _ = cryptohash.BaoEncodeBuf(msg, false)
+		}
+	})
+	b.Run("Decode", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			r := cryptohash.NewBaoReader(bytes.NewReader(enc), root)
+			if _, err := io.Copy(io.Discard, r); err != nil {
+				b.Fatalf("decode failed: %v", err)
+			}
+		}
+	})
+}
+
+func BenchmarkMultiHash(b *testing.B) {
+	msg := makeBytes(4<<20, 0x29)
+	constructors := []func() stdhash.Hash{
+		cryptohash.NewSHA256,
+		cryptohash.NewSHA512,
+		newBlake2b512(b),
+		cryptohash.NewBLAKE3,
+	}
+	b.Run("SumReader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			if _, err := cryptohash.SumReader(bytes.NewReader(msg), constructors...); err != nil {
+				b.Fatalf("SumReader failed: %v", err)
+			}
+		}
+	})
+	b.Run("Sequential", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		for i := 0; i < b.N; i++ {
+			for _, newHash := range constructors {
+				h := newHash()
+				h.Write(msg)
+				_ = h.Sum(nil)
+			}
+		}
+	})
+}
@@ -0,0 +1,167 @@
+package xoodyak_test
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	stdhash "hash"
+	"testing"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+//go:embed testdata/blake2_params_kat.json
+var blake2ParamsKAT []byte
+
+type blake2ParamsCase struct {
+	Variant    string `json:"variant"`
+	Size       int    `json:"size"`
+	Key        string `json:"key"`
+	Salt       string `json:"salt"`
+	Personal   string `json:"personal"`
+	Fanout     uint8  `json:"fanout"`
+	MaxDepth   uint8  `json:"max_depth"`
+	LeafLength uint32 `json:"leaf_length"`
+	NodeOffset uint64 `json:"node_offset"`
+	NodeDepth  uint8  `json:"node_depth"`
+	InnerSize  uint8  `json:"inner_size"`
+	LastNode   bool   `json:"last_node"`
+	MsgLen     int    `json:"msg_len"`
+	MD         string `json:"md"`
+}
+
+func (tc blake2ParamsCase) tree() cryptohash.Blake2Tree {
+	return cryptohash.Blake2Tree{
+		Fanout:     tc.Fanout,
+		MaxDepth:   tc.MaxDepth,
+		LeafLength: tc.LeafLength,
+		NodeOffset: tc.NodeOffset,
+		NodeDepth:  tc.NodeDepth,
+		InnerSize:  tc.InnerSize,
+		LastNode:   tc.LastNode,
+	}
+}
+
+func blake2ParamsCases(t *testing.T) []blake2ParamsCase {
+	t.Helper()
+	var cases []blake2ParamsCase
+	if err := json.Unmarshal(blake2ParamsKAT, &cases); err != nil {
+		t.Fatalf("failed to decode vectors: %v", err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("no BLAKE2 parameter vectors")
+	}
+	return cases
+}
+
+func TestBlake2ParamsKAT(t *testing.T) {
+	for idx, tc := range blake2ParamsCases(t) {
+		key := testutil.MustHex(t, tc.Key)
+		msg := blake3Input(tc.MsgLen)
+		want := testutil.MustHex(t, tc.MD)
+
+		var newHash func() (stdhash.Hash, error)
+		var hasher cryptohash.Hasher
+		var err error
+		switch tc.Variant {
+		case "BLAKE2b":
+			b := cryptohash.NewBlake2bBuilder().Size(tc.Size).Key(key).
+				Salt(testutil.MustHex(t, tc.Salt)).Personal(testutil.MustHex(t, tc.Personal)).Tree(tc.tree())
+			newHash = b.Hash
+			hasher, err = b.Hasher()
+		case "BLAKE2s":
+			b := cryptohash.NewBlake2sBuilder().Size(tc.Size).Key(key).
+				Salt(testutil.MustHex(t, tc.Salt)).Personal(testutil.MustHex(t, tc.Personal)).Tree(tc.tree())
+			newHash = b.Hash
+			hasher, err = b.Hasher()
+		case "BLAKE2bp":
+			newHash = func() (stdhash.Hash, error) { return cryptohash.NewBlake2bp(tc.Size, key) }
+			hasher, err = cryptohash.NewBlake2bpHasher(tc.Size, key)
+		case "BLAKE2sp":
+			newHash = func() (stdhash.Hash, error) { return cryptohash.NewBlake2sp(tc.Size, key) }
+			hasher, err = cryptohash.NewBlake2spHasher(tc.Size, key)
+		default:
+			t.Fatalf("unknown variant %q", tc.Variant)
+		}
+		if err != nil {
+			t.Fatalf("case %d (%s): hasher: %v", idx, tc.Variant, err)
+		}
+		if got := hasher.Hash(msg); !bytes.Equal(got, want) {
+			t.Fatalf("case %d (%s, %d bytes): Hasher mismatch\n got %x\nwant %x", idx, tc.Variant, tc.MsgLen, got, want)
+		}
+
+		h, err := newHash()
+		if err != nil {
+			t.Fatalf("case %d (%s): %v", idx, tc.Variant, err)
+		}
+		if h.Size() != tc.Size {
+			t.Fatalf("case %d (%s): Size() = %d, want %d", idx, tc.Variant, h.Size(), tc.Size)
+		}
+		for _, step := range []int{len(msg) + 1, 1, 63, 1000, 70000} {
+			h.Reset()
+			for off := 0; off < len(msg); off += step {
+				h.Write(msg[off:min(off+step, len(msg))])
+			}
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("case %d (%s, %d bytes): streaming mismatch with %d-byte writes\n got %x\nwant %x", idx, tc.Variant, tc.MsgLen, step, got, want)
+			}
+		}
+	}
+}
+
+func TestBlake2ParallelSumDoesNotModifyState(t *testing.T) {
+	msg := blake3Input(150000)
+	for _, newHash := range []func(int, []byte) (stdhash.Hash, error){cryptohash.NewBlake2bp, cryptohash.NewBlake2sp} {
+		whole, err := newHash(32, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		whole.Write(msg)
+		want := whole.Sum(nil)
+
+		h, _ := newHash(32, nil)
+		h.Write(msg[:100000])
+		h.Sum(nil)
+		h.Write(msg[100000:])
+		if got := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("Sum altered the running state\n got %x\nwant %x", got, want)
+		}
+	}
+}
+
+func TestBlake2ParamsValidation(t *testing.T) {
+	if _, err := cryptohash.NewBlake2bBuilder().Salt(make([]byte, 17)).Hash(); err == nil {
+		t.Fatal("expected error for 17-byte BLAKE2b salt")
+	}
+	if _, err := cryptohash.NewBlake2bBuilder().Personal(make([]byte, 17)).Hasher(); err == nil {
+		t.Fatal("expected error for 17-byte BLAKE2b personalization")
+	}
+	if _, err := cryptohash.NewBlake2sBuilder().Salt(make([]byte, 9)).Hash(); err == nil {
+		t.Fatal("expected error for 9-byte BLAKE2s salt")
+	}
+	if _, err := cryptohash.NewBlake2sBuilder().Personal(make([]byte, 9)).Hasher(); err == nil {
+		t.Fatal("expected error for 9-byte BLAKE2s personalization")
+	}
+	if _, err := cryptohash.NewBlake2sBuilder().Tree(cryptohash.Blake2Tree{Fanout: 2, MaxDepth: 2, NodeOffset: 1 << 48}).Hash(); err == nil {
+		t.Fatal("expected error for BLAKE2s node offset beyond 48 bits")
+	}
+	if _, err := cryptohash.NewBlake2bBuilder().Tree(cryptohash.Blake2Tree{Fanout: 2}).Hash(); err == nil {
+		t.Fatal("expected error for zero tree depth")
+	}
+	if _, err := cryptohash.NewBlake2bBuilder().Tree(cryptohash.Blake2Tree{Fanout: 1, MaxDepth: 1, InnerSize: 65}).Hash(); err == nil {
+		t.Fatal("expected error for oversized inner size")
+	}
+	if _, err := cryptohash.NewBlake2bBuilder().Salt([]byte("salt")).XOF(64); err == nil {
+		t.Fatal("expected error for salted BLAKE2b XOF")
+	}
+	if _, err := cryptohash.NewBlake2sBuilder().Personal([]byte("app")).XOF(32); err == nil {
+		t.Fatal("expected error for personalized BLAKE2s XOF")
+	}
+	if _, err := cryptohash.NewBlake2bp(65, nil); err == nil {
+		t.Fatal("expected error for 65-byte BLAKE2bp digest")
+	}
+	if _, err := cryptohash.NewBlake2spHasher(32, make([]byte, 33)); err == nil {
+		t.Fatal("expected error for 33-byte BLAKE2sp key")
+	}
+}
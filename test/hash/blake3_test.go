@@ -0,0 +1,144 @@
+package xoodyak_test
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"testing"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+	"github.com/AeonDave/cryptonite-go/xof"
+)
+
+//go:embed testdata/blake3_vectors.json
+var blake3VectorsJSON []byte
+
+type blake3Vectors struct {
+	Key           string `json:"key"`
+	ContextString string `json:"context_string"`
+	Cases         []struct {
+		InputLen  int    `json:"input_len"`
+		Hash      string `json:"hash"`
+		KeyedHash string `json:"keyed_hash"`
+		DeriveKey string `json:"derive_key"`
+	} `json:"cases"`
+}
+
+func loadBLAKE3Vectors(t *testing.T) blake3Vectors {
+	t.Helper()
+	var v blake3Vectors
+	if err := json.Unmarshal(blake3VectorsJSON, &v); err != nil {
+		t.Fatalf("parse vectors: %v", err)
+	}
+	return v
+}
+
+func blake3Input(n int) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(i % 251)
+	}
+	return buf
+}
+
+func TestBLAKE3OfficialVectors(t *testing.T) {
+	v := loadBLAKE3Vectors(t)
+	key := []byte(v.Key)
+	for _, tc := range v.Cases {
+		input := blake3Input(tc.InputLen)
+		want := testutil.MustHex(t, tc.Hash)
+		wantKeyed := testutil.MustHex(t, tc.KeyedHash)
+		wantDerive := testutil.MustHex(t, tc.DeriveKey)
+
+		if got := cryptohash.SumBLAKE3(input); !bytes.Equal(got, want[:32]) {
+			t.Fatalf("hash len=%d mismatch: %x", tc.InputLen, got)
+		}
+		keyed, err := cryptohash.NewBLAKE3Keyed(key)
+		if err != nil {
+			t.Fatalf("NewBLAKE3Keyed failed: %v", err)
+		}
+		_, _ = keyed.Write(input)
+		if got := keyed.Sum(nil); !bytes.Equal(got, wantKeyed[:32]) {
+			t.Fatalf("keyed_hash len=%d mismatch: %x", tc.InputLen, got)
+		}
+		derive := cryptohash.NewBLAKE3DeriveKey(v.ContextString)
+		_, _ = derive.Write(input)
+		if got := derive.Sum(nil); !bytes.Equal(got, wantDerive[:32]) {
+			t.Fatalf("derive_key len=%d mismatch: %x", tc.InputLen, got)
+		}
+		if got := cryptohash.DeriveKeyBLAKE3(v.ContextString, input, len(wantDerive)); !bytes.Equal(got, wantDerive) {
+			t.Fatalf("DeriveKeyBLAKE3 len=%d mismatch: %x", tc.InputLen, got)
+		}
+
+		x := xof.BLAKE3()
+		_, _ = x.Write(input)
+		out := make([]byte, len(want))
+		_, _ = x.Read(out)
+		if !bytes.Equal(out, want) {
+			t.Fatalf("XOF len=%d mismatch: %x", tc.InputLen, out)
+		}
+		kx, err := xof.BLAKE3Keyed(key)
+		if err != nil {
+			t.Fatalf("xof.BLAKE3Keyed failed: %v", err)
+		}
+		_, _ = kx.Write(input)
+		_, _ = kx.Read(out)
+		if !bytes.Equal(out, wantKeyed) {
+			t.Fatalf("keyed XOF len=%d mismatch: %x", tc.InputLen, out)
+		}
+		dx := xof.BLAKE3DeriveKey(v.ContextString)
+		_, _ = dx.Write(input)
+		_, _ = dx.Read(out)
+		if !bytes.Equal(out, wantDerive) {
+			t.Fatalf("derive_key XOF len=%d mismatch: %x", tc.InputLen, out)
+		}
+	}
+}
+
+func TestBLAKE3StreamingMatchesParallel(t *testing.T) {
+	// Large single writes take the multi-goroutine chunk path; byte-sized
+	// writes never do.
+	for _, n := range []int{64 << 10, 64<<10 + 1, 300*1024 + 17, 1 << 20} {
+		input := blake3Input(n)
+		oneShot := cryptohash.SumBLAKE3(input)
+
+		h := cryptohash.NewBLAKE3()
+		for off := 0; off < len(input); {
+			step := 1 + off%97
+			if off+step > len(input) {
+				step = len(input) - off
+			}
+			_, _ = h.Write(input[off : off+step])
+			off += step
+		}
+		if got := h.Sum(nil); !bytes.Equal(got, oneShot) {
+			t.Fatalf("len=%d streaming %x != one-shot %x", n, got, oneShot)
+		}
+
+		mixed := cryptohash.NewBLAKE3()
+		_, _ = mixed.Write(input[:1500])
+		_, _ = mixed.Write(input[1500:])
+		if got := mixed.Sum(nil); !bytes.Equal(got, oneShot) {
+			t.Fatalf("len=%d split write mismatch", n)
+		}
+	}
+}
+
+func TestBLAKE3KeyLength(t *testing.T) {
+	if _, err := cryptohash.NewBLAKE3Keyed(make([]byte, 31)); err == nil {
+		t.Fatal("expected error for short key")
+	}
+	if _, err := xof.BLAKE3Keyed(make([]byte, 33)); err == nil {
+		t.Fatal("expected error for long key")
+	}
+}
+
+func TestDeriveKeyBLAKE3NegativeLength(t *testing.T) {
+	if out := cryptohash.DeriveKeyBLAKE3("context", []byte("material"), -1); len(out) != 0 {
+		t.Fatalf("DeriveKeyBLAKE3 with negative length returned %d bytes", len(out))
+	}
+	if out := cryptohash.DeriveKeyBLAKE3("context", []byte("material"), 0); len(out) != 0 {
+		t.Fatalf("DeriveKeyBLAKE3 with zero length returned %d bytes", len(out))
+	}
+}
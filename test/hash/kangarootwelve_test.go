@@ -0,0 +1,168 @@
+package xoodyak_test
+
+import (
+	"bytes"
+	_ "embed"
+	"strconv"
+	"strings"
+	"testing"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+//go:embed testdata/kangarootwelve_kat.txt
+var kangarooTwelveKAT string
+
+type ktVector struct {
+	function string
+	msg      []byte
+	custom   []byte
+	domain   byte
+	outLen   int
+	output   []byte
+	tail     bool
+}
+
+func ktPattern(n int) []byte {
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = byte(i % 251)
+	}
+	return buf
+}
+
+func parseKTKAT(t *testing.T) []ktVector {
+	t.Helper()
+	var cases []ktVector
+	for _, block := range strings.Split(strings.TrimSpace(kangarooTwelveKAT), "\n\n") {
+		var v ktVector
+		for _, line := range strings.Split(block, "\n") {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				t.Fatalf("invalid line %q", line)
+			}
+			key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			atoi := func() int {
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					t.Fatalf("invalid %s: %v", key, err)
+				}
+				return n
+			}
+			switch key {
+			case "Function":
+				v.function = value
+			case "Msg":
+				v.msg = testutil.MustHex(t, value)
+			case "MsgPtn":
+				v.msg = ktPattern(atoi())
+			case "Custom":
+				v.custom = testutil.MustHex(t, value)
+			case "CustomPtn":
+				v.custom = ktPattern(atoi())
+			case "Domain":
+				v.domain = testutil.MustHex(t, value)[0]
+			case "OutLen":
+				v.outLen = atoi()
+			case "Output":
+				v.output = testutil.MustHex(t, value)
+			case "Tail":
+				v.output = testutil.MustHex(t, value)
+				v.tail = true
+			default:
+				t.Fatalf("unknown field %q", key)
+			}
+		}
+		if v.function != "" {
+			cases = append(cases, v)
+		}
+	}
+	return cases
+}
+
+func (v ktVector) check(t *testing.T, got []byte, how string) {
+	t.Helper()
+	if v.tail {
+		got = got[len(got)-len(v.output):]
+	}
+	if !bytes.Equal(got, v.output) {
+		t.Fatalf("%s msg=%d custom=%d out=%d (%s): got %x want %x", v.function, len(v.msg), len(v.custom), v.outLen, how, got, v.output)
+	}
+}
+
+func TestKangarooTwelveKAT(t *testing.T) {
+	for _, v := range parseKTKAT(t) {
+		var (
+			got []byte
+			err error
+		)
+		switch v.function {
+		case "TurboSHAKE128":
+			got, err = cryptohash.TurboSHAKE128(v.msg, v.domain, v.outLen)
+		case "TurboSHAKE256":
+			got, err = cryptohash.TurboSHAKE256(v.msg, v.domain, v.outLen)
+		case "KT128":
+			got, err = cryptohash.KT128(v.msg, v.outLen, v.custom)
+		case "KT256":
+			got, err = cryptohash.KT256(v.msg, v.outLen, v.custom)
+		default:
+			t.Fatalf("unknown function %q", v.function)
+		}
+		if err != nil {
+			t.Fatalf("%s failed: %v", v.function, err)
+		}
+		v.check(t, got, "one-shot")
+	}
+}
+
+func TestKangarooTwelveStreaming(t *testing.T) {
+	for _, v := range parseKTKAT(t) {
+		if !strings.HasPrefix(v.function, "KT") || len(v.msg) < 1000 {
+			continue
+		}
+		newKT := cryptohash.NewKT128
+		if v.function == "KT256" {
+			newKT = cryptohash.NewKT256
+		}
+		for _, step := range []int{1 + len(v.msg)/17, 8192, 7919} {
+			k, err := newKT(v.outLen, v.custom)
+			if err != nil {
+				t.Fatalf("constructor failed: %v", err)
+			}
+			for off := 0; off < len(v.msg); off += step {
+				end := off + step
+				if end > len(v.msg) {
+					end = len(v.msg)
+				}
+				_, _ = k.Write(v.msg[off:end])
+			}
+			v.check(t, k.Sum(nil), "streaming")
+			if !v.tail {
+				out := make([]byte, v.outLen)
+				_, _ = k.XOF().Read(out)
+				v.check(t, out, "XOF")
+			}
+		}
+	}
+}
+
+func TestKangarooTwelveParams(t *testing.T) {
+	if _, err := cryptohash.TurboSHAKE128(nil, 0x00, 32); err == nil {
+		t.Fatal("domain 0x00 accepted")
+	}
+	if _, err := cryptohash.TurboSHAKE256(nil, 0x80, 32); err == nil {
+		t.Fatal("domain 0x80 accepted")
+	}
+	if _, err := cryptohash.NewKT128(0, nil); err == nil {
+		t.Fatal("zero output length accepted")
+	}
+	a, _ := cryptohash.KT128([]byte("msg"), 32, []byte("A"))
+	b, _ := cryptohash.KT128([]byte("msg"), 32, []byte("B"))
+	if bytes.Equal(a, b) {
+		t.Fatal("customization string ignored")
+	}
+}
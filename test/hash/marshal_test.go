@@ -0,0 +1,423 @@
+package xoodyak_test
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	stdhash "hash"
+	"runtime"
+	"testing"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+type resumableHash interface {
+	stdhash.Hash
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+type resumableCase struct {
+	name  string
+	new   func(t *testing.T) resumableHash
+	clone func(h resumableHash) resumableHash
+}
+
+func cloneVia(h resumableHash) resumableHash {
+	return h.(cryptohash.Cloner).Clone().(resumableHash)
+}
+
+func resumable(t *testing.T, h stdhash.Hash, err error) resumableHash {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("constructor: %v", err)
+	}
+	r, ok := h.(resumableHash)
+	if !ok {
+		t.Fatalf("%T does not implement encoding.BinaryMarshaler/BinaryUnmarshaler", h)
+	}
+	return r
+}
+
+func resumableHashes() []resumableCase {
+	key32 := blake3Input(32)
+	std := func(name string, f func() stdhash.Hash) resumableCase {
+		return resumableCase{name: name, new: func(t *testing.T) resumableHash { return resumable(t, f(), nil) }, clone: cloneVia}
+	}
+	withErr := func(name string, f func() (stdhash.Hash, error)) resumableCase {
+		return resumableCase{name: name, new: func(t *testing.T) resumableHash {
+			h, err := f()
+			return resumable(t, h, err)
+		}, clone: cloneVia}
+	}
+	return []resumableCase{
+		std("SHA-224", cryptohash.NewSHA224),
+		std("SHA-256", cryptohash.NewSHA256),
+		std("SHA-384", cryptohash.NewSHA384),
+		std("SHA-512", cryptohash.NewSHA512),
+		std("SHA-512/224", cryptohash.NewSHA512_224),
+		std("SHA-512/256", cryptohash.NewSHA512_256),
+		std("SHA3-224", cryptohash.NewSHA3224),
+		std("SHA3-256", cryptohash.NewSHA3256),
+		std("SHA3-384", cryptohash.NewSHA3384),
+		std("SHA3-512", cryptohash.NewSHA3512),
+		std("Keccak-256", cryptohash.NewKeccak256),
+		std("BLAKE3", cryptohash.NewBLAKE3),
+		std("BLAKE3-derive", func() stdhash.Hash { return cryptohash.NewBLAKE3DeriveKey("resumable test") }),
+		std("Xoodyak", cryptohash.NewXoodyak),
+		withErr("BLAKE3-keyed", func() (stdhash.Hash, error) { return cryptohash.NewBLAKE3Keyed(key32) }),
+		withErr("BLAKE2b", func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(64, nil) }),
+		withErr("BLAKE2b-keyed", func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(40, key32) }),
+		withErr("BLAKE2b-params", func() (stdhash.Hash, error) {
+			return cryptohash.NewBlake2bBuilder().Size(48).Salt([]byte("0123456789abcdef")).Personal([]byte("resumable")).Hash()
+		}),
+		withErr("BLAKE2s", func() (stdhash.Hash, error) { return cryptohash.NewBlake2s(32, nil) }),
+		withErr("BLAKE2s-params", func() (stdhash.Hash, error) {
+			return cryptohash.NewBlake2sBuilder().Key(key32[:16]).Personal([]byte("resume")).Hash()
+		}),
+		withErr("BLAKE2bp", func() (stdhash.Hash, error) { return cryptohash.NewBlake2bp(64, key32) }),
+		withErr("BLAKE2sp", func() (stdhash.Hash, error) { return cryptohash.NewBlake2sp(32, nil) }),
+		{
+			name: "KT128",
+			new: func(t *testing.T) resumableHash {
+				h, err := cryptohash.NewKT128(32, []byte("resume"))
+				return resumable(t, h, err)
+			},
+			clone: func(h resumableHash) resumableHash { return h.(*cryptohash.KangarooTwelve).Clone() },
+		},
+		{
+			name: "KT256",
+			new: func(t *testing.T) resumableHash {
+				h, err := cryptohash.NewKT256(64, nil)
+				return resumable(t, h, err)
+			},
+			clone: func(h resumableHash) resumableHash { return h.(*cryptohash.KangarooTwelve).Clone() },
+		},
+		{
+			name: "ParallelHash128",
+			new: func(t *testing.T) resumableHash {
+				h, err := cryptohash.NewParallelHash128(1000, 32, []byte("resume"))
+				return resumable(t, h, err)
+			},
+			clone: func(h resumableHash) resumableHash { return h.(*cryptohash.ParallelHash).Clone() },
+		},
+	}
+}
+
+func TestHashMarshalResume(t *testing.T) {
+	msg := blake3Input(200_003)
+	for _, tc := range resumableHashes() {
+		for _, split := range []int{0, 1, 8191, 100_003, len(msg)} {
+			// Xoodyak treats each Write as a separate absorb call, so the
+			// reference splits its input the same way.
+			ref := tc.new(t)
+			ref.Write(msg[:split])
+			ref.Write(msg[split:])
+			want := ref.Sum(nil)
+
+			h := tc.new(t)
+			h.Write(msg[:split])
+			state, err := h.MarshalBinary()
+			if err != nil {
+				t.Fatalf("%s: MarshalBinary: %v", tc.name, err)
+			}
+			// Writing to the original after marshaling must not affect the
+			// saved state.
+			h.Write([]byte("diverge"))
+
+			resumed := tc.new(t)
+			resumed.Write([]byte("discarded by UnmarshalBinary"))
+			if err := resumed.UnmarshalBinary(state); err != nil {
+				t.Fatalf("%s split %d: UnmarshalBinary: %v", tc.name, split, err)
+			}
+			again, err := resumed.MarshalBinary()
+			if err != nil || !bytes.Equal(again, state) {
+				t.Fatalf("%s split %d: re-marshaled state differs", tc.name, split)
+			}
+			resumed.Write(msg[split:])
+			if got := resumed.Sum(nil); !bytes.Equal(got, want) {
+				t.Fatalf("%s split %d: resumed digest mismatch", tc.name, split)
+			}
+		}
+	}
+}
+
+func TestHashClone(t *testing.T) {
+	msg := blake3Input(150_000)
+	for _, tc := range resumableHashes() {
+		h := tc.new(t)
+		h.Write(msg[:70_001])
+		c := tc.clone(h)
+		c.Write(msg[70_001:])
+		h.Write([]byte("other suffix"))
+
+		ref := tc.new(t)
+		ref.Write(msg[:70_001])
+		ref.Write(msg[70_001:])
+		if got, want := c.Sum(nil), ref.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("%s: clone digest mismatch", tc.name)
+		}
+		ref.Reset()
+		ref.Write(msg[:70_001])
+		ref.Write([]byte("other suffix"))
+		if got, want := h.Sum(nil), ref.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("%s: original affected by clone", tc.name)
+		}
+	}
+}
+
+func TestHashMarshalRejectsMismatch(t *testing.T) {
+	cases := []struct {
+		name     string
+		from, to func() (stdhash.Hash, error)
+	}{
+		{"SHA-256 into SHA-224", wrap(cryptohash.NewSHA256), wrap(cryptohash.NewSHA224)},
+		{"SHA-512/256 into SHA-512", wrap(cryptohash.NewSHA512_256), wrap(cryptohash.NewSHA512)},
+		{"SHA3-256 into Keccak-256", wrap(cryptohash.NewSHA3256), wrap(cryptohash.NewKeccak256)},
+		{"BLAKE3 into BLAKE3 derive_key", wrap(cryptohash.NewBLAKE3), wrap(func() stdhash.Hash { return cryptohash.NewBLAKE3DeriveKey("ctx") })},
+		{"BLAKE2b-64 into BLAKE2b-32", func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(64, nil) }, func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(32, nil) }},
+		{"BLAKE2b unsalted into salted", func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(64, nil) }, func() (stdhash.Hash, error) {
+			return cryptohash.NewBlake2bBuilder().Salt([]byte("salt")).Hash()
+		}},
+		{"BLAKE2b into BLAKE2bp", func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(64, nil) }, func() (stdhash.Hash, error) { return cryptohash.NewBlake2bp(64, nil) }},
+		{"BLAKE2s into BLAKE2b", func() (stdhash.Hash, error) { return cryptohash.NewBlake2s(32, nil) }, func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(32, nil) }},
+		{"KT128 customization", func() (stdhash.Hash, error) { return cryptohash.NewKT128(32, []byte("a")) }, func() (stdhash.Hash, error) { return cryptohash.NewKT128(32, []byte("b")) }},
+		{"KT128 output length", func() (stdhash.Hash, error) { return cryptohash.NewKT128(32, nil) }, func() (stdhash.Hash, error) { return cryptohash.NewKT128(64, nil) }},
+		{"ParallelHash block size", func() (stdhash.Hash, error) { return cryptohash.NewParallelHash128(64, 32, nil) }, func() (stdhash.Hash, error) {
+			return cryptohash.NewParallelHash128(128, 32, nil)
+		}},
+	}
+	for _, tc := range cases {
+		h, err := tc.from()
+		from := resumable(t, h, err)
+		h, err = tc.to()
+		to := resumable(t, h, err)
+		from.Write([]byte("state"))
+		state, err := from.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary: %v", tc.name, err)
+		}
+		to.Write([]byte("kept"))
+		before := to.Sum(nil)
+		if err := to.UnmarshalBinary(state); !errors.Is(err, cryptohash.ErrStateMismatch) {
+			t.Fatalf("%s: got %v, want ErrStateMismatch", tc.name, err)
+		}
+		if !bytes.Equal(to.Sum(nil), before) {
+			t.Fatalf("%s: failed UnmarshalBinary modified the state", tc.name)
+		}
+	}
+}
+
+func TestHashMarshalRejectsCorruptState(t *testing.T) {
+	for _, tc := range resumableHashes() {
+		h := tc.new(t)
+		h.Write(blake3Input(1000))
+		state, _ := h.MarshalBinary()
+		for _, bad := range [][]byte{
+			nil,
+			state[:len(state)-1],
+			append(append([]byte(nil), state...), 0),
+			append([]byte("XYZ"), state[3:]...),
+			append([]byte{state[0], state[1], state[2], state[3] + 1}, state[4:]...),
+		} {
+			if err := tc.new(t).UnmarshalBinary(bad); !errors.Is(err, cryptohash.ErrInvalidState) {
+				t.Fatalf("%s: got %v, want ErrInvalidState", tc.name, err)
+			}
+		}
+	}
+}
+
+func TestBlake3MarshalRejectsInconsistentTree(t *testing.T) {
+	h := cryptohash.NewBLAKE3().(resumableHash)
+	h.Write(blake3Input(1024))
+	state, _ := h.MarshalBinary()
+	// Field offsets after the 11-byte "CNS" header: chunk counter, block
+	// length, blocks compressed and stack length.
+	const counterAt, blockLenAt, compressedAt, stackLenAt = 51, 127, 131, 135
+	corrupt := func(edit func(b []byte) []byte) []byte {
+		return edit(append([]byte(nil), state...))
+	}
+	for name, bad := range map[string][]byte{
+		"counter without stack": corrupt(func(b []byte) []byte {
+			binary.BigEndian.PutUint64(b[counterAt:], 1)
+			return b
+		}),
+		"stack without counter": corrupt(func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[stackLenAt:], 1)
+			return append(b, make([]byte, 32)...)
+		}),
+		"full stack": corrupt(func(b []byte) []byte {
+			binary.BigEndian.PutUint64(b[counterAt:], 1<<54-1)
+			binary.BigEndian.PutUint32(b[stackLenAt:], 54)
+			return append(b, make([]byte, 54*32)...)
+		}),
+		"empty block after compression": corrupt(func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[blockLenAt:], 0)
+			return b
+		}),
+		"compressed past chunk": corrupt(func(b []byte) []byte {
+			binary.BigEndian.PutUint32(b[compressedAt:], 16)
+			return b
+		}),
+	} {
+		if err := cryptohash.NewBLAKE3().(resumableHash).UnmarshalBinary(bad); !errors.Is(err, cryptohash.ErrInvalidState) {
+			t.Fatalf("%s: got %v, want ErrInvalidState", name, err)
+		}
+	}
+
+	// A consistent edit still decodes: one completed chunk on the stack.
+	good := corrupt(func(b []byte) []byte {
+		binary.BigEndian.PutUint64(b[counterAt:], 1)
+		binary.BigEndian.PutUint32(b[stackLenAt:], 1)
+		return append(b, make([]byte, 32)...)
+	})
+	r := cryptohash.NewBLAKE3().(resumableHash)
+	if err := r.UnmarshalBinary(good); err != nil {
+		t.Fatalf("consistent state: %v", err)
+	}
+	r.Write([]byte("more"))
+}
+
+// States written by golang.org/x/crypto/blake2b and blake2s v0.43.0 (the
+// encoding earlier releases used) after absorbing bytes 0..149 of a 200-byte
+// message, and the 32-byte digests of the full message.
+const (
+	legacyBlake2bState = "62326212dac38388ccdc2197e45bb8e401e5d1fa8850d93bf058eb3431934cee99d64275aabe1470b6cb92d4ad250c995d8c36a481b0bbedb175f73d8f34c505e0e2970000000000000080000000000000000020808182838485868788898a8b8c8d8e8f9091929394950000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000016"
+	legacyBlake2bSum   = "63c3d97a9f8894d5e043a707b0fee7f7ec4c049a23bbf1079df20b4165f9e22d"
+	legacyBlake2sState = "62327322b83fe9a40bc961fd72fef17244a621592c8d13389bf0f06823fd52d361d66c000000800000000020808182838485868788898a8b8c8d8e8f90919293949500000000000000000000000000000000000000000000000000000000000000000000000000000000000016"
+	legacyBlake2sSum   = "6d244e1a06ce4ef578dd0f63aff0936706735119ca9c8d22d86c801414ab9741"
+)
+
+func TestBlake2LegacyStateUnmarshal(t *testing.T) {
+	msg := blake3Input(200)
+	cases := []struct {
+		name       string
+		state, sum string
+		new        func() (stdhash.Hash, error)
+		mismatch   []func() (stdhash.Hash, error)
+	}{
+		{
+			name: "BLAKE2b", state: legacyBlake2bState, sum: legacyBlake2bSum,
+			new: func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(32, nil) },
+			mismatch: []func() (stdhash.Hash, error){
+				func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(64, nil) },
+				func() (stdhash.Hash, error) { return cryptohash.NewBlake2b(32, []byte("key")) },
+				func() (stdhash.Hash, error) {
+					return cryptohash.NewBlake2bBuilder().Size(32).Salt([]byte("salt")).Hash()
+				},
+			},
+		},
+		{
+			name: "BLAKE2s", state: legacyBlake2sState, sum: legacyBlake2sSum,
+			new: func() (stdhash.Hash, error) { return cryptohash.NewBlake2s(32, nil) },
+			mismatch: []func() (stdhash.Hash, error){
+				func() (stdhash.Hash, error) { return cryptohash.NewBlake2s(16, nil) },
+				func() (stdhash.Hash, error) { return cryptohash.NewBlake2s(32, []byte("key")) },
+			},
+		},
+	}
+	for _, tc := range cases {
+		state := testutil.MustHex(t, tc.state)
+		h, err := tc.new()
+		r := resumable(t, h, err)
+		if err := r.UnmarshalBinary(state); err != nil {
+			t.Fatalf("%s: UnmarshalBinary: %v", tc.name, err)
+		}
+		r.Write(msg[150:])
+		if got := r.Sum(nil); !bytes.Equal(got, testutil.MustHex(t, tc.sum)) {
+			t.Fatalf("%s: resumed digest %x, want %s", tc.name, got, tc.sum)
+		}
+		for i, f := range tc.mismatch {
+			h, err := f()
+			if err := resumable(t, h, err).UnmarshalBinary(state); !errors.Is(err, cryptohash.ErrStateMismatch) {
+				t.Fatalf("%s mismatch #%d: got %v, want ErrStateMismatch", tc.name, i, err)
+			}
+		}
+	}
+}
+
+func TestTupleHashMarshalResume(t *testing.T) {
+	elements := [][]byte{[]byte("first"), nil, blake3Input(300), []byte("last")}
+	ref, _ := cryptohash.NewTupleHash256(48, []byte("tuple"))
+	for _, e := range elements {
+		ref.WriteElement(e)
+	}
+	want := ref.Sum(nil)
+
+	h, _ := cryptohash.NewTupleHash256(48, []byte("tuple"))
+	h.WriteElement(elements[0])
+	h.WriteElement(elements[1])
+	state, err := h.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	clone := h.Clone()
+	resumed, _ := cryptohash.NewTupleHash256(48, []byte("tuple"))
+	if err := resumed.UnmarshalBinary(state); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	for _, b := range []*cryptohash.TupleHash{resumed, clone} {
+		b.WriteElement(elements[2])
+		b.WriteElement(elements[3])
+		if got := b.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("resumed TupleHash mismatch")
+		}
+	}
+
+	other, _ := cryptohash.NewTupleHash256(48, []byte("other"))
+	if err := other.UnmarshalBinary(state); !errors.Is(err, cryptohash.ErrStateMismatch) {
+		t.Fatalf("customization mismatch: got %v", err)
+	}
+	narrow, _ := cryptohash.NewTupleHash128(48, []byte("tuple"))
+	if err := narrow.UnmarshalBinary(state); !errors.Is(err, cryptohash.ErrStateMismatch) {
+		t.Fatalf("TupleHash128: got %v", err)
+	}
+}
+
+func wrap(f func() stdhash.Hash) func() (stdhash.Hash, error) {
+	return func() (stdhash.Hash, error) { return f(), nil }
+}
+
+func TestTreeHashMarshalAcrossGOMAXPROCS(t *testing.T) {
+	// KangarooTwelve and ParallelHash size their input batches from
+	// GOMAXPROCS, so a state saved on a larger machine may carry more
+	// buffered input than the restoring instance would accumulate.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	msg := blake3Input(300_001)
+	constructors := map[string]func() resumableHash{
+		"KT128": func() resumableHash {
+			h, _ := cryptohash.NewKT128(32, nil)
+			return h
+		},
+		"ParallelHash256": func() resumableHash {
+			h, _ := cryptohash.NewParallelHash256(4096, 64, nil)
+			return h
+		},
+	}
+	for name, newHash := range constructors {
+		runtime.GOMAXPROCS(1)
+		ref := newHash()
+		ref.Write(msg)
+		want := ref.Sum(nil)
+
+		runtime.GOMAXPROCS(16)
+		h := newHash()
+		h.Write(msg[:120_000])
+		state, err := h.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary: %v", name, err)
+		}
+
+		runtime.GOMAXPROCS(1)
+		resumed := newHash()
+		if err := resumed.UnmarshalBinary(state); err != nil {
+			t.Fatalf("%s: UnmarshalBinary: %v", name, err)
+		}
+		resumed.Write(msg[120_000:])
+		if got := resumed.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("%s: digest mismatch after restoring on fewer workers", name)
+		}
+	}
+}
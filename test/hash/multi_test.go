@@ -0,0 +1,121 @@
+package xoodyak_test
+
+import (
+	"bytes"
+	"errors"
+	stdhash "hash"
+	"io"
+	"runtime"
+	"testing"
+	"testing/iotest"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+)
+
+func newBlake2b512(t testing.TB) func() stdhash.Hash {
+	return func() stdhash.Hash {
+		h, err := cryptohash.NewBlake2b(64, nil)
+		if err != nil {
+			t.Fatalf("NewBlake2b: %v", err)
+		}
+		return h
+	}
+}
+
+func TestMultiHashMatchesIndividualHashes(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	constructors := []func() stdhash.Hash{
+		cryptohash.NewSHA256,
+		cryptohash.NewSHA512,
+		newBlake2b512(t),
+		cryptohash.NewBLAKE3,
+	}
+	for _, procs := range []int{1, 4} {
+		runtime.GOMAXPROCS(procs)
+		for _, size := range []int{0, 1, 1000, 64 << 10, 256 << 10, 700_001} {
+			msg := blake3Input(size)
+			// Odd-sized reads exercise partial chunks.
+			digests, err := cryptohash.SumReader(iotest.HalfReader(bytes.NewReader(msg)), constructors...)
+			if err != nil {
+				t.Fatalf("SumReader: %v", err)
+			}
+			if len(digests) != len(constructors) {
+				t.Fatalf("got %d digests, want %d", len(digests), len(constructors))
+			}
+			for i, newHash := range constructors {
+				h := newHash()
+				h.Write(msg)
+				if !bytes.Equal(digests[i].Sum, h.Sum(nil)) {
+					t.Fatalf("GOMAXPROCS=%d size %d: %s digest mismatch", procs, size, digests[i].Algorithm)
+				}
+			}
+
+			m := cryptohash.NewMultiHash(constructors...)
+			m.Write(msg[:size/3])
+			m.Write(msg[size/3:])
+			if m.Len() != int64(size) {
+				t.Fatalf("Len = %d, want %d", m.Len(), size)
+			}
+			for i, d := range m.Sums() {
+				if !bytes.Equal(d.Sum, digests[i].Sum) {
+					t.Fatalf("size %d: Write and ReadFrom disagree for %s", size, d.Algorithm)
+				}
+			}
+		}
+	}
+}
+
+func TestMultiHashAlgorithmNames(t *testing.T) {
+	kt := func() stdhash.Hash {
+		h, _ := cryptohash.NewKT128(32, nil)
+		return h
+	}
+	blake2s := func() stdhash.Hash {
+		h, _ := cryptohash.NewBlake2s(16, nil)
+		return h
+	}
+	blake2bp := func() stdhash.Hash {
+		h, _ := cryptohash.NewBlake2bp(64, nil)
+		return h
+	}
+	m := cryptohash.NewMultiHash(
+		cryptohash.NewSHA256, cryptohash.NewSHA512, cryptohash.NewSHA512_256, cryptohash.NewSHA3256,
+		cryptohash.NewKeccak256, newBlake2b512(t), blake2s, blake2bp, cryptohash.NewBLAKE3,
+		cryptohash.NewXoodyak, kt,
+	)
+	want := []string{
+		"SHA-256", "SHA-512", "SHA-512/256", "SHA3-256",
+		"Keccak-256", "BLAKE2b-512", "BLAKE2s-128", "BLAKE2bp-512", "BLAKE3",
+		"Xoodyak", "KT128",
+	}
+	for i, d := range m.Sums() {
+		if d.Algorithm != want[i] {
+			t.Fatalf("digest %d: algorithm %q, want %q", i, d.Algorithm, want[i])
+		}
+	}
+	for _, tc := range resumableHashes() {
+		if _, ok := stdhash.Hash(tc.new(t)).(cryptohash.Named); !ok {
+			t.Fatalf("%s does not implement hash.Named", tc.name)
+		}
+	}
+}
+
+func TestMultiHashReadError(t *testing.T) {
+	boom := errors.New("boom")
+	r := io.MultiReader(bytes.NewReader(blake3Input(300_000)), iotest.ErrReader(boom))
+	if _, err := cryptohash.SumReader(r, cryptohash.NewSHA256, cryptohash.NewBLAKE3); !errors.Is(err, boom) {
+		t.Fatalf("got %v, want read error", err)
+	}
+
+	m := cryptohash.NewMultiHash(cryptohash.NewSHA256)
+	m.Write([]byte("discarded"))
+	m.Reset()
+	n, err := m.ReadFrom(bytes.NewReader([]byte("abc")))
+	if err != nil || n != 3 {
+		t.Fatalf("ReadFrom after Reset = %d, %v", n, err)
+	}
+	want := cryptohash.SumSHA256([]byte("abc"))
+	if got := m.Sums()[0].Sum; !bytes.Equal(got, want[:]) {
+		t.Fatalf("digest after Reset mismatch")
+	}
+}
@@ -61,16 +61,20 @@ func TestSHA2KAT(t *testing.T) {
 		t.Fatal("no SHA-2 cases parsed")
 	}
 	constructors := map[string]func() stdhash.Hash{
-		"SHA-224": cryptohash.NewSHA224,
-		"SHA-256": cryptohash.NewSHA256,
-		"SHA-384": cryptohash.NewSHA384,
-		"SHA-512": cryptohash.NewSHA512,
+		"SHA-224":     cryptohash.NewSHA224,
+		"SHA-256":     cryptohash.NewSHA256,
+		"SHA-384":     cryptohash.NewSHA384,
+		"SHA-512":     cryptohash.NewSHA512,
+		"SHA-512/224": cryptohash.NewSHA512_224,
+		"SHA-512/256": cryptohash.NewSHA512_256,
 	}
 	stateless := map[string]func() cryptohash.Hasher{
-		"SHA-224": cryptohash.NewSHA224Hasher,
-		"SHA-256": cryptohash.NewSHA256Hasher,
-		"SHA-384": cryptohash.NewSHA384Hasher,
-		"SHA-512": cryptohash.NewSHA512Hasher,
+		"SHA-224":     cryptohash.NewSHA224Hasher,
+		"SHA-256":     cryptohash.NewSHA256Hasher,
+		"SHA-384":     cryptohash.NewSHA384Hasher,
+		"SHA-512":     cryptohash.NewSHA512Hasher,
+		"SHA-512/224": cryptohash.NewSHA512_224Hasher,
+		"SHA-512/256": cryptohash.NewSHA512_256Hasher,
 	}
 	for idx, tc := range cases {
 		newHash, ok := constructors[tc.variant]
@@ -120,6 +124,14 @@ func TestSHA2KAT(t *testing.T) {
 			if got := cryptohash.SumSHA512(tc.msg); !bytes.Equal(got[:], tc.md) {
 				t.Fatalf("SumSHA512 mismatch case %d", idx+1)
 			}
+		case "SHA-512/224":
+			if got := cryptohash.SumSHA512_224(tc.msg); !bytes.Equal(got[:], tc.md) {
+				t.Fatalf("SumSHA512_224 mismatch case %d", idx+1)
+			}
+		case "SHA-512/256":
+			if got := cryptohash.SumSHA512_256(tc.msg); !bytes.Equal(got[:], tc.md) {
+				t.Fatalf("SumSHA512_256 mismatch case %d", idx+1)
+			}
 		}
 
 		streaming, ok := h.(cryptohash.Hasher)
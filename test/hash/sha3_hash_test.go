@@ -61,16 +61,18 @@ func TestSHA3KAT(t *testing.T) {
 		t.Fatal("no SHA3 cases parsed")
 	}
 	constructors := map[string]func() stdhash.Hash{
-		"SHA3-224": cryptohash.NewSHA3224,
-		"SHA3-256": cryptohash.NewSHA3256,
-		"SHA3-384": cryptohash.NewSHA3384,
-		"SHA3-512": cryptohash.NewSHA3512,
+		"SHA3-224":   cryptohash.NewSHA3224,
+		"SHA3-256":   cryptohash.NewSHA3256,
+		"SHA3-384":   cryptohash.NewSHA3384,
+		"SHA3-512":   cryptohash.NewSHA3512,
+		"Keccak-256": cryptohash.NewKeccak256,
 	}
 	stateless := map[string]func() cryptohash.Hasher{
-		"SHA3-224": cryptohash.NewSHA3224Hasher,
-		"SHA3-256": cryptohash.NewSHA3256Hasher,
-		"SHA3-384": cryptohash.NewSHA3384Hasher,
-		"SHA3-512": cryptohash.NewSHA3512Hasher,
+		"SHA3-224":   cryptohash.NewSHA3224Hasher,
+		"SHA3-256":   cryptohash.NewSHA3256Hasher,
+		"SHA3-384":   cryptohash.NewSHA3384Hasher,
+		"SHA3-512":   cryptohash.NewSHA3512Hasher,
+		"Keccak-256": cryptohash.NewKeccak256Hasher,
 	}
 	for idx, tc := range cases {
 		newHash, ok := constructors[tc.variant]
@@ -120,6 +122,10 @@ func TestSHA3KAT(t *testing.T) {
 			if got := cryptohash.Sum512(tc.msg); !bytes.Equal(got[:], tc.md) {
 				t.Fatalf("Sum512 mismatch case %d", idx+1)
 			}
+		case "Keccak-256":
+			if got := cryptohash.SumKeccak256(tc.msg); !bytes.Equal(got[:], tc.md) {
+				t.Fatalf("SumKeccak256 mismatch case %d", idx+1)
+			}
 		}
 
 		streaming, ok := h.(cryptohash.Hasher)
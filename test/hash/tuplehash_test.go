@@ -3,6 +3,7 @@ package xoodyak_test
 import (
 	"encoding/hex"
 	"encoding/json"
+	"io"
 	"testing"
 
 	_ "embed"
@@ -33,6 +34,11 @@ type tupleParallelVectors struct {
 	TupleHash256    []tupleCase    `json:"tuplehash256"`
 	ParallelHash128 []parallelCase `json:"parallelhash128"`
 	ParallelHash256 []parallelCase `json:"parallelhash256"`
+
+	TupleHashXOF128    []tupleCase    `json:"tuplehashxof128"`
+	TupleHashXOF256    []tupleCase    `json:"tuplehashxof256"`
+	ParallelHashXOF128 []parallelCase `json:"parallelhashxof128"`
+	ParallelHashXOF256 []parallelCase `json:"parallelhashxof256"`
 }
 
 func TestTupleHashKAT(t *testing.T) {
@@ -93,6 +99,155 @@ func TestParallelHashKAT(t *testing.T) {
 	}
 }
 
+func TestTupleHashXOFKAT(t *testing.T) {
+	var vectors tupleParallelVectors
+	if err := json.Unmarshal(tupleParallelKAT, &vectors); err != nil {
+		t.Fatalf("failed to decode vectors: %v", err)
+	}
+	check := func(name string, cases []tupleCase, oneShot func([][]byte, int, []byte) ([]byte, error),
+		builder func(int, []byte) (*cryptohash.TupleHash, error)) {
+		if len(cases) == 0 {
+			t.Fatalf("no %s vectors", name)
+		}
+		for idx, tc := range cases {
+			tuple := decodeTuple(t, tc.Tuple)
+			digest := mustHex(t, tc.Digest)
+			out, err := oneShot(tuple, tc.OutLen, mustHex(t, tc.Customization))
+			if err != nil {
+				t.Fatalf("%s vector %d failed: %v", name, idx, err)
+			}
+			if !bytesEqual(out, digest) {
+				t.Fatalf("%s vector %d mismatch\n got %x\nwant %x", name, idx, out, digest)
+			}
+			b, err := builder(32, mustHex(t, tc.Customization))
+			if err != nil {
+				t.Fatalf("%s builder failed: %v", name, err)
+			}
+			for _, elem := range tuple {
+				b.WriteElement(elem)
+			}
+			out = make([]byte, tc.OutLen)
+			if _, err := io.ReadFull(b.XOF(), out); err != nil {
+				t.Fatalf("%s XOF read failed: %v", name, err)
+			}
+			if !bytesEqual(out, digest) {
+				t.Fatalf("%s builder vector %d mismatch\n got %x\nwant %x", name, idx, out, digest)
+			}
+		}
+	}
+	check("TupleHashXOF128", vectors.TupleHashXOF128, cryptohash.TupleHashXOF128, cryptohash.NewTupleHash128)
+	check("TupleHashXOF256", vectors.TupleHashXOF256, cryptohash.TupleHashXOF256, cryptohash.NewTupleHash256)
+}
+
+func TestTupleHashBuilderMatchesOneShot(t *testing.T) {
+	var vectors tupleParallelVectors
+	if err := json.Unmarshal(tupleParallelKAT, &vectors); err != nil {
+		t.Fatalf("failed to decode vectors: %v", err)
+	}
+	for idx, tc := range vectors.TupleHash256 {
+		b, err := cryptohash.NewTupleHash256(tc.OutLen, mustHex(t, tc.Customization))
+		if err != nil {
+			t.Fatalf("NewTupleHash256 failed: %v", err)
+		}
+		for _, elem := range decodeTuple(t, tc.Tuple) {
+			b.WriteElement(elem)
+		}
+		digest := mustHex(t, tc.Digest)
+		if got := b.Sum(nil); !bytesEqual(got, digest) {
+			t.Fatalf("TupleHash256 builder vector %d mismatch\n got %x\nwant %x", idx, got, digest)
+		}
+		if got := b.Sum(nil); !bytesEqual(got, digest) {
+			t.Fatalf("TupleHash256 builder vector %d: Sum altered state", idx)
+		}
+		b.Reset()
+		if got := b.Sum(nil); bytesEqual(got, digest) {
+			t.Fatalf("TupleHash256 builder vector %d: Reset kept elements", idx)
+		}
+	}
+	if _, err := cryptohash.NewTupleHash128(0, nil); err == nil {
+		t.Fatal("expected error for zero output length")
+	}
+}
+
+func TestParallelHashXOFKAT(t *testing.T) {
+	var vectors tupleParallelVectors
+	if err := json.Unmarshal(tupleParallelKAT, &vectors); err != nil {
+		t.Fatalf("failed to decode vectors: %v", err)
+	}
+	check := func(name string, cases []parallelCase, oneShot func([]byte, int, int, []byte) ([]byte, error),
+		stream func(int, int, []byte) (*cryptohash.ParallelHash, error)) {
+		if len(cases) == 0 {
+			t.Fatalf("no %s vectors", name)
+		}
+		for idx, tc := range cases {
+			msg := mustHex(t, tc.Message)
+			digest := mustHex(t, tc.Digest)
+			out, err := oneShot(msg, tc.BlockSize, tc.OutLen, mustHex(t, tc.Customization))
+			if err != nil {
+				t.Fatalf("%s vector %d failed: %v", name, idx, err)
+			}
+			if !bytesEqual(out, digest) {
+				t.Fatalf("%s vector %d mismatch\n got %x\nwant %x", name, idx, out, digest)
+			}
+			h, err := stream(tc.BlockSize, 32, mustHex(t, tc.Customization))
+			if err != nil {
+				t.Fatalf("%s stream failed: %v", name, err)
+			}
+			_, _ = h.Write(msg)
+			out = make([]byte, tc.OutLen)
+			if _, err := io.ReadFull(h.XOF(), out); err != nil {
+				t.Fatalf("%s XOF read failed: %v", name, err)
+			}
+			if !bytesEqual(out, digest) {
+				t.Fatalf("%s stream vector %d mismatch\n got %x\nwant %x", name, idx, out, digest)
+			}
+		}
+	}
+	check("ParallelHashXOF128", vectors.ParallelHashXOF128, cryptohash.ParallelHashXOF128, cryptohash.NewParallelHash128)
+	check("ParallelHashXOF256", vectors.ParallelHashXOF256, cryptohash.ParallelHashXOF256, cryptohash.NewParallelHash256)
+}
+
+func TestParallelHashStreamingMatchesOneShot(t *testing.T) {
+	msg := make([]byte, 3<<20+777)
+	for i := range msg {
+		msg[i] = byte(i*31 + i>>8)
+	}
+	customization := []byte("streaming")
+	for _, blockSize := range []int{1, 1000, 8192, 1 << 20} {
+		input := msg
+		if blockSize == 1 {
+			input = msg[:4096]
+		}
+		want, err := cryptohash.ParallelHash256(input, blockSize, 64, customization)
+		if err != nil {
+			t.Fatalf("ParallelHash256 failed: %v", err)
+		}
+		h, err := cryptohash.NewParallelHash256(blockSize, 64, customization)
+		if err != nil {
+			t.Fatalf("NewParallelHash256 failed: %v", err)
+		}
+		for off, step := 0, 1; off < len(input); step = step*3 + 1 {
+			end := off + step
+			if end > len(input) {
+				end = len(input)
+			}
+			_, _ = h.Write(input[off:end])
+			off = end
+		}
+		if got := h.Sum(nil); !bytesEqual(got, want) {
+			t.Fatalf("block size %d: streaming mismatch\n got %x\nwant %x", blockSize, got, want)
+		}
+		h.Reset()
+		_, _ = h.Write(input)
+		if got := h.Sum(nil); !bytesEqual(got, want) {
+			t.Fatalf("block size %d: mismatch after Reset", blockSize)
+		}
+	}
+	if _, err := cryptohash.NewParallelHash128(0, 32, nil); err == nil {
+		t.Fatal("expected error for zero block size")
+	}
+}
+
 func decodeTuple(t *testing.T, enc []string) [][]byte {
 	t.Helper()
 	out := make([][]byte, len(enc))
@@ -0,0 +1,34 @@
+package hash2curve_test
+
+import (
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/hash2curve"
+)
+
+func makeBytes(length int, seed byte) []byte {
+	buf := make([]byte, length)
+	for i := range buf {
+		buf[i] = seed + byte(i)
+	}
+	return buf
+}
+
+func BenchmarkHash(b *testing.B) {
+	msg := makeBytes(64, 0x21)
+	dst := []byte("cryptonite-go-bench")
+	for _, s := range []*hash2curve.Suite{
+		hash2curve.P256RO(), hash2curve.P384RO(), hash2curve.P521RO(),
+		hash2curve.Edwards25519RO(), hash2curve.Edwards25519NU(),
+		hash2curve.Curve25519RO(), hash2curve.Edwards448RO(), hash2curve.Curve448RO(),
+	} {
+		b.Run(s.ID(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.Hash(msg, dst); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package hash2curve_test
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	stdhash "hash"
+	"strings"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/ecdh"
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	"github.com/AeonDave/cryptonite-go/hash2curve"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+	"github.com/AeonDave/cryptonite-go/xof"
+)
+
+// hash2curve_kat.json holds the RFC 9380 Appendix K vectors for the
+// expanders and the Appendix J vectors for the P-256, P-384, P-521,
+// curve25519, edwards25519, curve448 and edwards448 suites.
+//
+//go:embed testdata/hash2curve_kat.json
+var hash2curveKAT []byte
+
+type expandVectors struct {
+	Name    string `json:"name"`
+	Hash    string `json:"hash"`
+	K       int    `json:"k"`
+	DST     string `json:"dst"`
+	Vectors []struct {
+		Msg          string `json:"msg"`
+		LenInBytes   int    `json:"len_in_bytes"`
+		UniformBytes string `json:"uniform_bytes"`
+	} `json:"vectors"`
+}
+
+type suiteVectors struct {
+	Suite   string `json:"suite"`
+	DST     string `json:"dst"`
+	Vectors []struct {
+		Msg string   `json:"msg"`
+		U   []string `json:"u"`
+		P   struct {
+			X string `json:"x"`
+			Y string `json:"y"`
+		} `json:"p"`
+	} `json:"vectors"`
+}
+
+func loadVectors(t *testing.T) (expand []expandVectors, suites []suiteVectors) {
+	t.Helper()
+	var v struct {
+		Expand []expandVectors `json:"expand"`
+		Suites []suiteVectors  `json:"suites"`
+	}
+	if err := json.Unmarshal(hash2curveKAT, &v); err != nil {
+		t.Fatalf("parse vectors: %v", err)
+	}
+	return v.Expand, v.Suites
+}
+
+func TestExpandMessageVectors(t *testing.T) {
+	expand, _ := loadVectors(t)
+	hashes := map[string]func() stdhash.Hash{"SHA256": cryptohash.NewSHA256, "SHA512": cryptohash.NewSHA512}
+	xofs := map[string]func() xof.XOF{"SHAKE128": xof.SHAKE128, "SHAKE256": xof.SHAKE256}
+	for _, group := range expand {
+		for _, v := range group.Vectors {
+			var got []byte
+			var err error
+			if group.Name == "expand_message_xmd" {
+				got, err = hash2curve.ExpandMessageXMD(hashes[group.Hash], []byte(v.Msg), []byte(group.DST), v.LenInBytes)
+			} else {
+				got, err = hash2curve.ExpandMessageXOF(xofs[group.Hash], group.K, []byte(v.Msg), []byte(group.DST), v.LenInBytes)
+			}
+			if err != nil {
+				t.Fatalf("%s %s: %v", group.Name, group.Hash, err)
+			}
+			if want := testutil.MustHex(t, v.UniformBytes); !bytes.Equal(got, want) {
+				t.Fatalf("%s %s dst=%d msg=%.16q len=%d mismatch", group.Name, group.Hash, len(group.DST), v.Msg, v.LenInBytes)
+			}
+		}
+	}
+}
+
+// encodePoint builds the expected output encoding of a suite from the
+// affine big-endian coordinates of the vectors.
+func encodePoint(t *testing.T, suite, x, y string) []byte {
+	xb, yb := testutil.MustHex(t, x), testutil.MustHex(t, y)
+	switch {
+	case strings.HasPrefix(suite, "P"):
+		return append(append([]byte{4}, xb...), yb...)
+	case strings.HasPrefix(suite, "curve"):
+		return reverse(xb)
+	default:
+		out := reverse(yb)
+		if strings.HasPrefix(suite, "edwards448") {
+			out = append(out, 0)
+		}
+		out[len(out)-1] |= xb[len(xb)-1] & 1 << 7
+		return out
+	}
+}
+
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
+
+func TestSuiteVectors(t *testing.T) {
+	_, suites := loadVectors(t)
+	if len(suites) != 14 {
+		t.Fatalf("expected 14 suites, got %d", len(suites))
+	}
+	for _, group := range suites {
+		s, err := hash2curve.Lookup(group.Suite)
+		if err != nil {
+			t.Fatalf("%s: %v", group.Suite, err)
+		}
+		if s.ID() != group.Suite || s.RandomOracle() != strings.HasSuffix(group.Suite, "_RO_") {
+			t.Fatalf("%s: unexpected suite metadata", group.Suite)
+		}
+		dst := []byte(group.DST)
+		for _, v := range group.Vectors {
+			u, err := s.HashToField([]byte(v.Msg), dst, len(v.U))
+			if err != nil {
+				t.Fatalf("%s: hash_to_field: %v", group.Suite, err)
+			}
+			for i := range v.U {
+				if want := testutil.MustHex(t, v.U[i]); !bytes.Equal(u[i], want) {
+					t.Fatalf("%s msg=%.16q: u[%d] = %x, want %x", group.Suite, v.Msg, i, u[i], want)
+				}
+			}
+			got, err := s.Hash([]byte(v.Msg), dst)
+			if err != nil {
+				t.Fatalf("%s: hash: %v", group.Suite, err)
+			}
+			if want := encodePoint(t, group.Suite, v.P.X, v.P.Y); !bytes.Equal(got, want) {
+				t.Fatalf("%s msg=%.16q: point = %x, want %x", group.Suite, v.Msg, got, want)
+			}
+		}
+	}
+}
+
+func TestHashedPointsAreECDHPublicKeys(t *testing.T) {
+	dst := []byte("cryptonite-go-test-ecdh")
+	cases := []struct {
+		suite *hash2curve.Suite
+		kx    ecdh.KeyExchange
+	}{
+		{hash2curve.P256RO(), ecdh.NewP256()},
+		{hash2curve.P384RO(), ecdh.NewP384()},
+		{hash2curve.Curve25519RO(), ecdh.NewX25519()},
+		{hash2curve.Curve448RO(), ecdh.NewX448()},
+	}
+	for _, tc := range cases {
+		for _, msg := range []string{"", "alice", "bob"} {
+			p, err := tc.suite.Hash([]byte(msg), dst)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.suite.ID(), err)
+			}
+			pub, err := tc.kx.NewPublicKey(p)
+			if err != nil {
+				t.Fatalf("%s: hashed point rejected: %v", tc.suite.ID(), err)
+			}
+			priv, err := tc.kx.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tc.kx.SharedSecret(priv, pub); err != nil {
+				t.Fatalf("%s: ECDH with hashed point: %v", tc.suite.ID(), err)
+			}
+		}
+	}
+}
+
+func TestSuitesAreDomainSeparated(t *testing.T) {
+	s := hash2curve.Edwards25519RO()
+	a, _ := s.Hash([]byte("msg"), []byte("protocol-A"))
+	b, _ := s.Hash([]byte("msg"), []byte("protocol-B"))
+	if bytes.Equal(a, b) {
+		t.Fatal("different tags produced the same point")
+	}
+	nu, _ := hash2curve.Edwards25519NU().Hash([]byte("msg"), []byte("protocol-A"))
+	if bytes.Equal(a, nu) {
+		t.Fatal("RO and NU suites produced the same point")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	if _, err := hash2curve.Lookup("P256_XMD:SHA-256_SSWU_XX_"); err == nil {
+		t.Fatal("expected error for unknown suite")
+	}
+	if _, err := hash2curve.P256RO().Hash([]byte("msg"), nil); err == nil {
+		t.Fatal("expected error for empty tag")
+	}
+	if _, err := hash2curve.P256RO().HashToField([]byte("msg"), []byte("dst"), 0); err == nil {
+		t.Fatal("expected error for zero field elements")
+	}
+	for _, n := range []int{0, 255*32 + 1, 65536} {
+		if _, err := hash2curve.ExpandMessageXMD(cryptohash.NewSHA256, nil, []byte("dst"), n); err == nil {
+			t.Fatalf("expected error for xmd length %d", n)
+		}
+	}
+	for _, n := range []int{0, 65536} {
+		if _, err := hash2curve.ExpandMessageXOF(xof.SHAKE128, 128, nil, []byte("dst"), n); err == nil {
+			t.Fatalf("expected error for xof length %d", n)
+		}
+	}
+	if _, err := hash2curve.ExpandMessageXOF(xof.SHAKE128, 128, nil, nil, 32); err == nil {
+		t.Fatal("expected error for empty tag")
+	}
+}
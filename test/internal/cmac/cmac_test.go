@@ -0,0 +1,67 @@
+package cmac_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/internal/cmac"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+// AES-128 examples from RFC 4493 section 4: the message is the first n bytes
+// of rfc4493Message.
+const (
+	rfc4493Key     = "2b7e151628aed2a6abf7158809cf4f3c"
+	rfc4493Message = "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51" +
+		"30c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710"
+)
+
+var rfc4493Vectors = []struct {
+	n   int
+	tag string
+}{
+	{0, "bb1d6929e95937287fa37d129b756746"},
+	{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+	{40, "dfa66747de9ae63030ca32611497c827"},
+	{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+}
+
+func TestCMACRFC4493(t *testing.T) {
+	block, err := aes.NewCipher(testutil.MustHex(t, rfc4493Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := cmac.New(block)
+	msg := testutil.MustHex(t, rfc4493Message)
+	for _, v := range rfc4493Vectors {
+		got := c.Sum(msg[:v.n])
+		if !bytes.Equal(got[:], testutil.MustHex(t, v.tag)) {
+			t.Fatalf("len %d: tag %x, want %s", v.n, got, v.tag)
+		}
+		// Splitting the message across parts must not change the tag.
+		for _, cut := range []int{0, v.n / 3, v.n} {
+			split := c.Sum(msg[:cut], nil, msg[cut:v.n])
+			if split != got {
+				t.Fatalf("len %d split at %d: tag %x, want %x", v.n, cut, split, got)
+			}
+		}
+	}
+}
+
+func TestDoubleRFC4493Subkeys(t *testing.T) {
+	block, err := aes.NewCipher(testutil.MustHex(t, rfc4493Key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var l [cmac.BlockSize]byte
+	block.Encrypt(l[:], l[:])
+	k1 := cmac.Double(l)
+	k2 := cmac.Double(k1)
+	if !bytes.Equal(k1[:], testutil.MustHex(t, "fbeed618357133667c85e08f7236a8de")) {
+		t.Fatalf("K1 %x", k1)
+	}
+	if !bytes.Equal(k2[:], testutil.MustHex(t, "f7ddac306ae266ccf90bc11ee46d513b")) {
+		t.Fatalf("K2 %x", k2)
+	}
+}
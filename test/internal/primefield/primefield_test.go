@@ -0,0 +1,266 @@
+package primefield_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/internal/primefield"
+)
+
+func mustBig(t *testing.T, hex string) *big.Int {
+	t.Helper()
+	v, ok := new(big.Int).SetString(hex, 16)
+	if !ok {
+		t.Fatalf("invalid constant %q", hex)
+	}
+	return v
+}
+
+type testField struct {
+	name string
+	f    *primefield.Field
+	p    *big.Int
+}
+
+// fields returns every field hash2curve maps into. p448 is checked against
+// the same modulus through both the Montgomery and the internal/x448 backend.
+func fields(t *testing.T) []testField {
+	p256 := mustBig(t, "ffffffff00000001000000000000000000000000ffffffffffffffffffffffff")
+	p384 := mustBig(t, "fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffff0000000000000000ffffffff")
+	p521 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 521), big.NewInt(1))
+	p25519 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	p448 := mustBig(t, "fffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	return []testField{
+		{"P-256", primefield.New(p256), p256},
+		{"P-384", primefield.New(p384), p384},
+		{"P-521", primefield.New(p521), p521},
+		{"2^255-19", primefield.New(p25519), p25519},
+		{"2^448-2^224-1", primefield.New(p448), p448},
+		{"x448", primefield.NewP448(), p448},
+	}
+}
+
+func randBelow(t *testing.T, p *big.Int) *big.Int {
+	t.Helper()
+	v, err := rand.Int(rand.Reader, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func toBig(f *primefield.Field, x *primefield.Element) *big.Int {
+	return new(big.Int).SetBytes(f.Bytes(x))
+}
+
+// samples returns the edge values 0, 1, 2 and p-1 followed by random
+// elements.
+func samples(t *testing.T, p *big.Int, n int) []*big.Int {
+	s := []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(2), new(big.Int).Sub(p, big.NewInt(1))}
+	for i := 0; i < n; i++ {
+		s = append(s, randBelow(t, p))
+	}
+	return s
+}
+
+func TestArithmetic(t *testing.T) {
+	for _, tf := range fields(t) {
+		f, p := tf.f, tf.p
+		vals := samples(t, p, 16)
+		for _, a := range vals {
+			for _, b := range vals {
+				x, y := f.FromBig(a), f.FromBig(b)
+				var z primefield.Element
+				check := func(op string, want *big.Int) {
+					t.Helper()
+					want.Mod(want, p)
+					if got := toBig(f, &z); got.Cmp(want) != 0 {
+						t.Fatalf("%s: %s(%x, %x) = %x, want %x", tf.name, op, a, b, got, want)
+					}
+				}
+				f.Mul(&z, &x, &y)
+				check("Mul", new(big.Int).Mul(a, b))
+				f.Add(&z, &x, &y)
+				check("Add", new(big.Int).Add(a, b))
+				f.Sub(&z, &x, &y)
+				check("Sub", new(big.Int).Sub(a, b))
+			}
+			x := f.FromBig(a)
+			var z primefield.Element
+			f.Sqr(&z, &x)
+			if got, want := toBig(f, &z), new(big.Int).Exp(a, big.NewInt(2), p); got.Cmp(want) != 0 {
+				t.Fatalf("%s: Sqr(%x) = %x, want %x", tf.name, a, got, want)
+			}
+			f.Neg(&z, &x)
+			if got, want := toBig(f, &z), new(big.Int).Mod(new(big.Int).Neg(a), p); got.Cmp(want) != 0 {
+				t.Fatalf("%s: Neg(%x) = %x, want %x", tf.name, a, got, want)
+			}
+		}
+	}
+}
+
+func TestInv(t *testing.T) {
+	for _, tf := range fields(t) {
+		f, p := tf.f, tf.p
+		var z primefield.Element
+		zero := f.FromBig(big.NewInt(0))
+		f.Inv(&z, &zero)
+		if f.IsZero(&z) != 1 {
+			t.Fatalf("%s: Inv(0) = %x, want 0 (inv0)", tf.name, f.Bytes(&z))
+		}
+		for _, a := range samples(t, p, 16)[1:] {
+			x := f.FromBig(a)
+			f.Inv(&z, &x)
+			if got, want := toBig(f, &z), new(big.Int).ModInverse(a, p); got.Cmp(want) != 0 {
+				t.Fatalf("%s: Inv(%x) = %x, want %x", tf.name, a, got, want)
+			}
+		}
+	}
+}
+
+func TestSqrt(t *testing.T) {
+	for _, tf := range fields(t) {
+		f, p := tf.f, tf.p
+		for _, a := range samples(t, p, 16) {
+			sq := new(big.Int).Exp(a, big.NewInt(2), p)
+			x := f.FromBig(sq)
+			if f.IsSquare(&x) != 1 {
+				t.Fatalf("%s: IsSquare(%x) = 0", tf.name, sq)
+			}
+			var r, r2 primefield.Element
+			f.Sqrt(&r, &x)
+			f.Sqr(&r2, &r)
+			if f.Equal(&r2, &x) != 1 {
+				t.Fatalf("%s: Sqrt(%x) = %x, which does not square back", tf.name, sq, f.Bytes(&r))
+			}
+			if got := toBig(f, &r); got.Cmp(a) != 0 && got.Cmp(new(big.Int).Sub(p, a)) != 0 && a.Sign() != 0 {
+				t.Fatalf("%s: Sqrt(%x) = %x, want ±%x", tf.name, sq, got, a)
+			}
+		}
+		for i := 0; i < 16; i++ {
+			a := randBelow(t, p)
+			x := f.FromBig(a)
+			want := uint64(0)
+			if big.Jacobi(a, p) >= 0 {
+				want = 1
+			}
+			if got := f.IsSquare(&x); got != want {
+				t.Fatalf("%s: IsSquare(%x) = %d, want %d", tf.name, a, got, want)
+			}
+		}
+	}
+}
+
+// TestSqrtM1FixUp drives both branches of the p = 5 mod 8 square root: the
+// candidate x^((p+3)/8) is a root for half of the squares and must be
+// multiplied by sqrt(-1) for the other half.
+func TestSqrtM1FixUp(t *testing.T) {
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	f := primefield.New(p)
+	e := new(big.Int).Rsh(new(big.Int).Add(p, big.NewInt(3)), 3)
+	var direct, fixed int
+	for direct < 8 || fixed < 8 {
+		sq := new(big.Int).Exp(randBelow(t, p), big.NewInt(2), p)
+		c := new(big.Int).Exp(sq, e, p)
+		if new(big.Int).Exp(c, big.NewInt(2), p).Cmp(sq) == 0 {
+			direct++
+		} else {
+			fixed++
+		}
+		x := f.FromBig(sq)
+		var r, r2 primefield.Element
+		f.Sqrt(&r, &x)
+		f.Sqr(&r2, &r)
+		if f.Equal(&r2, &x) != 1 {
+			t.Fatalf("Sqrt(%x) = %x, which does not square back", sq, f.Bytes(&r))
+		}
+	}
+	// 9 takes the direct branch, 4 and -1 the fix-up.
+	for _, sq := range []*big.Int{big.NewInt(9), big.NewInt(4), new(big.Int).Sub(p, big.NewInt(1))} {
+		x := f.FromBig(sq)
+		var r, r2 primefield.Element
+		f.Sqrt(&r, &x)
+		f.Sqr(&r2, &r)
+		if f.Equal(&r2, &x) != 1 {
+			t.Fatalf("Sqrt(%x) = %x, which does not square back", sq, f.Bytes(&r))
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	for _, tf := range fields(t) {
+		f, p := tf.f, tf.p
+		two := new(big.Int).Lsh(p, 1)
+		inputs := []*big.Int{
+			new(big.Int).Sub(p, big.NewInt(1)),
+			new(big.Int).Set(p),
+			new(big.Int).Add(p, big.NewInt(1)),
+			two,
+			new(big.Int).Add(two, big.NewInt(1)),
+			new(big.Int).Mul(p, big.NewInt(3)),
+			new(big.Int).Mul(p, p),
+			new(big.Int).Sub(new(big.Int).Mul(p, p), big.NewInt(1)),
+		}
+		for _, v := range inputs {
+			for _, n := range []int{(v.BitLen() + 7) / 8, f.Size() + 16, 2*f.Size() + 3} {
+				if n*8 < v.BitLen() {
+					continue
+				}
+				in := v.FillBytes(make([]byte, n))
+				var z primefield.Element
+				f.Reduce(&z, in)
+				if got, want := toBig(f, &z), new(big.Int).Mod(v, p); got.Cmp(want) != 0 {
+					t.Fatalf("%s: Reduce(%x) = %x, want %x", tf.name, in, got, want)
+				}
+			}
+		}
+		for n := 1; n <= 2*f.Size()+8; n++ {
+			for _, in := range [][]byte{bytes.Repeat([]byte{0xff}, n), randBytes(t, n)} {
+				var z primefield.Element
+				f.Reduce(&z, in)
+				want := new(big.Int).Mod(new(big.Int).SetBytes(in), p)
+				if got := toBig(f, &z); got.Cmp(want) != 0 {
+					t.Fatalf("%s: Reduce(%x) = %x, want %x", tf.name, in, got, want)
+				}
+			}
+		}
+	}
+}
+
+func randBytes(t *testing.T, n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestEncoding(t *testing.T) {
+	for _, tf := range fields(t) {
+		f, p := tf.f, tf.p
+		if f.Size() != (p.BitLen()+7)/8 || f.BitLen() != p.BitLen() {
+			t.Fatalf("%s: Size %d BitLen %d", tf.name, f.Size(), f.BitLen())
+		}
+		if one := f.One(); toBig(f, one).Cmp(big.NewInt(1)) != 0 {
+			t.Fatalf("%s: One = %x", tf.name, f.Bytes(one))
+		}
+		for _, a := range samples(t, p, 8) {
+			x := f.FromBig(a)
+			be := f.Bytes(&x)
+			if want := a.FillBytes(make([]byte, f.Size())); !bytes.Equal(be, want) {
+				t.Fatalf("%s: Bytes = %x, want %x", tf.name, be, want)
+			}
+			le := f.BytesLE(&x)
+			for i := range le {
+				if le[i] != be[len(be)-1-i] {
+					t.Fatalf("%s: BytesLE = %x, Bytes = %x", tf.name, le, be)
+				}
+			}
+			if got := f.Sgn0(&x); got != uint64(a.Bit(0)) {
+				t.Fatalf("%s: Sgn0(%x) = %d", tf.name, a, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package kdf_test
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/kdf"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+// argon2_kat.json holds the RFC 9106 section 5 test vectors for Argon2d,
+// Argon2i and Argon2id (with secret K and associated data X), plus vectors
+// generated with golang.org/x/crypto/argon2 for memory sizes that are not a
+// multiple of 4*threads (100 KiB over three lanes and 47105 KiB over one),
+// which pin H0 to the requested rather than the rounded memory size.
+//
+//go:embed testdata/argon2_kat.json
+var argon2KAT []byte
+
+type argon2Vector struct {
+	Mode           string `json:"mode"`
+	Password       string `json:"password"`
+	Salt           string `json:"salt"`
+	Secret         string `json:"secret"`
+	AssociatedData string `json:"associated_data"`
+	Time           uint32 `json:"time"`
+	MemoryKiB      uint32 `json:"memory_kib"`
+	Threads        uint32 `json:"threads"`
+	Tag            string `json:"tag"`
+}
+
+func argon2Deriver(t *testing.T, v argon2Vector) kdf.Deriver {
+	t.Helper()
+	key := testutil.MustHex(t, v.Secret)
+	data := testutil.MustHex(t, v.AssociatedData)
+	switch v.Mode {
+	case "d":
+		return kdf.Argon2dDeriver{MemoryKiB: v.MemoryKiB, Time: v.Time, Threads: v.Threads, Key: key, AssociatedData: data}
+	case "i":
+		return kdf.Argon2iDeriver{MemoryKiB: v.MemoryKiB, Time: v.Time, Threads: v.Threads, Key: key, AssociatedData: data}
+	case "id":
+		return kdf.Argon2idDeriver{MemoryKiB: v.MemoryKiB, Time: v.Time, Threads: v.Threads, Key: key, AssociatedData: data}
+	}
+	t.Fatalf("unknown argon2 mode %q", v.Mode)
+	return nil
+}
+
+func TestArgon2Vectors(t *testing.T) {
+	var vectors []argon2Vector
+	if err := json.Unmarshal(argon2KAT, &vectors); err != nil {
+		t.Fatalf("parse vectors: %v", err)
+	}
+	for i, v := range vectors {
+		want := testutil.MustHex(t, v.Tag)
+		got, err := argon2Deriver(t, v).Derive(kdf.DeriveParams{
+			Secret: testutil.MustHex(t, v.Password),
+			Salt:   testutil.MustHex(t, v.Salt),
+			Length: len(want),
+		})
+		if err != nil {
+			t.Fatalf("Argon2%s #%d: %v", v.Mode, i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("Argon2%s #%d mismatch\n got %x\nwant %x", v.Mode, i, got, want)
+		}
+	}
+}
+
+func TestArgon2Helpers(t *testing.T) {
+	password, salt := []byte("password"), []byte("somesalt")
+	helpers := map[string]struct {
+		fn      func(secret, salt []byte, time, memoryKiB, threads uint32, length int) ([]byte, error)
+		deriver kdf.Deriver
+	}{
+		"d":  {kdf.Argon2d, kdf.NewArgon2dWithParams(2, 64, 2)},
+		"i":  {kdf.Argon2i, kdf.NewArgon2iWithParams(2, 64, 2)},
+		"id": {kdf.Argon2id, kdf.NewArgon2idWithParams(2, 64, 2)},
+	}
+	outputs := map[string]bool{}
+	for mode, h := range helpers {
+		got, err := h.fn(password, salt, 2, 64, 2, 32)
+		if err != nil {
+			t.Fatalf("Argon2%s: %v", mode, err)
+		}
+		viaDeriver, err := h.deriver.Derive(kdf.DeriveParams{Secret: password, Salt: salt, Length: 32})
+		if err != nil {
+			t.Fatalf("Argon2%s deriver: %v", mode, err)
+		}
+		if !bytes.Equal(got, viaDeriver) {
+			t.Fatalf("Argon2%s helper and deriver disagree", mode)
+		}
+		outputs[string(got)] = true
+	}
+	if len(outputs) != len(helpers) {
+		t.Fatal("Argon2 variants produced identical outputs")
+	}
+
+	base := kdf.Argon2idDeriver{MemoryKiB: 64, Time: 1, Threads: 2}
+	params := kdf.DeriveParams{Secret: password, Salt: salt, Length: 32}
+	plain, _ := base.Derive(params)
+	base.Key = []byte("pepper")
+	peppered, _ := base.Derive(params)
+	base.AssociatedData = []byte("context")
+	bound, _ := base.Derive(params)
+	if bytes.Equal(plain, peppered) || bytes.Equal(peppered, bound) {
+		t.Fatal("secret key or associated data did not affect the output")
+	}
+}
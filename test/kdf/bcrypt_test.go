@@ -0,0 +1,125 @@
+package kdf_test
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/kdf"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+// bcrypt_kat.json holds the crypt_blowfish and OpenBSD test vectors
+// ($2a$/$2b$/$2y$, including non-ASCII and empty passwords), the
+// golang.org/x/crypto/bcrypt "allmine" vector and x/crypto-generated hashes
+// around the 72-byte password limit. Passwords are hex encoded.
+//
+//go:embed testdata/bcrypt_kat.json
+var bcryptKAT []byte
+
+func TestBcryptVectors(t *testing.T) {
+	var vectors []struct {
+		Password string `json:"password"`
+		Hash     string `json:"hash"`
+	}
+	if err := json.Unmarshal(bcryptKAT, &vectors); err != nil {
+		t.Fatalf("parse vectors: %v", err)
+	}
+	for i, v := range vectors {
+		pw := testutil.MustHex(t, v.Password)
+		if err := kdf.BcryptVerify(pw, v.Hash); err != nil {
+			t.Fatalf("#%d %s: %v", i, v.Hash, err)
+		}
+		if err := kdf.BcryptVerify(append(pw, 'x'), v.Hash); err == nil {
+			t.Fatalf("#%d: accepted a wrong password", i)
+		}
+
+		h, err := kdf.ParseBcrypt(v.Hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := kdf.BcryptKey(pw, h.Salt[:], h.Cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(key) != string(h.Hash[:]) {
+			t.Fatalf("#%d: BcryptKey mismatch", i)
+		}
+		if h.String() != v.Hash {
+			t.Fatalf("#%d: re-encoded %s, want %s", i, h.String(), v.Hash)
+		}
+	}
+}
+
+func TestBcryptRoundTrip(t *testing.T) {
+	pw := []byte("legacy user password")
+	encoded, err := kdf.Bcrypt(pw, kdf.BcryptMinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(encoded, "$2b$04$") || len(encoded) != 60 {
+		t.Fatalf("unexpected encoding %q", encoded)
+	}
+	if err := kdf.BcryptVerify(pw, encoded); err != nil {
+		t.Fatal(err)
+	}
+	if err := kdf.BcryptVerify([]byte("other"), encoded); !errors.Is(err, kdf.ErrBcryptMismatch) {
+		t.Fatalf("got %v, want ErrBcryptMismatch", err)
+	}
+	h, _ := kdf.ParseBcrypt(encoded)
+	if h.Version != "2b" || h.Cost != kdf.BcryptMinCost {
+		t.Fatalf("parsed %+v", h)
+	}
+	again, _ := kdf.Bcrypt(pw, kdf.BcryptMinCost)
+	if again == encoded {
+		t.Fatal("salts repeated")
+	}
+}
+
+func TestBcryptPasswordLimit(t *testing.T) {
+	pw72 := []byte(strings.Repeat("a", kdf.BcryptMaxPasswordLength))
+	encoded, err := kdf.Bcrypt(pw72, kdf.BcryptMinCost)
+	if err != nil {
+		t.Fatalf("72-byte password rejected: %v", err)
+	}
+	long := append(pw72, 'b')
+	if _, err := kdf.Bcrypt(long, kdf.BcryptMinCost); !errors.Is(err, kdf.ErrBcryptPasswordTooLong) {
+		t.Fatalf("got %v, want ErrBcryptPasswordTooLong", err)
+	}
+	// A truncating implementation would accept this password.
+	if err := kdf.BcryptVerify(long, encoded); !errors.Is(err, kdf.ErrBcryptPasswordTooLong) {
+		t.Fatalf("got %v, want ErrBcryptPasswordTooLong", err)
+	}
+}
+
+func TestBcryptErrors(t *testing.T) {
+	salt := make([]byte, kdf.BcryptSaltSize)
+	if _, err := kdf.BcryptKey([]byte("pw"), salt, 3); err == nil {
+		t.Fatal("expected error for cost below minimum")
+	}
+	if _, err := kdf.BcryptKey([]byte("pw"), salt[:15], 4); err == nil {
+		t.Fatal("expected error for short salt")
+	}
+	valid := "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW"
+	for _, s := range []string{
+		"",
+		valid[:59],
+		valid + ".",
+		"$2x" + valid[3:],
+		"$2$" + valid[3:],
+		"%2a" + valid[3:],
+		"$2a$32" + valid[6:],
+		"$2a$03" + valid[6:],
+		"$2a$5a" + valid[6:],
+		"$2a$05$CCCCCCCCCCCCCCCCCCCCC!E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+	} {
+		if _, err := kdf.ParseBcrypt(s); err == nil {
+			t.Fatalf("ParseBcrypt(%q) succeeded", s)
+		}
+		if err := kdf.BcryptVerify([]byte("U*U"), s); err == nil {
+			t.Fatalf("BcryptVerify(%q) succeeded", s)
+		}
+	}
+}
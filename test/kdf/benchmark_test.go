@@ -3,6 +3,7 @@ package kdf_test
 import (
 	"testing"
 
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
 	"github.com/AeonDave/cryptonite-go/kdf"
 )
 
@@ -81,6 +82,86 @@ func BenchmarkKDF(b *testing.B) {
 		}
 	})
 
+	for _, v := range []struct {
+		name    string
+		fn      func(secret, salt []byte, time, memoryKiB, threads uint32, length int) ([]byte, error)
+		threads uint32
+	}{
+		{"Argon2i", kdf.Argon2i, 1},
+		{"Argon2d", kdf.Argon2d, 1},
+		{"Argon2id-4threads", kdf.Argon2id, 4},
+	} {
+		b.Run(v.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(dkLen))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if out, err := v.fn(password, salt, 1, 4*1024, v.threads, dkLen); err != nil || len(out) != dkLen {
+					b.Fatalf("%s failed: %v len=%d", v.name, err, len(out))
+				}
+			}
+		})
+	}
+
+	b.Run("Bcrypt-cost10", func(b *testing.B) {
+		bcryptSalt := salt[:kdf.BcryptSaltSize]
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := kdf.BcryptKey(password, bcryptSalt, kdf.BcryptDefaultCost); err != nil {
+				b.Fatalf("bcrypt failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("KBKDF-HMAC-SHA256", func(b *testing.B) {
+		d := kdf.NewKBKDFHMAC(cryptohash.NewSHA256, kdf.KBKDFOptions{})
+		params := kdf.DeriveParams{Secret: secret, Info: info, Length: dkLen}
+		b.ReportAllocs()
+		b.SetBytes(int64(dkLen))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := d.Derive(params); err != nil {
+				b.Fatalf("kbkdf failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("KBKDF-CMAC-AES256", func(b *testing.B) {
+		d := kdf.NewKBKDFCMAC(kdf.KBKDFOptions{Mode: kdf.KBKDFFeedback})
+		params := kdf.DeriveParams{Secret: secret, Info: info, Length: dkLen}
+		b.ReportAllocs()
+		b.SetBytes(int64(dkLen))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := d.Derive(params); err != nil {
+				b.Fatalf("kbkdf failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("ConcatKDF-SHA256", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(dkLen))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := kdf.ConcatKDF(cryptohash.NewSHA256, secret, info, dkLen); err != nil {
+				b.Fatalf("concat kdf failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("X963KDF-SHA256", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(dkLen))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := kdf.X963KDF(cryptohash.NewSHA256, secret, info, dkLen); err != nil {
+				b.Fatalf("x9.63 kdf failed: %v", err)
+			}
+		}
+	})
+
 	b.Run("Scrypt", func(b *testing.B) {
 		n := 1 << 15
 		r := 8
@@ -0,0 +1,89 @@
+package kdf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AeonDave/cryptonite-go/kdf"
+)
+
+// owaspArgon2idMinMemoryKiB lists the OWASP Argon2id minimum memory per
+// number of passes.
+var owaspArgon2idMinMemoryKiB = map[uint32]uint32{1: 47104, 2: 19456, 3: 12288, 4: 9216, 5: 7168}
+
+func TestCalibrateArgon2id(t *testing.T) {
+	for _, target := range []kdf.CalibrationTarget{
+		{Duration: time.Millisecond, MemoryKiB: 8 * 1024},
+		{Duration: time.Millisecond, MemoryKiB: 32 * 1024},
+		{Duration: 50 * time.Millisecond, MemoryKiB: 16 * 1024, Threads: 2},
+	} {
+		d, err := kdf.CalibrateArgon2id(target)
+		if err != nil {
+			t.Fatalf("%+v: %v", target, err)
+		}
+		if d.MemoryKiB > target.MemoryKiB {
+			t.Fatalf("%+v: memory %d KiB exceeds budget", target, d.MemoryKiB)
+		}
+		minMemory, ok := owaspArgon2idMinMemoryKiB[min(d.Time, 5)]
+		if !ok || d.MemoryKiB < minMemory {
+			t.Fatalf("%+v: m=%d t=%d below OWASP minimum", target, d.MemoryKiB, d.Time)
+		}
+		if d.Threads != max(target.Threads, 1) {
+			t.Fatalf("%+v: threads = %d", target, d.Threads)
+		}
+		out, err := d.Derive(kdf.DeriveParams{Secret: []byte("password"), Salt: []byte("somesalt"), Length: 32})
+		if err != nil || len(out) != 32 {
+			t.Fatalf("%+v: derive with calibrated params: %v", target, err)
+		}
+	}
+	if _, err := kdf.CalibrateArgon2id(kdf.CalibrationTarget{MemoryKiB: 4096}); err == nil {
+		t.Fatal("expected error for memory budget below OWASP minimum")
+	}
+}
+
+func TestCalibrateScrypt(t *testing.T) {
+	p, err := kdf.CalibrateScrypt(kdf.CalibrationTarget{Duration: time.Millisecond, MemoryKiB: 8 * 1024})
+	if err != nil {
+		t.Fatalf("CalibrateScrypt: %v", err)
+	}
+	if p.N != 1<<13 || p.R != 8 || p.P < 10 {
+		t.Fatalf("got N=%d r=%d p=%d, want N=2^13 r=8 p>=10", p.N, p.R, p.P)
+	}
+	p, err = kdf.CalibrateScrypt(kdf.CalibrationTarget{Duration: time.Millisecond, MemoryKiB: 20 * 1024})
+	if err != nil {
+		t.Fatalf("CalibrateScrypt: %v", err)
+	}
+	if 128*p.N*p.R > 20*1024*1024 || p.N < 1<<13 {
+		t.Fatalf("N=%d outside memory budget", p.N)
+	}
+	if _, err := kdf.NewScrypt(p.N, p.R, p.P); err != nil {
+		t.Fatalf("calibrated params rejected: %v", err)
+	}
+	if _, err := kdf.CalibrateScrypt(kdf.CalibrationTarget{MemoryKiB: 4096}); err == nil {
+		t.Fatal("expected error for memory budget below OWASP minimum")
+	}
+}
+
+func TestCalibratePBKDF2(t *testing.T) {
+	n, err := kdf.CalibratePBKDF2SHA256(kdf.CalibrationTarget{Duration: time.Millisecond})
+	if err != nil {
+		t.Fatalf("CalibratePBKDF2SHA256: %v", err)
+	}
+	if n != 600_000 {
+		t.Fatalf("SHA-256 iterations = %d, want OWASP minimum 600000", n)
+	}
+	n, err = kdf.CalibratePBKDF2SHA1(kdf.CalibrationTarget{Duration: time.Millisecond})
+	if err != nil {
+		t.Fatalf("CalibratePBKDF2SHA1: %v", err)
+	}
+	if n != 1_300_000 {
+		t.Fatalf("SHA-1 iterations = %d, want OWASP minimum 1300000", n)
+	}
+	n, err = kdf.CalibratePBKDF2SHA256(kdf.CalibrationTarget{Duration: time.Minute})
+	if err != nil {
+		t.Fatalf("CalibratePBKDF2SHA256: %v", err)
+	}
+	if n <= 600_000 || n%1000 != 0 {
+		t.Fatalf("iterations for one minute = %d", n)
+	}
+}
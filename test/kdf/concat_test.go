@@ -0,0 +1,115 @@
+package kdf_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/json"
+	stdhash "hash"
+	"testing"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	"github.com/AeonDave/cryptonite-go/kdf"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+// concat_kat.json holds two groups. "external" carries the RFC 7518
+// Appendix C ECDH-ES Concat KDF example and the first CAVP ANSI X9.63
+// SHA-1 vector. "openssl" was generated with the OpenSSL 3 SSKDF (hash,
+// HMAC and KMAC options, with and without salt) and X963KDF providers.
+//
+//go:embed testdata/concat_kat.json
+var concatKAT []byte
+
+var concatHashes = map[string]func() stdhash.Hash{
+	"SHA-1":    sha1.New,
+	"SHA-256":  cryptohash.NewSHA256,
+	"SHA-384":  cryptohash.NewSHA384,
+	"SHA-512":  cryptohash.NewSHA512,
+	"SHA3-256": cryptohash.NewSHA3256,
+}
+
+func TestConcatAndX963Vectors(t *testing.T) {
+	var kat map[string][]struct {
+		KDF    string `json:"kdf"`
+		Hash   string `json:"hash"`
+		Z      string `json:"z"`
+		Salt   string `json:"salt"`
+		Info   string `json:"info"`
+		Output string `json:"output"`
+	}
+	if err := json.Unmarshal(concatKAT, &kat); err != nil {
+		t.Fatalf("parse vectors: %v", err)
+	}
+	for group, vectors := range kat {
+		for i, v := range vectors {
+			var d kdf.Deriver
+			var err error
+			switch v.KDF {
+			case "concat":
+				d = kdf.NewConcatKDF(concatHashes[v.Hash])
+			case "concat-hmac":
+				d = kdf.NewConcatKDFHMAC(concatHashes[v.Hash])
+			case "concat-kmac128":
+				d, err = kdf.NewConcatKDFKMAC(128)
+			case "concat-kmac256":
+				d, err = kdf.NewConcatKDFKMAC(256)
+			case "x963":
+				d = kdf.NewX963KDF(concatHashes[v.Hash])
+			default:
+				t.Fatalf("unknown kdf %q", v.KDF)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := testutil.MustHex(t, v.Output)
+			got, err := d.Derive(kdf.DeriveParams{
+				Secret: testutil.MustHex(t, v.Z),
+				Salt:   testutil.MustHex(t, v.Salt),
+				Info:   testutil.MustHex(t, v.Info),
+				Length: len(want),
+			})
+			if err != nil {
+				t.Fatalf("%s #%d (%s %s): %v", group, i, v.KDF, v.Hash, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("%s #%d (%s %s) mismatch\n got %x\nwant %x", group, i, v.KDF, v.Hash, got, want)
+			}
+		}
+	}
+}
+
+func TestOtherInfo(t *testing.T) {
+	// RFC 7518 Appendix C: A128GCM, apu "Alice", apv "Bob", 128-bit key.
+	got := kdf.OtherInfo([]byte("A128GCM"), []byte("Alice"), []byte("Bob"), kdf.OtherInfoKeyLength(16), nil)
+	want := testutil.MustHex(t, "000000074131323847434d00000005416c69636500000003426f6200000080")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("OtherInfo %x, want %x", got, want)
+	}
+	withPriv := kdf.OtherInfo(nil, nil, nil, nil, []byte{0xaa})
+	if !bytes.Equal(withPriv, testutil.MustHex(t, "000000000000000000000000aa")) {
+		t.Fatalf("OtherInfo with empty fields %x", withPriv)
+	}
+}
+
+func TestConcatErrors(t *testing.T) {
+	z := makeBytes(32, 0x20)
+	if _, err := kdf.ConcatKDF(cryptohash.NewSHA256, nil, nil, 16); err == nil {
+		t.Fatal("expected error for empty shared secret")
+	}
+	if _, err := kdf.X963KDF(cryptohash.NewSHA256, z, nil, 0); err == nil {
+		t.Fatal("expected error for zero length")
+	}
+	if _, err := kdf.ConcatKDFKMAC256(z, nil, nil, -1); err == nil {
+		t.Fatal("expected error for negative length")
+	}
+	if _, err := kdf.NewConcatKDFKMAC(512); err == nil {
+		t.Fatal("expected error for KMAC security level")
+	}
+	// The counter placement distinguishes the two constructions.
+	a, _ := kdf.ConcatKDF(cryptohash.NewSHA256, z, []byte("info"), 32)
+	b, _ := kdf.X963KDF(cryptohash.NewSHA256, z, []byte("info"), 32)
+	if bytes.Equal(a, b) {
+		t.Fatal("Concat KDF and X9.63 KDF outputs coincide")
+	}
+}
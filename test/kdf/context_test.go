@@ -0,0 +1,206 @@
+package kdf_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AeonDave/cryptonite-go/kdf"
+)
+
+func contextDerivers(t *testing.T) map[string]kdf.Deriver {
+	t.Helper()
+	scrypt, err := kdf.NewScrypt(1<<10, 8, 1)
+	if err != nil {
+		t.Fatalf("NewScrypt: %v", err)
+	}
+	return map[string]kdf.Deriver{
+		"Argon2id":     kdf.NewArgon2idWithParams(2, 1024, 2),
+		"Argon2i":      kdf.NewArgon2iWithParams(2, 1024, 1),
+		"Argon2d":      kdf.NewArgon2dWithParams(2, 1024, 1),
+		"Scrypt":       scrypt,
+		"PBKDF2SHA1":   kdf.NewPBKDF2SHA1(),
+		"PBKDF2SHA256": kdf.NewPBKDF2SHA256(),
+	}
+}
+
+func TestDeriveContextMatchesDerive(t *testing.T) {
+	params := kdf.DeriveParams{Secret: []byte("password"), Salt: []byte("somesalt"), Iterations: 10_000, Length: 32}
+	for name, d := range contextDerivers(t) {
+		if _, ok := d.(kdf.ContextDeriver); !ok {
+			t.Fatalf("%s does not implement ContextDeriver", name)
+		}
+		want, err := d.Derive(params)
+		if err != nil {
+			t.Fatalf("%s Derive: %v", name, err)
+		}
+		got, err := kdf.DeriveContext(context.Background(), d, params)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("%s DeriveContext = %x, %v; want %x", name, got, err, want)
+		}
+	}
+}
+
+func TestDeriveContextCancelled(t *testing.T) {
+	params := kdf.DeriveParams{Secret: []byte("password"), Salt: []byte("somesalt"), Iterations: 10_000, Length: 32}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, d := range contextDerivers(t) {
+		if out, err := kdf.DeriveContext(ctx, d, params); !errors.Is(err, context.Canceled) || out != nil {
+			t.Fatalf("%s: got %x, %v; want context.Canceled", name, out, err)
+		}
+	}
+	hkdf := kdf.NewHKDFSHA256()
+	if _, err := kdf.DeriveContext(ctx, hkdf, kdf.DeriveParams{Secret: []byte("ikm"), Length: 32}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("plain deriver: got %v, want context.Canceled", err)
+	}
+}
+
+func TestDeriveContextCancelledShortPBKDF2(t *testing.T) {
+	// Below the periodic check interval only the entry check can notice the
+	// cancellation.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, iterations := range []int{1, 1000} {
+		params := kdf.DeriveParams{Secret: []byte("password"), Salt: []byte("somesalt"), Iterations: iterations, Length: 64}
+		for name, d := range map[string]kdf.Deriver{"PBKDF2SHA1": kdf.NewPBKDF2SHA1(), "PBKDF2SHA256": kdf.NewPBKDF2SHA256()} {
+			if out, err := kdf.DeriveContext(ctx, d, params); !errors.Is(err, context.Canceled) || out != nil {
+				t.Fatalf("%s iterations=%d: got %x, %v; want context.Canceled", name, iterations, out, err)
+			}
+		}
+	}
+}
+
+func TestDeriveContextAbortsLongDerivation(t *testing.T) {
+	scrypt, err := kdf.NewScrypt(1<<14, 8, 1000)
+	if err != nil {
+		t.Fatalf("NewScrypt: %v", err)
+	}
+	for name, d := range map[string]kdf.Deriver{
+		"Argon2id":     kdf.NewArgon2idWithParams(1000, 8*1024, 1),
+		"Scrypt":       scrypt,
+		"PBKDF2SHA256": kdf.NewPBKDF2SHA256(),
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		start := time.Now()
+		_, err := kdf.DeriveContext(ctx, d, kdf.DeriveParams{
+			Secret: []byte("password"), Salt: []byte("somesalt"), Iterations: 1 << 30, Length: 32,
+		})
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("%s: got %v, want context.DeadlineExceeded", name, err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Fatalf("%s: cancellation took %v", name, elapsed)
+		}
+	}
+}
+
+// blockingDeriver records how many derivations run at once.
+type blockingDeriver struct {
+	running, peak *int64
+	release       chan struct{}
+}
+
+func (d blockingDeriver) Derive(kdf.DeriveParams) ([]byte, error) {
+	n := atomic.AddInt64(d.running, 1)
+	for {
+		p := atomic.LoadInt64(d.peak)
+		if n <= p || atomic.CompareAndSwapInt64(d.peak, p, n) {
+			break
+		}
+	}
+	<-d.release
+	atomic.AddInt64(d.running, -1)
+	return []byte{1}, nil
+}
+
+func TestLimiterBoundsConcurrency(t *testing.T) {
+	var running, peak int64
+	release := make(chan struct{})
+	limiter := kdf.NewLimiter(3 * 1024)
+	d := limiter.Limit(blockingDeriver{running: &running, peak: &peak, release: release}, 1024)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := d.Derive(kdf.DeriveParams{}); err != nil {
+				t.Errorf("Derive: %v", err)
+			}
+		}()
+	}
+	for atomic.LoadInt64(&running) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The limiter is full, so a waiter with a short deadline gives up.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	if _, err := d.DeriveContext(ctx, kdf.DeriveParams{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	cancel()
+
+	close(release)
+	wg.Wait()
+	if peak != 3 {
+		t.Fatalf("peak concurrency = %d, want 3", peak)
+	}
+}
+
+func TestLimiterAcquireRelease(t *testing.T) {
+	limiter := kdf.NewLimiter(4)
+	ctx := context.Background()
+	if err := limiter.Acquire(ctx, 5); err == nil {
+		t.Fatal("expected error for cost above capacity")
+	}
+	if err := limiter.Acquire(ctx, 3); err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	// A large waiter queues ahead of later small ones.
+	acquired := make(chan int64, 2)
+	go func() {
+		if err := limiter.Acquire(ctx, 4); err == nil {
+			acquired <- 4
+		}
+	}()
+	for !waitingFor(limiter, 1) {
+		time.Sleep(time.Millisecond)
+	}
+	go func() {
+		if err := limiter.Acquire(ctx, 1); err == nil {
+			acquired <- 1
+		}
+	}()
+	select {
+	case n := <-acquired:
+		t.Fatalf("cost %d admitted while an earlier waiter is queued", n)
+	case <-time.After(10 * time.Millisecond):
+	}
+	limiter.Release(3)
+	if n := <-acquired; n != 4 {
+		t.Fatalf("admitted cost %d first, want 4", n)
+	}
+	limiter.Release(4)
+	if n := <-acquired; n != 1 {
+		t.Fatalf("admitted cost %d, want 1", n)
+	}
+	limiter.Release(1)
+}
+
+// waitingFor reports whether an Acquire of cost would currently block.
+func waitingFor(l *kdf.Limiter, cost int64) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := l.Acquire(ctx, cost); err != nil {
+		return true
+	}
+	l.Release(cost)
+	return false
+}
@@ -39,6 +39,33 @@ func BenchmarkMAC(b *testing.B) {
 		}
 	})
 
+	b.Run("GMAC", func(b *testing.B) {
+		nonce := makeBytes(12, 0x55)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := mac.SumGMAC(key, nonce, msg); err != nil {
+				b.Fatalf("gmac failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("POLYVAL", func(b *testing.B) {
+		p, err := mac.NewPOLYVAL(key[:mac.UniversalHashSize])
+		if err != nil {
+			b.Fatalf("polyval init failed: %v", err)
+		}
+		b.ReportAllocs()
+		b.SetBytes(int64(len(msg)))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			p.Reset()
+			_, _ = p.Write(msg)
+			_ = p.Sum(nil)
+		}
+	})
+
 	b.Run("KMAC128", func(b *testing.B) {
 		customization := []byte("custom")
 		b.ReportAllocs()
@@ -0,0 +1,168 @@
+package mac_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	_ "embed"
+	stdhash "hash"
+	"strings"
+	"testing"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	"github.com/AeonDave/cryptonite-go/mac"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+//go:embed testdata/hmac_kat.txt
+var hmacKAT string
+
+type hmacVector struct {
+	variant string
+	key     []byte
+	msg     []byte
+	tag     []byte
+}
+
+func parseHMACKAT(t *testing.T) []hmacVector {
+	t.Helper()
+	var cases []hmacVector
+	for _, block := range strings.Split(strings.TrimSpace(hmacKAT), "\n\n") {
+		var v hmacVector
+		for _, line := range strings.Split(block, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				t.Fatalf("invalid line %q", line)
+			}
+			value := strings.TrimSpace(parts[1])
+			switch strings.TrimSpace(parts[0]) {
+			case "Variant":
+				v.variant = value
+			case "Key":
+				v.key = testutil.MustHex(t, value)
+			case "Msg":
+				v.msg = testutil.MustHex(t, value)
+			case "Tag":
+				v.tag = testutil.MustHex(t, value)
+			default:
+				t.Fatalf("unexpected label in line %q", line)
+			}
+		}
+		if v.variant != "" {
+			cases = append(cases, v)
+		}
+	}
+	if len(cases) == 0 {
+		t.Fatal("no HMAC vectors parsed")
+	}
+	return cases
+}
+
+var hmacPresets = map[string]func([]byte) *mac.HMAC{
+	"SHA-224":  mac.NewHMACSHA224,
+	"SHA-256":  mac.NewHMACSHA256,
+	"SHA-384":  mac.NewHMACSHA384,
+	"SHA-512":  mac.NewHMACSHA512,
+	"SHA3-224": mac.NewHMACSHA3224,
+	"SHA3-256": mac.NewHMACSHA3256,
+	"SHA3-384": mac.NewHMACSHA3384,
+	"SHA3-512": mac.NewHMACSHA3512,
+	"BLAKE2b":  mac.NewHMACBlake2b,
+	"BLAKE2s":  mac.NewHMACBlake2s,
+	"BLAKE3":   mac.NewHMACBLAKE3,
+}
+
+func TestHMACPresetsKAT(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, tc := range parseHMACKAT(t) {
+		preset, ok := hmacPresets[tc.variant]
+		if !ok {
+			t.Fatalf("no preset for %q", tc.variant)
+		}
+		seen[tc.variant] = true
+		h := preset(tc.key)
+		if h.Size() != len(tc.tag) {
+			t.Fatalf("%s: Size %d, want %d", tc.variant, h.Size(), len(tc.tag))
+		}
+		_, _ = h.Write(tc.msg)
+		if got := h.Sum(nil); !bytes.Equal(got, tc.tag) {
+			t.Fatalf("%s: mismatch\n got %x\nwant %x", tc.variant, got, tc.tag)
+		}
+		if !h.Verify(tc.tag) {
+			t.Fatalf("%s: Verify rejected valid tag", tc.variant)
+		}
+		if h.Verify(tc.tag[:len(tc.tag)-1]) {
+			t.Fatalf("%s: Verify accepted short tag", tc.variant)
+		}
+		tampered := append([]byte(nil), tc.tag...)
+		tampered[len(tampered)-1] ^= 0x01
+		if h.Verify(tampered) {
+			t.Fatalf("%s: Verify accepted tampered tag", tc.variant)
+		}
+		h.Reset()
+		_, _ = h.Write(tc.msg)
+		if got := h.Sum(nil); !bytes.Equal(got, tc.tag) {
+			t.Fatalf("%s: mismatch after Reset", tc.variant)
+		}
+	}
+	for name := range hmacPresets {
+		if !seen[name] {
+			t.Fatalf("no vectors for preset %q", name)
+		}
+	}
+}
+
+func TestHMACGeneric(t *testing.T) {
+	// RFC 2202 test case 2 (HMAC-SHA-1).
+	key := []byte("Jefe")
+	msg := []byte("what do ya want for nothing?")
+	want := testutil.MustHex(t, "effcdf6ae5eb2fa2d27416d5f184df9c259a7c79")
+	newSHA1 := func() stdhash.Hash { return sha1.New() }
+	if got := mac.SumHMAC(newSHA1, key, msg); !bytes.Equal(got, want) {
+		t.Fatalf("SumHMAC mismatch\n got %x\nwant %x", got, want)
+	}
+	if !mac.VerifyHMAC(newSHA1, key, msg, want) {
+		t.Fatal("VerifyHMAC rejected valid tag")
+	}
+	if mac.VerifyHMAC(newSHA1, key, msg, want[:12]) {
+		t.Fatal("VerifyHMAC accepted truncated tag")
+	}
+}
+
+func TestHMACTruncated(t *testing.T) {
+	// RFC 4231 test case 5: HMAC-SHA-512 truncated to 128 bits.
+	key := bytes.Repeat([]byte{0x0c}, 20)
+	msg := []byte("Test With Truncation")
+	want := testutil.MustHex(t, "415fad6271580a531d4179bc891d87a6")
+	full := mac.NewHMACSHA512(key)
+	_, _ = full.Write(msg)
+	if !bytes.Equal(full.Sum(nil)[:16], want) {
+		t.Fatal("full tag prefix mismatch")
+	}
+	h, err := mac.NewHMACTruncated(cryptohash.NewSHA512, key, 16)
+	if err != nil {
+		t.Fatalf("NewHMACTruncated: %v", err)
+	}
+	_, _ = h.Write(msg)
+	if h.Size() != 16 {
+		t.Fatalf("Size = %d, want 16", h.Size())
+	}
+	if got := h.Sum(nil); !bytes.Equal(got, want) {
+		t.Fatalf("truncated mismatch\n got %x\nwant %x", got, want)
+	}
+	if !h.Verify(want) {
+		t.Fatal("Verify rejected truncated tag")
+	}
+	if h.Verify(want[:12]) {
+		t.Fatal("Verify accepted over-truncated tag")
+	}
+	if _, err := mac.NewHMACTruncated(cryptohash.NewSHA512, key, mac.HMACMinTagSize-1); err == nil {
+		t.Fatal("expected error for tag below minimum")
+	}
+	if _, err := mac.NewHMACTruncated(cryptohash.NewSHA512, key, 65); err == nil {
+		t.Fatal("expected error for tag above digest size")
+	}
+}
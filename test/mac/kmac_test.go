@@ -3,12 +3,17 @@ package mac_test
 import (
 	"bytes"
 	_ "embed"
+	"encoding"
+	"errors"
+	"fmt"
+	stdhash "hash"
 	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/AeonDave/cryptonite-go/mac"
 	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+	"github.com/AeonDave/cryptonite-go/xof"
 )
 
 //go:embed testdata/kmac_kat.txt
@@ -17,6 +22,9 @@ var kmacKAT string
 type kmacVectors struct {
 	KMAC128 []kmacVector `json:"kmac128"`
 	KMAC256 []kmacVector `json:"kmac256"`
+
+	KMACXOF128 []kmacVector `json:"kmacxof128"`
+	KMACXOF256 []kmacVector `json:"kmacxof256"`
 }
 
 type kmacVector struct {
@@ -79,6 +87,10 @@ func parseKMACVectors(t *testing.T) kmacVectors {
 			vectors.KMAC128 = append(vectors.KMAC128, v)
 		case "KMAC256":
 			vectors.KMAC256 = append(vectors.KMAC256, v)
+		case "KMACXOF128":
+			vectors.KMACXOF128 = append(vectors.KMACXOF128, v)
+		case "KMACXOF256":
+			vectors.KMACXOF256 = append(vectors.KMACXOF256, v)
 		default:
 			t.Fatalf("unknown variant %q at line %d", variant, i+1)
 		}
@@ -162,3 +174,154 @@ func TestKMACReset(t *testing.T) {
 		t.Fatalf("Reset did not reproduce the same MAC")
 	}
 }
+
+func TestKMACXOFKAT(t *testing.T) {
+	vectors := parseKMACVectors(t)
+	if len(vectors.KMACXOF128) == 0 || len(vectors.KMACXOF256) == 0 {
+		t.Fatal("no KMACXOF test vectors present")
+	}
+	run := func(t *testing.T, tc kmacVector, oneShot func(key, customization, msg []byte, outLen int) []byte,
+		stream func(key, customization []byte) xof.XOF) {
+		key := testutil.MustHex(t, tc.Key)
+		customization := testutil.MustHex(t, tc.Customization)
+		msg := testutil.MustHex(t, tc.Message)
+		want := testutil.MustHex(t, tc.Mac)
+		if got := oneShot(key, customization, msg, tc.OutLen); !bytes.Equal(got, want) {
+			t.Fatalf("unexpected output\n got  %x\n want %x", got, want)
+		}
+		x := stream(key, customization)
+		for i := range msg {
+			_, _ = x.Write(msg[i : i+1])
+		}
+		got := make([]byte, 0, tc.OutLen)
+		chunk := make([]byte, 7)
+		for len(got) < tc.OutLen {
+			n := tc.OutLen - len(got)
+			if n > len(chunk) {
+				n = len(chunk)
+			}
+			_, _ = x.Read(chunk[:n])
+			got = append(got, chunk[:n]...)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("unexpected streamed output\n got  %x\n want %x", got, want)
+		}
+		if _, err := x.Write(msg); err == nil {
+			t.Fatal("expected error writing after read")
+		}
+		x.Reset()
+		_, _ = x.Write(msg)
+		got = make([]byte, tc.OutLen)
+		_, _ = x.Read(got)
+		if !bytes.Equal(got, want) {
+			t.Fatalf("unexpected output after Reset\n got  %x\n want %x", got, want)
+		}
+	}
+	for i, tc := range vectors.KMACXOF128 {
+		t.Run(fmt.Sprintf("KMACXOF128_%d", i), func(t *testing.T) {
+			run(t, tc, mac.KMACXOF128, mac.NewKMACXOF128)
+		})
+	}
+	for i, tc := range vectors.KMACXOF256 {
+		t.Run(fmt.Sprintf("KMACXOF256_%d", i), func(t *testing.T) {
+			run(t, tc, mac.KMACXOF256, mac.NewKMACXOF256)
+		})
+	}
+}
+
+func TestKMACXOFDiffersFromKMAC(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	msg := []byte("length binding")
+	if bytes.Equal(mac.KMAC128(key, nil, msg, 32), mac.KMACXOF128(key, nil, msg, 32)) {
+		t.Fatal("KMACXOF128 output must not equal KMAC128 output")
+	}
+}
+
+func TestKMACXOFNegativeLength(t *testing.T) {
+	key := bytes.Repeat([]byte{0x42}, 32)
+	if out := mac.KMACXOF128(key, nil, []byte("msg"), -1); len(out) != 0 {
+		t.Fatalf("KMACXOF128 with negative length returned %d bytes", len(out))
+	}
+	if out := mac.KMACXOF256(key, nil, []byte("msg"), -8); len(out) != 0 {
+		t.Fatalf("KMACXOF256 with negative length returned %d bytes", len(out))
+	}
+}
+
+type resumableMAC interface {
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+func TestKMACMarshalResume(t *testing.T) {
+	key := makeBytes(32, 0x10)
+	msg := makeBytes(1000, 0x20)
+	want := mac.KMAC256(key, []byte("resume"), msg, 48)
+
+	h := mac.NewKMAC256WithSize(key, []byte("resume"), 48)
+	h.Write(msg[:333])
+	state, err := h.(resumableMAC).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	clone := h.(interface{ Clone() stdhash.Hash }).Clone()
+	h.Write([]byte("diverge"))
+
+	resumed := mac.NewKMAC256WithSize(key, []byte("resume"), 48)
+	if err := resumed.(resumableMAC).UnmarshalBinary(state); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	for _, m := range []stdhash.Hash{resumed, clone} {
+		m.Write(msg[333:])
+		if got := m.Sum(nil); !bytes.Equal(got, want) {
+			t.Fatalf("resumed KMAC mismatch")
+		}
+	}
+
+	for name, other := range map[string]stdhash.Hash{
+		"KMAC128":       mac.NewKMAC128WithSize(key, []byte("resume"), 48),
+		"customization": mac.NewKMAC256WithSize(key, []byte("other"), 48),
+		"output length": mac.NewKMAC256WithSize(key, []byte("resume"), 32),
+	} {
+		if err := other.(resumableMAC).UnmarshalBinary(state); !errors.Is(err, xof.ErrStateMismatch) {
+			t.Fatalf("%s: got %v, want ErrStateMismatch", name, err)
+		}
+	}
+	if err := resumed.(resumableMAC).UnmarshalBinary(state[:len(state)-2]); !errors.Is(err, xof.ErrInvalidState) {
+		t.Fatalf("truncated state: got %v, want ErrInvalidState", err)
+	}
+}
+
+func TestKMACXOFMarshalResume(t *testing.T) {
+	key := makeBytes(32, 0x30)
+	msg := makeBytes(500, 0x40)
+	want := mac.KMACXOF128(key, nil, msg, 96)
+
+	x := mac.NewKMACXOF128(key, nil)
+	x.Write(msg)
+	got := make([]byte, 96)
+	x.Read(got[:50])
+	state, err := x.(resumableMAC).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	clone := x.(xof.Cloner).Clone()
+
+	resumed := mac.NewKMACXOF128(key, nil)
+	if err := resumed.(resumableMAC).UnmarshalBinary(state); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	if _, err := resumed.Write([]byte("late")); err == nil {
+		t.Fatalf("resumed KMACXOF accepted a write after read")
+	}
+	for _, r := range []xof.XOF{resumed, clone} {
+		out := make([]byte, 96)
+		copy(out, got[:50])
+		r.Read(out[50:])
+		if !bytes.Equal(out, want) {
+			t.Fatalf("resumed KMACXOF output mismatch")
+		}
+	}
+	if err := mac.NewKMAC128(key, nil).(resumableMAC).UnmarshalBinary(state); !errors.Is(err, xof.ErrStateMismatch) {
+		t.Fatalf("KMACXOF state into KMAC: got %v, want ErrStateMismatch", err)
+	}
+}
@@ -0,0 +1,151 @@
+package mac_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/mac"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+func TestPOLYVALRFC8452(t *testing.T) {
+	// RFC 8452, Appendix A.
+	key := testutil.MustHex(t, "25629347589242761d31f826ba4b757b")
+	msg := testutil.MustHex(t, "4f4f95668c83dfb6401762bb2d01a262d1a24ddd2721d006bbe45f20d3c9f362")
+	want := testutil.MustHex(t, "f7a3b47b846119fae5b7866cf5e5b77e")
+
+	p, err := mac.NewPOLYVAL(key)
+	if err != nil {
+		t.Fatalf("NewPOLYVAL: %v", err)
+	}
+	for _, b := range msg {
+		_, _ = p.Write([]byte{b})
+	}
+	if got := p.Sum(nil); !bytes.Equal(got, want) {
+		t.Fatalf("POLYVAL mismatch\n got %x\nwant %x", got, want)
+	}
+	p.Reset()
+	_, _ = p.Write(msg)
+	if got := p.Sum(nil); !bytes.Equal(got, want) {
+		t.Fatalf("POLYVAL mismatch after Reset\n got %x\nwant %x", got, want)
+	}
+	if _, err := mac.NewPOLYVAL(key[:15]); err == nil {
+		t.Fatal("expected error for short POLYVAL key")
+	}
+}
+
+func TestGHASHGCMTestCase2(t *testing.T) {
+	// GCM specification test case 2: GHASH(H, {}, C).
+	h := testutil.MustHex(t, "66e94bd4ef8a2c3b884cfa59ca342b2e")
+	c := testutil.MustHex(t, "0388dace60b6a392f328c2b971b2fe78")
+	lengths := testutil.MustHex(t, "00000000000000000000000000000080")
+	want := testutil.MustHex(t, "f38cbb1ad69223dcc3457ae5b6b0f885")
+
+	g, err := mac.NewGHASH(h)
+	if err != nil {
+		t.Fatalf("NewGHASH: %v", err)
+	}
+	_, _ = g.Write(c)
+	_, _ = g.Write(lengths)
+	if got := g.Sum(nil); !bytes.Equal(got, want) {
+		t.Fatalf("GHASH mismatch\n got %x\nwant %x", got, want)
+	}
+}
+
+func TestGHASHPadAlignsFields(t *testing.T) {
+	h := makeBytes(16, 0x21)
+	g1, _ := mac.NewGHASH(h)
+	_, _ = g1.Write([]byte("abc"))
+	g1.Pad()
+	_, _ = g1.Write([]byte("defg"))
+
+	padded := make([]byte, 16)
+	copy(padded, "abc")
+	g2, _ := mac.NewGHASH(h)
+	_, _ = g2.Write(padded)
+	_, _ = g2.Write([]byte("defg"))
+
+	if !bytes.Equal(g1.Sum(nil), g2.Sum(nil)) {
+		t.Fatal("Pad did not zero-fill the partial block")
+	}
+}
+
+func TestGMACMatchesGCM(t *testing.T) {
+	for _, keyLen := range []int{16, 24, 32} {
+		key := makeBytes(keyLen, 0x42)
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, nonceLen := range []int{12, 8, 16, 60} {
+			gcm, err := cipher.NewGCMWithNonceSize(block, nonceLen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nonce := makeBytes(nonceLen, 0x07)
+			for _, adLen := range []int{0, 1, 15, 16, 17, 100} {
+				ad := makeBytes(adLen, 0x90)
+				want := gcm.Seal(nil, nonce, nil, ad)
+				got, err := mac.SumGMAC(key, nonce, ad)
+				if err != nil {
+					t.Fatalf("SumGMAC: %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Fatalf("key %d nonce %d ad %d: mismatch\n got %x\nwant %x", keyLen, nonceLen, adLen, got, want)
+				}
+				ok, err := mac.VerifyGMAC(key, nonce, ad, want)
+				if err != nil || !ok {
+					t.Fatalf("VerifyGMAC rejected valid tag: %v", err)
+				}
+				tampered := append([]byte(nil), want...)
+				tampered[0] ^= 1
+				if ok, _ := mac.VerifyGMAC(key, nonce, ad, tampered); ok {
+					t.Fatal("VerifyGMAC accepted tampered tag")
+				}
+			}
+		}
+	}
+	if _, err := mac.NewGMAC(makeBytes(16, 0), nil); err == nil {
+		t.Fatal("expected error for empty GMAC nonce")
+	}
+}
+
+func TestPoly1305AES(t *testing.T) {
+	cases := []struct {
+		key, nonce, msg, tag string
+	}{
+		{
+			// Bernstein, "The Poly1305-AES message-authentication code", Appendix B.
+			key:   "75deaa25c09f208e1dc4ce6b5cad3fbfa0f3080000f46400d0c7e9076c834403",
+			nonce: "61ee09218d29b0aaed7e154a2c5509cc",
+			tag:   "dd3fab2251f11ac759f0887129cc2ee7",
+		},
+		{
+			key:   "ec074c835580741701425b623235add6851fc40c3467ac0be05cc20404f3f570",
+			nonce: "fb447350c4e868c52ac3275cf9d4327e",
+			msg:   "f3f6",
+			tag:   "efc633c3044fc145f84f335cb8196df0",
+		},
+	}
+	for i, tc := range cases {
+		key := testutil.MustHex(t, tc.key)
+		nonce := testutil.MustHex(t, tc.nonce)
+		msg := testutil.MustHex(t, tc.msg)
+		want := testutil.MustHex(t, tc.tag)
+		got, err := mac.SumPoly1305AES(key, nonce, msg)
+		if err != nil {
+			t.Fatalf("case %d: SumPoly1305AES: %v", i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("case %d: mismatch\n got %x\nwant %x", i, got, want)
+		}
+		if ok, err := mac.VerifyPoly1305AES(key, nonce, msg, want); err != nil || !ok {
+			t.Fatalf("case %d: VerifyPoly1305AES rejected valid tag: %v", i, err)
+		}
+	}
+	if _, err := mac.SumPoly1305AES(makeBytes(32, 0), makeBytes(12, 0), nil); err == nil {
+		t.Fatal("expected error for short Poly1305-AES nonce")
+	}
+}
@@ -0,0 +1,71 @@
+package merkle_test
+
+import (
+	"testing"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	"github.com/AeonDave/cryptonite-go/merkle"
+)
+
+func makeBytes(length int, seed byte) []byte {
+	buf := make([]byte, length)
+	for i := range buf {
+		buf[i] = seed + byte(i)
+	}
+	return buf
+}
+
+func BenchmarkMerkle(b *testing.B) {
+	h := cryptohash.NewSHA256Hasher()
+	leaf := makeBytes(256, 0x11)
+
+	b.Run("Append", func(b *testing.B) {
+		tree := merkle.New(h)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(leaf)))
+		for i := 0; i < b.N; i++ {
+			tree.Append(leaf)
+		}
+	})
+
+	tree := merkle.New(h)
+	for i := 0; i < 1<<16; i++ {
+		tree.Append(leaf)
+	}
+	size := tree.Size()
+	root := tree.Root()
+	leafHash, _ := tree.LeafHash(12345)
+
+	b.Run("InclusionProof", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = tree.InclusionProof(uint64(i)%size, size)
+		}
+	})
+
+	proof, _ := tree.InclusionProof(12345, size)
+	b.Run("VerifyInclusion", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := merkle.VerifyInclusion(h, 12345, size, leafHash, proof, root); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("ConsistencyProof", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = tree.ConsistencyProof(uint64(i)%size+1, size)
+		}
+	})
+
+	b.Run("CompactRangeAppend", func(b *testing.B) {
+		r := merkle.NewRange(h, 0)
+		lh := merkle.HashLeaf(h, leaf)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.Append(lh)
+		}
+	})
+}
@@ -0,0 +1,345 @@
+package merkle_test
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	"github.com/AeonDave/cryptonite-go/merkle"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+)
+
+// merkle_kat.json was generated from a direct transcription of the RFC 9162
+// MTH, PATH and PROOF definitions over SHA-256. The first eight leaves are
+// the Certificate Transparency reference inputs.
+//
+//go:embed testdata/merkle_kat.json
+var merkleKAT []byte
+
+type merkleVectors struct {
+	Leaves    []string `json:"leaves"`
+	Roots     []string `json:"roots"`
+	Inclusion []struct {
+		Index uint64   `json:"index"`
+		Size  uint64   `json:"size"`
+		Proof []string `json:"proof"`
+	} `json:"inclusion"`
+	Consistency []struct {
+		OldSize uint64   `json:"old_size"`
+		NewSize uint64   `json:"new_size"`
+		Proof   []string `json:"proof"`
+	} `json:"consistency"`
+}
+
+func loadMerkleVectors(t *testing.T) (merkleVectors, *merkle.Tree) {
+	t.Helper()
+	var v merkleVectors
+	if err := json.Unmarshal(merkleKAT, &v); err != nil {
+		t.Fatalf("parse vectors: %v", err)
+	}
+	tree := merkle.New(cryptohash.NewSHA256Hasher())
+	for _, leaf := range v.Leaves {
+		tree.Append(testutil.MustHex(t, leaf))
+	}
+	return v, tree
+}
+
+func hexList(t *testing.T, in []string) [][]byte {
+	out := make([][]byte, len(in))
+	for i, s := range in {
+		out[i] = testutil.MustHex(t, s)
+	}
+	return out
+}
+
+func equalProofs(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCertificateTransparencyRoots(t *testing.T) {
+	// Reference roots of the Certificate Transparency test tree.
+	want := []string{
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+	v, _ := loadMerkleVectors(t)
+	tree := merkle.New(cryptohash.NewSHA256Hasher())
+	r := merkle.NewRange(cryptohash.NewSHA256Hasher(), 0)
+	for i, root := range want {
+		leaf := testutil.MustHex(t, v.Leaves[i])
+		tree.Append(leaf)
+		r.Append(merkle.HashLeaf(cryptohash.NewSHA256Hasher(), leaf))
+		if got := tree.Root(); !bytes.Equal(got, testutil.MustHex(t, root)) {
+			t.Fatalf("size %d: root %x, want %s", i+1, got, root)
+		}
+		if got, err := r.Root(); err != nil || !bytes.Equal(got, testutil.MustHex(t, root)) {
+			t.Fatalf("size %d: compact range root %x (%v), want %s", i+1, got, err, root)
+		}
+	}
+}
+
+func TestTreeRootsKAT(t *testing.T) {
+	v, tree := loadMerkleVectors(t)
+	for size, want := range v.Roots {
+		got, err := tree.RootAt(uint64(size))
+		if err != nil {
+			t.Fatalf("RootAt(%d): %v", size, err)
+		}
+		if !bytes.Equal(got, testutil.MustHex(t, want)) {
+			t.Fatalf("RootAt(%d) = %x, want %s", size, got, want)
+		}
+	}
+	if _, err := tree.RootAt(tree.Size() + 1); err == nil {
+		t.Fatalf("RootAt beyond the tree size succeeded")
+	}
+}
+
+func TestInclusionProofKAT(t *testing.T) {
+	v, tree := loadMerkleVectors(t)
+	h := tree.Hasher()
+	for _, tc := range v.Inclusion {
+		want := hexList(t, tc.Proof)
+		proof, err := tree.InclusionProof(tc.Index, tc.Size)
+		if err != nil {
+			t.Fatalf("InclusionProof(%d, %d): %v", tc.Index, tc.Size, err)
+		}
+		if !equalProofs(proof, want) {
+			t.Fatalf("InclusionProof(%d, %d) mismatch", tc.Index, tc.Size)
+		}
+		leaf, _ := tree.LeafHash(tc.Index)
+		root := testutil.MustHex(t, v.Roots[tc.Size])
+		if err := merkle.VerifyInclusion(h, tc.Index, tc.Size, leaf, proof, root); err != nil {
+			t.Fatalf("VerifyInclusion(%d, %d): %v", tc.Index, tc.Size, err)
+		}
+	}
+}
+
+func TestConsistencyProofKAT(t *testing.T) {
+	v, tree := loadMerkleVectors(t)
+	h := tree.Hasher()
+	for _, tc := range v.Consistency {
+		want := hexList(t, tc.Proof)
+		proof, err := tree.ConsistencyProof(tc.OldSize, tc.NewSize)
+		if err != nil {
+			t.Fatalf("ConsistencyProof(%d, %d): %v", tc.OldSize, tc.NewSize, err)
+		}
+		if !equalProofs(proof, want) {
+			t.Fatalf("ConsistencyProof(%d, %d) mismatch", tc.OldSize, tc.NewSize)
+		}
+		oldRoot := testutil.MustHex(t, v.Roots[tc.OldSize])
+		newRoot := testutil.MustHex(t, v.Roots[tc.NewSize])
+		if err := merkle.VerifyConsistency(h, tc.OldSize, tc.NewSize, oldRoot, newRoot, proof); err != nil {
+			t.Fatalf("VerifyConsistency(%d, %d): %v", tc.OldSize, tc.NewSize, err)
+		}
+	}
+}
+
+func TestProofsAllSizes(t *testing.T) {
+	h := cryptohash.NewSHA256Hasher()
+	tree := merkle.New(h)
+	for i := 0; i < 40; i++ {
+		tree.Append([]byte{byte(i)})
+	}
+	for size := uint64(1); size <= tree.Size(); size++ {
+		root, _ := tree.RootAt(size)
+		for index := uint64(0); index < size; index++ {
+			proof, err := tree.InclusionProof(index, size)
+			if err != nil {
+				t.Fatalf("InclusionProof(%d, %d): %v", index, size, err)
+			}
+			leaf, _ := tree.LeafHash(index)
+			if err := merkle.VerifyInclusion(h, index, size, leaf, proof, root); err != nil {
+				t.Fatalf("VerifyInclusion(%d, %d): %v", index, size, err)
+			}
+			if index+1 < size {
+				if merkle.VerifyInclusion(h, index+1, size, leaf, proof, root) == nil {
+					t.Fatalf("VerifyInclusion(%d, %d) accepted the wrong index", index+1, size)
+				}
+			}
+		}
+		for old := uint64(0); old <= size; old++ {
+			proof, err := tree.ConsistencyProof(old, size)
+			if err != nil {
+				t.Fatalf("ConsistencyProof(%d, %d): %v", old, size, err)
+			}
+			oldRoot, _ := tree.RootAt(old)
+			if err := merkle.VerifyConsistency(h, old, size, oldRoot, root, proof); err != nil {
+				t.Fatalf("VerifyConsistency(%d, %d): %v", old, size, err)
+			}
+		}
+	}
+}
+
+func TestVerifyRejectsTamperedProofs(t *testing.T) {
+	h := cryptohash.NewSHA256Hasher()
+	tree := merkle.New(h)
+	for i := 0; i < 21; i++ {
+		tree.Append([]byte{byte(i), 0xaa})
+	}
+	root := tree.Root()
+	leaf, _ := tree.LeafHash(6)
+	proof, _ := tree.InclusionProof(6, 21)
+
+	check := func(name string, err error) {
+		t.Helper()
+		if !errors.Is(err, merkle.ErrInvalidProof) {
+			t.Fatalf("%s: got %v, want ErrInvalidProof", name, err)
+		}
+	}
+	for i := range proof {
+		bad := hexCopy(proof)
+		bad[i][0] ^= 1
+		check("flipped inclusion element", merkle.VerifyInclusion(h, 6, 21, leaf, bad, root))
+	}
+	check("truncated inclusion", merkle.VerifyInclusion(h, 6, 21, leaf, proof[:len(proof)-1], root))
+	check("extended inclusion", merkle.VerifyInclusion(h, 6, 21, leaf, append(hexCopy(proof), root), root))
+	check("wrong size", merkle.VerifyInclusion(h, 6, 16, leaf, proof, root))
+	check("index out of range", merkle.VerifyInclusion(h, 21, 21, leaf, proof, root))
+	check("raw data instead of leaf hash", merkle.VerifyInclusion(h, 6, 21, []byte{6, 0xaa}, proof, root))
+
+	oldRoot, _ := tree.RootAt(13)
+	cproof, _ := tree.ConsistencyProof(13, 21)
+	for i := range cproof {
+		bad := hexCopy(cproof)
+		bad[i][0] ^= 1
+		check("flipped consistency element", merkle.VerifyConsistency(h, 13, 21, oldRoot, root, bad))
+	}
+	check("truncated consistency", merkle.VerifyConsistency(h, 13, 21, oldRoot, root, cproof[:len(cproof)-1]))
+	check("wrong old root", merkle.VerifyConsistency(h, 13, 21, root, root, cproof))
+	check("wrong old size", merkle.VerifyConsistency(h, 12, 21, oldRoot, root, cproof))
+	check("shrinking tree", merkle.VerifyConsistency(h, 21, 13, root, oldRoot, cproof))
+	check("equal sizes, different roots", merkle.VerifyConsistency(h, 21, 21, oldRoot, root, nil))
+	check("empty proof", merkle.VerifyConsistency(h, 13, 21, oldRoot, root, nil))
+}
+
+func TestCompactRange(t *testing.T) {
+	h := cryptohash.NewSHA256Hasher()
+	tree := merkle.New(h)
+	const n = 45
+	for i := 0; i < n; i++ {
+		tree.Append([]byte{byte(i), 0x55})
+	}
+	for begin := uint64(0); begin <= n; begin++ {
+		for end := begin; end <= n; end++ {
+			want, err := tree.Range(begin, end)
+			if err != nil {
+				t.Fatalf("Range(%d, %d): %v", begin, end, err)
+			}
+			r := merkle.NewRange(h, begin)
+			for i := begin; i < end; i++ {
+				leaf, _ := tree.LeafHash(i)
+				r.Append(leaf)
+			}
+			if r.End() != end || !equalProofs(r.Hashes(), want.Hashes()) {
+				t.Fatalf("[%d, %d): appended range differs from tree range", begin, end)
+			}
+			for mid := begin; mid <= end; mid += 3 {
+				left, _ := tree.Range(begin, mid)
+				right, _ := tree.Range(mid, end)
+				if err := left.AppendRange(right); err != nil {
+					t.Fatalf("AppendRange: %v", err)
+				}
+				if !equalProofs(left.Hashes(), want.Hashes()) {
+					t.Fatalf("[%d, %d) split at %d: merged range differs", begin, end, mid)
+				}
+			}
+			restored, err := merkle.RestoreRange(h, begin, end, want.Hashes())
+			if err != nil || !equalProofs(restored.Hashes(), want.Hashes()) {
+				t.Fatalf("[%d, %d): RestoreRange: %v", begin, end, err)
+			}
+		}
+		if begin == 0 {
+			continue
+		}
+		r, _ := tree.Range(begin, n)
+		if _, err := r.Root(); err == nil {
+			t.Fatalf("Root of a range starting at %d succeeded", begin)
+		}
+	}
+	for size := uint64(0); size <= n; size++ {
+		r, _ := tree.Range(0, size)
+		got, err := r.Root()
+		want, _ := tree.RootAt(size)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("Range(0, %d).Root() mismatch", size)
+		}
+	}
+}
+
+func TestCompactRangeErrors(t *testing.T) {
+	h := cryptohash.NewSHA256Hasher()
+	node := make([]byte, h.Size())
+	if _, err := merkle.RestoreRange(h, 0, 3, [][]byte{node}); err == nil {
+		t.Fatalf("RestoreRange accepted the wrong number of hashes")
+	}
+	if _, err := merkle.RestoreRange(h, 0, 3, [][]byte{node, node[:5]}); err == nil {
+		t.Fatalf("RestoreRange accepted a short hash")
+	}
+	a := merkle.NewRange(h, 0)
+	a.Append(node)
+	if err := a.AppendRange(merkle.NewRange(h, 2)); err == nil {
+		t.Fatalf("AppendRange accepted a non-adjacent range")
+	}
+}
+
+func TestTreeDoesNotAliasCallerBuffers(t *testing.T) {
+	h := cryptohash.NewSHA256Hasher()
+	tree := merkle.New(h)
+	leaf := merkle.HashLeaf(h, []byte("leaf"))
+	tree.AppendLeafHash(leaf)
+	tree.Append([]byte("second"))
+	root := tree.Root()
+	leaf[0] ^= 0xff
+	proof, _ := tree.InclusionProof(0, 2)
+	proof[0][0] ^= 0xff
+	if !bytes.Equal(tree.Root(), root) {
+		t.Fatalf("tree state changed through a caller-owned buffer")
+	}
+}
+
+func TestMerkleOverOtherHashes(t *testing.T) {
+	b2, err := cryptohash.NewBlake2bHasher(32, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []cryptohash.Hasher{b2, cryptohash.NewBLAKE3Hasher(), cryptohash.NewSHA3256Hasher()} {
+		tree := merkle.New(h)
+		for i := 0; i < 11; i++ {
+			tree.Append([]byte{byte(i)})
+		}
+		proof, _ := tree.InclusionProof(9, 11)
+		leaf := merkle.HashLeaf(h, []byte{9})
+		if err := merkle.VerifyInclusion(h, 9, 11, leaf, proof, tree.Root()); err != nil {
+			t.Fatalf("%T: %v", h, err)
+		}
+		want := h.Hash(append([]byte{1}, append(merkle.HashLeaf(h, []byte{0}), merkle.HashLeaf(h, []byte{1})...)...))
+		if got, _ := tree.RootAt(2); !bytes.Equal(got, want) {
+			t.Fatalf("%T: two-leaf root mismatch", h)
+		}
+	}
+}
+
+func hexCopy(in [][]byte) [][]byte {
+	out := make([][]byte, len(in))
+	for i := range in {
+		out[i] = append([]byte(nil), in[i]...)
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package otp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AeonDave/cryptonite-go/otp"
+)
+
+func BenchmarkOTP(b *testing.B) {
+	h, err := otp.NewHOTP(seed20, otp.Params{Window: 10})
+	if err != nil {
+		b.Fatalf("NewHOTP failed: %v", err)
+	}
+	tp, err := otp.NewTOTP(seed20, otp.Params{Window: 1})
+	if err != nil {
+		b.Fatalf("NewTOTP failed: %v", err)
+	}
+	now := time.Unix(1111111111, 0)
+
+	b.Run("HOTP/Generate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = h.Generate(uint64(i))
+		}
+	})
+
+	b.Run("HOTP/VerifyWindow10", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = h.Verify("000000", 0)
+		}
+	})
+
+	b.Run("TOTP/Verify", func(b *testing.B) {
+		code := tp.Generate(now)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, ok := tp.Verify(code, now); !ok {
+				b.Fatal("verify failed")
+			}
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package otp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AeonDave/cryptonite-go/otp"
+)
+
+var (
+	seed20 = []byte("12345678901234567890")
+	seed32 = []byte("12345678901234567890123456789012")
+	seed64 = []byte("1234567890123456789012345678901234567890123456789012345678901234")
+)
+
+// RFC 4226 Appendix D.
+func TestHOTPRFC4226(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	h, err := otp.NewHOTP(seed20, otp.Params{})
+	if err != nil {
+		t.Fatalf("NewHOTP failed: %v", err)
+	}
+	for i, code := range want {
+		if got := h.Generate(uint64(i)); got != code {
+			t.Fatalf("counter %d: got %s want %s", i, got, code)
+		}
+	}
+}
+
+// RFC 6238 Appendix B.
+func TestTOTPRFC6238(t *testing.T) {
+	cases := []struct {
+		secs int64
+		sha1 string
+		s256 string
+		s512 string
+	}{
+		{59, "94287082", "46119246", "90693936"},
+		{1111111109, "07081804", "68084774", "25091201"},
+		{1111111111, "14050471", "67062674", "99943326"},
+		{1234567890, "89005924", "91819424", "93441116"},
+		{2000000000, "69279037", "90698825", "38618901"},
+		{20000000000, "65353130", "77737706", "47863826"},
+	}
+	newTOTP := func(seed []byte, alg otp.Algorithm) *otp.TOTP {
+		tp, err := otp.NewTOTP(seed, otp.Params{Algorithm: alg, Digits: 8})
+		if err != nil {
+			t.Fatalf("NewTOTP failed: %v", err)
+		}
+		return tp
+	}
+	t1 := newTOTP(seed20, otp.SHA1)
+	t256 := newTOTP(seed32, otp.SHA256)
+	t512 := newTOTP(seed64, otp.SHA512)
+	for _, tc := range cases {
+		at := time.Unix(tc.secs, 0)
+		if got := t1.Generate(at); got != tc.sha1 {
+			t.Fatalf("SHA1 at %d: got %s want %s", tc.secs, got, tc.sha1)
+		}
+		if got := t256.Generate(at); got != tc.s256 {
+			t.Fatalf("SHA256 at %d: got %s want %s", tc.secs, got, tc.s256)
+		}
+		if got := t512.Generate(at); got != tc.s512 {
+			t.Fatalf("SHA512 at %d: got %s want %s", tc.secs, got, tc.s512)
+		}
+	}
+}
+
+func TestHOTPLookAheadAndResync(t *testing.T) {
+	h, err := otp.NewHOTP(seed20, otp.Params{Window: 3})
+	if err != nil {
+		t.Fatalf("NewHOTP failed: %v", err)
+	}
+	if next, ok := h.Verify("969429", 0); !ok || next != 4 {
+		t.Fatalf("look-ahead: next=%d ok=%v", next, ok)
+	}
+	if next, ok := h.Verify("338314", 0); ok || next != 0 {
+		t.Fatalf("code outside window accepted: next=%d ok=%v", next, ok)
+	}
+	if _, ok := h.Verify("755 224", 0); !ok {
+		t.Fatal("spaced code rejected")
+	}
+	if _, ok := h.Verify("75522", 0); ok {
+		t.Fatal("short code accepted")
+	}
+	if next, ok := h.Resync("287922", "162583", 0, 100); !ok || next != 8 {
+		t.Fatalf("resync: next=%d ok=%v", next, ok)
+	}
+	if _, ok := h.Resync("287922", "399871", 0, 100); ok {
+		t.Fatal("resync accepted non-consecutive codes")
+	}
+}
+
+func TestTOTPWindow(t *testing.T) {
+	tp, err := otp.NewTOTP(seed20, otp.Params{Digits: 8, Window: 1})
+	if err != nil {
+		t.Fatalf("NewTOTP failed: %v", err)
+	}
+	at := time.Unix(1111111111, 0)
+	step := tp.Step(at)
+	prev := tp.Generate(at.Add(-30 * time.Second))
+	if got, ok := tp.Verify(prev, at); !ok || got != step-1 {
+		t.Fatalf("previous step: got %d ok=%v", got, ok)
+	}
+	next := tp.Generate(at.Add(30 * time.Second))
+	if got, ok := tp.Verify(next, at); !ok || got != step+1 {
+		t.Fatalf("next step: got %d ok=%v", got, ok)
+	}
+	if _, ok := tp.Verify(tp.Generate(at.Add(90*time.Second)), at); ok {
+		t.Fatal("code outside window accepted")
+	}
+}
+
+func TestInvalidParams(t *testing.T) {
+	if _, err := otp.NewHOTP(nil, otp.Params{}); err == nil {
+		t.Fatal("empty secret accepted")
+	}
+	if _, err := otp.NewHOTP(seed20, otp.Params{Digits: 5}); err == nil {
+		t.Fatal("5 digits accepted")
+	}
+	if _, err := otp.NewTOTP(seed20, otp.Params{Period: 1500 * time.Millisecond}); err == nil {
+		t.Fatal("fractional period accepted")
+	}
+	if _, err := otp.NewHOTP(seed20, otp.Params{Algorithm: otp.Algorithm(9)}); err == nil {
+		t.Fatal("unknown algorithm accepted")
+	}
+}
+
+func TestKeyURIRoundTrip(t *testing.T) {
+	k := &otp.Key{
+		Kind:    otp.KindTOTP,
+		Issuer:  "Example Co",
+		Account: "alice@example.com",
+		Secret:  seed20,
+		Params:  otp.Params{Algorithm: otp.SHA256, Digits: 8, Period: 60 * time.Second},
+	}
+	uri, err := k.URI()
+	if err != nil {
+		t.Fatalf("URI failed: %v", err)
+	}
+	want := "otpauth://totp/Example%20Co:alice@example.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&issuer=Example+Co&algorithm=SHA256&digits=8&period=60"
+	if uri != want {
+		t.Fatalf("URI mismatch:\n got %s\nwant %s", uri, want)
+	}
+	parsed, err := otp.ParseURI(uri)
+	if err != nil {
+		t.Fatalf("ParseURI failed: %v", err)
+	}
+	if parsed.Kind != k.Kind || parsed.Issuer != k.Issuer || parsed.Account != k.Account ||
+		!bytes.Equal(parsed.Secret, k.Secret) || parsed.Params != k.Params {
+		t.Fatalf("round trip mismatch: %+v", parsed)
+	}
+	tp, err := parsed.TOTP(0)
+	if err != nil {
+		t.Fatalf("TOTP failed: %v", err)
+	}
+	if tp.Params().Period != time.Minute {
+		t.Fatalf("unexpected period %v", tp.Params().Period)
+	}
+}
+
+func TestParseURIHOTPDefaults(t *testing.T) {
+	k, err := otp.ParseURI("otpauth://hotp/alice?secret=gezdgnbvgy3tqojqgezdgnbvgy3tqojq&counter=5")
+	if err != nil {
+		t.Fatalf("ParseURI failed: %v", err)
+	}
+	if k.Account != "alice" || k.Counter != 5 || k.Params.Digits != otp.DefaultDigits || k.Params.Algorithm != otp.SHA1 {
+		t.Fatalf("unexpected key %+v", k)
+	}
+	h, err := k.HOTP(0)
+	if err != nil {
+		t.Fatalf("HOTP failed: %v", err)
+	}
+	if got := h.Generate(k.Counter); got != "254676" {
+		t.Fatalf("unexpected code %s", got)
+	}
+	if _, err := k.TOTP(0); err == nil {
+		t.Fatal("TOTP accepted an HOTP key")
+	}
+}
+
+func TestParseURI80BitSecret(t *testing.T) {
+	// 80-bit secrets are common in existing authenticator enrolments; they
+	// must verify even though GenerateSecret never produces them.
+	k, err := otp.ParseURI("otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example")
+	if err != nil {
+		t.Fatalf("ParseURI failed: %v", err)
+	}
+	if len(k.Secret) != 10 {
+		t.Fatalf("unexpected secret length %d", len(k.Secret))
+	}
+	tp, err := k.TOTP(0)
+	if err != nil {
+		t.Fatalf("TOTP failed: %v", err)
+	}
+	at := time.Unix(1111111109, 0)
+	if got := tp.Generate(at); got != "071271" {
+		t.Fatalf("unexpected code %s", got)
+	}
+	if _, ok := tp.Verify("071271", at); !ok {
+		t.Fatal("valid code rejected")
+	}
+}
+
+func TestParseURIRejects(t *testing.T) {
+	bad := []string{
+		"https://totp/a?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"otpauth://motp/a?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"otpauth://totp/a",
+		"otpauth://totp/a?secret=not*base32",
+		"otpauth://totp/A:a?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&issuer=B",
+		"otpauth://totp/a?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&algorithm=MD5",
+		"otpauth://hotp/a?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+	}
+	for _, uri := range bad {
+		if _, err := otp.ParseURI(uri); err == nil {
+			t.Fatalf("accepted %s", uri)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	s, err := otp.GenerateSecret(0)
+	if err != nil {
+		t.Fatalf("GenerateSecret failed: %v", err)
+	}
+	if len(s) != otp.DefaultSecretSize {
+		t.Fatalf("unexpected length %d", len(s))
+	}
+	decoded, err := otp.DecodeSecret(strings.ToLower(otp.EncodeSecret(s)))
+	if err != nil || !bytes.Equal(decoded, s) {
+		t.Fatalf("base32 round trip failed: %v", err)
+	}
+	if _, err := otp.GenerateSecret(8); err == nil {
+		t.Fatal("short secret size accepted")
+	}
+}
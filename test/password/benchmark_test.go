@@ -0,0 +1,49 @@
+package password_test
+
+import (
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/password"
+)
+
+func makeBytes(length int, seed byte) []byte {
+	buf := make([]byte, length)
+	for i := range buf {
+		buf[i] = seed + byte(i)
+	}
+	return buf
+}
+
+func BenchmarkPassword(b *testing.B) {
+	pw := makeBytes(16, 0x41)
+	policies := []struct {
+		name   string
+		policy password.Policy
+	}{
+		{"Argon2id-Interactive", password.Interactive},
+		{"Scrypt-ln15", password.Policy{Algorithm: password.Scrypt, ScryptLogN: 15}},
+		{"PBKDF2-SHA256-100k", password.Policy{Algorithm: password.PBKDF2SHA256, Iterations: 100_000}},
+	}
+	for _, p := range policies {
+		encoded, err := p.policy.Hash(pw)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(p.name+"/Hash", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := p.policy.Hash(pw); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(p.name+"/Verify", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if !password.Verify(pw, encoded) {
+					b.Fatal("verify failed")
+				}
+			}
+		})
+	}
+}
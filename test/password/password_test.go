@@ -0,0 +1,136 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/password"
+)
+
+// External PHC strings: the argon2 reference CLI README example, the
+// argon2-cffi documentation example, the passlib scrypt and pbkdf2_sha256
+// documentation examples and a legacy bcrypt hash.
+var phcVectors = []struct {
+	password string
+	encoded  string
+}{
+	{"password", "$argon2i$v=19$m=65536,t=2,p=4$c29tZXNhbHQ$RdescudvJCsgt3ub+b+dWRWJTmaaJObG"},
+	{"correct horse battery staple", "$argon2id$v=19$m=65536,t=3,p=4$MIIRqgvgQbgj220jfp0MPA$YfwJSVjtjSU0zzV/P3S9nnQ/USre2wvJMjfCIjrTQbg"},
+	{"password", "$scrypt$ln=16,r=8,p=1$aM15713r3Xsvxbi31lqr1Q$nFNh2CVHVjNldFVKDHDlm4CbdRSCdEBsjjJxD+iCs5E"},
+	{"toomanysecrets", "$pbkdf2-sha256$29000$N2YMIWQsBWBMae09x1jrPQ$1t8iyB2A.WF/Z5JZv.lfCIhXXN33N23OSgQYThBYRfk"},
+	{"allmine", "$2a$10$XajjQvNhvvRt5GSeFk1xFeyqRrsxkhBkUiQeg0dt.wU1qD4aFDcga"},
+}
+
+func TestVerifyVectors(t *testing.T) {
+	for _, v := range phcVectors {
+		if !password.Verify([]byte(v.password), v.encoded) {
+			t.Fatalf("Verify rejected %s", v.encoded)
+		}
+		if password.Verify([]byte(v.password+"!"), v.encoded) {
+			t.Fatalf("Verify accepted a wrong password for %s", v.encoded)
+		}
+	}
+}
+
+var cheapPolicies = map[string]password.Policy{
+	"$argon2id$v=19$m=64,t=1,p=2$": {Algorithm: password.Argon2id, Time: 1, MemoryKiB: 64, Threads: 2},
+	"$argon2i$v=19$m=32,t=3,p=1$":  {Algorithm: password.Argon2i, Time: 3, MemoryKiB: 32},
+	"$argon2d$v=19$m=32,t=2,p=1$":  {Algorithm: password.Argon2d, MemoryKiB: 32},
+	"$scrypt$ln=10,r=8,p=1$":       {Algorithm: password.Scrypt, ScryptLogN: 10},
+	"$pbkdf2-sha256$1000$":         {Algorithm: password.PBKDF2SHA256, Iterations: 1000, SaltLength: 24, KeyLength: 20},
+}
+
+func TestHashRoundTrip(t *testing.T) {
+	pw := []byte("hunter2")
+	for prefix, policy := range cheapPolicies {
+		encoded, err := policy.Hash(pw)
+		if err != nil {
+			t.Fatalf("%s: %v", prefix, err)
+		}
+		if !strings.HasPrefix(encoded, prefix) {
+			t.Fatalf("encoded %q, want prefix %q", encoded, prefix)
+		}
+		if !password.Verify(pw, encoded) || password.Verify([]byte("hunter3"), encoded) {
+			t.Fatalf("%s: verification result wrong", prefix)
+		}
+		if policy.NeedsRehash(encoded) {
+			t.Fatalf("%s: NeedsRehash under its own policy", prefix)
+		}
+		for otherPrefix, other := range cheapPolicies {
+			if otherPrefix != prefix && !other.NeedsRehash(encoded) {
+				t.Fatalf("%s: no rehash needed under %s policy", prefix, otherPrefix)
+			}
+		}
+		again, _ := policy.Hash(pw)
+		if again == encoded {
+			t.Fatalf("%s: salts repeated", prefix)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current := "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHRzb21lc2FsdA$" + strings.Repeat("A", 43)
+	if password.NeedsRehash(current) || password.Interactive.NeedsRehash(current) {
+		t.Fatal("Interactive hash reported as outdated")
+	}
+	for _, p := range []password.Policy{password.Moderate, password.Sensitive, password.ScryptDefault, password.PBKDF2SHA256Default} {
+		if !p.NeedsRehash(current) {
+			t.Fatalf("%s policy accepted an Interactive hash", p.Algorithm)
+		}
+	}
+	for _, v := range phcVectors {
+		if !password.NeedsRehash(v.encoded) {
+			t.Fatalf("NeedsRehash(%s) = false", v.encoded)
+		}
+	}
+	if !password.NeedsRehash("$argon2id$v=16$m=65536,t=2,p=1$c29tZXNhbHRzb21lc2FsdA$" + strings.Repeat("A", 43)) {
+		t.Fatal("old argon2 version not flagged")
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	salt, key := "c29tZXNhbHQ", "RdescudvJCsgt3ub+b+dWRWJTmaaJObG"
+	for _, s := range []string{
+		"",
+		"argon2i$v=19$m=65536,t=2,p=4$" + salt + "$" + key,
+		"$argon2x$v=19$m=65536,t=2,p=4$" + salt + "$" + key,
+		"$argon2i$v=16$m=65536,t=2,p=4$" + salt + "$" + key,
+		"$argon2i$m=65536,t=2,p=4$" + salt + "$" + key,
+		"$argon2i$v=19$t=2,m=65536,p=4$" + salt + "$" + key,
+		"$argon2i$v=19$m=65536,t=2$" + salt + "$" + key,
+		"$argon2i$v=19$m=065536,t=2,p=4$" + salt + "$" + key,
+		"$argon2i$v=19$m=65536,t=0,p=4$" + salt + "$" + key,
+		"$argon2i$v=19$m=65536,t=2,p=4$" + salt + "=$" + key,
+		"$argon2i$v=19$m=65536,t=2,p=4$c29tZQ$" + key,
+		"$argon2i$v=19$m=65536,t=2,p=4$" + salt + "$" + key + "$",
+		"$scrypt$ln=0,r=8,p=1$" + salt + "$" + key,
+		"$scrypt$ln=16,r=8,p=0$" + salt + "$" + key,
+		"$scrypt$ln=64,r=8,p=1$" + salt + "$" + key,
+		"$pbkdf2-sha256$0$" + salt + "$" + key,
+		"$pbkdf2-sha256$+29000$" + salt + "$" + key,
+	} {
+		if password.Verify([]byte("password"), s) {
+			t.Fatalf("Verify accepted %q", s)
+		}
+		if !password.NeedsRehash(s) {
+			t.Fatalf("NeedsRehash(%q) = false", s)
+		}
+	}
+}
+
+func TestPolicyErrors(t *testing.T) {
+	if _, err := password.Hash(nil); err == nil {
+		t.Fatal("expected error for empty password")
+	}
+	bad := []password.Policy{
+		{Algorithm: "bcrypt"},
+		{Algorithm: password.PBKDF2SHA256, SaltLength: 4},
+		{Algorithm: password.Scrypt, ScryptLogN: 40},
+		{Algorithm: password.Argon2id, MemoryKiB: 8, Threads: 2},
+	}
+	for _, p := range bad {
+		if _, err := p.Hash([]byte("pw")); err == nil {
+			t.Fatalf("expected error for policy %+v", p)
+		}
+	}
+}
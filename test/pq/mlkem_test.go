@@ -3,69 +3,26 @@ package pq_test
 import (
 	"bufio"
 	"bytes"
-	"crypto/aes"
 	"encoding/hex"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/AeonDave/cryptonite-go/drbg"
 	internalKyber "github.com/AeonDave/cryptonite-go/internal/kyber"
 	"github.com/AeonDave/cryptonite-go/kem"
 	"github.com/AeonDave/cryptonite-go/pq"
 )
 
-type ctrDRBG struct {
-	key [32]byte
-	v   [16]byte
-}
-
-func newCTRDRBG(seed []byte) *ctrDRBG {
-	var init [48]byte
-	copy(init[:], seed)
-	g := &ctrDRBG{}
-	g.update(&init)
-	return g
-}
-
-func (g *ctrDRBG) increment() {
-	for i := len(g.v) - 1; i >= 0; i-- {
-		g.v[i]++
-		if g.v[i] != 0 {
-			break
-		}
-	}
-}
-
-func (g *ctrDRBG) update(pd *[48]byte) {
-	var buf [48]byte
-	block, _ := aes.NewCipher(g.key[:])
-	for i := 0; i < 3; i++ {
-		g.increment()
-		block.Encrypt(buf[i*16:(i+1)*16], g.v[:])
-	}
-	if pd != nil {
-		for i := 0; i < len(buf); i++ {
-			buf[i] ^= pd[i]
-		}
-	}
-	copy(g.key[:], buf[:32])
-	copy(g.v[:], buf[32:])
-}
-
-func (g *ctrDRBG) fill(out []byte) {
-	var block [16]byte
-	cipher, _ := aes.NewCipher(g.key[:])
-	for len(out) > 0 {
-		g.increment()
-		cipher.Encrypt(block[:], g.v[:])
-		if len(out) < len(block) {
-			copy(out, block[:len(out)])
-			break
-		}
-		copy(out, block[:])
-		out = out[len(block):]
+// newKATDRBG returns the AES-256 CTR_DRBG without derivation function that
+// NIST's PQCgenKAT tooling seeds from each 48-byte KAT seed.
+func newKATDRBG(t *testing.T, seed []byte) drbg.DRBG {
+	t.Helper()
+	rng, err := drbg.NewCTRNoDF(drbg.Params{EntropyInput: seed})
+	if err != nil {
+		t.Fatalf("drbg: %v", err)
 	}
-	g.update(nil)
+	return rng
 }
 
 func TestMLKEMKAT(t *testing.T) {
@@ -168,12 +125,12 @@ func TestMLKEMKAT(t *testing.T) {
 					genSS = nil
 				case "seed":
 					seed := mustDecodeHex(t, val, 48)
-					drbg := newCTRDRBG(seed)
+					rng := newKATDRBG(t, seed)
 					kseed = make([]byte, 64)
-					drbg.fill(kseed[:32])
-					drbg.fill(kseed[32:])
+					rng.Read(kseed[:32])
+					rng.Read(kseed[32:])
 					coins = make([]byte, 32)
-					drbg.fill(coins)
+					rng.Read(coins)
 					genPK, genSK = tc.scheme.KeyGen(kseed)
 				case "pk":
 					pkBytes = mustDecodeHex(t, val, tc.publicLen)
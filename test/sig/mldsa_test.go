@@ -3,69 +3,25 @@ package sig_test
 import (
 	"bufio"
 	"bytes"
-	"crypto/aes"
 	"encoding/hex"
 	"os"
 	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/AeonDave/cryptonite-go/drbg"
 	sig "github.com/AeonDave/cryptonite-go/sig"
 )
 
-// DRBG replicates the AES-CTR DRBG used by NIST's PQCgenKAT toolchain.
-type drbg struct {
-	key [32]byte
-	v   [16]byte
-}
-
-func (g *drbg) incV() {
-	for j := 15; j >= 0; j-- {
-		if g.v[j] == 255 {
-			g.v[j] = 0
-		} else {
-			g.v[j]++
-			break
-		}
-	}
-}
-
-func (g *drbg) update(pd *[48]byte) {
-	var buf [48]byte
-	block, _ := aes.NewCipher(g.key[:])
-	for i := 0; i < 3; i++ {
-		g.incV()
-		block.Encrypt(buf[i*16:(i+1)*16], g.v[:])
-	}
-	if pd != nil {
-		for i := 0; i < len(buf); i++ {
-			buf[i] ^= pd[i]
-		}
-	}
-	copy(g.key[:], buf[:32])
-	copy(g.v[:], buf[32:])
-}
-
-func newDRBG(seed *[48]byte) drbg {
-	var g drbg
-	g.update(seed)
-	return g
-}
-
-func (g *drbg) fill(out []byte) {
-	var block [16]byte
-	cipher, _ := aes.NewCipher(g.key[:])
-	for len(out) > 0 {
-		g.incV()
-		cipher.Encrypt(block[:], g.v[:])
-		if len(out) < len(block) {
-			copy(out, block[:len(out)])
-			break
-		}
-		copy(out, block[:])
-		out = out[len(block):]
+// newKATDRBG returns the AES-256 CTR_DRBG without derivation function used
+// by NIST's PQCgenKAT toolchain.
+func newKATDRBG(t *testing.T, seed []byte) drbg.DRBG {
+	t.Helper()
+	rng, err := drbg.NewCTRNoDF(drbg.Params{EntropyInput: seed})
+	if err != nil {
+		t.Fatalf("drbg: %v", err)
 	}
-	g.update(nil)
+	return rng
 }
 
 func TestMLDSAKAT(t *testing.T) {
@@ -149,7 +105,7 @@ func runDilithiumKAT(t *testing.T, tc struct {
 	for i := 0; i < len(seed); i++ {
 		seed[i] = byte(i)
 	}
-	generator := newDRBG(&seed)
+	generator := newKATDRBG(t, seed[:])
 
 	for {
 		line, err := reader.ReadString('\n')
@@ -172,13 +128,13 @@ func runDilithiumKAT(t *testing.T, tc struct {
 			if len(data) != len(seed) {
 				t.Fatalf("seed length mismatch: got %d, want %d", len(data), len(seed))
 			}
-			generator.fill(seed[:])
+			generator.Read(seed[:])
 			if !bytes.Equal(seed[:], data) {
 				t.Fatalf("kat seed mismatch at vector %d", count)
 			}
-			drbg := newDRBG(&seed)
+			rng := newKATDRBG(t, seed[:])
 			var extSeed [32]byte
-			drbg.fill(extSeed[:])
+			rng.Read(extSeed[:])
 			pub, priv, err = tc.keygen(extSeed[:])
 			if err != nil {
 				t.Fatalf("keygen failed: %v", err)
@@ -192,7 +148,7 @@ func runDilithiumKAT(t *testing.T, tc struct {
 				t.Fatalf("mlen does not match expected formula, got %d", mlen)
 			}
 			msg = make([]byte, mlen)
-			generator.fill(msg)
+			generator.Read(msg)
 		case "msg":
 			data, err := hex.DecodeString(raw)
 			if err != nil {
@@ -0,0 +1,59 @@
+package tls13_test
+
+import (
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/aead"
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	"github.com/AeonDave/cryptonite-go/tls13"
+)
+
+func makeBytes(length int, seed byte) []byte {
+	buf := make([]byte, length)
+	for i := range buf {
+		buf[i] = seed + byte(i)
+	}
+	return buf
+}
+
+func BenchmarkTLS13(b *testing.B) {
+	newHash := cryptohash.NewSHA256
+	shared := makeBytes(32, 0x11)
+	transcript := makeBytes(32, 0x22)
+
+	b.Run("KeySchedule", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ks := tls13.NewKeySchedule(newHash, nil)
+			if err := ks.AdvanceHandshake(shared); err != nil {
+				b.Fatalf("handshake failed: %v", err)
+			}
+			if _, err := ks.ServerHandshakeTrafficSecret(transcript); err != nil {
+				b.Fatalf("derive failed: %v", err)
+			}
+			if err := ks.AdvanceMaster(); err != nil {
+				b.Fatalf("master failed: %v", err)
+			}
+			if _, err := ks.ServerApplicationTrafficSecret(transcript); err != nil {
+				b.Fatalf("derive failed: %v", err)
+			}
+		}
+	})
+
+	b.Run("Seal/1KiB", func(b *testing.B) {
+		c, err := tls13.NewTrafficCipher(aead.NewAESGCM(), newHash, makeBytes(32, 0x33), 16)
+		if err != nil {
+			b.Fatalf("cipher failed: %v", err)
+		}
+		header := []byte{0x17, 0x03, 0x03, 0x04, 0x10}
+		pt := makeBytes(1024, 0x44)
+		b.ReportAllocs()
+		b.SetBytes(int64(len(pt)))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := c.Seal(header, pt); err != nil {
+				b.Fatalf("seal failed: %v", err)
+			}
+		}
+	})
+}
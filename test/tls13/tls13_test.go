@@ -0,0 +1,450 @@
+package tls13_test
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	stdhash "hash"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/aead"
+	cryptohash "github.com/AeonDave/cryptonite-go/hash"
+	"github.com/AeonDave/cryptonite-go/kdf"
+	"github.com/AeonDave/cryptonite-go/test/internal/testutil"
+	"github.com/AeonDave/cryptonite-go/tls13"
+)
+
+// Values from the "Simple 1-RTT Handshake" trace of RFC 8448 section 3
+// (TLS_AES_128_GCM_SHA256, x25519).
+//
+//go:embed testdata/rfc8448_simple.json
+var simpleTraceJSON []byte
+
+type simpleTrace struct {
+	ECDHE            string `json:"ecdhe"`
+	EarlySecret      string `json:"early_secret"`
+	HandshakeSecret  string `json:"handshake_secret"`
+	MasterSecret     string `json:"master_secret"`
+	HelloHash        string `json:"hello_hash"`
+	ClientHSTraffic  string `json:"client_hs_traffic"`
+	ServerHSTraffic  string `json:"server_hs_traffic"`
+	ServerHSKey      string `json:"server_hs_key"`
+	ServerHSIV       string `json:"server_hs_iv"`
+	ClientHSKey      string `json:"client_hs_key"`
+	ClientHSIV       string `json:"client_hs_iv"`
+	ServerCVHash     string `json:"server_cv_hash"`
+	ServerFinished   string `json:"server_finished"`
+	ClientAppTraffic string `json:"client_app_traffic"`
+	ServerAppTraffic string `json:"server_app_traffic"`
+	ServerAppKey     string `json:"server_app_key"`
+	ServerAppIV      string `json:"server_app_iv"`
+	ClientAppKey     string `json:"client_app_key"`
+	ClientAppIV      string `json:"client_app_iv"`
+	ResumptionMaster string `json:"resumption_master"`
+	TicketNonce      string `json:"ticket_nonce"`
+	ResumptionPSK    string `json:"resumption_psk"`
+}
+
+func loadTrace(t *testing.T) simpleTrace {
+	t.Helper()
+	var tr simpleTrace
+	if err := json.Unmarshal(simpleTraceJSON, &tr); err != nil {
+		t.Fatalf("parse trace: %v", err)
+	}
+	return tr
+}
+
+func expectHex(t *testing.T, name string, got []byte, err error, want string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if !bytes.Equal(got, testutil.MustHex(t, want)) {
+		t.Fatalf("%s mismatch:\n got %x\nwant %s", name, got, want)
+	}
+}
+
+func TestKeyScheduleRFC8448(t *testing.T) {
+	tr := loadTrace(t)
+	newHash := cryptohash.NewSHA256
+
+	ks := tls13.NewKeySchedule(newHash, nil)
+	expectHex(t, "early secret", ks.Secret(), nil, tr.EarlySecret)
+	if err := ks.AdvanceHandshake(testutil.MustHex(t, tr.ECDHE)); err != nil {
+		t.Fatalf("AdvanceHandshake: %v", err)
+	}
+	expectHex(t, "handshake secret", ks.Secret(), nil, tr.HandshakeSecret)
+
+	hello := testutil.MustHex(t, tr.HelloHash)
+	chs, err := ks.ClientHandshakeTrafficSecret(hello)
+	expectHex(t, "client hs traffic", chs, err, tr.ClientHSTraffic)
+	shs, err := ks.ServerHandshakeTrafficSecret(hello)
+	expectHex(t, "server hs traffic", shs, err, tr.ServerHSTraffic)
+
+	key, iv, err := tls13.TrafficKeys(newHash, shs, 16)
+	expectHex(t, "server hs key", key, err, tr.ServerHSKey)
+	expectHex(t, "server hs iv", iv, nil, tr.ServerHSIV)
+	key, iv, err = tls13.TrafficKeys(newHash, chs, 16)
+	expectHex(t, "client hs key", key, err, tr.ClientHSKey)
+	expectHex(t, "client hs iv", iv, nil, tr.ClientHSIV)
+
+	sfin, err := tls13.FinishedVerifyData(newHash, shs, testutil.MustHex(t, tr.ServerCVHash))
+	expectHex(t, "server finished", sfin, err, tr.ServerFinished)
+
+	if err := ks.AdvanceMaster(); err != nil {
+		t.Fatalf("AdvanceMaster: %v", err)
+	}
+	expectHex(t, "master secret", ks.Secret(), nil, tr.MasterSecret)
+
+	// The trace keeps no transcript hashes past the ServerHello, so only the
+	// keys of its application secrets are checked here; TestKeyScheduleACVP
+	// and TestKeyScheduleOpenSSLTrace derive those secrets from transcripts.
+	key, iv, err = tls13.TrafficKeys(newHash, testutil.MustHex(t, tr.ServerAppTraffic), 16)
+	expectHex(t, "server app key", key, err, tr.ServerAppKey)
+	expectHex(t, "server app iv", iv, nil, tr.ServerAppIV)
+	key, iv, err = tls13.TrafficKeys(newHash, testutil.MustHex(t, tr.ClientAppTraffic), 16)
+	expectHex(t, "client app key", key, err, tr.ClientAppKey)
+	expectHex(t, "client app iv", iv, nil, tr.ClientAppIV)
+
+	res := testutil.MustHex(t, tr.ResumptionMaster)
+	psk, err := tls13.ResumptionPSK(newHash, res, testutil.MustHex(t, tr.TicketNonce))
+	expectHex(t, "resumption psk", psk, err, tr.ResumptionPSK)
+}
+
+// NIST ACVP TLS-v1.3-KDF-RFC8446 case with a PSK and DHE over SHA-256 (also
+// used by Go's crypto/tls). The four random values stand in for handshake
+// messages: each is written to the transcript hash in turn, the client hello
+// random before the early secrets, the server hello random before the
+// handshake secrets, the server finished random before the application and
+// exporter secrets and the client finished random before the resumption
+// master secret. The binder keys are not part of the ACVP case; they were
+// computed for the same PSK with the OpenSSL 3 TLS13-KDF, which reproduces
+// the ACVP client early traffic secret.
+var acvpSchedule = struct {
+	psk, dhe                                       string
+	clientHello, serverHello, serverFin, clientFin string
+	extBinder, resBinder                           string
+	clientEarly, earlyExporter                     string
+	clientHS, serverHS                             string
+	clientApp, serverApp, exporter, resumption     string
+}{
+	psk:           "56288b726c73829f7a3e47b103837c8139acf552e7530c7a710b35ed41191698",
+	dhe:           "effe9ec26aa29fd750dfa6a10b944d74071595b27ee88887d5e11c84590b5cc3",
+	clientHello:   "e9137679e582ba7c1db41cf725f86c6d09c8c05f297bad9a65b552eaf524fde4",
+	serverHello:   "23eccfd030790748c8f8d8a656fd98d717f1b62af3712f97211d2070b499f98a",
+	serverFin:     "c750eda6696cd101b142bd79e00e6ac8c5f2c0abc78dd64f4d991326659e9299",
+	clientFin:     "62a62fa75563ed4fdcaa0bc16567b314871c304acf06b0ffc3f08c1797594d43",
+	extBinder:     "6c6de8848fbadd3233005e4fdf88308bf6a9f1b959401b02a30eb8b1cb0d23bc",
+	resBinder:     "3bfc101c063b1087f499b3bf78763598592a68dc3e9692a39b824d02c3df10a2",
+	clientEarly:   "3272189698c3594d18f58efa3f12b638a249515099be7a2fa9836babe74f0111",
+	earlyExporter: "88e078f562cdc930219f6a5e98a1ce8c6e5f3dac5ac516459a96f2ef8f114c66",
+	clientHS:      "b32306c3ce9932c460a1fe6c0f060593974842036b96fa45049b7352e71c2ad2",
+	serverHS:      "22787f8ca269d34bc549ac8ba19f2040938a3aa370d7cc9d60f720882b88d01b",
+	clientApp:     "47d7ea08397b5871154b0fe85584bcc30a87c69e84d69b56007c5b21f76493ba",
+	serverApp:     "efbdb0c873c0480da57307083839a8984be25b9a8545e4fca029940fe2800565",
+	exporter:      "8a43d787ee3804ead4a2a5b32972f9896b696295645d7222e1fd081ddd939834",
+	resumption:    "5f4c961329c91044011acbecb0b289282e0e3fed045cb3ea924dffe5fe654b3d",
+}
+
+func TestKeyScheduleACVP(t *testing.T) {
+	v := acvpSchedule
+	ks := tls13.NewKeySchedule(cryptohash.NewSHA256, testutil.MustHex(t, v.psk))
+	transcript := cryptohash.NewSHA256()
+
+	ext, err := ks.ExternalBinderKey()
+	expectHex(t, "ext binder", ext, err, v.extBinder)
+	res, err := ks.ResumptionBinderKey()
+	expectHex(t, "res binder", res, err, v.resBinder)
+
+	transcript.Write(testutil.MustHex(t, v.clientHello))
+	th := transcript.Sum(nil)
+	secret, err := ks.ClientEarlyTrafficSecret(th)
+	expectHex(t, "client early traffic", secret, err, v.clientEarly)
+	secret, err = ks.EarlyExporterMasterSecret(th)
+	expectHex(t, "early exporter master", secret, err, v.earlyExporter)
+
+	if err := ks.AdvanceHandshake(testutil.MustHex(t, v.dhe)); err != nil {
+		t.Fatalf("AdvanceHandshake: %v", err)
+	}
+	transcript.Write(testutil.MustHex(t, v.serverHello))
+	th = transcript.Sum(nil)
+	secret, err = ks.ClientHandshakeTrafficSecret(th)
+	expectHex(t, "client hs traffic", secret, err, v.clientHS)
+	secret, err = ks.ServerHandshakeTrafficSecret(th)
+	expectHex(t, "server hs traffic", secret, err, v.serverHS)
+
+	if err := ks.AdvanceMaster(); err != nil {
+		t.Fatalf("AdvanceMaster: %v", err)
+	}
+	transcript.Write(testutil.MustHex(t, v.serverFin))
+	th = transcript.Sum(nil)
+	secret, err = ks.ClientApplicationTrafficSecret(th)
+	expectHex(t, "client app traffic", secret, err, v.clientApp)
+	secret, err = ks.ServerApplicationTrafficSecret(th)
+	expectHex(t, "server app traffic", secret, err, v.serverApp)
+	secret, err = ks.ExporterMasterSecret(th)
+	expectHex(t, "exporter master", secret, err, v.exporter)
+
+	transcript.Write(testutil.MustHex(t, v.clientFin))
+	secret, err = ks.ResumptionMasterSecret(transcript.Sum(nil))
+	expectHex(t, "resumption master", secret, err, v.resumption)
+}
+
+// Full handshake without a PSK from the rustls key schedule tests, generated
+// with OpenSSL: the handshake secrets use the ClientHello...ServerHello
+// transcript hash and the application secrets the
+// ClientHello...server Finished hash. Keys are 16 bytes.
+var opensslSchedule = struct {
+	ecdhe, helloHash, finishedHash string
+	secrets                        []struct{ name, secret, key, iv string }
+}{
+	ecdhe:        "e7b8fef8903b520cb9a18971b69dd45dca53ce2f12bf3bef9315e31271df4b40",
+	helloHash:    "ec147a06dea3c8846c02b2238e41bddc9d89f9aea17b5efd4d7482af75881c0a",
+	finishedHash: "751a3d4a14dfabeb68e92ca5918e2408b9bcb0748982ec9c3230ac30bbeb23e2",
+	secrets: []struct{ name, secret, key, iv string }{
+		{"client hs", "617b35076b9d0e08cf731d94a86614784109ef255551921dd46e040135cf46ab", "62d0dd00f69619d3b8193ab4a09585a7", "fff75df5ad35d5cb3c53f3a9"},
+		{"server hs", "fcf7dfe64fa2c04f6235387f434e01422336d9c039de6847a0b9ddcf29a88759", "0467f316a805b8c497ee67047bbcbc54", "de83a73e9d814b04c48b7809"},
+		{"client app", "c14a6d7976d8102b5a0c9951493fee87dcaff82c24cab214e8be71a8206dbda5", "cc9f5f980b5f10306cbad7be98d7572e", "b80929e8d02c70f61162ed6b"},
+		{"server app", "2c907738d3f83702d1e4598f4848531d9f9365491b9f7f52c822290d4c232192", "0cb29562d8d88f48b02cbfbed7e62bb3", "0db28f988586a1b7e4d5c69c"},
+	},
+}
+
+func TestKeyScheduleOpenSSLTrace(t *testing.T) {
+	v := opensslSchedule
+	newHash := cryptohash.NewSHA256
+	ks := tls13.NewKeySchedule(newHash, nil)
+	if err := ks.AdvanceHandshake(testutil.MustHex(t, v.ecdhe)); err != nil {
+		t.Fatalf("AdvanceHandshake: %v", err)
+	}
+	hello := testutil.MustHex(t, v.helloHash)
+	chs, err := ks.ClientHandshakeTrafficSecret(hello)
+	if err != nil {
+		t.Fatalf("ClientHandshakeTrafficSecret: %v", err)
+	}
+	shs, err := ks.ServerHandshakeTrafficSecret(hello)
+	if err != nil {
+		t.Fatalf("ServerHandshakeTrafficSecret: %v", err)
+	}
+	if err := ks.AdvanceMaster(); err != nil {
+		t.Fatalf("AdvanceMaster: %v", err)
+	}
+	finished := testutil.MustHex(t, v.finishedHash)
+	cas, err := ks.ClientApplicationTrafficSecret(finished)
+	if err != nil {
+		t.Fatalf("ClientApplicationTrafficSecret: %v", err)
+	}
+	sas, err := ks.ServerApplicationTrafficSecret(finished)
+	if err != nil {
+		t.Fatalf("ServerApplicationTrafficSecret: %v", err)
+	}
+
+	for i, got := range [][]byte{chs, shs, cas, sas} {
+		want := v.secrets[i]
+		expectHex(t, want.name+" traffic", got, nil, want.secret)
+		key, iv, err := tls13.TrafficKeys(newHash, got, 16)
+		expectHex(t, want.name+" key", key, err, want.key)
+		expectHex(t, want.name+" iv", iv, nil, want.iv)
+	}
+}
+
+// psk_ke handshake (no (EC)DHE): the handshake secret is extracted from a
+// zero string of Hash.length. Checked with OpenSSL's TLS13-KDF for the
+// external PSK 00..1f and a transcript hash of
+// SHA-256("psk_ke ClientHello...ServerHello").
+var pskKESchedule = struct {
+	psk, helloHash, handshake, clientHS, serverHS string
+}{
+	psk:       "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+	helloHash: "51c3ce63f99c3a6923996163bc267a3eb829d0f541df555768a1d6814638fa1a",
+	handshake: "9859803b3c3ddc750d3be02a2b1f673e7173cbeaa722d48391b7998cb96cb771",
+	clientHS:  "62960e31668522d7ebe83541c01890a9370b962a8b455908e5a685585b3a43e2",
+	serverHS:  "b2792833f7381ca6ff4a31d938d94b801c637f1a14f309d935de72ef8d849fbd",
+}
+
+func TestKeySchedulePSKOnly(t *testing.T) {
+	v := pskKESchedule
+	hello := testutil.MustHex(t, v.helloHash)
+	for _, ikm := range [][]byte{nil, {}, make([]byte, 32)} {
+		ks := tls13.NewKeySchedule(cryptohash.NewSHA256, testutil.MustHex(t, v.psk))
+		if err := ks.AdvanceHandshake(ikm); err != nil {
+			t.Fatalf("AdvanceHandshake(%x): %v", ikm, err)
+		}
+		expectHex(t, "handshake secret", ks.Secret(), nil, v.handshake)
+		secret, err := ks.ClientHandshakeTrafficSecret(hello)
+		expectHex(t, "client hs traffic", secret, err, v.clientHS)
+		secret, err = ks.ServerHandshakeTrafficSecret(hello)
+		expectHex(t, "server hs traffic", secret, err, v.serverHS)
+	}
+}
+
+func TestKeyScheduleStages(t *testing.T) {
+	newHash := cryptohash.NewSHA256
+	ks := tls13.NewKeySchedule(newHash, nil)
+	th := make([]byte, 32)
+
+	if _, err := ks.ClientHandshakeTrafficSecret(th); err == nil {
+		t.Fatal("expected error for handshake secret at early stage")
+	}
+	if err := ks.AdvanceMaster(); err == nil {
+		t.Fatal("expected error advancing to master from early stage")
+	}
+	if _, err := ks.ExternalBinderKey(); err != nil {
+		t.Fatalf("ExternalBinderKey: %v", err)
+	}
+	if err := ks.AdvanceHandshake(make([]byte, 32)); err != nil {
+		t.Fatalf("AdvanceHandshake: %v", err)
+	}
+	if ks.Stage() != tls13.StageHandshake {
+		t.Fatalf("stage = %d, want handshake", ks.Stage())
+	}
+	if _, err := ks.ClientEarlyTrafficSecret(th); err == nil {
+		t.Fatal("expected error for early secret at handshake stage")
+	}
+	if _, err := ks.ClientApplicationTrafficSecret(th); err == nil {
+		t.Fatal("expected error for application secret at handshake stage")
+	}
+	if err := ks.AdvanceHandshake(make([]byte, 32)); err == nil {
+		t.Fatal("expected error advancing to handshake twice")
+	}
+	if err := ks.AdvanceMaster(); err != nil {
+		t.Fatalf("AdvanceMaster: %v", err)
+	}
+	if _, err := ks.ServerHandshakeTrafficSecret(th); err == nil {
+		t.Fatal("expected error for handshake secret at master stage")
+	}
+	if _, err := ks.ResumptionMasterSecret(th); err != nil {
+		t.Fatalf("ResumptionMasterSecret: %v", err)
+	}
+}
+
+func TestHKDFExpandLabelErrors(t *testing.T) {
+	newHash := cryptohash.NewSHA256
+	secret := make([]byte, 32)
+	if _, err := kdf.HKDFExpandLabel(newHash, secret, "key", nil, 0); err == nil {
+		t.Fatal("expected error for zero length")
+	}
+	if _, err := kdf.HKDFExpandLabel(newHash, secret, string(make([]byte, 250)), nil, 16); err == nil {
+		t.Fatal("expected error for long label")
+	}
+	if _, err := kdf.HKDFExpandLabel(newHash, secret, "key", make([]byte, 256), 16); err == nil {
+		t.Fatal("expected error for long context")
+	}
+}
+
+// Exporter outputs from OpenSSL 3 s_client -keymatexport, which uses an
+// empty context, over TLS 1.3 sessions whose EXPORTER_SECRET was taken from
+// the key log. The case with a context was computed from the same secret
+// with the OpenSSL 3 TLS13-KDF following RFC 8446 section 7.5.
+var exporterVectors = []struct {
+	hash    string
+	secret  string
+	label   string
+	context string
+	output  string
+}{
+	{
+		"SHA-256", "9fec286d4194be957ab69bdad39a4e40b3c98091085a028fcbdb251b3839505b",
+		"EXPERIMENTAL cryptonite", "",
+		"007ac6d4571fa751684408d8caf67936dcf757a3cfc0300532a980864f4404f2c6a098e4a948f1912dbd824ff9eb25e3",
+	},
+	{
+		"SHA-256", "9fec286d4194be957ab69bdad39a4e40b3c98091085a028fcbdb251b3839505b",
+		"EXPERIMENTAL cryptonite", "context",
+		"6817800dfc4da6240de4de23f4aa58daa935b02d8afb88184a55422cc2400c849211070d3d35a98b21dd1bf2a1cbb708",
+	},
+	{
+		"SHA-384", "f6abc0b8425232d207fa977b1128e38e09dfe2f308f607c513cd8ff50fd37d712bfc14b4edf2801f900d18cf8a47a4e5",
+		"EXPORTER-cryptonite-test", "",
+		"1e01c7748a3b84141dbda85369ed8519bf9800cd7045c4bae374d0caa2586390",
+	},
+}
+
+func TestExportKeyingMaterial(t *testing.T) {
+	hashes := map[string]func() stdhash.Hash{
+		"SHA-256": cryptohash.NewSHA256,
+		"SHA-384": cryptohash.NewSHA384,
+	}
+	for _, v := range exporterVectors {
+		want := testutil.MustHex(t, v.output)
+		var context []byte
+		if v.context != "" {
+			context = []byte(v.context)
+		}
+		got, err := tls13.ExportKeyingMaterial(hashes[v.hash], testutil.MustHex(t, v.secret), v.label, context, len(want))
+		expectHex(t, v.hash+" "+v.label+" exporter", got, err, v.output)
+	}
+}
+
+func TestTrafficCipher(t *testing.T) {
+	tr := loadTrace(t)
+	newHash := cryptohash.NewSHA256
+	secret := testutil.MustHex(t, tr.ServerAppTraffic)
+
+	sender, err := tls13.NewTrafficCipher(aead.NewAESGCM(), newHash, secret, 16)
+	if err != nil {
+		t.Fatalf("NewTrafficCipher: %v", err)
+	}
+	receiver, err := tls13.NewTrafficCipher(aead.NewAESGCM(), newHash, secret, 16)
+	if err != nil {
+		t.Fatalf("NewTrafficCipher: %v", err)
+	}
+
+	// The first record uses the IV itself as the nonce.
+	key := testutil.MustHex(t, tr.ServerAppKey)
+	iv := testutil.MustHex(t, tr.ServerAppIV)
+	header := []byte{0x17, 0x03, 0x03, 0x00, 0x15}
+	pt := []byte("hello")
+	want, err := aead.NewAESGCM().Encrypt(key, iv, header, pt)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	ct, err := sender.Seal(header, pt)
+	expectHex(t, "record 0", ct, err, hex.EncodeToString(want))
+
+	// The second record XORs sequence number 1 into the last IV byte.
+	iv[len(iv)-1] ^= 1
+	want, _ = aead.NewAESGCM().Encrypt(key, iv, header, pt)
+	ct1, err := sender.Seal(header, pt)
+	expectHex(t, "record 1", ct1, err, hex.EncodeToString(want))
+
+	for i, record := range [][]byte{ct, ct1} {
+		got, err := receiver.Open(header, record)
+		if err != nil || !bytes.Equal(got, pt) {
+			t.Fatalf("Open record %d: %q, %v", i, got, err)
+		}
+	}
+	if receiver.Sequence() != 2 {
+		t.Fatalf("sequence = %d, want 2", receiver.Sequence())
+	}
+
+	if err := sender.Update(); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := receiver.Update(); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if sender.Sequence() != 0 {
+		t.Fatalf("sequence after update = %d, want 0", sender.Sequence())
+	}
+	next, err := tls13.NextTrafficSecret(newHash, secret)
+	if err != nil {
+		t.Fatalf("NextTrafficSecret: %v", err)
+	}
+	key, iv, err = tls13.TrafficKeys(newHash, next, 16)
+	if err != nil {
+		t.Fatalf("TrafficKeys: %v", err)
+	}
+	want, _ = aead.NewAESGCM().Encrypt(key, iv, header, pt)
+	ct2, err := sender.Seal(header, pt)
+	expectHex(t, "record after update", ct2, err, hex.EncodeToString(want))
+	if got, err := receiver.Open(header, ct2); err != nil || !bytes.Equal(got, pt) {
+		t.Fatalf("Open after update: %q, %v", got, err)
+	}
+
+	ct3, _ := sender.Seal(header, pt)
+	ct3[0] ^= 1
+	if _, err := receiver.Open(header, ct3); err == nil {
+		t.Fatal("expected authentication failure for tampered record")
+	}
+}
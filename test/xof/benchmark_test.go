@@ -34,6 +34,14 @@ func mustBlake2sXOF(length uint32) func() xof.XOF {
 	}
 }
 
+func mustTurboSHAKE128() xof.XOF {
+	inst, err := xof.TurboSHAKE128(0x1f)
+	if err != nil {
+		panic(err)
+	}
+	return inst
+}
+
 func benchmarkXOF(b *testing.B, name string, newXOF func() xof.XOF) {
 	input := makeBytes(4096, 0x51)
 	out := make([]byte, 1024)
@@ -65,6 +73,10 @@ func BenchmarkXOF(b *testing.B) {
 		{"Blake2bXOF", mustBlake2bXOF(64)},
 		{"Blake2sXOF", mustBlake2sXOF(32)},
 		{"XoodyakXOF", xof.Xoodyak},
+		{"BLAKE3XOF", func() xof.XOF { return xof.BLAKE3() }},
+		{"TurboSHAKE128", mustTurboSHAKE128},
+		{"KT128", func() xof.XOF { return xof.KT128(nil) }},
+		{"KT256", func() xof.XOF { return xof.KT256(nil) }},
 	}
 	for _, spec := range specs {
 		benchmarkXOF(b, spec.name, spec.ctor)
@@ -0,0 +1,87 @@
+package xof_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/hash"
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+	"github.com/AeonDave/cryptonite-go/xof"
+)
+
+func TestBLAKE3XOFSeek(t *testing.T) {
+	msg := []byte("seekable BLAKE3 output")
+	x := xof.BLAKE3()
+	_, _ = x.Write(msg)
+	full := make([]byte, 1000)
+	if _, err := x.Read(full); err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if !bytes.Equal(full[:32], hash.SumBLAKE3(msg)) {
+		t.Fatal("XOF prefix differs from digest")
+	}
+
+	for _, off := range []int64{0, 1, 63, 64, 65, 500, 937} {
+		if pos, err := x.Seek(off, io.SeekStart); err != nil || pos != off {
+			t.Fatalf("Seek(%d) = %d, %v", off, pos, err)
+		}
+		got := make([]byte, 1000-int(off))
+		// Read in uneven pieces to cross block boundaries.
+		for i := 0; i < len(got); {
+			n := 1 + i%70
+			if i+n > len(got) {
+				n = len(got) - i
+			}
+			_, _ = x.Read(got[i : i+n])
+			i += n
+		}
+		if !bytes.Equal(got, full[off:]) {
+			t.Fatalf("output after Seek(%d) mismatch", off)
+		}
+	}
+
+	_, _ = x.Seek(100, io.SeekStart)
+	if pos, err := x.Seek(-36, io.SeekCurrent); err != nil || pos != 64 {
+		t.Fatalf("relative seek = %d, %v", pos, err)
+	}
+	if _, err := x.Seek(-1, io.SeekStart); err == nil {
+		t.Fatal("negative seek accepted")
+	}
+	if _, err := x.Seek(0, io.SeekEnd); err == nil {
+		t.Fatal("SeekEnd accepted")
+	}
+	if _, err := x.Write([]byte("late")); err == nil {
+		t.Fatal("write after read accepted")
+	}
+
+	x.Reset()
+	_, _ = x.Write(msg)
+	again := make([]byte, 64)
+	_, _ = x.Read(again)
+	if !bytes.Equal(again, full[:64]) {
+		t.Fatal("output after Reset mismatch")
+	}
+}
+
+func TestBLAKE3XOFFarSeek(t *testing.T) {
+	x := xof.BLAKE3()
+	_, _ = x.Write([]byte("abc"))
+	// Output block 2^32 exercises the high half of the block counter.
+	off := int64(1) << 38
+	if _, err := x.Seek(off+10, io.SeekStart); err != nil {
+		t.Fatalf("Seek failed: %v", err)
+	}
+	tail := make([]byte, 20)
+	_, _ = x.Read(tail)
+	_, _ = x.Seek(off, io.SeekStart)
+	block := make([]byte, 30)
+	_, _ = x.Read(block)
+	want := testutil.MustHex(t, "27db0cdf9952a2ccff10e83701421d48d249234bbab4d3c3b6a2d5626dca")
+	if !bytes.Equal(block, want) {
+		t.Fatalf("far seek output mismatch: %x", block)
+	}
+	if !bytes.Equal(block[10:], tail) {
+		t.Fatal("far seek output inconsistent")
+	}
+}
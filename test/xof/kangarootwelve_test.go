@@ -0,0 +1,65 @@
+package xof_test
+
+import (
+	"bytes"
+	"testing"
+
+	testutil "github.com/AeonDave/cryptonite-go/test/internal/testutil"
+	"github.com/AeonDave/cryptonite-go/xof"
+)
+
+// RFC 9861 section 5.
+func TestTurboSHAKEXOF(t *testing.T) {
+	x, err := xof.TurboSHAKE128(0x1f)
+	if err != nil {
+		t.Fatalf("TurboSHAKE128 failed: %v", err)
+	}
+	out := make([]byte, 64)
+	for i := 0; i < 64; i += 16 {
+		_, _ = x.Read(out[i : i+16])
+	}
+	want := testutil.MustHex(t, "1e415f1c5983aff2169217277d17bb538cd945a397ddec541f1ce41af2c1b74c3e8ccae2a4dae56c84a04c2385c03c15e8193bdf58737363321691c05462c8df")
+	if !bytes.Equal(out, want) {
+		t.Fatalf("TurboSHAKE128 mismatch: %x", out)
+	}
+
+	y, err := xof.TurboSHAKE256(0x1f)
+	if err != nil {
+		t.Fatalf("TurboSHAKE256 failed: %v", err)
+	}
+	_, _ = y.Read(out)
+	want = testutil.MustHex(t, "367a329dafea871c7802ec67f905ae13c57695dc2c6663c61035f59a18f8e7db11edc0e12e91ea60eb6b32df06dd7f002fbafabb6e13ec1cc20d995547600db0")
+	if !bytes.Equal(out, want) {
+		t.Fatalf("TurboSHAKE256 mismatch: %x", out)
+	}
+	if _, err := xof.TurboSHAKE128(0); err == nil {
+		t.Fatal("domain 0 accepted")
+	}
+}
+
+func TestKTXOF(t *testing.T) {
+	x := xof.KT128(nil)
+	out := make([]byte, 32)
+	_, _ = x.Read(out[:10])
+	_, _ = x.Read(out[10:])
+	want := testutil.MustHex(t, "1ac2d450fc3b4205d19da7bfca1b37513c0803577ac7167f06fe2ce1f0ef39e5")
+	if !bytes.Equal(out, want) {
+		t.Fatalf("KT128 mismatch: %x", out)
+	}
+	if _, err := x.Write([]byte{1}); err == nil {
+		t.Fatal("write after read accepted")
+	}
+	x.Reset()
+	_, _ = x.Read(out)
+	if !bytes.Equal(out, want) {
+		t.Fatal("KT128 mismatch after Reset")
+	}
+
+	y := xof.KT256(nil)
+	long := make([]byte, 64)
+	_, _ = y.Read(long)
+	want = testutil.MustHex(t, "b23d2e9cea9f4904e02bec06817fc10ce38ce8e93ef4c89e6537076af8646404e3e8b68107b8833a5d30490aa33482353fd4adc7148ecb782855003aaebde4a9")
+	if !bytes.Equal(long, want) {
+		t.Fatalf("KT256 mismatch: %x", long)
+	}
+}
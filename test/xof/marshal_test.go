@@ -0,0 +1,175 @@
+package xof_test
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"testing"
+
+	"github.com/AeonDave/cryptonite-go/xof"
+)
+
+type resumableXOF interface {
+	xof.Cloner
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+func resumableXOFs(t *testing.T) map[string]func() resumableXOF {
+	key := makeBytes(32, 0x40)
+	must := func(x xof.XOF, err error) resumableXOF {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("constructor: %v", err)
+		}
+		r, ok := x.(resumableXOF)
+		if !ok {
+			t.Fatalf("%T does not implement Cloner and encoding.BinaryMarshaler/BinaryUnmarshaler", x)
+		}
+		return r
+	}
+	return map[string]func() resumableXOF{
+		"SHAKE128":      func() resumableXOF { return must(xof.SHAKE128(), nil) },
+		"SHAKE256":      func() resumableXOF { return must(xof.SHAKE256(), nil) },
+		"TurboSHAKE128": func() resumableXOF { return must(xof.TurboSHAKE128(0x07)) },
+		"TurboSHAKE256": func() resumableXOF { return must(xof.TurboSHAKE256(0x1f)) },
+		"cSHAKE128":     func() resumableXOF { return must(xof.CSHAKE128([]byte("N"), []byte("S")), nil) },
+		"cSHAKE256":     func() resumableXOF { return must(xof.CSHAKE256(nil, nil), nil) },
+		"BLAKE2Xb":      func() resumableXOF { return must(xof.Blake2b(100, key)) },
+		"BLAKE2Xb-unknown": func() resumableXOF {
+			return must(xof.Blake2b(xof.Blake2bUnknown, nil))
+		},
+		"BLAKE2Xs":      func() resumableXOF { return must(xof.Blake2s(100, key[:16])) },
+		"BLAKE3":        func() resumableXOF { return must(xof.BLAKE3(), nil) },
+		"BLAKE3-keyed":  func() resumableXOF { return must(xof.BLAKE3Keyed(key)) },
+		"BLAKE3-derive": func() resumableXOF { return must(xof.BLAKE3DeriveKey("resume"), nil) },
+		"Xoodyak":       func() resumableXOF { return must(xof.Xoodyak(), nil) },
+		"KT128":         func() resumableXOF { return must(xof.KT128([]byte("resume")), nil) },
+		"KT256":         func() resumableXOF { return must(xof.KT256(nil), nil) },
+	}
+}
+
+func TestXOFMarshalResume(t *testing.T) {
+	msg := makeBytes(50_000, 3)
+	for name, newXOF := range resumableXOFs(t) {
+		ref := newXOF()
+		ref.Write(msg[:20_001])
+		ref.Write(msg[20_001:])
+		want := make([]byte, 100)
+		ref.Read(want[:40])
+		ref.Read(want[40:])
+
+		// Save while absorbing.
+		x := newXOF()
+		x.Write(msg[:20_001])
+		state, err := x.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary: %v", name, err)
+		}
+		x.Write([]byte("diverge"))
+		resumed := newXOF()
+		if err := resumed.UnmarshalBinary(state); err != nil {
+			t.Fatalf("%s: UnmarshalBinary: %v", name, err)
+		}
+		resumed.Write(msg[20_001:])
+		got := make([]byte, 100)
+		resumed.Read(got[:40])
+
+		// Save while squeezing.
+		state, err = resumed.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary after Read: %v", name, err)
+		}
+		again := newXOF()
+		if err := again.UnmarshalBinary(state); err != nil {
+			t.Fatalf("%s: UnmarshalBinary after Read: %v", name, err)
+		}
+		again.Read(got[40:])
+		if !bytes.Equal(got, want) {
+			t.Fatalf("%s: resumed output mismatch", name)
+		}
+	}
+}
+
+func TestXOFClone(t *testing.T) {
+	msg := makeBytes(30_000, 9)
+	for name, newXOF := range resumableXOFs(t) {
+		ref := newXOF()
+		ref.Write(msg)
+		// Xoodyak squeezes each Read separately, so the reference reads
+		// in the same pieces.
+		want := make([]byte, 90)
+		ref.Read(want[:30])
+		ref.Read(want[30:])
+
+		x := newXOF()
+		x.Write(msg)
+		head := make([]byte, 30)
+		x.Read(head)
+		c := x.Clone()
+		tail := make([]byte, 60)
+		c.Read(tail)
+		if !bytes.Equal(append(head, tail...), want) {
+			t.Fatalf("%s: clone output mismatch", name)
+		}
+		x.Read(tail)
+		if !bytes.Equal(tail, want[30:]) {
+			t.Fatalf("%s: original affected by clone", name)
+		}
+	}
+}
+
+func TestXOFMarshalRejectsMismatch(t *testing.T) {
+	key := makeBytes(32, 0x40)
+	turbo := func(domain byte) xof.XOF {
+		x, _ := xof.TurboSHAKE128(domain)
+		return x
+	}
+	blake2b := func(length uint32) xof.XOF {
+		x, _ := xof.Blake2b(length, key)
+		return x
+	}
+	cases := []struct {
+		name     string
+		from, to xof.XOF
+	}{
+		{"SHAKE128 into SHAKE256", xof.SHAKE128(), xof.SHAKE256()},
+		{"SHAKE128 into TurboSHAKE128", xof.SHAKE128(), turbo(0x1f)},
+		{"TurboSHAKE128 domain", turbo(0x07), turbo(0x0b)},
+		{"cSHAKE128 customization", xof.CSHAKE128(nil, []byte("a")), xof.CSHAKE128(nil, []byte("b"))},
+		{"BLAKE2Xb length", blake2b(64), blake2b(65)},
+		{"BLAKE3 into derive_key", xof.BLAKE3(), xof.BLAKE3DeriveKey("ctx")},
+		{"KT128 into KT256", xof.KT128(nil), xof.KT256(nil)},
+		{"KT128 customization", xof.KT128([]byte("a")), xof.KT128(nil)},
+		{"Xoodyak into SHAKE128", xof.Xoodyak(), xof.SHAKE128()},
+	}
+	for _, tc := range cases {
+		tc.from.Write([]byte("state"))
+		state, err := tc.from.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary: %v", tc.name, err)
+		}
+		err = tc.to.(encoding.BinaryUnmarshaler).UnmarshalBinary(state)
+		if !errors.Is(err, xof.ErrStateMismatch) {
+			t.Fatalf("%s: got %v, want ErrStateMismatch", tc.name, err)
+		}
+	}
+}
+
+func TestXOFMarshalRejectsCorruptState(t *testing.T) {
+	for name, newXOF := range resumableXOFs(t) {
+		x := newXOF()
+		x.Write(makeBytes(500, 1))
+		state, _ := x.MarshalBinary()
+		for _, bad := range [][]byte{
+			nil,
+			state[:len(state)-1],
+			append(append([]byte(nil), state...), 0),
+			append([]byte{'C', 'N', 'S', state[3] + 1}, state[4:]...),
+		} {
+			if err := newXOF().UnmarshalBinary(bad); !errors.Is(err, xof.ErrInvalidState) {
+				t.Fatalf("%s: got %v, want ErrInvalidState", name, err)
+			}
+		}
+	}
+}
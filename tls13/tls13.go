@@ -0,0 +1,314 @@
+// Package tls13 implements the TLS 1.3 key schedule (RFC 8446 section 7)
+// for protocols that reuse it outside crypto/tls, such as QUIC-like
+// transports.
+//
+// A KeySchedule walks the early, handshake and master secrets. Each stage
+// derives its traffic and exporter secrets from a transcript hash, and
+// TrafficKeys and TrafficCipher turn a traffic secret into AEAD keys and
+// per-record nonces for any aead.Aead.
+package tls13
+
+import (
+	"encoding/binary"
+	"errors"
+	stdhash "hash"
+
+	"github.com/AeonDave/cryptonite-go/aead"
+	"github.com/AeonDave/cryptonite-go/kdf"
+	"github.com/AeonDave/cryptonite-go/mac"
+)
+
+// IVSize is the size of TLS 1.3 record IVs and nonces.
+const IVSize = 12
+
+// Stage identifies the secret a KeySchedule currently holds.
+type Stage int
+
+const (
+	StageEarly Stage = iota
+	StageHandshake
+	StageMaster
+)
+
+var (
+	errStage       = errors.New("tls13: secret not available at the current key schedule stage")
+	errSequence    = errors.New("tls13: record sequence number exhausted")
+	errKeyLength   = errors.New("tls13: invalid AEAD key length")
+	errNilSchedule = errors.New("tls13: nil key schedule")
+)
+
+// KeySchedule is the TLS 1.3 key schedule for one connection. It is not safe
+// for concurrent use.
+type KeySchedule struct {
+	newHash   func() stdhash.Hash
+	emptyHash []byte
+	stage     Stage
+	secret    []byte
+}
+
+// NewKeySchedule starts a key schedule at the early secret,
+// HKDF-Extract(0, psk). A nil psk stands for the all-zero input used when no
+// pre-shared key is negotiated.
+func NewKeySchedule(newHash func() stdhash.Hash, psk []byte) *KeySchedule {
+	h := newHash()
+	if len(psk) == 0 {
+		psk = make([]byte, h.Size())
+	}
+	return &KeySchedule{
+		newHash:   newHash,
+		emptyHash: h.Sum(nil),
+		secret:    kdf.HKDFExtractWith(newHash, nil, psk),
+	}
+}
+
+// Stage returns the current stage.
+func (k *KeySchedule) Stage() Stage { return k.stage }
+
+// Secret returns a copy of the current early, handshake or master secret.
+func (k *KeySchedule) Secret() []byte { return append([]byte(nil), k.secret...) }
+
+// AdvanceHandshake mixes the (EC)DHE shared secret into the schedule,
+// moving from the early secret to the handshake secret. A nil sharedSecret
+// stands for the all-zero input of a psk_ke handshake, which has no (EC)DHE
+// exchange.
+func (k *KeySchedule) AdvanceHandshake(sharedSecret []byte) error {
+	return k.advance(StageEarly, sharedSecret)
+}
+
+// AdvanceMaster moves from the handshake secret to the master secret.
+func (k *KeySchedule) AdvanceMaster() error {
+	return k.advance(StageHandshake, nil)
+}
+
+// advance extracts the next secret from ikm, or from a zero string of the
+// hash length if ikm is empty.
+func (k *KeySchedule) advance(from Stage, ikm []byte) error {
+	if k == nil {
+		return errNilSchedule
+	}
+	if k.stage != from {
+		return errStage
+	}
+	if len(ikm) == 0 {
+		ikm = make([]byte, len(k.emptyHash))
+	}
+	derived, err := kdf.DeriveSecret(k.newHash, k.secret, "derived", k.emptyHash)
+	if err != nil {
+		return err
+	}
+	clear(k.secret)
+	k.secret = kdf.HKDFExtractWith(k.newHash, derived, ikm)
+	k.stage++
+	return nil
+}
+
+func (k *KeySchedule) derive(stage Stage, label string, transcriptHash []byte) ([]byte, error) {
+	if k == nil {
+		return nil, errNilSchedule
+	}
+	if k.stage != stage {
+		return nil, errStage
+	}
+	return kdf.DeriveSecret(k.newHash, k.secret, label, transcriptHash)
+}
+
+// ExternalBinderKey returns the binder key for an external PSK.
+func (k *KeySchedule) ExternalBinderKey() ([]byte, error) {
+	return k.derive(StageEarly, "ext binder", k.emptyHash)
+}
+
+// ResumptionBinderKey returns the binder key for a resumption PSK.
+func (k *KeySchedule) ResumptionBinderKey() ([]byte, error) {
+	return k.derive(StageEarly, "res binder", k.emptyHash)
+}
+
+// ClientEarlyTrafficSecret derives the 0-RTT traffic secret from the
+// ClientHello transcript hash.
+func (k *KeySchedule) ClientEarlyTrafficSecret(transcriptHash []byte) ([]byte, error) {
+	return k.derive(StageEarly, "c e traffic", transcriptHash)
+}
+
+// EarlyExporterMasterSecret derives the early exporter secret from the
+// ClientHello transcript hash.
+func (k *KeySchedule) EarlyExporterMasterSecret(transcriptHash []byte) ([]byte, error) {
+	return k.derive(StageEarly, "e exp master", transcriptHash)
+}
+
+// ClientHandshakeTrafficSecret derives the client handshake traffic secret
+// from the ClientHello...ServerHello transcript hash.
+func (k *KeySchedule) ClientHandshakeTrafficSecret(transcriptHash []byte) ([]byte, error) {
+	return k.derive(StageHandshake, "c hs traffic", transcriptHash)
+}
+
+// ServerHandshakeTrafficSecret derives the server handshake traffic secret
+// from the ClientHello...ServerHello transcript hash.
+func (k *KeySchedule) ServerHandshakeTrafficSecret(transcriptHash []byte) ([]byte, error) {
+	return k.derive(StageHandshake, "s hs traffic", transcriptHash)
+}
+
+// ClientApplicationTrafficSecret derives the first client application
+// traffic secret from the ClientHello...server Finished transcript hash.
+func (k *KeySchedule) ClientApplicationTrafficSecret(transcriptHash []byte) ([]byte, error) {
+	return k.derive(StageMaster, "c ap traffic", transcriptHash)
+}
+
+// ServerApplicationTrafficSecret derives the first server application
+// traffic secret from the ClientHello...server Finished transcript hash.
+func (k *KeySchedule) ServerApplicationTrafficSecret(transcriptHash []byte) ([]byte, error) {
+	return k.derive(StageMaster, "s ap traffic", transcriptHash)
+}
+
+// ExporterMasterSecret derives the exporter secret from the
+// ClientHello...server Finished transcript hash.
+func (k *KeySchedule) ExporterMasterSecret(transcriptHash []byte) ([]byte, error) {
+	return k.derive(StageMaster, "exp master", transcriptHash)
+}
+
+// ResumptionMasterSecret derives the resumption secret from the
+// ClientHello...client Finished transcript hash.
+func (k *KeySchedule) ResumptionMasterSecret(transcriptHash []byte) ([]byte, error) {
+	return k.derive(StageMaster, "res master", transcriptHash)
+}
+
+// Destroy wipes the current secret.
+func (k *KeySchedule) Destroy() {
+	if k == nil {
+		return
+	}
+	clear(k.secret)
+	k.secret = nil
+}
+
+// TrafficKeys derives the AEAD key of keyLen bytes and the 12-byte IV for a
+// traffic secret ("key" and "iv" labels).
+func TrafficKeys(newHash func() stdhash.Hash, trafficSecret []byte, keyLen int) (key, iv []byte, err error) {
+	if keyLen <= 0 {
+		return nil, nil, errKeyLength
+	}
+	if key, err = kdf.HKDFExpandLabel(newHash, trafficSecret, "key", nil, keyLen); err != nil {
+		return nil, nil, err
+	}
+	if iv, err = kdf.HKDFExpandLabel(newHash, trafficSecret, "iv", nil, IVSize); err != nil {
+		return nil, nil, err
+	}
+	return key, iv, nil
+}
+
+// NextTrafficSecret derives application_traffic_secret_N+1 for a KeyUpdate.
+func NextTrafficSecret(newHash func() stdhash.Hash, trafficSecret []byte) ([]byte, error) {
+	return kdf.HKDFExpandLabel(newHash, trafficSecret, "traffic upd", nil, newHash().Size())
+}
+
+// FinishedVerifyData computes the Finished verify_data from the sender's
+// handshake traffic secret and the transcript hash up to, but excluding,
+// the Finished message.
+func FinishedVerifyData(newHash func() stdhash.Hash, baseKey, transcriptHash []byte) ([]byte, error) {
+	finishedKey, err := kdf.HKDFExpandLabel(newHash, baseKey, "finished", nil, newHash().Size())
+	if err != nil {
+		return nil, err
+	}
+	m := mac.NewHMAC(newHash, finishedKey)
+	m.Write(transcriptHash)
+	return m.Sum(nil), nil
+}
+
+// ResumptionPSK derives the PSK for a NewSessionTicket nonce.
+func ResumptionPSK(newHash func() stdhash.Hash, resumptionMasterSecret, ticketNonce []byte) ([]byte, error) {
+	return kdf.HKDFExpandLabel(newHash, resumptionMasterSecret, "resumption", ticketNonce, newHash().Size())
+}
+
+// ExportKeyingMaterial implements the TLS 1.3 exporter (RFC 8446 section
+// 7.5) over an exporter or early exporter master secret.
+func ExportKeyingMaterial(newHash func() stdhash.Hash, exporterSecret []byte, label string, context []byte, length int) ([]byte, error) {
+	h := newHash()
+	secret, err := kdf.DeriveSecret(newHash, exporterSecret, label, h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	h.Write(context)
+	return kdf.HKDFExpandLabel(newHash, secret, "exporter", h.Sum(nil), length)
+}
+
+// TrafficCipher protects records under one traffic secret: it derives the
+// key and IV, forms each nonce by XORing the 64-bit record sequence number
+// into the IV and advances the sequence number after every record. It is
+// not safe for concurrent use.
+type TrafficCipher struct {
+	aead    aead.Aead
+	newHash func() stdhash.Hash
+	keyLen  int
+	secret  []byte
+	key, iv []byte
+	seq     uint64
+	done    bool
+}
+
+// NewTrafficCipher returns a TrafficCipher for a, whose keys are keyLen
+// bytes (16 for AES-128-GCM, 32 for AES-256-GCM and ChaCha20-Poly1305).
+func NewTrafficCipher(a aead.Aead, newHash func() stdhash.Hash, trafficSecret []byte, keyLen int) (*TrafficCipher, error) {
+	c := &TrafficCipher{aead: a, newHash: newHash, keyLen: keyLen}
+	if err := c.rekey(append([]byte(nil), trafficSecret...)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *TrafficCipher) rekey(secret []byte) error {
+	key, iv, err := TrafficKeys(c.newHash, secret, c.keyLen)
+	if err != nil {
+		return err
+	}
+	clear(c.secret)
+	clear(c.key)
+	c.secret, c.key, c.iv, c.seq, c.done = secret, key, iv, 0, false
+	return nil
+}
+
+// Seal encrypts the next record with additional data ad, which in TLS 1.3
+// is the record header.
+func (c *TrafficCipher) Seal(ad, plaintext []byte) ([]byte, error) {
+	nonce, err := c.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+	return c.aead.Encrypt(c.key, nonce, ad, plaintext)
+}
+
+// Open decrypts the next record. A failed record still consumes its
+// sequence number; TLS tears the connection down after any failure.
+func (c *TrafficCipher) Open(ad, ciphertext []byte) ([]byte, error) {
+	nonce, err := c.nextNonce()
+	if err != nil {
+		return nil, err
+	}
+	return c.aead.Decrypt(c.key, nonce, ad, ciphertext)
+}
+
+// Sequence returns the sequence number of the next record.
+func (c *TrafficCipher) Sequence() uint64 { return c.seq }
+
+// Update switches to the next traffic secret (a KeyUpdate) and resets the
+// sequence number.
+func (c *TrafficCipher) Update() error {
+	next, err := NextTrafficSecret(c.newHash, c.secret)
+	if err != nil {
+		return err
+	}
+	return c.rekey(next)
+}
+
+func (c *TrafficCipher) nextNonce() ([]byte, error) {
+	if c.done {
+		return nil, errSequence
+	}
+	nonce := make([]byte, IVSize)
+	copy(nonce, c.iv)
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], c.seq)
+	for i := range seq {
+		nonce[IVSize-8+i] ^= seq[i]
+	}
+	c.seq++
+	c.done = c.seq == 0
+	return nonce, nil
+}
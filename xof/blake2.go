@@ -19,7 +19,11 @@ const Blake2sUnknown = uint32(blake2s.OutputLengthUnknown)
 // Blake2b constructs a BLAKE2b extendable-output instance. Pass Blake2bUnknown
 // when the desired output length is not known ahead of time.
 func Blake2b(length uint32, key []byte) (XOF, error) {
-	return blake2b.NewXOF(length, key)
+	x, err := blake2b.NewXOF(length, key)
+	if err != nil {
+		return nil, err
+	}
+	return blake2bXOF{x}, nil
 }
 
 // Blake2s constructs a BLAKE2s extendable-output instance. Pass Blake2sUnknown
@@ -28,5 +32,21 @@ func Blake2s(length uint32, key []byte) (XOF, error) {
 	if length != Blake2sUnknown && length > math.MaxUint16 {
 		return nil, errors.New("xof: blake2s XOF length too large")
 	}
-	return blake2s.NewXOF(uint16(length), key)
+	x, err := blake2s.NewXOF(uint16(length), key)
+	if err != nil {
+		return nil, err
+	}
+	return blake2sXOF{x}, nil
 }
+
+// blake2bXOF and blake2sXOF adapt the internal BLAKE2X states, whose Clone
+// methods return their own interface types, to Cloner.
+type blake2bXOF struct{ blake2b.XOF }
+
+// Clone returns an independent copy of the XOF.
+func (x blake2bXOF) Clone() XOF { return blake2bXOF{x.XOF.Clone()} }
+
+type blake2sXOF struct{ blake2s.XOF }
+
+// Clone returns an independent copy of the XOF.
+func (x blake2sXOF) Clone() XOF { return blake2sXOF{x.XOF.Clone()} }
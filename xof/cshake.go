@@ -1,6 +1,12 @@
 package xof
 
-import "github.com/AeonDave/cryptonite-go/internal/keccak"
+import (
+	"bytes"
+	"math"
+
+	"github.com/AeonDave/cryptonite-go/internal/keccak"
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
 
 const (
 	domainCSHAKE byte = 0x04
@@ -80,6 +86,46 @@ func SumCSHAKE256(functionName, customization, msg []byte, outLen int) []byte {
 	return out
 }
 
+func (x *cshakeXOF) alg() string {
+	if x.rate == 168 {
+		return "cSHAKE128"
+	}
+	return "cSHAKE256"
+}
+
+// MarshalBinary encodes the function name and customization prefix together
+// with the sponge state.
+func (x *cshakeXOF) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder(x.alg())
+	e.Bytes(x.prefix)
+	x.sponge.Encode(e)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on an instance
+// with the same function name and customization string.
+func (x *cshakeXOF) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, x.alg())
+	if err != nil {
+		return err
+	}
+	prefix := d.Bytes(math.MaxInt32)
+	d.Check(bytes.Equal(prefix, x.prefix), marshal.ErrMismatch)
+	s := x.sponge
+	s.Decode(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	x.sponge = s
+	return nil
+}
+
+// Clone returns an independent copy of the XOF.
+func (x *cshakeXOF) Clone() XOF {
+	c := *x
+	return &c
+}
+
 func cloneBytes(in []byte) []byte {
 	if len(in) == 0 {
 		return nil
@@ -0,0 +1,111 @@
+package xof
+
+import (
+	"errors"
+
+	"github.com/AeonDave/cryptonite-go/internal/keccak"
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
+var errKTWriteAfterRead = errors.New("xof: KangarooTwelve write after read")
+
+// TurboSHAKE128 returns a TurboSHAKE128 extendable-output function with the
+// given domain separation byte (0x01..0x7f; 0x1f is the default).
+func TurboSHAKE128(domain byte) (XOF, error) {
+	return newTurboSHAKE(keccak.TurboSHAKE128Rate, domain)
+}
+
+// TurboSHAKE256 returns a TurboSHAKE256 extendable-output function with the
+// given domain separation byte (0x01..0x7f; 0x1f is the default).
+func TurboSHAKE256(domain byte) (XOF, error) {
+	return newTurboSHAKE(keccak.TurboSHAKE256Rate, domain)
+}
+
+func newTurboSHAKE(rate int, domain byte) (XOF, error) {
+	if domain == 0 || domain > 0x7f {
+		return nil, errors.New("xof: TurboSHAKE domain byte must be in 0x01..0x7f")
+	}
+	x := &shakeXOF{rate: rate, ds: domain, rounds: keccak.TurboRounds}
+	x.Reset()
+	return x, nil
+}
+
+type ktXOF struct {
+	state  *keccak.KangarooTwelve
+	reader *keccak.Reader
+}
+
+// KT128 returns a KangarooTwelve (KT128) extendable-output function with the
+// given customization string. Writes are rejected once output has been read;
+// call Reset to start over.
+func KT128(customization []byte) XOF {
+	return &ktXOF{state: keccak.NewKangarooTwelve(keccak.TurboSHAKE128Rate, customization)}
+}
+
+// KT256 returns a KT256 extendable-output function with the given
+// customization string.
+func KT256(customization []byte) XOF {
+	return &ktXOF{state: keccak.NewKangarooTwelve(keccak.TurboSHAKE256Rate, customization)}
+}
+
+func (x *ktXOF) Write(p []byte) (int, error) {
+	if x.reader != nil {
+		return 0, errKTWriteAfterRead
+	}
+	x.state.Write(p)
+	return len(p), nil
+}
+
+func (x *ktXOF) Read(out []byte) (int, error) {
+	if x.reader == nil {
+		x.reader = x.state.XOF()
+	}
+	return x.reader.Read(out)
+}
+
+func (x *ktXOF) Reset() {
+	x.state.Reset()
+	x.reader = nil
+}
+
+// MarshalBinary encodes the tree state and, once output has been read, the
+// output position.
+func (x *ktXOF) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder("KangarooTwelve-XOF")
+	x.state.Encode(e)
+	e.Bool(x.reader != nil)
+	if x.reader != nil {
+		x.reader.Encode(e)
+	}
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on an instance
+// of the same function and customization string.
+func (x *ktXOF) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, "KangarooTwelve-XOF")
+	if err != nil {
+		return err
+	}
+	state := x.state.Clone()
+	state.Decode(d)
+	var reader *keccak.Reader
+	if d.Bool() {
+		reader = keccak.DecodeReader(d, state.Rate())
+	}
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	x.state, x.reader = state, reader
+	return nil
+}
+
+// Clone returns an independent copy of the XOF, including its output
+// position.
+func (x *ktXOF) Clone() XOF {
+	c := &ktXOF{state: x.state.Clone()}
+	if x.reader != nil {
+		c.reader = x.reader.Clone()
+	}
+	return c
+}
@@ -1,11 +1,17 @@
 package xof
 
-import "github.com/AeonDave/cryptonite-go/internal/keccak"
+import (
+	"github.com/AeonDave/cryptonite-go/internal/keccak"
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
 
 type shakeXOF struct {
 	sponge keccak.Sponge
 	rate   int
 	ds     byte
+	// rounds selects a reduced-round permutation (TurboSHAKE); zero means
+	// the full Keccak-f[1600].
+	rounds int
 }
 
 func newShakeXOF(rate int, ds byte) *shakeXOF {
@@ -25,7 +31,7 @@ func (x *shakeXOF) Read(out []byte) (int, error) {
 }
 
 func (x *shakeXOF) Reset() {
-	x.sponge.Init(x.rate, x.ds)
+	x.sponge.InitRounds(x.rate, x.ds, x.rounds)
 }
 
 func newSHAKEXOF(rate int, ds byte) XOF {
@@ -34,3 +40,44 @@ func newSHAKEXOF(rate int, ds byte) XOF {
 
 func SHAKE128() XOF { return newSHAKEXOF(168, 0x1f) }
 func SHAKE256() XOF { return newSHAKEXOF(136, 0x1f) }
+
+func (x *shakeXOF) alg() string {
+	name := "SHAKE"
+	if x.rounds != 0 {
+		name = "TurboSHAKE"
+	}
+	if x.rate == keccak.TurboSHAKE128Rate {
+		return name + "128"
+	}
+	return name + "256"
+}
+
+// MarshalBinary encodes the sponge state, including the output position once
+// reading has started.
+func (x *shakeXOF) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder(x.alg())
+	x.sponge.Encode(e)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary on an instance
+// of the same function and domain byte.
+func (x *shakeXOF) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, x.alg())
+	if err != nil {
+		return err
+	}
+	s := x.sponge
+	s.Decode(d)
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	x.sponge = s
+	return nil
+}
+
+// Clone returns an independent copy of the XOF.
+func (x *shakeXOF) Clone() XOF {
+	c := *x
+	return &c
+}
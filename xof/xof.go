@@ -1,13 +1,59 @@
 package xof
 
+import (
+	"encoding"
+
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+)
+
 // XOF represents an extendable-output function (XOF) backed by one of the
 // primitives exposed by the library. It mirrors the behaviour of hashing
 // XOFs such as SHAKE, BLAKE2X, or Xoodyak Cyclist.
 //
 // Implementations are expected to be stateful objects supporting absorbing
 // (Write), squeezing (Read), and resetting to the initial state.
+//
+// Every XOF returned by this package also implements Cloner and
+// encoding.BinaryMarshaler/BinaryUnmarshaler, so a computation can be forked
+// or saved and resumed later, including mid-output.
 type XOF interface {
 	Reset()
 	Write([]byte) (int, error)
 	Read([]byte) (int, error)
 }
+
+// Cloner is an XOF that can be copied. Clone returns an independent instance
+// in the same state, including the output position once reading has started.
+type Cloner interface {
+	XOF
+	Clone() XOF
+}
+
+var (
+	// ErrInvalidState is returned by UnmarshalBinary for truncated, corrupted
+	// or unsupported state encodings.
+	ErrInvalidState = marshal.ErrInvalid
+	// ErrStateMismatch is returned by UnmarshalBinary when the encoding was
+	// produced by a different algorithm or configuration (rate, domain byte,
+	// customization, output length) than the receiving instance. Keys are not
+	// part of the encoding: restore keyed states into an instance created
+	// with the same key.
+	ErrStateMismatch = marshal.ErrMismatch
+)
+
+// resumable is the set of interfaces every XOF in this package implements.
+type resumable interface {
+	Cloner
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+var (
+	_ resumable = (*shakeXOF)(nil)
+	_ resumable = (*cshakeXOF)(nil)
+	_ resumable = blake2bXOF{}
+	_ resumable = blake2sXOF{}
+	_ resumable = (*blake3XOF)(nil)
+	_ resumable = (*xoodyakXOF)(nil)
+	_ resumable = (*ktXOF)(nil)
+)
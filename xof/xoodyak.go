@@ -1,6 +1,9 @@
 package xof
 
-import "github.com/AeonDave/cryptonite-go/internal/xoodyak"
+import (
+	"github.com/AeonDave/cryptonite-go/internal/marshal"
+	"github.com/AeonDave/cryptonite-go/internal/xoodyak"
+)
 
 type xoodyakXOF struct {
 	inst    xoodyak.Instance
@@ -38,3 +41,33 @@ func (x *xoodyakXOF) Read(p []byte) (int, error) {
 
 // Xoodyak returns a new Xoodyak extendable-output instance.
 func Xoodyak() XOF { return newXoodyakXOF() }
+
+// MarshalBinary encodes the Cyclist state and whether squeezing has begun.
+func (x *xoodyakXOF) MarshalBinary() ([]byte, error) {
+	e := marshal.NewEncoder("Xoodyak-XOF")
+	x.inst.Encode(e)
+	e.Bool(x.started)
+	return e.Data(), nil
+}
+
+// UnmarshalBinary restores a state produced by MarshalBinary.
+func (x *xoodyakXOF) UnmarshalBinary(b []byte) error {
+	d, err := marshal.NewDecoder(b, "Xoodyak-XOF")
+	if err != nil {
+		return err
+	}
+	inst := x.inst
+	inst.Decode(d)
+	started := d.Bool()
+	if err := d.Finish(); err != nil {
+		return err
+	}
+	x.inst, x.started = inst, started
+	return nil
+}
+
+// Clone returns an independent copy of the XOF.
+func (x *xoodyakXOF) Clone() XOF {
+	c := *x
+	return &c
+}